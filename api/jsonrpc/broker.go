@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	_ "github.com/ethereum/go-ethereum/eth/tracers/js"
 	_ "github.com/ethereum/go-ethereum/eth/tracers/native"
@@ -22,6 +24,9 @@ import (
 	"github.com/axiomesh/axiom-ledger/pkg/repo"
 )
 
+// shutdownTimeout bounds the wait for in-flight requests when the service stops
+const shutdownTimeout = 10 * time.Second
+
 type ChainBrokerService struct {
 	rep *repo.Repo
 
@@ -30,6 +35,8 @@ type ChainBrokerService struct {
 
 	server              *rpc.Server
 	wsServer            *rpc.Server
+	httpServer          *http.Server
+	wsHTTPServer        *http.Server
 	logger              logrus.FieldLogger
 	rateLimiterForRead  *ratelimiter.JRateLimiter
 	rateLimiterForWrite *ratelimiter.JRateLimiter
@@ -121,12 +128,15 @@ func (cbs *ChainBrokerService) Start() error {
 	wsHandler := node.NewWSHandlerStack(cbs.wsServer.WebsocketHandler([]string{"*"}), []byte(""))
 	wsRouter.Handle("/", wsHandler)
 
+	cbs.httpServer = &http.Server{Addr: fmt.Sprintf(":%d", cbs.rep.Config.Port.JsonRpc), Handler: cors.Default().Handler(router)}
+	cbs.wsHTTPServer = &http.Server{Addr: fmt.Sprintf(":%d", cbs.rep.Config.Port.WebSocket), Handler: cors.Default().Handler(wsRouter)}
+
 	go func() {
 		cbs.logger.WithFields(logrus.Fields{
 			"port": cbs.rep.Config.Port.JsonRpc,
 		}).Info("JSON-RPC service started")
 
-		if err := http.ListenAndServe(fmt.Sprintf(":%d", cbs.rep.Config.Port.JsonRpc), cors.Default().Handler(router)); err != nil {
+		if err := cbs.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			cbs.logger.WithFields(logrus.Fields{
 				"error": err.Error(),
 			}).Fatalf("Failed to start JSON_RPC service: %s", err.Error())
@@ -139,7 +149,7 @@ func (cbs *ChainBrokerService) Start() error {
 			"port": cbs.rep.Config.Port.WebSocket,
 		}).Info("Websocket service started")
 
-		if err := http.ListenAndServe(fmt.Sprintf(":%d", cbs.rep.Config.Port.WebSocket), cors.Default().Handler(wsRouter)); err != nil {
+		if err := cbs.wsHTTPServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			cbs.logger.WithFields(logrus.Fields{
 				"error": err.Error(),
 			}).Fatalf("Failed to start websocket service: %s", err.Error())
@@ -150,10 +160,27 @@ func (cbs *ChainBrokerService) Start() error {
 	return nil
 }
 
+// Stop stops accepting requests and waits for the in-flight http requests to finish, websocket connections are
+// closed by the rpc server.
 func (cbs *ChainBrokerService) Stop() error {
 	cbs.cancel()
 
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	for _, srv := range []*http.Server{cbs.httpServer, cbs.wsHTTPServer} {
+		if srv == nil {
+			continue
+		}
+		if err := srv.Shutdown(ctx); err != nil {
+			cbs.logger.WithFields(logrus.Fields{
+				"addr": srv.Addr,
+				"err":  err,
+			}).Warning("Wait for in-flight json-rpc requests timeout")
+		}
+	}
+
 	cbs.server.Stop()
+	cbs.wsServer.Stop()
 
 	cbs.logger.Info("JSON-RPC service stopped")
 
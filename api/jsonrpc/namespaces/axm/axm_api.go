@@ -29,10 +29,14 @@ func (api *AxmAPI) GetIncentiveAddress() (res common.Address, err error) {
 
 func (api *AxmAPI) Status() any {
 	syncStatus := make(map[string]string)
-	ready, status := api.api.Broker().ConsensusReady()
-	if !ready {
+	// the tx pool is reported separately, a full pool rejects new txs but the consensus keeps working
+	syncStatus["tx_pool"] = "normal"
+	if api.api.Broker().TxPoolFull() {
+		syncStatus["tx_pool"] = "full"
+	}
+	if err := api.api.Broker().ConsensusReady(); err != nil {
 		syncStatus["status"] = "abnormal"
-		syncStatus["error_msg"] = status
+		syncStatus["error_msg"] = err.Error()
 		return syncStatus
 	}
 	syncStatus["status"] = "normal"
@@ -27,22 +27,32 @@ import (
 	"github.com/axiomesh/axiom-ledger/internal/executor"
 	syscommon "github.com/axiomesh/axiom-ledger/internal/executor/system/common"
 	"github.com/axiomesh/axiom-ledger/internal/ledger"
-	"github.com/axiomesh/axiom-ledger/internal/ledger/utils"
 	"github.com/axiomesh/axiom-ledger/pkg/repo"
 )
 
 // BlockChain API provides an API for accessing blockchain data
 type BlockChainAPI struct {
-	ctx    context.Context
-	cancel context.CancelFunc
-	rep    *repo.Repo
-	api    api.CoreAPI
-	logger logrus.FieldLogger
+	ctx       context.Context
+	cancel    context.CancelFunc
+	rep       *repo.Repo
+	api       api.CoreAPI
+	logger    logrus.FieldLogger
+	gasBudget *callGasBudget
+	// storageFilter restricts the storage reads, e.g. eth_getStorageAt
+	storageFilter *storageReadFilter
 }
 
 func NewBlockChainAPI(rep *repo.Repo, api api.CoreAPI, logger logrus.FieldLogger) *BlockChainAPI {
 	ctx, cancel := context.WithCancel(context.Background())
-	return &BlockChainAPI{ctx: ctx, cancel: cancel, rep: rep, api: api, logger: logger}
+	return &BlockChainAPI{
+		ctx:           ctx,
+		cancel:        cancel,
+		rep:           rep,
+		api:           api,
+		logger:        logger,
+		gasBudget:     newCallGasBudget(rep.Config.JsonRPC.CallGasBudget),
+		storageFilter: newStorageReadFilter(rep.Config.JsonRPC.StorageReadFilter),
+	}
 }
 
 // ChainId returns the chain's identifier in hex format
@@ -127,62 +137,59 @@ func (api *BlockChainAPI) GetProof(address common.Address, storageKeys []string,
 		}
 	}(time.Now())
 
-	stateLedger, err := getStateLedgerAt(api.api, blockNrOrHash)
+	meta, err := api.api.Chain().Meta()
 	if err != nil {
 		return nil, err
 	}
-	addr := types.NewAddress(address.Bytes())
-
-	// construct account proof
-	acc := stateLedger.GetOrCreateAccount(addr)
-	rawAccountProof, err := stateLedger.Prove(common.Hash{}, utils.CompositeAccountKey(addr))
+	blockHeader, err := getBlockHeaderAt(api.api, meta, blockNrOrHash)
 	if err != nil {
 		return nil, err
 	}
-	var accountProof []string
-	for _, proof := range rawAccountProof.Proof {
-		accountProof = append(accountProof, base64.StdEncoding.EncodeToString(proof))
-	}
-	ret = &AccountResult{
-		Address:      address,
-		Nonce:        (ethhexutil.Uint64)(acc.GetNonce()),
-		Balance:      (*ethhexutil.Big)(acc.GetBalance()),
-		CodeHash:     common.BytesToHash(acc.CodeHash()),
-		StorageHash:  acc.GetStorageRoot(),
-		AccountProof: accountProof,
-	}
 
-	// construct storage proof
 	keys := make([]common.Hash, len(storageKeys))
+	hashKeys := make([][]byte, len(storageKeys))
 	for i, hexKey := range storageKeys {
-		var err error
 		keys[i], err = hexutil.DecodeHash(hexKey)
 		if err != nil {
 			return nil, err
 		}
-	}
-	for _, key := range keys {
-		hashKey := crypto.Keccak256(key.Bytes())
-		rawStorageProof, err := stateLedger.Prove(acc.GetStorageRoot(), utils.CompositeStorageKey(addr, hashKey))
-		if err != nil {
+		if err = api.storageFilter.check(address, keys[i]); err != nil {
 			return nil, err
 		}
-		var storageProof []string
-		for _, proof := range rawStorageProof.Proof {
-			storageProof = append(storageProof, base64.StdEncoding.EncodeToString(proof))
-		}
-		evmStateDB := &ledger.EvmStateDBAdaptor{StateLedger: stateLedger}
-		storageResult := StorageResult{
-			Key:   hexutil.Encode(key[:]),
-			Value: (*ethhexutil.Big)(evmStateDB.GetState(addr.ETHAddress(), common.BytesToHash(hashKey)).Big()),
-			Proof: storageProof,
-		}
-		ret.StorageProof = append(ret.StorageProof, storageResult)
+		hashKeys[i] = crypto.Keccak256(keys[i].Bytes())
+	}
+	proof, err := api.api.Broker().GetViewStateLedger().GetProof(blockHeader, types.NewAddress(address.Bytes()), hashKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	ret = &AccountResult{
+		Address:      address,
+		Nonce:        (ethhexutil.Uint64)(proof.Nonce),
+		Balance:      (*ethhexutil.Big)(proof.Balance),
+		CodeHash:     common.BytesToHash(proof.CodeHash),
+		StorageHash:  proof.StorageRoot,
+		AccountProof: encodeProof(proof.AccountProof),
+	}
+	for i, storageProof := range proof.StorageProof {
+		ret.StorageProof = append(ret.StorageProof, StorageResult{
+			Key:   hexutil.Encode(keys[i][:]),
+			Value: (*ethhexutil.Big)(common.BytesToHash(storageProof.Value).Big()),
+			Proof: encodeProof(storageProof.Proof),
+		})
 	}
 
 	return ret, nil
 }
 
+func encodeProof(proof [][]byte) []string {
+	var res []string
+	for _, node := range proof {
+		res = append(res, base64.StdEncoding.EncodeToString(node))
+	}
+	return res
+}
+
 // GetBlockByNumber returns the block identified by number.
 func (api *BlockChainAPI) GetBlockByNumber(blockNum rpctypes.BlockNumber, fullTx bool) (ret map[string]any, err error) {
 	defer func(start time.Time) {
@@ -270,12 +277,15 @@ func (api *BlockChainAPI) GetStorageAt(address common.Address, key string, block
 
 	api.logger.Debugf("eth_getStorageAt, address: %s, key: %s", address, key)
 
-	stateLedger, err := getStateLedgerAt(api.api, blockNrOrHash)
+	hash, err := hexutil.DecodeHash(key)
 	if err != nil {
 		return nil, err
 	}
+	if err = api.storageFilter.check(address, hash); err != nil {
+		return nil, err
+	}
 
-	hash, err := hexutil.DecodeHash(key)
+	stateLedger, err := getStateLedgerAt(api.api, blockNrOrHash)
 	if err != nil {
 		return nil, err
 	}
@@ -300,7 +310,7 @@ func (api *BlockChainAPI) Call(args types.CallArgs, blockNrOrHash *rpctypes.Bloc
 
 	api.logger.Debugf("eth_call, args: %v, state overrides: %v, block overrides: %v", args, overrides, blockOverrides)
 
-	receipt, err := DoCall(api.ctx, blockNrOrHash, overrides, blockOverrides, api.api, args, api.rep.Config.JsonRPC.EVMTimeout.ToDuration(), api.rep.Config.JsonRPC.GasCap, api.logger)
+	receipt, err := DoCall(api.ctx, blockNrOrHash, overrides, blockOverrides, api.api, args, api.rep.Config.JsonRPC.EVMTimeout.ToDuration(), api.rep.Config.JsonRPC.GasCap, api.gasBudget, api.logger)
 	if err != nil {
 		return nil, err
 	}
@@ -313,7 +323,7 @@ func (api *BlockChainAPI) Call(args types.CallArgs, blockNrOrHash *rpctypes.Bloc
 }
 
 // DoCall todo call with historical ledger
-func DoCall(ctx context.Context, blockNrOrHash *rpctypes.BlockNumberOrHash, overrides *tracers.StateOverride, blockOverrides *tracers.BlockOverrides, api api.CoreAPI, args types.CallArgs, timeout time.Duration, globalGasCap uint64, logger logrus.FieldLogger) (*core.ExecutionResult, error) {
+func DoCall(ctx context.Context, blockNrOrHash *rpctypes.BlockNumberOrHash, overrides *tracers.StateOverride, blockOverrides *tracers.BlockOverrides, api api.CoreAPI, args types.CallArgs, timeout time.Duration, globalGasCap uint64, gasBudget *callGasBudget, logger logrus.FieldLogger) (*core.ExecutionResult, error) {
 	defer func(start time.Time) { logger.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
 	var cancel context.CancelFunc
@@ -329,6 +339,10 @@ func DoCall(ctx context.Context, blockNrOrHash *rpctypes.BlockNumberOrHash, over
 	if err != nil {
 		return nil, err
 	}
+	if err := gasBudget.acquire(msg.GasLimit); err != nil {
+		return nil, err
+	}
+	defer gasBudget.release(msg.GasLimit)
 
 	// use copy state ledger to call
 	stateLedger, err := getStateLedgerAt(api, blockNrOrHash)
@@ -456,7 +470,7 @@ func (api *BlockChainAPI) EstimateGas(args types.CallArgs, blockNrOrHash *rpctyp
 		blockOveride := tracers.BlockOverrides{
 			Time: &t,
 		}
-		result, err := DoCall(api.ctx, blockNrOrHash, nil, &blockOveride, api.api, args, api.rep.Config.JsonRPC.EVMTimeout.ToDuration(), api.rep.Config.JsonRPC.GasCap, api.logger)
+		result, err := DoCall(api.ctx, blockNrOrHash, nil, &blockOveride, api.api, args, api.rep.Config.JsonRPC.EVMTimeout.ToDuration(), api.rep.Config.JsonRPC.GasCap, api.gasBudget, api.logger)
 		if err != nil {
 			if errors.Is(err, core.ErrIntrinsicGas) {
 				return true, nil, nil // Special case, raise gas limit
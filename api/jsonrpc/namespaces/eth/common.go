@@ -19,13 +19,27 @@ var (
 )
 
 func getStateLedgerAt(api api.CoreAPI, blockNrOrHash *rpctypes.BlockNumberOrHash) (ledger.StateLedger, error) {
-	var blockHeader *types.BlockHeader
-	var err error
-
 	meta, err := api.Chain().Meta()
 	if err != nil {
 		return nil, err
 	}
+	blockHeader, err := getBlockHeaderAt(api, meta, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+
+	enableSnapshot := blockHeader.Number == meta.Height
+	lg, err := api.Broker().GetViewStateLedger().NewLimitedView(blockHeader, enableSnapshot)
+	if err != nil {
+		return nil, fmt.Errorf("GetViewStateLedger error: %v", err)
+	}
+	return lg, nil
+}
+
+// getBlockHeaderAt returns the header of the block identified by blockNrOrHash, nil means the latest block
+func getBlockHeaderAt(api api.CoreAPI, meta *types.ChainMeta, blockNrOrHash *rpctypes.BlockNumberOrHash) (*types.BlockHeader, error) {
+	var blockHeader *types.BlockHeader
+	var err error
 
 	if blockNrOrHash != nil {
 		if blockNumber, ok := blockNrOrHash.Number(); ok {
@@ -54,12 +68,7 @@ func getStateLedgerAt(api api.CoreAPI, blockNrOrHash *rpctypes.BlockNumberOrHash
 		}
 	}
 
-	enableSnapshot := blockHeader.Number == meta.Height
-	lg, err := api.Broker().GetViewStateLedger().NewView(blockHeader, enableSnapshot)
-	if err != nil {
-		return nil, fmt.Errorf("GetViewStateLedger error: %v", err)
-	}
-	return lg, nil
+	return blockHeader, nil
 }
 
 // NewRPCTransaction returns a transaction that will serialize to the RPC representation
@@ -411,7 +411,7 @@ func (api *FilterAPI) GetLogs(ctx context.Context, ethCrit FilterCriteria) ([]*e
 	crit := ethCrit.toAxmFilterQuery()
 	if crit.BlockHash != nil {
 		// Block filter requested, construct a single-shot filter
-		filter = NewBlockFilter(api.api, crit.BlockHash, crit.Addresses, crit.Topics)
+		filter = NewBlockFilter(api.api, crit.BlockHash, crit.Addresses, crit.Topics, api.rep.Config.JsonRPC.QueryLimit.ResultLimit)
 	} else {
 		// Convert the RPC block numbers into internal representations
 		begin := rpc.LatestBlockNumber.Int64()
@@ -426,7 +426,7 @@ func (api *FilterAPI) GetLogs(ctx context.Context, ethCrit FilterCriteria) ([]*e
 			end = crit.ToBlock.Int64()
 		}
 		// Construct the range filter
-		filter = NewRangeFilter(api.api, begin, end, crit.Addresses, crit.Topics, api.rep.Config.JsonRPC.QueryLimit.GetLogsBlockRangeLimit)
+		filter = NewRangeFilter(api.api, begin, end, crit.Addresses, crit.Topics, api.rep.Config.JsonRPC.QueryLimit.GetLogsBlockRangeLimit, api.rep.Config.JsonRPC.QueryLimit.ResultLimit)
 	}
 	// Run the filter and return all the logs
 	logs, err := filter.Logs(ctx)
@@ -470,7 +470,7 @@ func (api *FilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*ethereum
 	var filter *Filter
 	if f.crit.BlockHash != nil {
 		// Block filter requested, construct a single-shot filter
-		filter = NewBlockFilter(api.api, f.crit.BlockHash, f.crit.Addresses, f.crit.Topics)
+		filter = NewBlockFilter(api.api, f.crit.BlockHash, f.crit.Addresses, f.crit.Topics, api.rep.Config.JsonRPC.QueryLimit.ResultLimit)
 	} else {
 		// Convert the RPC block numbers into internal representations
 		begin := rpc.LatestBlockNumber.Int64()
@@ -485,7 +485,7 @@ func (api *FilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*ethereum
 			end = f.crit.ToBlock.Int64()
 		}
 		// Construct the range filter
-		filter = NewRangeFilter(api.api, begin, end, f.crit.Addresses, f.crit.Topics, api.rep.Config.JsonRPC.QueryLimit.GetLogsBlockRangeLimit)
+		filter = NewRangeFilter(api.api, begin, end, f.crit.Addresses, f.crit.Topics, api.rep.Config.JsonRPC.QueryLimit.GetLogsBlockRangeLimit, api.rep.Config.JsonRPC.QueryLimit.ResultLimit)
 	}
 	// Run the filter and return all the logs
 	logs, err := filter.Logs(ctx)
@@ -39,16 +39,33 @@ type Filter struct {
 	begin           int64
 	end             int64 // Range interval if filtering multiple blocks
 	blockRangeLimit uint64
+	resultLimit     uint64 // max number of logs in a single query, 0 means unlimited
 	matcher         *bloombits.Matcher
 }
 
+// ResultLimitExceededError is returned when the number of query results exceeds the configured limit,
+// clients should narrow the query range and paginate.
+type ResultLimitExceededError struct {
+	Limit uint64
+}
+
+func (e *ResultLimitExceededError) Error() string {
+	return fmt.Sprintf("query returned more than %d results, please narrow the query range", e.Limit)
+}
+
+// ErrorCode returns the JSON error code for limit exceeded.
+// See: https://eips.ethereum.org/EIPS/eip-1474#error-codes
+func (e *ResultLimitExceededError) ErrorCode() int {
+	return -32005
+}
+
 type bytesBacked interface {
 	Bytes() []byte
 }
 
 // NewRangeFilter creates a new filter which uses a bloom filter on blocks to
 // figure out whether a particular block is interesting or not.
-func NewRangeFilter(api api.CoreAPI, begin, end int64, addresses []types.Address, topics [][]types.Hash, blockRangeLimit uint64) *Filter {
+func NewRangeFilter(api api.CoreAPI, begin, end int64, addresses []types.Address, topics [][]types.Hash, blockRangeLimit uint64, resultLimit uint64) *Filter {
 	// Flatten the address and topic filter clauses into a single bloombits filter
 	// system. Since the bloombits are not positional, nil topics are permitted,
 	// which get flattened into a nil byte slice.
@@ -70,7 +87,7 @@ func NewRangeFilter(api api.CoreAPI, begin, end int64, addresses []types.Address
 	size, _ := api.Feed().BloomStatus()
 
 	// Create a generic filter and convert it into a range filter
-	filter := newFilter(api, addresses, topics)
+	filter := newFilter(api, addresses, topics, resultLimit)
 
 	filter.matcher = bloombits.NewMatcher(size, filters)
 	filter.begin = begin
@@ -82,23 +99,32 @@ func NewRangeFilter(api api.CoreAPI, begin, end int64, addresses []types.Address
 
 // NewBlockFilter creates a new filter which directly inspects the contents of
 // a block to figure out whether it is interesting or not.
-func NewBlockFilter(api api.CoreAPI, block *types.Hash, addresses []types.Address, topics [][]types.Hash) *Filter {
+func NewBlockFilter(api api.CoreAPI, block *types.Hash, addresses []types.Address, topics [][]types.Hash, resultLimit uint64) *Filter {
 	// Create a generic filter and convert it into a block filter
-	filter := newFilter(api, addresses, topics)
+	filter := newFilter(api, addresses, topics, resultLimit)
 	filter.block = block
 	return filter
 }
 
 // newFilter creates a generic filter that can either filter based on a block hash,
 // or based on range queries. The search criteria needs to be explicitly set.
-func newFilter(api api.CoreAPI, addresses []types.Address, topics [][]types.Hash) *Filter {
+func newFilter(api api.CoreAPI, addresses []types.Address, topics [][]types.Hash, resultLimit uint64) *Filter {
 	return &Filter{
-		api:       api,
-		addresses: addresses,
-		topics:    topics,
+		api:         api,
+		addresses:   addresses,
+		topics:      topics,
+		resultLimit: resultLimit,
 	}
 }
 
+// appendLogs appends found logs and checks the result limit.
+func (f *Filter) appendLogs(logs []*types.EvmLog, found []*types.EvmLog) ([]*types.EvmLog, error) {
+	if f.resultLimit != 0 && uint64(len(logs)+len(found)) > f.resultLimit {
+		return nil, &ResultLimitExceededError{Limit: f.resultLimit}
+	}
+	return append(logs, found...), nil
+}
+
 // Logs searches the blockchain for matching log entries, returning all from the
 // first block that contains matches, updating the start of the filter accordingly.
 func (f *Filter) Logs(ctx context.Context) ([]*types.EvmLog, error) {
@@ -187,7 +213,9 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.EvmLog,
 			if err != nil {
 				return logs, err
 			}
-			logs = append(logs, found...)
+			if logs, err = f.appendLogs(logs, found); err != nil {
+				return nil, err
+			}
 
 		case <-ctx.Done():
 			return logs, ctx.Err()
@@ -209,7 +237,9 @@ func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*types.EvmLog
 		if err != nil {
 			return logs, err
 		}
-		logs = append(logs, found...)
+		if logs, err = f.appendLogs(logs, found); err != nil {
+			return nil, err
+		}
 	}
 	return logs, nil
 }
@@ -221,7 +251,7 @@ func (f *Filter) blockLogs(ctx context.Context, header *types.BlockHeader) (logs
 		if err != nil {
 			return logs, err
 		}
-		logs = append(logs, found...)
+		return f.appendLogs(logs, found)
 	}
 	return logs, nil
 }
@@ -0,0 +1,62 @@
+package eth
+
+import (
+	"fmt"
+	"sync"
+)
+
+// CallGasBudgetExceededError is returned when the total gas of in-flight eth_call and eth_estimateGas executions
+// would exceed the configured budget, clients should retry later.
+type CallGasBudgetExceededError struct {
+	Budget uint64
+}
+
+func (e *CallGasBudgetExceededError) Error() string {
+	return fmt.Sprintf("in-flight call gas exceeds the budget %d, please retry later", e.Budget)
+}
+
+// ErrorCode returns the JSON error code for limit exceeded.
+// See: https://eips.ethereum.org/EIPS/eip-1474#error-codes
+func (e *CallGasBudgetExceededError) ErrorCode() int {
+	return -32005
+}
+
+// callGasBudget bounds the total gas of concurrent call executions, so that a burst of expensive simulations
+// can not starve block processing of CPU. A nil budget is unlimited.
+type callGasBudget struct {
+	lock     sync.Mutex
+	budget   uint64
+	inFlight uint64
+}
+
+func newCallGasBudget(budget uint64) *callGasBudget {
+	if budget == 0 {
+		return nil
+	}
+	return &callGasBudget{budget: budget}
+}
+
+// acquire reserves gas for a call execution, it fails immediately instead of waiting if the budget is exhausted
+func (b *callGasBudget) acquire(gas uint64) error {
+	if b == nil {
+		return nil
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if gas > b.budget || b.inFlight > b.budget-gas {
+		return &CallGasBudgetExceededError{Budget: b.budget}
+	}
+	b.inFlight += gas
+	callInFlightGas.Set(float64(b.inFlight))
+	return nil
+}
+
+func (b *callGasBudget) release(gas uint64) {
+	if b == nil {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.inFlight -= gas
+	callInFlightGas.Set(float64(b.inFlight))
+}
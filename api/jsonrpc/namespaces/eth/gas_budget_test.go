@@ -0,0 +1,80 @@
+package eth
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallGasBudget_Unlimited(t *testing.T) {
+	b := newCallGasBudget(0)
+	require.Nil(t, b)
+	require.Nil(t, b.acquire(^uint64(0)))
+	b.release(^uint64(0))
+}
+
+func TestCallGasBudget_AcquireRelease(t *testing.T) {
+	b := newCallGasBudget(100)
+
+	require.Nil(t, b.acquire(60))
+	require.Nil(t, b.acquire(40))
+	require.Equal(t, float64(100), testutil.ToFloat64(callInFlightGas))
+
+	// the budget is exhausted, the call is shed immediately
+	err := b.acquire(1)
+	require.NotNil(t, err)
+	exceeded, ok := err.(*CallGasBudgetExceededError)
+	require.True(t, ok)
+	require.Equal(t, uint64(100), exceeded.Budget)
+	require.Equal(t, -32005, exceeded.ErrorCode())
+
+	b.release(60)
+	require.Equal(t, float64(40), testutil.ToFloat64(callInFlightGas))
+	require.Nil(t, b.acquire(60))
+	b.release(60)
+	b.release(40)
+	require.Equal(t, float64(0), testutil.ToFloat64(callInFlightGas))
+
+	// a single call larger than the budget never fits
+	require.NotNil(t, b.acquire(101))
+	require.Equal(t, uint64(0), b.inFlight)
+}
+
+func TestCallGasBudget_ConcurrentShedding(t *testing.T) {
+	b := newCallGasBudget(100)
+
+	var (
+		wg       sync.WaitGroup
+		accepted atomic.Int64
+		shed     atomic.Int64
+		start    = make(chan struct{})
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			if err := b.acquire(25); err != nil {
+				shed.Add(1)
+				return
+			}
+			accepted.Add(1)
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	// the in-flight gas never exceeds the budget
+	require.Equal(t, int64(4), accepted.Load())
+	require.Equal(t, int64(16), shed.Load())
+	require.Equal(t, uint64(100), b.inFlight)
+
+	for i := int64(0); i < accepted.Load(); i++ {
+		b.release(25)
+	}
+	require.Equal(t, uint64(0), b.inFlight)
+	require.Nil(t, b.acquire(100))
+}
@@ -40,6 +40,13 @@ var (
 		Help:      "The latency of invoking send raw tx rpc interface",
 		Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 10),
 	})
+
+	callInFlightGas = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "axiom_ledger",
+		Subsystem: "jsonrpc",
+		Name:      "call_in_flight_gas",
+		Help:      "The total gas of in-flight eth_call and eth_estimateGas executions",
+	})
 )
 
 func init() {
@@ -48,4 +55,5 @@ func init() {
 	prometheus.MustRegister(invokeReadOnlyDuration)
 	prometheus.MustRegister(invokeCallContractDuration)
 	prometheus.MustRegister(invokeSendRawTxDuration)
+	prometheus.MustRegister(callInFlightGas)
 }
@@ -0,0 +1,42 @@
+package eth
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/axiomesh/axiom-ledger/pkg/repo"
+)
+
+var ErrStorageReadDenied = errors.New("storage read denied")
+
+// storageReadFilter enforces jsonrpc.storage_read_filter on the storage reads of rpc
+type storageReadFilter struct {
+	allow [][]byte
+	deny  [][]byte
+}
+
+func newStorageReadFilter(cfg repo.StorageReadFilter) *storageReadFilter {
+	// invalid prefixes are rejected by config validation
+	allow, deny, _ := cfg.Decode()
+	return &storageReadFilter{allow: allow, deny: deny}
+}
+
+func (f *storageReadFilter) check(address common.Address, key common.Hash) error {
+	k := append(address.Bytes(), key.Bytes()...)
+	if matchPrefix(f.deny, k) || (len(f.allow) != 0 && !matchPrefix(f.allow, k)) {
+		return fmt.Errorf("%w: address %s, key %s", ErrStorageReadDenied, address, key)
+	}
+	return nil
+}
+
+func matchPrefix(prefixes [][]byte, k []byte) bool {
+	for _, prefix := range prefixes {
+		if bytes.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -192,7 +192,7 @@ func (api *TracerAPI) TraceCall(args types.CallArgs, blockNrOrHash *rpctypes.Blo
 		return nil, err
 	}
 
-	statedb, err := api.api.Broker().GetViewStateLedger().NewView(blockHeader, false)
+	statedb, err := api.api.Broker().GetViewStateLedger().NewLimitedView(blockHeader, false)
 	if err != nil {
 		return nil, err
 	}
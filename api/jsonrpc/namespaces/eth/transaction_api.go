@@ -325,9 +325,9 @@ func (api *TransactionAPI) SendRawTransaction(data hexutil.Bytes) (ret common.Ha
 		return [32]byte{}, fmt.Errorf("check transaction fail for %s", err.Error())
 	}
 
-	if ready, status := api.api.Broker().ConsensusReady(); !ready {
+	if err = api.api.Broker().ConsensusReady(); err != nil {
 		if api.rep.Config.JsonRPC.RejectTxsIfConsensusAbnormal {
-			return [32]byte{}, fmt.Errorf("the system is temporarily unavailable %s, tx: %s", status, tx.GetHash().String())
+			return [32]byte{}, fmt.Errorf("the system is temporarily unavailable: %w, tx: %s", err, tx.GetHash().String())
 		}
 	} else {
 		api.logger.Debugf("Receive new eth tx: %s", tx.GetHash().String())
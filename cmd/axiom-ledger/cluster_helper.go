@@ -48,6 +48,7 @@ type ClusterNode struct {
 	IsDataSyncer        bool              `mapstructure:"is_data_syncer" toml:"is_data_syncer" json:"is_data_syncer"`
 	StakeNumber         *types.CoinNumber `mapstructure:"stake_number" toml:"stake_number" json:"stake_number"`
 	CommissionRate      uint64            `mapstructure:"commission_rate" toml:"commission_rate" json:"commission_rate"`
+	OperatorBalance     *types.CoinNumber `mapstructure:"operator_balance" toml:"operator_balance" json:"operator_balance"`
 	IP                  string            `mapstructure:"ip" toml:"ip" json:"ip"`
 	Port                ClusterNodePort   `mapstructure:"port" toml:"port" json:"port"`
 }
@@ -330,10 +331,18 @@ func (h *ClusterGeneratorHelper) populateGenesisConfig() error {
 			CommissionRate: nodeConfig.CommissionRate,
 		})
 
-		if h.cfg.MintForOperatorCoinAmount != nil {
+		// operator balance of the node overrides the shared mint amount
+		operatorBalance := h.cfg.MintForOperatorCoinAmount
+		if nodeConfig.OperatorBalance != nil {
+			if nodeConfig.OperatorBalance.ToBigInt().Sign() < 0 {
+				return errors.Errorf("invalid operator balance %s for node %d, cannot be negative", nodeConfig.OperatorBalance.String(), nodeID)
+			}
+			operatorBalance = nodeConfig.OperatorBalance
+		}
+		if operatorBalance != nil {
 			h.genesisCfgTemplate.Accounts = append(h.genesisCfgTemplate.Accounts, &repo.Account{
 				Address: operatorAddress,
-				Balance: h.cfg.MintForOperatorCoinAmount,
+				Balance: operatorBalance,
 			})
 		}
 
@@ -729,8 +729,9 @@ func generateTrie(ctx *cli.Context) error {
 		}
 	}
 
+	// an interrupted generation is resumed from the progress in target storage on the next run
 	errC := make(chan error)
-	go originStateLedger.IterateTrie(&ledger.SnapshotMeta{
+	go originStateLedger.IterateTrieResume(&ledger.SnapshotMeta{
 		BlockHeader: blockHeader,
 	}, targetStateStorage, errC)
 	err = <-errC
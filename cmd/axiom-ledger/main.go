@@ -77,6 +77,13 @@ func main() {
 					Destination: &syncSnapshotArgs.remotePeers,
 					Required:    false,
 				},
+				&cli.StringSliceFlag{
+					Name:        "config-overlay",
+					Usage:       "config files merged into config.toml in order, later files override earlier, e.g. --config-overlay config.prod.toml",
+					Value:       &cli.StringSlice{},
+					Destination: &startArgs.ConfigOverlays,
+					Required:    false,
+				},
 				common.KeystorePasswordFlag(),
 			},
 		},
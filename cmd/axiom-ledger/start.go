@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	_ "net/http/pprof"
 	"os"
@@ -12,6 +13,7 @@ import (
 	"syscall"
 
 	"github.com/axiomesh/axiom-ledger/pkg/profile"
+	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 
 	"github.com/axiomesh/axiom-kit/fileutil"
@@ -24,8 +26,9 @@ import (
 )
 
 var startArgs = struct {
-	Readonly bool
-	Snapshot bool
+	Readonly       bool
+	Snapshot       bool
+	ConfigOverlays cli.StringSlice
 }{}
 
 var syncSnapshotArgs = struct {
@@ -51,7 +54,7 @@ func start(ctx *cli.Context) error {
 		}
 	}
 
-	r, err := repo.Load(p)
+	r, err := repo.Load(p, startArgs.ConfigOverlays.Value()...)
 	if err != nil {
 		return err
 	}
@@ -90,6 +93,18 @@ func start(ctx *cli.Context) error {
 		log.Info(c)
 	})
 
+	preflightResults := r.PreflightCheck()
+	for _, res := range preflightResults {
+		status := "PASS"
+		if !res.Passed {
+			status = "FAIL"
+		}
+		log.Infof("preflight check: %-20s %-4s %s", res.Name, status, res.Detail)
+	}
+	if repo.PreflightFailed(preflightResults) {
+		return errors.New("preflight check failed, please fix the failed items above")
+	}
+
 	runtime.SetMutexProfileFraction(1)
 	runtime.SetBlockProfileRate(1)
 
@@ -135,9 +150,10 @@ func start(ctx *cli.Context) error {
 		if err := cbs.Start(); err != nil {
 			return fmt.Errorf("start chain broker service failed: %w", err)
 		}
+		axm.RegisterRPCService(cbs)
 
 		wg.Add(1)
-		handleShutdown(axm, &wg)
+		handleShutdown(axm, monitor, log, &wg)
 
 		if err := axm.Start(); err != nil {
 			return fmt.Errorf("start axiom-ledger failed: %w", err)
@@ -167,7 +183,7 @@ func printVersion(writer func(c string)) {
 	writer(fmt.Sprintf("Golang version: %s", repo.GoVersion))
 }
 
-func handleShutdown(node *app.AxiomLedger, wg *sync.WaitGroup) {
+func handleShutdown(node *app.AxiomLedger, monitor *profile.Monitor, log logrus.FieldLogger, wg *sync.WaitGroup) {
 	var stop = make(chan os.Signal, 2)
 	signal.Notify(stop, syscall.SIGTERM)
 	signal.Notify(stop, syscall.SIGINT)
@@ -176,6 +192,10 @@ func handleShutdown(node *app.AxiomLedger, wg *sync.WaitGroup) {
 		if err := n.Stop(); err != nil {
 			panic(err)
 		}
+		// flush metrics and record the clean shutdown marker after all modules are stopped
+		if err := monitor.Stop(); err != nil {
+			log.WithField("err", err).Error("Stop monitor failed")
+		}
 		wg.Done()
 		os.Exit(0)
 	}
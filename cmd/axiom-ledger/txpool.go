@@ -46,7 +46,7 @@ func getAllTxRecords(ctx *cli.Context) error {
 	if err != nil {
 		return err
 	}
-	p := path.Join(repo.GetStoragePath(r.RepoRoot), storagemgr.TxPool, txpool.TxRecordsFile)
+	p := txpool.GetTxRecordsFilePath(r.RepoRoot, r.ConsensusConfig.TxPool.TxRecordsDir)
 	if !fileutil.Exist(p) {
 		err = fmt.Errorf("axiom-ledger is not starting, please run axiom-ledger first, " + p)
 		return err
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sync"
 	"syscall"
 	"time"
 
@@ -17,7 +18,6 @@ import (
 	consensuspb "github.com/axiomesh/axiom-bft/common/consensus"
 	"github.com/axiomesh/axiom-kit/log"
 	"github.com/axiomesh/axiom-kit/storage/kv"
-	"github.com/axiomesh/axiom-kit/txpool"
 	"github.com/axiomesh/axiom-kit/types"
 	"github.com/axiomesh/axiom-ledger/internal/chainstate"
 	"github.com/axiomesh/axiom-ledger/internal/consensus"
@@ -48,7 +48,7 @@ type AxiomLedger struct {
 	ViewLedger    *ledger.Ledger
 	BlockExecutor executor.Executor
 	Consensus     consensus.Consensus
-	TxPool        txpool.TxPool[types.Transaction, *types.Transaction]
+	TxPool        txpool2.TxPool[types.Transaction, *types.Transaction]
 	Network       network.Network
 	Sync          synccomm.Sync
 	BloomRequests chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
@@ -57,6 +57,16 @@ type AxiomLedger struct {
 	epochStore kv.Storage
 	snapMeta   *snapMeta
 	StopCh     chan error
+	// haltOnce guards halting the node for a trie verification failure, which may be reported repeatedly
+	haltOnce sync.Once
+
+	// rpcService serves json-rpc queries on the ledger, it is stopped before any other component in shutdown
+	rpcService Service
+	// background tracks the goroutines stopped by Ctx, shutdown waits for them before closing storages
+	background sync.WaitGroup
+
+	// rwLedger is the ledger written by executor, its caches are flushed in shutdown
+	rwLedger *ledger.Ledger
 }
 
 func NewAxiomLedger(rep *repo.Repo, ctx context.Context, cancel context.CancelFunc) (*AxiomLedger, error) {
@@ -82,8 +92,8 @@ func NewAxiomLedger(rep *repo.Repo, ctx context.Context, cancel context.CancelFu
 
 		priceLimit := poolConf.PriceLimit
 		// ensure price limit is not less than min gas price
-		if axm.ChainState.EpochInfo.FinanceParams.MinGasPrice.ToBigInt().Cmp(priceLimit.ToBigInt()) > 0 {
-			priceLimit = axm.ChainState.EpochInfo.FinanceParams.MinGasPrice
+		if currentGasPrice := axm.ChainState.CurrentGasPrice(); currentGasPrice.Cmp(priceLimit.ToBigInt()) > 0 {
+			priceLimit = types.CoinNumberByBigInt(currentGasPrice)
 		}
 
 		txpoolConf := txpool2.Config{
@@ -91,6 +101,7 @@ func NewAxiomLedger(rep *repo.Repo, ctx context.Context, cancel context.CancelFu
 			PoolSize:               poolConf.PoolSize,
 			ToleranceTime:          poolConf.ToleranceTime.ToDuration(),
 			ToleranceRemoveTime:    poolConf.ToleranceRemoveTime.ToDuration(),
+			TxMaxAge:               poolConf.TxMaxAge.ToDuration(),
 			ToleranceNonceGap:      poolConf.ToleranceNonceGap,
 			CleanEmptyAccountTime:  poolConf.CleanEmptyAccountTime.ToDuration(),
 			GetAccountNonce:        fn,
@@ -98,9 +109,16 @@ func NewAxiomLedger(rep *repo.Repo, ctx context.Context, cancel context.CancelFu
 			EnableLocalsPersist:    poolConf.EnableLocalsPersist,
 			RepoRoot:               rep.RepoRoot,
 			RotateTxLocalsInterval: poolConf.RotateTxLocalsInterval.ToDuration(),
+			MaxLoadingRecordTxs:    poolConf.MaxLoadingRecordTxs,
 			PriceLimit:             priceLimit.ToBigInt().Uint64(),
 			PriceBump:              poolConf.PriceBump,
 			GenerateBatchType:      poolConf.GenerateBatchType,
+			TxRecordsDir:           poolConf.TxRecordsDir,
+			HighWatermarkPercent:   poolConf.HighWatermarkPercent,
+			LowWatermarkPercent:    poolConf.LowWatermarkPercent,
+			BatchDigestAlgo:        rep.GenesisConfig.BatchDigestAlgo,
+			MaxAccountsPerBatch:    poolConf.MaxAccountsPerBatch,
+			EvictByFee:             poolConf.EvictByFee,
 		}
 		axm.TxPool, err = txpool2.NewTxPool[types.Transaction, *types.Transaction](txpoolConf, axm.ChainState)
 		if err != nil {
@@ -198,11 +216,8 @@ func NewAxiomLedgerWithoutConsensus(rep *repo.Repo, ctx context.Context, cancel
 		vl = rwLdg.NewView()
 
 		// 2. wait for generating snapshot of target block
-		errC := make(chan error)
-		go vl.StateLedger.GenerateSnapshot(meta.BlockHeader, errC)
-		err = <-errC
-		if err != nil {
-			return nil, fmt.Errorf("snap-sync generate snapshot failed: %w", err)
+		if err = generateSnapshot(rwLdg, vl, meta.BlockHeader, rep.Config.Ledger, logger); err != nil {
+			return nil, err
 		}
 
 		// 3. verify whether trie snapshot is legal (async with snap sync)
@@ -300,6 +315,7 @@ func NewAxiomLedgerWithoutConsensus(rep *repo.Repo, ctx context.Context, cancel
 
 		snapMeta:   snap,
 		epochStore: epochStore,
+		rwLedger:   rwLdg,
 		StopCh:     make(chan error, 1),
 	}
 
@@ -408,30 +424,106 @@ func (axm *AxiomLedger) Start() error {
 
 	axm.start()
 
+	if interval := axm.Repo.Config.Ledger.TrieVerifyInterval.ToDuration(); interval > 0 {
+		verifier := ledger.NewTrieVerifier(axm.ViewLedger, interval, axm.Repo.Config.Ledger.TrieVerifySamples, loggers.Logger(loggers.Storage), axm.onTrieVerifyFailure)
+		axm.goBackground(func() {
+			verifier.Run(axm.Ctx)
+		})
+	}
+
+	if !axm.Repo.Config.Ledger.EnablePrune {
+		axm.logger.WithField(log.OnlyWriteMsgWithoutFormatterField, nil).Info(`
+=========================================================================================
+Full-archive mode: state pruning is disabled, the state of all historical blocks is kept
+and the state storage keeps growing with the chain
+=========================================================================================
+`)
+	}
+
 	axm.printLogo()
 
 	return nil
 }
 
-func (axm *AxiomLedger) Stop() error {
-	if axm.Repo.Config.Consensus.Type != repo.ConsensusTypeSolo && !axm.Repo.StartArgs.ReadonlyMode {
-		if err := axm.Network.Stop(); err != nil {
-			return fmt.Errorf("network stop: %w", err)
-		}
+// onTrieVerifyFailure stops the node if configured, so that a corrupted state is not propagated. The stop goes
+// through StopCh like a consensus NotifyStop, so that the shutdown handler stops every component exactly once.
+func (axm *AxiomLedger) onTrieVerifyFailure(header *types.BlockHeader, err error) {
+	if !axm.Repo.Config.Ledger.TrieVerifyHaltOnFailure || axm.Repo.StartArgs.ReadonlyMode {
+		return
 	}
-	if !axm.Repo.StartArgs.ReadonlyMode {
-		axm.Consensus.Stop()
-	}
-	if err := axm.BlockExecutor.Stop(); err != nil {
-		return fmt.Errorf("block executor stop: %w", err)
+	axm.haltOnce.Do(func() {
+		axm.logger.WithFields(logrus.Fields{
+			"height": header.Number,
+			"err":    err,
+		}).Error("Halt node for state trie verification failure, the node should be repaired and restarted")
+		select {
+		case axm.StopCh <- fmt.Errorf("state trie verification failed at height %d: %w", header.Number, err):
+		default:
+			// a stop is already pending
+		}
+	})
+}
+
+func (axm *AxiomLedger) Stop() error {
+	if err := axm.shutdownLifecycle().Stop(); err != nil {
+		return err
 	}
-	axm.Cancel()
 
 	axm.logger.Infof("%s stopped", repo.AppName)
 
 	return nil
 }
 
+// Service is a component started outside the app(e.g. the json-rpc service) whose lifetime is bound to the node
+type Service interface {
+	Stop() error
+}
+
+// RegisterRPCService binds the json-rpc service to the node, it is stopped first in shutdown so that no query
+// reads a ledger being closed
+func (axm *AxiomLedger) RegisterRPCService(s Service) {
+	axm.rpcService = s
+}
+
+// goBackground runs f in a goroutine which must return once Ctx is done, shutdown waits for it before closing storages
+func (axm *AxiomLedger) goBackground(f func()) {
+	axm.background.Add(1)
+	go func() {
+		defer axm.background.Done()
+		f()
+	}()
+}
+
+// shutdownLifecycle stops the json-rpc service and consensus first so that no new query or block arrives, then waits
+// for the in-flight block to be written into the ledger and the background services to exit, flushes the ledger
+// caches and closes all storages at last.
+func (axm *AxiomLedger) shutdownLifecycle() *Lifecycle {
+	l := NewLifecycle(axm.logger)
+	if axm.rpcService != nil {
+		l.Register("json-rpc", axm.rpcService.Stop)
+	}
+	if axm.Repo.Config.Consensus.Type != repo.ConsensusTypeSolo && !axm.Repo.StartArgs.ReadonlyMode {
+		l.Register("network", axm.Network.Stop)
+	}
+	if !axm.Repo.StartArgs.ReadonlyMode {
+		l.Register("consensus", func() error {
+			axm.Consensus.Stop()
+			return nil
+		})
+	}
+	l.Register("block executor", axm.BlockExecutor.Stop)
+	l.Register("background services", func() error {
+		axm.Cancel()
+		axm.background.Wait()
+		return nil
+	})
+	if axm.rwLedger != nil {
+		l.Register("ledger caches", axm.rwLedger.StateLedger.FlushCaches)
+	}
+	l.Register("storages", storagemgr.CloseAll)
+	return l
+}
+
 func (axm *AxiomLedger) initChainState() error {
 	lg := axm.ViewLedger.NewView()
 	chainMeta := lg.ChainLedger.GetChainMeta()
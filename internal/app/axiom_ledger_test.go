@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/axiomesh/axiom-kit/log"
+	"github.com/axiomesh/axiom-kit/types"
+	"github.com/axiomesh/axiom-ledger/internal/executor"
+	"github.com/axiomesh/axiom-ledger/pkg/repo"
+)
+
+func TestAxiomLedger_OnTrieVerifyFailure(t *testing.T) {
+	rep := repo.MockRepo(t)
+	axm := &AxiomLedger{
+		Repo:   rep,
+		logger: log.NewWithModule("app"),
+		StopCh: make(chan error, 1),
+	}
+	header := &types.BlockHeader{Number: 10}
+
+	// not configured to halt
+	axm.onTrieVerifyFailure(header, errors.New("state root mismatch"))
+	require.Len(t, axm.StopCh, 0)
+
+	// repeated failures halt the node only once
+	rep.Config.Ledger.TrieVerifyHaltOnFailure = true
+	for i := 0; i < 3; i++ {
+		axm.onTrieVerifyFailure(header, errors.New("state root mismatch"))
+	}
+	require.Len(t, axm.StopCh, 1)
+	err := <-axm.StopCh
+	require.Contains(t, err.Error(), "height 10")
+	axm.onTrieVerifyFailure(header, errors.New("state root mismatch"))
+	require.Len(t, axm.StopCh, 0)
+}
+
+type recordStop struct {
+	name  string
+	mu    *sync.Mutex
+	order *[]string
+}
+
+func (r *recordStop) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*r.order = append(*r.order, r.name)
+	return nil
+}
+
+type recordStopExecutor struct {
+	executor.Executor
+	*recordStop
+}
+
+func (e *recordStopExecutor) Stop() error {
+	return e.recordStop.Stop()
+}
+
+func TestAxiomLedger_ShutdownLifecycle(t *testing.T) {
+	rep := repo.MockRepo(t)
+	rep.StartArgs.ReadonlyMode = true
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	var order []string
+	axm := &AxiomLedger{
+		Repo:          rep,
+		logger:        log.NewWithModule("app"),
+		Ctx:           ctx,
+		Cancel:        cancel,
+		BlockExecutor: &recordStopExecutor{recordStop: &recordStop{name: "block executor", mu: &mu, order: &order}},
+	}
+	axm.RegisterRPCService(&recordStop{name: "json-rpc", mu: &mu, order: &order})
+
+	// storages must not be closed before a slow background service exits
+	bg := &recordStop{name: "background", mu: &mu, order: &order}
+	axm.goBackground(func() {
+		<-axm.Ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		_ = bg.Stop()
+	})
+
+	require.Nil(t, axm.shutdownLifecycle().Stop())
+	require.Equal(t, []string{"json-rpc", "block executor", "background"}, order)
+}
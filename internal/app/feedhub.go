@@ -7,8 +7,8 @@ import (
 )
 
 func (axm *AxiomLedger) start() {
-	go axm.listenWaitReportBlock()
-	go axm.listenWaitExecuteBlock()
+	axm.goBackground(axm.listenWaitReportBlock)
+	axm.goBackground(axm.listenWaitExecuteBlock)
 }
 
 func (axm *AxiomLedger) listenWaitReportBlock() {
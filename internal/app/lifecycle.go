@@ -0,0 +1,46 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// shutdownStage is a named step of the node shutdown
+type shutdownStage struct {
+	name string
+	stop func() error
+}
+
+// Lifecycle stops the components in the order they are registered. The order must follow the write path,
+// i.e. consensus -> executor -> ledger caches -> storages, so that no component writes into a closed storage.
+type Lifecycle struct {
+	logger logrus.FieldLogger
+	stages []shutdownStage
+}
+
+func NewLifecycle(logger logrus.FieldLogger) *Lifecycle {
+	return &Lifecycle{logger: logger}
+}
+
+// Register appends a shutdown stage, it is stopped after all the stages registered before it
+func (l *Lifecycle) Register(name string, stop func() error) {
+	l.stages = append(l.stages, shutdownStage{name: name, stop: stop})
+}
+
+// Stop runs the shutdown stages in order, the remaining stages are skipped if a stage fails,
+// because a later stage(e.g. closing storages) is unsafe while an earlier one is still running.
+func (l *Lifecycle) Stop() error {
+	for _, stage := range l.stages {
+		start := time.Now()
+		if err := stage.stop(); err != nil {
+			return fmt.Errorf("%s stop: %w", stage.name, err)
+		}
+		l.logger.WithFields(logrus.Fields{
+			"stage":    stage.name,
+			"duration": time.Since(start),
+		}).Info("Shutdown stage finished")
+	}
+	return nil
+}
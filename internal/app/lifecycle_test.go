@@ -0,0 +1,40 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/axiomesh/axiom-kit/log"
+)
+
+func TestLifecycle_Stop(t *testing.T) {
+	var order []string
+	stage := func(name string, err error) func() error {
+		return func() error {
+			order = append(order, name)
+			return err
+		}
+	}
+
+	l := NewLifecycle(log.NewWithModule("app"))
+	l.Register("consensus", stage("consensus", nil))
+	l.Register("block executor", stage("block executor", nil))
+	l.Register("ledger caches", stage("ledger caches", nil))
+	l.Register("storages", stage("storages", nil))
+	require.Nil(t, l.Stop())
+	require.Equal(t, []string{"consensus", "block executor", "ledger caches", "storages"}, order)
+
+	// storages must not be closed if the ledger caches are not flushed
+	order = nil
+	flushErr := errors.New("flush failed")
+	l = NewLifecycle(log.NewWithModule("app"))
+	l.Register("consensus", stage("consensus", nil))
+	l.Register("ledger caches", stage("ledger caches", flushErr))
+	l.Register("storages", stage("storages", nil))
+	err := l.Stop()
+	require.ErrorIs(t, err, flushErr)
+	require.Contains(t, err.Error(), "ledger caches stop")
+	require.Equal(t, []string{"consensus", "ledger caches"}, order)
+}
@@ -27,6 +27,41 @@ type snapMeta struct {
 	snapPeers        []*common.Node
 }
 
+// snapshotRetryBackoff is the wait time before retrying snapshot generation
+var snapshotRetryBackoff = 5 * time.Second
+
+// generateSnapshot generates the snapshot of the snap target block with retries, when it still fails, the node stops
+// with fatal policy, or the snapshot is detached from both ledgers with disable policy so that state is read
+// through the trie until the node is restarted.
+func generateSnapshot(rwLdg, vl *ledger.Ledger, header *types.BlockHeader, conf repo.Ledger, logger logrus.FieldLogger) error {
+	var err error
+	for attempt := 0; attempt <= conf.SnapshotGenerationRetries; attempt++ {
+		if attempt > 0 {
+			logger.WithFields(logrus.Fields{
+				"attempt": attempt,
+				"err":     err,
+			}).Warning("Retry generating snapshot")
+			time.Sleep(snapshotRetryBackoff)
+		}
+		errC := make(chan error, 1)
+		go vl.StateLedger.GenerateSnapshot(header, errC)
+		if err = <-errC; err == nil {
+			return nil
+		}
+	}
+
+	if conf.SnapshotFailurePolicy != repo.SnapshotFailureDisable {
+		return fmt.Errorf("snap-sync generate snapshot failed: %w", err)
+	}
+	rwLdg.StateLedger.DisableSnapshot()
+	vl.StateLedger.DisableSnapshot()
+	logger.WithFields(logrus.Fields{
+		"height": header.Number,
+		"err":    err,
+	}).Error("Generate snapshot failed, disable snapshot and read state through trie until restart")
+	return nil
+}
+
 func loadSnapMeta(lg *ledger.Ledger, header *types.BlockHeader, selfPeerId string, args *repo.SyncArgs) (*snapMeta, error) {
 	epochContract := framework.EpochManagerBuildConfig.Build(syscommon.NewViewVMContext(lg.StateLedger))
 	currentEpochInfo, err := epochContract.CurrentEpoch()
@@ -1,6 +1,7 @@
 package chainstate
 
 import (
+	"math/big"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -106,6 +107,12 @@ func (c *ChainState) UpdateByEpochInfo(epochInfo *types.EpochInfo, validatorSet
 	return nil
 }
 
+// CurrentGasPrice returns the effective gas price of current epoch, it starts from the genesis value and
+// follows the min gas price set by governance, which takes effect from the next epoch.
+func (c *ChainState) CurrentGasPrice() *big.Int {
+	return c.EpochInfo.FinanceParams.MinGasPrice.ToBigInt()
+}
+
 func (c *ChainState) TryUpdateSelfNodeInfo() {
 	if c.selfRegistered && !c.IsDataSyncer {
 		return
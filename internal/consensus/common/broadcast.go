@@ -0,0 +1,71 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/libp2p/go-msgio"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	broadcastAttemptCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "axiom_ledger",
+		Subsystem: "consensus",
+		Name:      "broadcast_attempt_total",
+		Help:      "the total number of broadcast attempts, labeled by message type",
+	}, []string{"type"})
+
+	broadcastFailedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "axiom_ledger",
+		Subsystem: "consensus",
+		Name:      "broadcast_failed_total",
+		Help:      "the total number of broadcasts failed after all attempts, labeled by message type",
+	}, []string{"type"})
+)
+
+func init() {
+	prometheus.MustRegister(broadcastAttemptCounter)
+	prometheus.MustRegister(broadcastFailedCounter)
+}
+
+// Broadcaster is the broadcast part of a p2p pipe
+type Broadcaster interface {
+	Broadcast(ctx context.Context, targets []string, data []byte) error
+}
+
+// BroadcastWithRetry broadcasts data to all peers of pipe, a failed broadcast is retried up to attempts times in total,
+// the backoff doubles after each failure. It stops retrying once ctx is done or the error is permanent(e.g. the
+// message is too large), and returns the last error.
+func BroadcastWithRetry(ctx context.Context, pipe Broadcaster, msgType string, data []byte, attempts uint64, backoff time.Duration) error {
+	if attempts == 0 {
+		attempts = 1
+	}
+	var err error
+	for i := uint64(0); i < attempts; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				broadcastFailedCounter.WithLabelValues(msgType).Inc()
+				return err
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		broadcastAttemptCounter.WithLabelValues(msgType).Inc()
+		if err = pipe.Broadcast(ctx, nil, data); err == nil {
+			return nil
+		}
+		if isPermanentBroadcastError(err) {
+			break
+		}
+	}
+	broadcastFailedCounter.WithLabelValues(msgType).Inc()
+	return err
+}
+
+// isPermanentBroadcastError reports whether a retry of the failed broadcast would fail again
+func isPermanentBroadcastError(err error) bool {
+	return errors.Is(err, msgio.ErrMsgTooLarge)
+}
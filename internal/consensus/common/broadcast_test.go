@@ -0,0 +1,73 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-msgio"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type mockBroadcaster struct {
+	failures int
+	calls    int
+	err      error
+}
+
+func (b *mockBroadcaster) Broadcast(_ context.Context, _ []string, _ []byte) error {
+	b.calls++
+	if b.calls <= b.failures {
+		if b.err != nil {
+			return b.err
+		}
+		return errors.New("broadcast failed")
+	}
+	return nil
+}
+
+func TestBroadcastWithRetry(t *testing.T) {
+	msgType := "test_broadcast"
+	attemptsBefore := testutil.ToFloat64(broadcastAttemptCounter.WithLabelValues(msgType))
+	failedBefore := testutil.ToFloat64(broadcastFailedCounter.WithLabelValues(msgType))
+
+	// succeed after retry
+	b := &mockBroadcaster{failures: 2}
+	err := BroadcastWithRetry(context.Background(), b, msgType, []byte("data"), 3, time.Millisecond)
+	require.Nil(t, err)
+	require.Equal(t, 3, b.calls)
+	require.Equal(t, attemptsBefore+3, testutil.ToFloat64(broadcastAttemptCounter.WithLabelValues(msgType)))
+	require.Equal(t, failedBefore, testutil.ToFloat64(broadcastFailedCounter.WithLabelValues(msgType)))
+
+	// fail after all attempts
+	b = &mockBroadcaster{failures: 3}
+	err = BroadcastWithRetry(context.Background(), b, msgType, []byte("data"), 2, time.Millisecond)
+	require.NotNil(t, err)
+	require.Equal(t, 2, b.calls)
+	require.Equal(t, failedBefore+1, testutil.ToFloat64(broadcastFailedCounter.WithLabelValues(msgType)))
+
+	// zero attempts means no retry
+	b = &mockBroadcaster{failures: 1}
+	err = BroadcastWithRetry(context.Background(), b, msgType, []byte("data"), 0, time.Millisecond)
+	require.NotNil(t, err)
+	require.Equal(t, 1, b.calls)
+
+	// permanent error is not retried
+	failedBefore = testutil.ToFloat64(broadcastFailedCounter.WithLabelValues(msgType))
+	b = &mockBroadcaster{failures: 3, err: fmt.Errorf("pipe broadcast msg failed: %w", msgio.ErrMsgTooLarge)}
+	err = BroadcastWithRetry(context.Background(), b, msgType, []byte("data"), 3, time.Millisecond)
+	require.ErrorIs(t, err, msgio.ErrMsgTooLarge)
+	require.Equal(t, 1, b.calls)
+	require.Equal(t, failedBefore+1, testutil.ToFloat64(broadcastFailedCounter.WithLabelValues(msgType)))
+
+	// stop retrying once ctx is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b = &mockBroadcaster{failures: 3}
+	err = BroadcastWithRetry(ctx, b, msgType, []byte("data"), 3, time.Minute)
+	require.NotNil(t, err)
+	require.Equal(t, 1, b.calls)
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/axiomesh/axiom-kit/storage/kv"
-	"github.com/axiomesh/axiom-kit/txpool"
 	"github.com/axiomesh/axiom-kit/types"
 	"github.com/axiomesh/axiom-ledger/internal/chainstate"
 	"github.com/axiomesh/axiom-ledger/internal/network"
@@ -23,7 +22,7 @@ type Config struct {
 	GenesisEpochInfo   *types.EpochInfo
 	Network            network.Network
 	BlockSync          common.Sync
-	TxPool             txpool.TxPool[types.Transaction, *types.Transaction]
+	TxPool             TxPool
 	Applied            uint64
 	Digest             string
 	GenesisDigest      string
@@ -32,6 +31,14 @@ type Config struct {
 	GetAccountNonce    func(address *types.Address) uint64
 	NotifyStop         func(err error)
 	EpochStore         kv.Storage
+
+	// TxValidator is an optional admission rule of deployment(e.g. sender allowlist), it runs in pre-check
+	// after signature verification, and the tx is rejected with the returned error if it is not nil
+	TxValidator func(tx *types.Transaction) error
+
+	// TxCommitObserver is an optional debugging hook, it is called for each tx of the blocks produced by consensus,
+	// in a separate goroutine, so it never blocks consensus but may miss blocks if it is slow
+	TxCommitObserver func(info TxCommitInfo)
 }
 
 type Option func(*Config)
@@ -42,7 +49,7 @@ func WithRepo(rep *repo.Repo) Option {
 	}
 }
 
-func WithTxPool(tp txpool.TxPool[types.Transaction, *types.Transaction]) Option {
+func WithTxPool(tp TxPool) Option {
 	return func(config *Config) {
 		config.TxPool = tp
 	}
@@ -120,6 +127,18 @@ func WithEpochStore(epochStore kv.Storage) Option {
 	}
 }
 
+func WithTxValidator(f func(tx *types.Transaction) error) Option {
+	return func(config *Config) {
+		config.TxValidator = f
+	}
+}
+
+func WithTxCommitObserver(f func(info TxCommitInfo)) Option {
+	return func(config *Config) {
+		config.TxCommitObserver = f
+	}
+}
+
 func WithNotifyStopCh(f func(err error)) Option {
 	return func(config *Config) {
 		config.NotifyStop = f
@@ -0,0 +1,27 @@
+package common
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// RejectReasonDuplicate is the reason of the txs rejected by consensus as client retries before entering txpool
+const RejectReasonDuplicate = "duplicate"
+
+// rejectTxCounter is shared by txpool and consensus, so that the txs rejected before entering txpool
+// are counted together with the txs rejected by txpool
+var rejectTxCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "txpool",
+		Name:      "reject_tx_counter",
+		Help:      "the total number of rejected transactions",
+	},
+	[]string{"reason"},
+)
+
+func init() {
+	prometheus.MustRegister(rejectTxCounter)
+}
+
+// TraceRejectedTx records a tx rejected by txpool or by consensus before entering txpool
+func TraceRejectedTx(reason string) {
+	rejectTxCounter.With(prometheus.Labels{"reason": reason}).Inc()
+	rejectTxCounter.With(prometheus.Labels{"reason": "all"}).Inc()
+}
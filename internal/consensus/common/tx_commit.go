@@ -0,0 +1,80 @@
+package common
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/axiomesh/axiom-kit/types"
+)
+
+// txCommitNotifyBufferSize is the number of blocks waiting for the tx commit observer, later blocks are dropped
+const txCommitNotifyBufferSize = 1024
+
+// TxCommitInfo describes a tx ordered into a block by consensus, it is passed to Config.TxCommitObserver.
+// The block is not executed yet when the observer is called, so the tx has no receipt status.
+type TxCommitInfo struct {
+	TxHash string
+	From   string
+	Nonce  uint64
+	Height uint64
+	Index  int
+}
+
+type txCommitBlock struct {
+	height uint64
+	txs    []*types.Transaction
+}
+
+// TxCommitNotifier calls the tx commit observer in its own goroutine, so that a slow observer never blocks
+// consensus, blocks are dropped if the observer falls behind. A nil notifier does nothing.
+type TxCommitNotifier struct {
+	observer func(info TxCommitInfo)
+	blockC   chan txCommitBlock
+	logger   logrus.FieldLogger
+}
+
+// NewTxCommitNotifier returns nil if observer is nil, otherwise it calls observer until ctx is done.
+func NewTxCommitNotifier(ctx context.Context, observer func(info TxCommitInfo), logger logrus.FieldLogger) *TxCommitNotifier {
+	if observer == nil {
+		return nil
+	}
+	n := &TxCommitNotifier{
+		observer: observer,
+		blockC:   make(chan txCommitBlock, txCommitNotifyBufferSize),
+		logger:   logger,
+	}
+	go n.listen(ctx)
+	return n
+}
+
+// Notify queues the txs of block for the observer without blocking.
+func (n *TxCommitNotifier) Notify(block *types.Block) {
+	if n == nil {
+		return
+	}
+	select {
+	case n.blockC <- txCommitBlock{height: block.Height(), txs: block.Transactions}:
+	default:
+		n.logger.WithField("height", block.Height()).Warn("Tx commit observer falls behind, drop block")
+	}
+}
+
+func (n *TxCommitNotifier) listen(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case b := <-n.blockC:
+			for i, tx := range b.txs {
+				n.observer(TxCommitInfo{
+					TxHash: tx.RbftGetTxHash(),
+					From:   tx.RbftGetFrom(),
+					Nonce:  tx.RbftGetNonce(),
+					Height: b.height,
+					Index:  i,
+				})
+			}
+		}
+	}
+}
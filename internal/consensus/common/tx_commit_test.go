@@ -0,0 +1,61 @@
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/axiomesh/axiom-kit/types"
+	"github.com/axiomesh/axiom-ledger/pkg/loggers"
+)
+
+func TestTxCommitNotifier(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := loggers.Logger(loggers.Consensus)
+
+	// nil observer gets nil notifier
+	var notifier *TxCommitNotifier
+	require.Nil(t, NewTxCommitNotifier(ctx, nil, logger))
+	notifier.Notify(&types.Block{Header: &types.BlockHeader{Number: 1}})
+
+	tx1, err := types.GenerateEmptyTransactionAndSigner()
+	require.Nil(t, err)
+	tx2, err := types.GenerateEmptyTransactionAndSigner()
+	require.Nil(t, err)
+
+	infoC := make(chan TxCommitInfo, 2)
+	notifier = NewTxCommitNotifier(ctx, func(info TxCommitInfo) {
+		infoC <- info
+	}, logger)
+	notifier.Notify(&types.Block{Header: &types.BlockHeader{Number: 3}, Transactions: []*types.Transaction{tx1, tx2}})
+	for i, tx := range []*types.Transaction{tx1, tx2} {
+		select {
+		case info := <-infoC:
+			require.Equal(t, TxCommitInfo{TxHash: tx.RbftGetTxHash(), From: tx.RbftGetFrom(), Nonce: tx.RbftGetNonce(), Height: 3, Index: i}, info)
+		case <-time.After(time.Second):
+			require.FailNow(t, "observer is not called")
+		}
+	}
+
+	// a blocked observer never blocks Notify, the blocks beyond the buffer are dropped
+	release := make(chan struct{})
+	notifier = NewTxCommitNotifier(ctx, func(info TxCommitInfo) {
+		<-release
+	}, logger)
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < txCommitNotifyBufferSize+10; i++ {
+			notifier.Notify(&types.Block{Header: &types.BlockHeader{Number: uint64(i)}, Transactions: []*types.Transaction{tx1}})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.FailNow(t, "Notify is blocked by the observer")
+	}
+	close(release)
+}
@@ -0,0 +1,42 @@
+package common
+
+import (
+	"github.com/axiomesh/axiom-kit/txpool"
+	"github.com/axiomesh/axiom-kit/types"
+)
+
+// TxPool is the txpool used by consensus, it extends the txpool of axiom-kit with the methods provided by
+// the txpool of the node
+type TxPool interface {
+	txpool.TxPool[types.Transaction, *types.Transaction]
+
+	// FilterKnownTxs returns the subset of hashes whose txs are in txpool
+	FilterKnownTxs(hashes []string) map[string]struct{}
+
+	// PendingBatches returns the generation time(unix nano) of the batches which are not committed yet, keyed by batch digest
+	PendingBatches() map[string]int64
+}
+
+// MockTxPool adapts the txpool mocks of axiom-kit to TxPool, FilterKnownTxs looks up the hashes one by one
+// and PendingBatches reports no batch
+type MockTxPool struct {
+	txpool.TxPool[types.Transaction, *types.Transaction]
+}
+
+func NewMockTxPool(pool txpool.TxPool[types.Transaction, *types.Transaction]) *MockTxPool {
+	return &MockTxPool{TxPool: pool}
+}
+
+func (p *MockTxPool) FilterKnownTxs(hashes []string) map[string]struct{} {
+	known := make(map[string]struct{})
+	for _, hash := range hashes {
+		if p.GetPendingTxByHash(hash) != nil {
+			known[hash] = struct{}{}
+		}
+	}
+	return known
+}
+
+func (p *MockTxPool) PendingBatches() map[string]int64 {
+	return nil
+}
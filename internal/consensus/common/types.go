@@ -3,6 +3,7 @@ package common
 import (
 	"github.com/pkg/errors"
 
+	"github.com/axiomesh/axiom-kit/txpool"
 	"github.com/axiomesh/axiom-kit/types"
 	"github.com/axiomesh/axiom-ledger/internal/components/timer"
 )
@@ -21,6 +22,18 @@ var (
 	ErrorPreCheck       = errors.New("precheck failed")
 	ErrorAddTxPool      = errors.New("add txpool failed")
 	ErrorConsensusStart = errors.New("consensus not start yet")
+	ErrorDuplicateTx    = errors.New("duplicate transaction")
+)
+
+// errors returned by Consensus.Ready, use errors.Is to check the specific state
+var (
+	ErrInConfChange      = errors.New("system is in conf change")
+	ErrInViewChange      = errors.New("system is in view change")
+	ErrInRecovery        = errors.New("system is in recovery")
+	ErrInSyncState       = errors.New("system is in sync state")
+	ErrStateTransferring = errors.New("system is in state update")
+	ErrPending           = errors.New("system is in pending state")
+	ErrStopped           = errors.New("system is stopped")
 )
 
 var DataSyncerPipeName = []string{
@@ -56,9 +69,61 @@ type TxResp struct {
 	ErrorMsg string
 }
 
+// GenReason is the cause of producing a block, it tells an empty block generated by no-tx timeout apart from
+// a block which happens to have no user txs
+type GenReason int
+
+const (
+	// GenReasonUnknown is used by the consensus which does not attribute blocks, e.g. rbft
+	GenReasonUnknown GenReason = iota
+	// GenReasonTimeout means the batch timer fired with txs in pool
+	GenReasonTimeout
+	// GenReasonNoTxTimeout means the no-tx batch timer fired with no tx in pool, the block is empty
+	GenReasonNoTxTimeout
+	// GenReasonSize means the txs in pool reached the batch size
+	GenReasonSize
+	// GenReasonForced means the batch is generated without waiting for the batch size or timer
+	GenReasonForced
+	// GenReasonSlot means the txs in pool are sealed at a slot boundary
+	GenReasonSlot
+)
+
+var genReasonToStr = map[GenReason]string{
+	GenReasonUnknown:     "unknown",
+	GenReasonTimeout:     "timeout",
+	GenReasonNoTxTimeout: "no_tx_timeout",
+	GenReasonSize:        "size",
+	GenReasonForced:      "forced",
+	GenReasonSlot:        "slot",
+}
+
+func (r GenReason) String() string {
+	if s, ok := genReasonToStr[r]; ok {
+		return s
+	}
+	return genReasonToStr[GenReasonUnknown]
+}
+
+// GenReasonOf maps the batch event type of txpool to the block generation reason
+func GenReasonOf(genBatchEventType int) GenReason {
+	switch genBatchEventType {
+	case txpool.GenBatchTimeoutEvent:
+		return GenReasonTimeout
+	case txpool.GenBatchNoTxTimeoutEvent:
+		return GenReasonNoTxTimeout
+	case txpool.GenBatchSizeEvent:
+		return GenReasonSize
+	case txpool.GenBatchFirstEvent:
+		return GenReasonForced
+	default:
+		return GenReasonUnknown
+	}
+}
+
 type CommitEvent struct {
 	Block                  *types.Block
 	StateUpdatedCheckpoint *Checkpoint
+	GenReason              GenReason
 }
 
 type Checkpoint struct {
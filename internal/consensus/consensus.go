@@ -32,6 +32,10 @@ type Consensus interface {
 
 	Status() (bool, string)
 
+	// Ready returns nil if the consensus is able to accept txs, otherwise returns an error of the current state,
+	// use errors.Is to check the specific state
+	Ready() error
+
 	// ReportState means block was persisted and report it to the consensus engine
 	ReportState(height uint64, blockHash *types.Hash, txHashList []*events.TxPointer, stateUpdatedCheckpoint *common.Checkpoint, needRemoveTxs bool)
 
@@ -41,6 +45,12 @@ type Consensus interface {
 	// GetLowWatermark will return the low watermark of consensus engine
 	GetLowWatermark() uint64
 
+	// IsFinalized reports whether the block at height is finalized(checkpointed), an executed block may be not finalized
+	IsFinalized(height uint64) bool
+
+	// CurrentEpoch will return the epoch info currently used by consensus engine
+	CurrentEpoch() (*types.EpochInfo, error)
+
 	SubscribeTxEvent(events chan<- []*types.Transaction) event.Subscription
 
 	SubscribeMockBlockEvent(ch chan<- events.ExecutedEvent) event.Subscription
@@ -61,6 +71,6 @@ func New(consensusType string, opts ...common.Option) (Consensus, error) {
 	case repo.ConsensusTypeSoloDev:
 		return solo_dev.NewNode(config)
 	default:
-		return nil, fmt.Errorf("unsupport consensus type: %s", consensusType)
+		return nil, fmt.Errorf("unsupport consensus type: %s, available: %v", consensusType, repo.RegisteredConsensusTypes())
 	}
 }
@@ -1,6 +1,18 @@
 package precheck
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"github.com/gammazero/workerpool"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/axiomesh/axiom-ledger/internal/consensus/common"
+)
+
+// stages of pre-check, used as the label of queue depth
+const (
+	preCheckStageBasicCheck = "basic_check"
+	preCheckStageVerifySign = "verify_sign"
+	preCheckStageVerifyData = "verify_data"
+)
 
 var (
 	basicCheckDuration = prometheus.NewSummaryVec(
@@ -47,6 +59,15 @@ var (
 			Help:      "The number of valid tx",
 		},
 	)
+	queueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "axiom_ledger",
+			Subsystem: "pre_check",
+			Name:      "queue_depth",
+			Help:      "The number of tx events waiting in each pre-check stage, a growing one indicates the bottleneck",
+		},
+		[]string{"stage"},
+	)
 )
 
 func init() {
@@ -55,4 +76,26 @@ func init() {
 	prometheus.MustRegister(verifyBalanceDuration)
 	prometheus.MustRegister(rejectTxCounter)
 	prometheus.MustRegister(validTxCounter)
+	prometheus.MustRegister(queueDepth)
+}
+
+// tracePreCheckQueueDepth records the events buffered in the channel and waiting for a worker of the stage
+func tracePreCheckQueueDepth(stage string, buffered int, wp *workerpool.WorkerPool) {
+	queueDepth.WithLabelValues(stage).Set(float64(buffered + wp.WaitingQueueSize()))
+}
+
+// traceRejectTx records a remote tx rejected by pre-check, local txs are recorded when they are responded
+func traceRejectTx(err error) {
+	reason, _ := convertErrorType(err)
+	rejectTxCounter.WithLabelValues(reason).Inc()
+}
+
+// submitPreCheckTask submits task of the stage to wp, the queue depth is recorded on submit and again after task
+// finishes, so that the gauge drops back when the stage drains instead of keeping the depth of the last submit
+func submitPreCheckTask(stage string, ch chan *common.UncheckedTxEvent, wp *workerpool.WorkerPool, task func()) {
+	tracePreCheckQueueDepth(stage, len(ch), wp)
+	wp.Submit(func() {
+		task()
+		tracePreCheckQueueDepth(stage, len(ch), wp)
+	})
 }
@@ -51,7 +51,7 @@ func newMockPreCheckMgr(ledger *mockDb, t *testing.T) (*TxPreCheckMgr, *logrus.E
 		},
 		ChainState:        chainstate.NewMockChainState(r.GenesisConfig, nil),
 		GetAccountBalance: getAccountBalance,
-		TxPool:            mockPool,
+		TxPool:            consensuscommon.NewMockTxPool(mockPool),
 	}
 
 	return NewTxPreCheckMgr(ctx, cnf), logger, cancel
@@ -129,6 +129,51 @@ func generateLegacyTx(s *types.Signer, to *common.Address, nonce uint64, data []
 	return tx, nil
 }
 
+func generateAccessListTx(s *types.Signer, to *common.Address, data []byte, gasLimit uint64, value, gasPrice *big.Int) (*types.Transaction, error) {
+	inner := &types.AccessListTx{
+		ChainID:  big.NewInt(1),
+		Nonce:    0,
+		GasPrice: gasPrice,
+		Gas:      gasLimit,
+		To:       to,
+		Data:     data,
+		Value:    value,
+	}
+	tx := &types.Transaction{
+		Inner: inner,
+		Time:  time.Now(),
+	}
+
+	if err := tx.SignByTxType(s.Sk); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func generateIncentiveTx(s *types.Signer, to *common.Address, data []byte,
+	gasLimit uint64, value, gasFeeCap, gasTipCap *big.Int) (*types.Transaction, error) {
+	inner := &types.IncentiveTx{
+		ChainID:          big.NewInt(1),
+		Nonce:            0,
+		GasTipCap:        gasTipCap,
+		GasFeeCap:        gasFeeCap,
+		Gas:              gasLimit,
+		To:               to,
+		Data:             data,
+		Value:            value,
+		IncentiveAddress: to,
+	}
+	tx := &types.Transaction{
+		Inner: inner,
+		Time:  time.Now(),
+	}
+
+	if err := tx.SignByTxType(s.Sk); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
 func generateDynamicFeeTx(s *types.Signer, to *common.Address, data []byte,
 	gasLimit uint64, value, gasFeeCap, gasTipCap *big.Int) (*types.Transaction, error) {
 	inner := &types.DynamicFeeTx{
@@ -30,8 +30,6 @@ const (
 )
 
 var (
-	concurrencyLimit = runtime.NumCPU()
-
 	// ErrOversizedData is returned if the input data of a transaction is greater
 	// than some meaningful limit a user might use. This is not a consensus error
 	// making the transaction invalid, rather a DOS protection.
@@ -56,15 +54,24 @@ type TxPreCheckMgr struct {
 
 	BaseFee      *big.Int // current is 0
 	getBalanceFn func(address string) *big.Int
+	txValidator  func(tx *types.Transaction) error
 
 	ctx       context.Context
 	txMaxSize atomic.Uint64
+	// workers is the concurrency of each pre-check stage
+	workers int
 }
 
 func (tp *TxPreCheckMgr) UpdateEpochInfo(epoch *types.EpochInfo) {
 	tp.txMaxSize.Store(epoch.MiscParams.TxMaxSize)
 }
 
+// minGasPrice reads the min gas price of current epoch on every check,
+// so the price updated by governance takes effect from the next epoch without restart
+func (tp *TxPreCheckMgr) minGasPrice() *big.Int {
+	return tp.chainState.CurrentGasPrice()
+}
+
 func (tp *TxPreCheckMgr) PostUncheckedTxEvent(ev *common.UncheckedTxEvent) {
 	tp.basicCheckCh <- ev
 }
@@ -84,7 +91,12 @@ func NewTxPreCheckMgr(ctx context.Context, conf *common.Config) *TxPreCheckMgr {
 		ctx:          ctx,
 		BaseFee:      big.NewInt(0),
 		getBalanceFn: conf.GetAccountBalance,
+		txValidator:  conf.TxValidator,
 		txpool:       conf.TxPool,
+		workers:      runtime.NumCPU(),
+	}
+	if conf.Repo != nil && conf.Repo.ConsensusConfig.PreCheck.Workers > 0 {
+		tp.workers = int(conf.Repo.ConsensusConfig.PreCheck.Workers)
 	}
 
 	if conf.GenesisEpochInfo.MiscParams.TxMaxSize == 0 {
@@ -101,7 +113,7 @@ func (tp *TxPreCheckMgr) Start() {
 	go tp.dispatchVerifySignEvent()
 	go tp.dispatchVerifyDataEvent()
 	go tp.postValidTxs()
-	tp.logger.Info("tx precheck manager started")
+	tp.logger.WithField("workers", tp.workers).Info("tx precheck manager started")
 }
 
 func (tp *TxPreCheckMgr) postValidTxs() {
@@ -124,7 +136,7 @@ func (tp *TxPreCheckMgr) postValidTxs() {
 }
 
 func (tp *TxPreCheckMgr) dispatchTxEvent() {
-	wp := workerpool.New(concurrencyLimit)
+	wp := workerpool.New(tp.workers)
 
 	for {
 		select {
@@ -132,7 +144,7 @@ func (tp *TxPreCheckMgr) dispatchTxEvent() {
 			wp.StopWait()
 			return
 		case ev := <-tp.basicCheckCh:
-			wp.Submit(func() {
+			submitPreCheckTask(preCheckStageBasicCheck, tp.basicCheckCh, wp, func() {
 				switch ev.EventType {
 				case common.LocalTxEvent:
 					now := time.Now()
@@ -160,6 +172,7 @@ func (tp *TxPreCheckMgr) dispatchTxEvent() {
 					for _, tx := range txSet {
 						if err := tp.basicCheckTx(tx); err != nil {
 							tp.logger.Warningf("basic check remote tx err:%s", err)
+							traceRejectTx(err)
 							continue
 						}
 						validSignTxs = append(validSignTxs, tx)
@@ -177,14 +190,14 @@ func (tp *TxPreCheckMgr) dispatchTxEvent() {
 }
 
 func (tp *TxPreCheckMgr) dispatchVerifySignEvent() {
-	wp := workerpool.New(concurrencyLimit)
+	wp := workerpool.New(tp.workers)
 	for {
 		select {
 		case <-tp.ctx.Done():
 			wp.StopWait()
 			return
 		case ev := <-tp.verifySignCh:
-			wp.Submit(func() {
+			submitPreCheckTask(preCheckStageVerifySign, tp.verifySignCh, wp, func() {
 				now := time.Now()
 				switch ev.EventType {
 				case common.LocalTxEvent:
@@ -211,6 +224,7 @@ func (tp *TxPreCheckMgr) dispatchVerifySignEvent() {
 					for _, tx := range txSet {
 						if err := tp.verifySignature(tx); err != nil {
 							tp.logger.Warningf("verify signature remote tx err:%s", err)
+							traceRejectTx(err)
 							continue
 						}
 						validSignTxs = append(validSignTxs, tx)
@@ -228,14 +242,14 @@ func (tp *TxPreCheckMgr) dispatchVerifySignEvent() {
 }
 
 func (tp *TxPreCheckMgr) dispatchVerifyDataEvent() {
-	wp := workerpool.New(concurrencyLimit)
+	wp := workerpool.New(tp.workers)
 	for {
 		select {
 		case <-tp.ctx.Done():
 			wp.StopWait()
 			return
 		case ev := <-tp.verifyDataCh:
-			wp.Submit(func() {
+			submitPreCheckTask(preCheckStageVerifyData, tp.verifyDataCh, wp, func() {
 				var (
 					validDataTxs     []*types.Transaction
 					local            bool
@@ -267,6 +281,7 @@ func (tp *TxPreCheckMgr) dispatchVerifyDataEvent() {
 					for _, tx := range txSet {
 						if err := components.VerifyInsufficientBalance[types.Transaction, *types.Transaction](tx, tp.getBalanceFn); err != nil {
 							tp.logger.Warningf("verify remote tx balance failed: %v", err)
+							traceRejectTx(err)
 							continue
 						}
 
@@ -303,27 +318,41 @@ func (tp *TxPreCheckMgr) verifySignature(tx *types.Transaction) error {
 			return err
 		}
 	}
+
+	// custom admission rule runs after signature verified, so it can trust the sender
+	if tp.txValidator != nil {
+		if err := tp.txValidator(tx); err != nil {
+			return fmt.Errorf("%w: %w", errTxValidator, err)
+		}
+	}
 	return nil
 }
 
 func (tp *TxPreCheckMgr) basicCheckTx(tx *types.Transaction) error {
-	// 1. reject transactions over defined size to prevent DOS attacks
-	if uint64(tx.Size()) > tp.txMaxSize.Load() {
-		return ErrOversizedData
+	// 0. only accept EIP-2718 transaction types supported by executor
+	switch tx.GetType() {
+	case types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType, types.IncentiveTxType:
+	default:
+		return fmt.Errorf("%w: [nonce:%d] tx type %d", errTxTypeNotSupported, tx.GetNonce(), tx.GetType())
 	}
 
-	minGasPrice := tp.chainState.EpochInfo.FinanceParams.MinGasPrice.ToBigInt()
+	// 1. reject transactions over defined size to prevent DOS attacks
+	if size, limit := uint64(tx.Size()), tp.txMaxSize.Load(); size > limit {
+		return fmt.Errorf("%w: [hash:%s, nonce:%d] tx size %d exceeds limit %d", ErrOversizedData,
+			tx.GetHash().String(), tx.GetNonce(), size, limit)
+	}
 
 	if tx.GetGasPrice() == nil {
 		return errors.New("tx has no gas price")
 	}
-	if tx.GetGasPrice().Cmp(minGasPrice) < 0 {
-		return fmt.Errorf("%w:[hash:%s, nonce:%d] expect min gasPrice: %v, get price %v",
-			errGasPriceTooLow, tx.GetHash().String(), tx.GetNonce(), minGasPrice, tx.GetGasPrice())
+	// the price of dynamic fee tx is the effective gas price paid in execution, rather than maxFeePerGas
+	if minGasPrice, price := tp.minGasPrice(), tx.GetInner().EffectiveGasPrice(tp.BaseFee); price.Cmp(minGasPrice) < 0 {
+		return fmt.Errorf("%w: [hash:%s, nonce:%d] required gas price: %v, provided gas price: %v",
+			ErrUnderpriced, tx.GetHash().String(), tx.GetNonce(), minGasPrice, price)
 	}
 
-	// 2. check the gas parameters's format are valid
-	if tx.GetType() == types.DynamicFeeTxType {
+	// 2. check the gas parameters's format are valid, incentive tx shares the fee fields of dynamic fee tx
+	if txType := tx.GetType(); txType == types.DynamicFeeTxType || txType == types.IncentiveTxType {
 		if tx.GetGasFeeCap().BitLen() > 0 || tx.GetGasTipCap().BitLen() > 0 {
 			if l := tx.GetGasFeeCap().BitLen(); l > 256 {
 				return fmt.Errorf("%w: [hash:%s, nonce:%d], maxFeePerGas bit length: %d", errFeeCapVeryHigh,
@@ -3,13 +3,17 @@ package precheck
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"math/big"
+	"runtime"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/gammazero/workerpool"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 
@@ -207,6 +211,7 @@ func TestTxPreCheckMgr_BasicCheck(t *testing.T) {
 		resp := <-localEvent.Event.(*consensuscommon.TxWithResp).CheckCh
 		require.False(t, resp.Status)
 		require.Contains(t, resp.ErrorMsg, txpool.ErrOversizedData.Error())
+		require.Contains(t, resp.ErrorMsg, fmt.Sprintf("tx size %d exceeds limit %d", tx.Size(), tx.Size()-1))
 
 		originalOutput := lg.Logger.Out
 		var logOutput bytes.Buffer
@@ -247,7 +252,7 @@ func TestTxPreCheckMgr_BasicCheck(t *testing.T) {
 		tp.PostUncheckedTxEvent(localEvent)
 		resp := <-localEvent.Event.(*consensuscommon.TxWithResp).CheckCh
 		require.False(t, resp.Status)
-		require.Contains(t, resp.ErrorMsg, errGasPriceTooLow.Error())
+		require.Contains(t, resp.ErrorMsg, ErrUnderpriced.Error())
 	})
 }
 
@@ -489,6 +494,155 @@ func TestTxPreCheckMgr_VerifyData(t *testing.T) {
 	})
 }
 
+func TestTxPreCheckMgr_BasicCheckTxTypes(t *testing.T) {
+	tp, _, _ := setupPrecheck(t)
+	s, err := types.GenerateSigner()
+	require.Nil(t, err)
+
+	minGasPrice := tp.chainState.EpochInfo.FinanceParams.MinGasPrice.ToBigInt()
+	price := new(big.Int).Add(minGasPrice, tp.BaseFee)
+	lowPrice := new(big.Int).Sub(minGasPrice, big.NewInt(1))
+
+	testcases := []struct {
+		name   string
+		genTx  func() (*types.Transaction, error)
+		expErr error
+	}{
+		{
+			name: "legacy tx",
+			genTx: func() (*types.Transaction, error) {
+				return generateLegacyTx(s, &toAddr, 0, nil, uint64(basicGas), price.Uint64(), big.NewInt(0))
+			},
+		},
+		{
+			name: "legacy tx with low gas price",
+			genTx: func() (*types.Transaction, error) {
+				return generateLegacyTx(s, &toAddr, 0, nil, uint64(basicGas), lowPrice.Uint64(), big.NewInt(0))
+			},
+			expErr: ErrUnderpriced,
+		},
+		{
+			name: "access list tx",
+			genTx: func() (*types.Transaction, error) {
+				return generateAccessListTx(s, &toAddr, nil, uint64(basicGas), big.NewInt(0), price)
+			},
+		},
+		{
+			name: "access list tx with low gas price",
+			genTx: func() (*types.Transaction, error) {
+				return generateAccessListTx(s, &toAddr, nil, uint64(basicGas), big.NewInt(0), lowPrice)
+			},
+			expErr: ErrUnderpriced,
+		},
+		{
+			name: "dynamic fee tx",
+			genTx: func() (*types.Transaction, error) {
+				return generateDynamicFeeTx(s, &toAddr, nil, uint64(basicGas), big.NewInt(0), price, big.NewInt(0))
+			},
+		},
+		{
+			name: "dynamic fee tx with tip above fee cap",
+			genTx: func() (*types.Transaction, error) {
+				return generateDynamicFeeTx(s, &toAddr, nil, uint64(basicGas), big.NewInt(0), price, new(big.Int).Add(price, big.NewInt(1)))
+			},
+			expErr: errTipAboveFeeCap,
+		},
+		{
+			name: "incentive tx",
+			genTx: func() (*types.Transaction, error) {
+				return generateIncentiveTx(s, &toAddr, nil, uint64(basicGas), big.NewInt(0), price, big.NewInt(0))
+			},
+		},
+		{
+			name: "incentive tx with tip above fee cap",
+			genTx: func() (*types.Transaction, error) {
+				return generateIncentiveTx(s, &toAddr, nil, uint64(basicGas), big.NewInt(0), price, new(big.Int).Add(price, big.NewInt(1)))
+			},
+			expErr: errTipAboveFeeCap,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tx, err := tc.genTx()
+			require.Nil(t, err)
+			err = tp.basicCheckTx(tx)
+			if tc.expErr == nil {
+				require.Nil(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.expErr)
+		})
+	}
+}
+
+func TestTxPreCheckMgr_MinGasPrice(t *testing.T) {
+	tp, _, _ := setupPrecheck(t)
+	s, err := types.GenerateSigner()
+	require.Nil(t, err)
+
+	epoch := tp.chainState.EpochInfo.Clone()
+	epoch.FinanceParams.MinGasPrice = types.CoinNumberByMol(100)
+	tp.chainState.EpochInfo = epoch
+
+	legacyTx, err := generateLegacyTx(s, &toAddr, 0, nil, uint64(basicGas), 99, big.NewInt(0))
+	require.Nil(t, err)
+	err = tp.basicCheckTx(legacyTx)
+	require.ErrorIs(t, err, ErrUnderpriced)
+	require.Contains(t, err.Error(), "required gas price: 100, provided gas price: 99")
+
+	// the effective gas price of dynamic fee tx is min(maxFeePerGas, baseFee + maxPriorityFeePerGas)
+	dynamicFeeTx, err := generateDynamicFeeTx(s, &toAddr, nil, uint64(basicGas), big.NewInt(0), big.NewInt(1000), big.NewInt(99))
+	require.Nil(t, err)
+	err = tp.basicCheckTx(dynamicFeeTx)
+	require.ErrorIs(t, err, ErrUnderpriced)
+	require.Contains(t, err.Error(), "required gas price: 100, provided gas price: 99")
+
+	dynamicFeeTx, err = generateDynamicFeeTx(s, &toAddr, nil, uint64(basicGas), big.NewInt(0), big.NewInt(1000), big.NewInt(100))
+	require.Nil(t, err)
+	require.Nil(t, tp.basicCheckTx(dynamicFeeTx))
+
+	// min gas price updated by governance takes effect without restart
+	epoch = tp.chainState.EpochInfo.Clone()
+	epoch.FinanceParams.MinGasPrice = types.CoinNumberByMol(99)
+	tp.chainState.EpochInfo = epoch
+	require.Equal(t, big.NewInt(99), tp.chainState.CurrentGasPrice())
+	require.Nil(t, tp.basicCheckTx(legacyTx))
+}
+
+func TestTxPreCheckMgr_TxValidator(t *testing.T) {
+	tp, _, cancel := newMockPreCheckMgr(&mockDb{db: make(map[string]*big.Int)}, t)
+	defer cancel()
+	allowed, err := types.GenerateSigner()
+	require.Nil(t, err)
+	other, err := types.GenerateSigner()
+	require.Nil(t, err)
+	tp.txValidator = func(tx *types.Transaction) error {
+		if tx.GetFrom().String() != allowed.Addr.String() {
+			return fmt.Errorf("sender %s is not allowed", tx.GetFrom())
+		}
+		return nil
+	}
+
+	allowedTx, err := generateLegacyTx(allowed, &toAddr, 0, nil, uint64(basicGas), 1, big.NewInt(0))
+	require.Nil(t, err)
+	require.Nil(t, tp.verifySignature(allowedTx))
+
+	otherTx, err := generateLegacyTx(other, &toAddr, 0, nil, uint64(basicGas), 1, big.NewInt(0))
+	require.Nil(t, err)
+	err = tp.verifySignature(otherTx)
+	require.ErrorIs(t, err, errTxValidator)
+	require.Contains(t, err.Error(), "is not allowed")
+
+	// local tx is responded with the error of validator
+	tp.Start()
+	event := createLocalTxEvent(otherTx)
+	tp.PostUncheckedTxEvent(event)
+	resp := <-event.Event.(*consensuscommon.TxWithResp).CheckCh
+	require.False(t, resp.Status)
+	require.Contains(t, resp.ErrorMsg, "is not allowed")
+}
+
 func TestTxPreCheckMgr_UpdateEpochInfo(t *testing.T) {
 	tp, _, _ := newMockPreCheckMgr(nil, t)
 	oldTxMaxSize := tp.txMaxSize.Load()
@@ -501,6 +655,22 @@ func TestTxPreCheckMgr_UpdateEpochInfo(t *testing.T) {
 	require.Equal(t, oldTxMaxSize+1, newTxMaxSize)
 }
 
+func TestTxPreCheckMgr_Workers(t *testing.T) {
+	tp, lg, cancel := newMockPreCheckMgr(nil, t)
+	defer cancel()
+	require.Equal(t, runtime.NumCPU(), tp.workers)
+
+	r := repo.MockRepo(t)
+	r.ConsensusConfig.PreCheck.Workers = 3
+	tp = NewTxPreCheckMgr(context.Background(), &consensuscommon.Config{
+		Logger:           lg,
+		Repo:             r,
+		GenesisEpochInfo: &types.EpochInfo{},
+		ChainState:       chainstate.NewMockChainState(r.GenesisConfig, nil),
+	})
+	require.Equal(t, 3, tp.workers)
+}
+
 func setupPrecheck(t *testing.T) (*TxPreCheckMgr, *logrus.Entry, *mockDb) {
 	ledger := &mockDb{
 		db: make(map[string]*big.Int),
@@ -509,3 +679,31 @@ func setupPrecheck(t *testing.T) (*TxPreCheckMgr, *logrus.Entry, *mockDb) {
 	tp.Start()
 	return tp, lg, ledger
 }
+
+func TestSubmitPreCheckTask_QueueDepth(t *testing.T) {
+	stage := "test_stage"
+	ch := make(chan *consensuscommon.UncheckedTxEvent, 10)
+	wp := workerpool.New(1)
+	defer wp.StopWait()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	submitPreCheckTask(stage, ch, wp, func() {
+		close(started)
+		<-release
+	})
+	<-started
+	// events buffered in the channel are counted on submit
+	ch <- &consensuscommon.UncheckedTxEvent{}
+	ch <- &consensuscommon.UncheckedTxEvent{}
+	submitPreCheckTask(stage, ch, wp, func() {})
+	require.Equal(t, float64(2), testutil.ToFloat64(queueDepth.WithLabelValues(stage)))
+
+	// the gauge drops back once the stage drains
+	<-ch
+	<-ch
+	close(release)
+	require.Eventually(t, func() bool {
+		return wp.WaitingQueueSize() == 0 && testutil.ToFloat64(queueDepth.WithLabelValues(stage)) == 0
+	}, time.Second, 10*time.Millisecond)
+}
@@ -9,10 +9,13 @@ import (
 
 var precheckErrPrefix = errors.New("verify tx err")
 
+// ErrUnderpriced is returned if the gas price(effective gas price for dynamic fee tx) of a tx is lower than the min gas price
+var ErrUnderpriced = errors.New("transaction underpriced")
+
 var (
 	errTxSign                       = errors.New("tx signature verify failed")
 	errTo                           = errors.New("tx from and to address is same")
-	errGasPriceTooLow               = errors.New("gas price too low")
+	errTxValidator                  = errors.New("tx rejected by validator")
 	errFeeCapVeryHigh               = core.ErrFeeCapVeryHigh
 	errTipVeryHigh                  = core.ErrTipVeryHigh
 	errTipAboveFeeCap               = core.ErrTipAboveFeeCap
@@ -21,12 +24,14 @@ var (
 	errInsufficientFunds            = core.ErrInsufficientFunds
 	errIntrinsicGas                 = core.ErrIntrinsicGas
 	errInsufficientFundsForTransfer = core.ErrInsufficientFundsForTransfer
+	errTxTypeNotSupported           = core.ErrTxTypeNotSupported
 )
 
 var errorTypes = map[error]string{
 	errTxSign:                       errTxSign.Error(),
 	errTo:                           errTo.Error(),
-	errGasPriceTooLow:               errGasPriceTooLow.Error(),
+	errTxValidator:                  errTxValidator.Error(),
+	ErrUnderpriced:                  ErrUnderpriced.Error(),
 	errFeeCapVeryHigh:               errFeeCapVeryHigh.Error(),
 	errTipVeryHigh:                  errTipVeryHigh.Error(),
 	errTipAboveFeeCap:               errTipAboveFeeCap.Error(),
@@ -35,6 +40,8 @@ var errorTypes = map[error]string{
 	errInsufficientFunds:            errInsufficientFunds.Error(),
 	errIntrinsicGas:                 errIntrinsicGas.Error(),
 	errInsufficientFundsForTransfer: core.ErrInsufficientFundsForTransfer.Error(),
+	ErrOversizedData:                ErrOversizedData.Error(),
+	errTxTypeNotSupported:           errTxTypeNotSupported.Error(),
 }
 
 const (
@@ -2,6 +2,7 @@ package adaptor
 
 import (
 	"context"
+	"time"
 
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/pkg/errors"
@@ -26,6 +27,11 @@ var _ rbft.ServiceOutbound[types.Transaction, *types.Transaction] = (*RBFTAdapto
 var _ rbft.EpochService = (*RBFTAdaptor)(nil)
 var _ rbft.Ledger = (*RBFTAdaptor)(nil)
 
+const defaultCommitEventBufferSize = 1024
+
+// commitEventBlockWarnInterval is the interval of warning while blocking on a full commit event buffer
+var commitEventBlockWarnInterval = 10 * time.Second
+
 type RBFTAdaptor struct {
 	epochStore        kv.Storage
 	store             rbft.Storage
@@ -60,6 +66,10 @@ type Ready struct {
 }
 
 func NewRBFTAdaptor(config *common.Config) (*RBFTAdaptor, error) {
+	if config.Repo.ConsensusConfig.Rbft.CommitEventOverflowPolicy == repo.CommitEventOverflowFatal && config.NotifyStop == nil {
+		return nil, errors.Errorf("commit event overflow policy %s requires NotifyStop", repo.CommitEventOverflowFatal)
+	}
+
 	var err error
 	storePath := repo.GetStoragePath(config.Repo.RepoRoot, storagemgr.Consensus)
 	var store rbft.Storage
@@ -75,13 +85,18 @@ func NewRBFTAdaptor(config *common.Config) (*RBFTAdaptor, error) {
 		return nil, errors.Errorf("open consensus storage %s failed: %v", storePath, err)
 	}
 
+	blockCSize := config.Repo.ConsensusConfig.Rbft.CommitEventBufferSize
+	if blockCSize == 0 {
+		blockCSize = defaultCommitEventBufferSize
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	stack := &RBFTAdaptor{
 		epochStore: config.EpochStore,
 		store:      store,
 		network:    config.Network,
 		ReadyC:     make(chan *Ready, 1024),
-		BlockC:     make(chan *common.CommitEvent, 1024),
+		BlockC:     make(chan *common.CommitEvent, blockCSize),
 		quitSync:   make(chan struct{}, 1),
 		logger:     config.Logger,
 		config:     config,
@@ -14,6 +14,7 @@ import (
 	"github.com/axiomesh/axiom-kit/types"
 	"github.com/axiomesh/axiom-ledger/internal/consensus/common"
 	"github.com/axiomesh/axiom-ledger/internal/consensus/rbft/testutil"
+	"github.com/axiomesh/axiom-ledger/pkg/repo"
 	network "github.com/axiomesh/axiom-p2p"
 )
 
@@ -317,6 +318,80 @@ func TestRBFTAdaptor_PostCommitEvent(t *testing.T) {
 	ast.Equal(uint64(1), commitEvent.Block.Header.Number)
 }
 
+func TestRBFTAdaptor_PostCommitEventOverflow(t *testing.T) {
+	ast := assert.New(t)
+	ctrl := gomock.NewController(t)
+
+	newEvent := func(height uint64) *common.CommitEvent {
+		return &common.CommitEvent{Block: &types.Block{Header: &types.BlockHeader{Number: height}}}
+	}
+
+	t.Run("block", func(t *testing.T) {
+		logger := log.NewWithModule("consensus")
+		cfg, _ := testutil.MockConsensusConfig(logger, ctrl, t)
+		cfg.Repo.ConsensusConfig.Rbft.CommitEventBufferSize = 1
+		adaptor, err := NewRBFTAdaptor(cfg)
+		ast.Nil(err)
+		ast.Equal(1, cap(adaptor.GetCommitChannel()))
+
+		adaptor.PostCommitEvent(newEvent(1))
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			adaptor.PostCommitEvent(newEvent(2))
+		}()
+		select {
+		case <-done:
+			t.Fatal("post commit event should block when the buffer is full")
+		case <-time.After(100 * time.Millisecond):
+		}
+		ast.Equal(uint64(1), (<-adaptor.GetCommitChannel()).Block.Header.Number)
+		<-done
+		ast.Equal(uint64(2), (<-adaptor.GetCommitChannel()).Block.Header.Number)
+	})
+
+	t.Run("fatal", func(t *testing.T) {
+		logger := log.NewWithModule("consensus")
+		cfg, _ := testutil.MockConsensusConfig(logger, ctrl, t)
+		cfg.Repo.ConsensusConfig.Rbft.CommitEventBufferSize = 1
+		cfg.Repo.ConsensusConfig.Rbft.CommitEventOverflowPolicy = repo.CommitEventOverflowFatal
+		stopCh := make(chan error, 1)
+		cfg.NotifyStop = func(err error) {
+			stopCh <- err
+		}
+		adaptor, err := NewRBFTAdaptor(cfg)
+		ast.Nil(err)
+
+		adaptor.PostCommitEvent(newEvent(1))
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			adaptor.PostCommitEvent(newEvent(2))
+		}()
+		err = <-stopCh
+		ast.Contains(err.Error(), "commit event buffer is full")
+
+		// the event is not dropped, posting blocks until the node stops
+		select {
+		case <-done:
+			t.Fatal("post commit event should block until the node stops")
+		case <-time.After(100 * time.Millisecond):
+		}
+		adaptor.Cancel()
+		<-done
+		ast.Equal(1, len(adaptor.GetCommitChannel()))
+	})
+
+	t.Run("fatal without NotifyStop", func(t *testing.T) {
+		logger := log.NewWithModule("consensus")
+		cfg, _ := testutil.MockConsensusConfig(logger, ctrl, t)
+		cfg.Repo.ConsensusConfig.Rbft.CommitEventOverflowPolicy = repo.CommitEventOverflowFatal
+		cfg.NotifyStop = nil
+		_, err := NewRBFTAdaptor(cfg)
+		ast.ErrorContains(err, "requires NotifyStop")
+	})
+}
+
 func TestLedger(t *testing.T) {
 	ast := assert.New(t)
 	ctrl := gomock.NewController(t)
@@ -0,0 +1,26 @@
+package adaptor
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	commitEventBufferDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "axiom_ledger",
+		Subsystem: "consensus",
+		Name:      "commit_event_buffer_depth",
+		Help:      "the number of committed blocks waiting for execution",
+	})
+
+	commitEventBufferFullCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "axiom_ledger",
+		Subsystem: "consensus",
+		Name:      "commit_event_buffer_full_total",
+		Help:      "the total times of posting commit event to a full buffer",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(commitEventBufferDepth)
+	prometheus.MustRegister(commitEventBufferFullCounter)
+}
@@ -16,6 +16,7 @@ import (
 	syscommon "github.com/axiomesh/axiom-ledger/internal/executor/system/common"
 	synccomm "github.com/axiomesh/axiom-ledger/internal/sync/common"
 	"github.com/axiomesh/axiom-ledger/pkg/events"
+	"github.com/axiomesh/axiom-ledger/pkg/repo"
 )
 
 func (a *RBFTAdaptor) Execute(requests []*types.Transaction, localList []bool, seqNo uint64, timestamp int64, proposerNodeID uint64) {
@@ -201,7 +202,46 @@ func (a *RBFTAdaptor) PostCommitEvent(commitEvent *common.CommitEvent) {
 }
 
 func (a *RBFTAdaptor) postCommitEvent(commitEvent *common.CommitEvent) {
-	a.BlockC <- commitEvent
+	defer func() {
+		commitEventBufferDepth.Set(float64(len(a.BlockC)))
+	}()
+	select {
+	case a.BlockC <- commitEvent:
+		return
+	default:
+	}
+
+	// the executor stalls, the consensus goroutine can not handle any other event(e.g. view change) while blocking here
+	commitEventBufferFullCounter.Inc()
+	fields := logrus.Fields{
+		"height":      commitEvent.Block.Height(),
+		"buffer_size": cap(a.BlockC),
+	}
+	if a.config.Repo.ConsensusConfig.Rbft.CommitEventOverflowPolicy == repo.CommitEventOverflowFatal {
+		err := fmt.Errorf("commit event buffer is full(size %d), executor may be stalled", cap(a.BlockC))
+		a.logger.WithFields(fields).Error("Commit event buffer is full, stop the node")
+		a.config.NotifyStop(err)
+		// keep blocking instead of dropping the event, otherwise rbft regards the block as delivered and goes on
+		// committing the later heights, which leaves a height gap to the executor before the stop takes effect
+		select {
+		case a.BlockC <- commitEvent:
+		case <-a.ctx.Done():
+		}
+		return
+	}
+
+	start := time.Now()
+	for {
+		select {
+		case a.BlockC <- commitEvent:
+			a.logger.WithFields(fields).Warnf("Commit event buffer is available after blocking %v", time.Since(start))
+			return
+		case <-time.After(commitEventBlockWarnInterval):
+			a.logger.WithFields(fields).Errorf("Commit event buffer is full, consensus has been blocked for %v", time.Since(start))
+		case <-a.ctx.Done():
+			return
+		}
+	}
 }
 
 func (a *RBFTAdaptor) GetCommitChannel() chan *common.CommitEvent {
@@ -78,7 +78,7 @@ func mockConfig(t *testing.T, ctrl *gomock.Controller) (map[int32]p2p.Pipe, *com
 	conf.BlockSync = mockBlockSync
 
 	mockTxpool := mock_txpool.NewMockMinimalTxPool[types.Transaction, *types.Transaction](500, ctrl)
-	conf.TxPool = mockTxpool
+	conf.TxPool = common.NewMockTxPool(mockTxpool)
 
 	consensusMsgPipes := make(map[int32]p2p.Pipe)
 	for id, name := range consensus.Type_name {
@@ -0,0 +1,34 @@
+package rbft
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	remoteTxDuplicateCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "axiom_ledger",
+		Subsystem: "rbft",
+		Name:      "remote_tx_duplicate_total",
+		Help:      "the total number of remote txs skipped since they are already known",
+	})
+
+	localTxResubmitCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "axiom_ledger",
+		Subsystem: "rbft",
+		Name:      "local_tx_resubmit_total",
+		Help:      "the total number of local txs resubmitted after view change",
+	})
+
+	proposalTimeoutCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "axiom_ledger",
+		Subsystem: "rbft",
+		Name:      "proposal_timeout_total",
+		Help:      "the total number of proposals(batches) not committed within the proposal timeout",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(remoteTxDuplicateCounter)
+	prometheus.MustRegister(localTxResubmitCounter)
+	prometheus.MustRegister(proposalTimeoutCounter)
+}
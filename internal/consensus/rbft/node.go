@@ -32,15 +32,20 @@ import (
 const (
 	consensusMsgPipeIDPrefix = "consensus_msg_pipe_v1_"
 	txsBroadcastMsgPipeID    = "txs_broadcast_msg_pipe_v1"
+	txsBroadcastMsgType      = "PUSH_TXS"
 )
 
 func init() {
-	repo.Register(repo.ConsensusTypeRbft, true)
+	repo.Register(repo.ConsensusTypeRbft, repo.ConsensusCapabilities{
+		SupportMultiNode:         true,
+		SupportDynamicMembership: true,
+		SupportBFT:               true,
+	})
 }
 
 type Node struct {
 	config                  *common.Config
-	txpool                  txpool.TxPool[types.Transaction, *types.Transaction]
+	txpool                  common.TxPool
 	n                       rbft.InboundNode
 	stack                   *adaptor.RBFTAdaptor
 	logger                  logrus.FieldLogger
@@ -57,9 +62,22 @@ type Node struct {
 	txPreCheck precheck.PreCheck
 
 	txFeed event.Feed
+
+	// txCommitNotifier calls the tx commit observer off the consensus loop, nil means disabled
+	txCommitNotifier *common.TxCommitNotifier
+
+	// localTxs tracks the local txs accepted by tx pool until they are committed, they are resubmitted after view change
+	localTxs *localTxTracker
+
+	// lastReportedHeight is the last height reported to rbft by ReportExecuted or ReportStateUpdated,
+	// it is only accessed by the goroutine reporting states
+	lastReportedHeight uint64
 }
 
 func NewNode(config *common.Config) (*Node, error) {
+	if err := repo.CheckBatchDigestAlgo(repo.ConsensusTypeRbft, config.Repo.GenesisConfig.BatchDigestAlgo); err != nil {
+		return nil, err
+	}
 	rbftConfig, err := generateRbftConfig(config)
 	if err != nil {
 		return nil, err
@@ -101,6 +119,8 @@ func NewNode(config *common.Config) (*Node, error) {
 		network:           config.Network,
 		txPreCheck:        precheck.NewTxPreCheckMgr(ctx, config),
 		txpool:            config.TxPool,
+		localTxs:          newLocalTxTracker(),
+		txCommitNotifier:  common.NewTxCommitNotifier(ctx, config.TxCommitObserver, config.Logger),
 	}, nil
 }
 
@@ -160,6 +180,8 @@ func (n *Node) Start() error {
 	go n.listenBatchMemTxsToBroadcast()
 	go n.listenConsensusMsg()
 	go n.listenTxsBroadcastMsg()
+	go n.listenViewChangeToResubmit()
+	go n.listenProposalTimeout()
 
 	// start txpool engine
 	if err = n.txpool.Start(); err != nil {
@@ -270,6 +292,7 @@ func (n *Node) listenExecutedBlockToReport() {
 			commitEvent := &common.CommitEvent{
 				Block: block,
 			}
+			n.txCommitNotifier.Notify(block)
 			n.stack.PostCommitEvent(commitEvent)
 		case <-n.ctx.Done():
 			return
@@ -287,7 +310,8 @@ func (n *Node) broadcastTxs(txSetData [][]byte) error {
 	}
 
 	n.logger.Debugf("broadcast %d txs", len(txSetData))
-	return n.txsBroadcastMsgPipe.Broadcast(context.TODO(), nil, data)
+	retry := n.config.Repo.ConsensusConfig.BroadcastRetry
+	return common.BroadcastWithRetry(n.ctx, n.txsBroadcastMsgPipe, txsBroadcastMsgType, data, retry.Attempts, retry.Backoff.ToDuration())
 }
 
 func (n *Node) listenBatchMemTxsToBroadcast() {
@@ -332,6 +356,17 @@ func (n *Node) Prepare(tx *types.Transaction) error {
 		return common.ErrorConsensusStart
 	}
 
+	if err := n.submitLocalTx(tx); err != nil {
+		return err
+	}
+	if !n.localTxs.track(tx) {
+		n.logger.Warnf("Too many uncommitted local txs, tx %s will not be resubmitted after view change", tx.RbftGetTxHash())
+	}
+	return nil
+}
+
+// submitLocalTx pre-checks a local tx and adds it to tx pool, then the tx will be broadcast
+func (n *Node) submitLocalTx(tx *types.Transaction) error {
 	txWithResp := &common.TxWithResp{
 		Tx:      tx,
 		CheckCh: make(chan *common.TxResp, 1),
@@ -354,15 +389,47 @@ func (n *Node) Prepare(tx *types.Transaction) error {
 }
 
 func (n *Node) submitTxsFromRemote(txs [][]byte) {
-	var requests []*types.Transaction
+	var candidates []*types.Transaction
+	var hashes []string
+	seen := make(map[string]struct{}, len(txs))
+	duplicates := 0
 	for _, item := range txs {
 		tx := &types.Transaction{}
 		if err := tx.RbftUnmarshal(item); err != nil {
 			n.logger.Error(err)
 			continue
 		}
+		// the same tx may arrive from multiple peers, skip the known ones before pre-check
+		txHash := tx.RbftGetTxHash()
+		if _, ok := seen[txHash]; ok {
+			duplicates++
+			continue
+		}
+		seen[txHash] = struct{}{}
+		candidates = append(candidates, tx)
+		hashes = append(hashes, txHash)
+	}
+
+	// look up the batch in a single round-trip of the txpool event loop
+	var known map[string]struct{}
+	if len(hashes) > 0 {
+		known = n.txpool.FilterKnownTxs(hashes)
+	}
+	requests := make([]*types.Transaction, 0, len(candidates))
+	for i, tx := range candidates {
+		if _, ok := known[hashes[i]]; ok {
+			duplicates++
+			continue
+		}
 		requests = append(requests, tx)
 	}
+	if duplicates > 0 {
+		remoteTxDuplicateCounter.Add(float64(duplicates))
+		n.logger.Debugf("Skip %d known remote txs", duplicates)
+	}
+	if len(requests) == 0 {
+		return
+	}
 
 	n.txFeed.Send(requests)
 	ev := &common.UncheckedTxEvent{
@@ -403,10 +470,33 @@ func (n *Node) Status() (isNormal bool, currentStatus string) {
 	return
 }
 
+// Ready returns nil if the node is able to accept txs, otherwise returns an error describing the current state.
+// A full tx pool is not a consensus state, it is reported by the tx pool itself.
+func (n *Node) Ready() error {
+	status := n.n.Status().Status
+	if status != rbft.Normal && status != data_syncer.InCommitStatus {
+		return statusError(status)
+	}
+	return nil
+}
+
 func (n *Node) GetLowWatermark() uint64 {
 	return n.n.GetLowWatermark()
 }
 
+// IsFinalized reports whether the block at height is covered by the stable checkpoint of rbft
+func (n *Node) IsFinalized(height uint64) bool {
+	return height <= n.n.GetLowWatermark()
+}
+
+func (n *Node) CurrentEpoch() (*types.EpochInfo, error) {
+	epochInfo := n.stack.EpochInfo
+	if epochInfo == nil {
+		return nil, errors.New("epoch info is not initialized")
+	}
+	return epochInfo.Clone(), nil
+}
+
 func (n *Node) ReportState(height uint64, blockHash *types.Hash, txPointerList []*events.TxPointer, ckp *common.Checkpoint, needRemoveTxs bool) {
 	n.logger.Infof("Receive report state: height = %d, blockHash = %s, ckp = %v, needRemoveTxs = %v", height, blockHash, ckp, needRemoveTxs)
 
@@ -428,6 +518,16 @@ func (n *Node) ReportState(height uint64, blockHash *types.Hash, txPointerList [
 		return
 	}
 
+	// ignore stale or duplicate reports in normal operation, reporting a regressed height confuses rbft,
+	// heights may go back only through state update(e.g. rollback)
+	if !n.stack.StateUpdating && n.lastReportedHeight != 0 && height <= n.lastReportedHeight {
+		n.logger.WithFields(logrus.Fields{
+			"height":      height,
+			"last_height": n.lastReportedHeight,
+		}).Warn("Ignore stale report state")
+		return
+	}
+
 	// need update cached epoch info, old epochInfo
 	epochInfo := n.stack.EpochInfo
 	epochChanged := false
@@ -466,6 +566,8 @@ func (n *Node) ReportState(height uint64, blockHash *types.Hash, txPointerList [
 			}
 			n.n.ReportStateUpdated(state)
 		}
+		n.lastReportedHeight = height
+		n.localTxs.removeCommitted(txPointerList)
 
 		if n.stack.StateUpdateHeight == height {
 			n.stack.StateUpdating = false
@@ -494,6 +596,8 @@ func (n *Node) ReportState(height uint64, blockHash *types.Hash, txPointerList [
 		Epoch: currentEpoch,
 	}
 	n.n.ReportExecuted(state)
+	n.lastReportedHeight = height
+	n.localTxs.removeCommitted(txPointerList)
 
 	if n.stack.StateUpdateHeight == height {
 		n.stack.StateUpdating = false
@@ -537,24 +641,30 @@ func (n *Node) switchInBoundNode() bool {
 
 // status2String returns a long description of SystemStatus
 func status2String(status rbft.StatusType) string {
-	switch status {
-	case rbft.Normal:
+	if status == rbft.Normal {
 		return "Normal"
+	}
+	return statusError(status).Error()
+}
+
+// statusError returns the error of an abnormal SystemStatus
+func statusError(status rbft.StatusType) error {
+	switch status {
 	case rbft.InConfChange:
-		return "system is in conf change"
+		return common.ErrInConfChange
 	case rbft.InViewChange:
-		return "system is in view change"
+		return common.ErrInViewChange
 	case rbft.InRecovery:
-		return "system is in recovery"
+		return common.ErrInRecovery
 	case rbft.InSyncState:
-		return "system is in sync state"
+		return common.ErrInSyncState
 	case rbft.StateTransferring:
-		return "system is in state update"
+		return common.ErrStateTransferring
 	case rbft.Pending:
-		return "system is in pending state"
+		return common.ErrPending
 	case rbft.Stopped:
-		return "system is stopped"
+		return common.ErrStopped
 	default:
-		return fmt.Sprintf("Unknown status: %d", status)
+		return errors.Errorf("Unknown status: %d", status)
 	}
 }
@@ -2,6 +2,9 @@ package rbft
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"math/big"
 	"strings"
@@ -18,7 +21,9 @@ import (
 
 	rbft "github.com/axiomesh/axiom-bft"
 	"github.com/axiomesh/axiom-bft/common/consensus"
+	rbfttypes "github.com/axiomesh/axiom-bft/types"
 	"github.com/axiomesh/axiom-kit/log"
+	"github.com/axiomesh/axiom-kit/txpool"
 	"github.com/axiomesh/axiom-kit/txpool/mock_txpool"
 	"github.com/axiomesh/axiom-kit/types"
 	"github.com/axiomesh/axiom-ledger/internal/consensus/common"
@@ -64,6 +69,7 @@ func MockMinNode(ctrl *gomock.Controller, t *testing.T) *Node {
 		txFeed:     event.Feed{},
 		txPreCheck: mockPrecheckMgr,
 		txpool:     consensusConf.TxPool,
+		localTxs:   newLocalTxTracker(),
 	}
 	return node
 }
@@ -100,7 +106,7 @@ func TestStart(t *testing.T) {
 			setupMocks: func(n *Node, ctrl *gomock.Controller) {
 				pool := mock_txpool.NewMockTxPool[types.Transaction, *types.Transaction](ctrl)
 				pool.EXPECT().Start().Return(errors.New("start txpool error")).AnyTimes()
-				n.txpool = pool
+				n.txpool = common.NewMockTxPool(pool)
 			},
 			expectedErrMsg: "start txpool error",
 		},
@@ -121,7 +127,7 @@ func TestStart(t *testing.T) {
 				pool := mock_txpool.NewMockTxPool[types.Transaction, *types.Transaction](ctrl)
 				pool.EXPECT().Start().Return(nil).AnyTimes()
 				pool.EXPECT().GetLocalTxs().Return(data).AnyTimes()
-				n.txpool = pool
+				n.txpool = common.NewMockTxPool(pool)
 			},
 			expectedErrMsg: "",
 		},
@@ -319,6 +325,110 @@ func TestPrepare(t *testing.T) {
 	})
 }
 
+func TestSubmitTxsFromRemoteDedup(t *testing.T) {
+	ast := assert.New(t)
+	ctrl := gomock.NewController(t)
+	node := MockMinNode(ctrl, t)
+
+	signer, err := types.GenerateSigner()
+	ast.Nil(err)
+	to, err := types.GenerateSigner()
+	ast.Nil(err)
+	var txs []*types.Transaction
+	var raw [][]byte
+	for i := 0; i < 3; i++ {
+		tx, err := types.GenerateTransactionWithSigner(uint64(i), to.Addr, big.NewInt(0), nil, signer)
+		ast.Nil(err)
+		data, err := tx.RbftMarshal()
+		ast.Nil(err)
+		txs = append(txs, tx)
+		raw = append(raw, data)
+	}
+
+	// txs[0] is already in pool, txs[1] arrives twice in the batch
+	pool := &batchLookupPool{
+		MockTxPool: mock_txpool.NewMockTxPool[types.Transaction, *types.Transaction](ctrl),
+		known:      map[string]struct{}{txs[0].RbftGetTxHash(): {}},
+	}
+	node.txpool = pool
+
+	var posted []*types.Transaction
+	precheckMgr := mock_precheck.NewMockPreCheck(ctrl)
+	precheckMgr.EXPECT().PostUncheckedTxEvent(gomock.Any()).Do(func(ev *common.UncheckedTxEvent) {
+		posted = ev.Event.([]*types.Transaction)
+	}).Times(1)
+	node.txPreCheck = precheckMgr
+
+	node.submitTxsFromRemote([][]byte{raw[0], raw[1], raw[1], raw[2]})
+	ast.Equal(2, len(posted))
+	ast.Equal(txs[1].RbftGetTxHash(), posted[0].RbftGetTxHash())
+	ast.Equal(txs[2].RbftGetTxHash(), posted[1].RbftGetTxHash())
+
+	// all known, nothing is posted
+	node.submitTxsFromRemote([][]byte{raw[0]})
+}
+
+// batchLookupPool counts the batch lookups of the txs in known, the embedded mock expects no other calls
+type batchLookupPool struct {
+	*mock_txpool.MockTxPool[types.Transaction, *types.Transaction]
+	known   map[string]struct{}
+	lookups int
+}
+
+func (p *batchLookupPool) FilterKnownTxs(hashes []string) map[string]struct{} {
+	p.lookups++
+	known := make(map[string]struct{})
+	for _, hash := range hashes {
+		if _, ok := p.known[hash]; ok {
+			known[hash] = struct{}{}
+		}
+	}
+	return known
+}
+
+func (p *batchLookupPool) PendingBatches() map[string]int64 {
+	return nil
+}
+
+func TestSubmitTxsFromRemoteBatchLookup(t *testing.T) {
+	ast := assert.New(t)
+	ctrl := gomock.NewController(t)
+	node := MockMinNode(ctrl, t)
+
+	signer, err := types.GenerateSigner()
+	ast.Nil(err)
+	to, err := types.GenerateSigner()
+	ast.Nil(err)
+	var txs []*types.Transaction
+	var raw [][]byte
+	for i := 0; i < 100; i++ {
+		tx, err := types.GenerateTransactionWithSigner(uint64(i), to.Addr, big.NewInt(0), nil, signer)
+		ast.Nil(err)
+		data, err := tx.RbftMarshal()
+		ast.Nil(err)
+		txs = append(txs, tx)
+		raw = append(raw, data)
+	}
+
+	pool := &batchLookupPool{
+		MockTxPool: mock_txpool.NewMockTxPool[types.Transaction, *types.Transaction](ctrl),
+		known:      map[string]struct{}{txs[0].RbftGetTxHash(): {}, txs[1].RbftGetTxHash(): {}},
+	}
+	node.txpool = pool
+
+	var posted []*types.Transaction
+	precheckMgr := mock_precheck.NewMockPreCheck(ctrl)
+	precheckMgr.EXPECT().PostUncheckedTxEvent(gomock.Any()).Do(func(ev *common.UncheckedTxEvent) {
+		posted = ev.Event.([]*types.Transaction)
+	}).Times(1)
+	node.txPreCheck = precheckMgr
+
+	node.submitTxsFromRemote(raw)
+	ast.Equal(1, pool.lookups, "one pool round-trip per message")
+	ast.Equal(len(txs)-2, len(posted))
+	ast.Equal(txs[2].RbftGetTxHash(), posted[0].RbftGetTxHash())
+}
+
 func TestStop(t *testing.T) {
 	ast := assert.New(t)
 	ctrl := gomock.NewController(t)
@@ -360,6 +470,40 @@ func TestReadConfig(t *testing.T) {
 	ast.Equal(5*time.Minute, rbftConf.CheckPoolTimeout)
 }
 
+func TestNewNodeRejectsNonMD5BatchDigest(t *testing.T) {
+	ast := assert.New(t)
+	ctrl := gomock.NewController(t)
+	logger := log.NewWithModule("consensus")
+	consensusConf, _ := testutil.MockConsensusConfig(logger, ctrl, t)
+
+	// a pre-prepare whose batch digest is hashed by sha256 with the same layout as the txpool
+	hashList := []string{"tx1", "tx2"}
+	timestamp := time.Now().UnixNano()
+	h := sha256.New()
+	for _, txHash := range hashList {
+		_, _ = h.Write([]byte(txHash))
+	}
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, uint64(timestamp))
+	_, _ = h.Write(b)
+	preprep := &consensus.PrePrepare{
+		BatchDigest: hex.EncodeToString(h.Sum(nil)),
+		HashBatch:   &consensus.HashBatch{RequestHashList: hashList, Timestamp: timestamp},
+	}
+
+	// backups recompute the digest of pre-prepare with md5, which is the same as GenerateBatchHash,
+	// the mismatch makes them send view change, so rbft must refuse a non-md5 algorithm
+	md5Batch := &txpool.RequestHashBatch[types.Transaction, *types.Transaction]{
+		TxHashList: preprep.HashBatch.RequestHashList,
+		Timestamp:  preprep.HashBatch.Timestamp,
+	}
+	ast.NotEqual(md5Batch.GenerateBatchHash(), preprep.BatchDigest)
+
+	consensusConf.Repo.GenesisConfig.BatchDigestAlgo = repo.BatchDigestSha256
+	_, err := NewNode(consensusConf)
+	ast.ErrorContains(err, "batch_digest_algo")
+}
+
 func TestStep(t *testing.T) {
 	ast := assert.New(t)
 	ctrl := gomock.NewController(t)
@@ -405,6 +549,27 @@ func TestReportState(t *testing.T) {
 	})
 }
 
+func TestReportStateOutOfOrder(t *testing.T) {
+	ast := assert.New(t)
+	ctrl := gomock.NewController(t)
+	node := MockMinNode(ctrl, t)
+
+	var reported []uint64
+	mockRbft := rbft.NewMockNode[types.Transaction, *types.Transaction](ctrl)
+	mockRbft.EXPECT().ArchiveMode().Return(false).AnyTimes()
+	mockRbft.EXPECT().ReportExecuted(gomock.Any()).Do(func(state *rbfttypes.ServiceState) {
+		reported = append(reported, state.MetaState.Height)
+	}).AnyTimes()
+	node.n = mockRbft
+
+	block := testutil.ConstructBlock("blockHash", uint64(10))
+	for _, height := range []uint64{10, 11, 11, 9, 12} {
+		node.ReportState(height, block.Hash(), nil, nil, false)
+	}
+	ast.Equal([]uint64{10, 11, 12}, reported)
+	ast.EqualValues(12, node.lastReportedHeight)
+}
+
 func TestNotifyStop(t *testing.T) {
 	ast := assert.New(t)
 	ctrl := gomock.NewController(t)
@@ -466,3 +631,61 @@ func TestStatus2String(t *testing.T) {
 		ast.Equal(assertStatusStr, statusStr)
 	}
 }
+
+func TestReady(t *testing.T) {
+	ast := assert.New(t)
+	ctrl := gomock.NewController(t)
+	node := MockMinNode(ctrl, t)
+
+	var status rbft.StatusType
+	mockRbft := rbft.NewMockMinimalNode[types.Transaction, *types.Transaction](ctrl)
+	mockRbft.EXPECT().Status().DoAndReturn(func() rbft.NodeStatus {
+		return rbft.NodeStatus{Status: status}
+	}).AnyTimes()
+	node.n = mockRbft
+
+	poolFull := false
+	pool := mock_txpool.NewMockTxPool[types.Transaction, *types.Transaction](ctrl)
+	pool.EXPECT().IsPoolFull().DoAndReturn(func() bool {
+		return poolFull
+	}).AnyTimes()
+	node.txpool = common.NewMockTxPool(pool)
+
+	assertMapping := map[rbft.StatusType]error{
+		rbft.InConfChange:      common.ErrInConfChange,
+		rbft.InViewChange:      common.ErrInViewChange,
+		rbft.InRecovery:        common.ErrInRecovery,
+		rbft.InSyncState:       common.ErrInSyncState,
+		rbft.StateTransferring: common.ErrStateTransferring,
+		rbft.Pending:           common.ErrPending,
+		rbft.Stopped:           common.ErrStopped,
+	}
+	for s, expectErr := range assertMapping {
+		status = s
+		err := node.Ready()
+		ast.True(errors.Is(err, expectErr))
+		ast.Equal(status2String(s), err.Error())
+	}
+
+	status = 1000
+	ast.Equal("Unknown status: 1000", node.Ready().Error())
+
+	status = rbft.Normal
+	ast.Nil(node.Ready())
+
+	// a full pool does not make the consensus abnormal
+	poolFull = true
+	ast.Nil(node.Ready())
+}
+
+func TestCurrentEpoch(t *testing.T) {
+	ast := assert.New(t)
+	ctrl := gomock.NewController(t)
+	node := MockMinNode(ctrl, t)
+
+	epochInfo, err := node.CurrentEpoch()
+	ast.Nil(err)
+	ast.Equal(node.stack.EpochInfo.Epoch, epochInfo.Epoch)
+	ast.Equal(node.stack.EpochInfo.StartBlock, epochInfo.StartBlock)
+	ast.Equal(node.stack.EpochInfo.EpochPeriod, epochInfo.EpochPeriod)
+}
@@ -0,0 +1,229 @@
+package rbft
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	rbft "github.com/axiomesh/axiom-bft"
+	"github.com/axiomesh/axiom-kit/types"
+	"github.com/axiomesh/axiom-ledger/pkg/events"
+)
+
+// maxTrackedLocalTxs limits the memory used by tracking local txs, txs exceeding it will not be resubmitted
+const maxTrackedLocalTxs = 10000
+
+// statusCheckInterval is the interval of checking whether a view change is finished
+var statusCheckInterval = time.Second
+
+// localTxTracker tracks the local txs which are accepted by tx pool but not committed yet
+type localTxTracker struct {
+	lock sync.Mutex
+	txs  map[string]*trackedTx
+}
+
+type trackedTx struct {
+	tx *types.Transaction
+	// since is the time when the tx is tracked or last reported as stalled
+	since time.Time
+}
+
+func newLocalTxTracker() *localTxTracker {
+	return &localTxTracker{
+		txs: make(map[string]*trackedTx),
+	}
+}
+
+func (t *localTxTracker) track(tx *types.Transaction) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if len(t.txs) >= maxTrackedLocalTxs {
+		return false
+	}
+	t.txs[tx.RbftGetTxHash()] = &trackedTx{tx: tx, since: time.Now()}
+	return true
+}
+
+func (t *localTxTracker) untrack(txHash string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.txs, txHash)
+}
+
+func (t *localTxTracker) removeCommitted(txPointerList []*events.TxPointer) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for _, p := range txPointerList {
+		delete(t.txs, p.Hash.String())
+	}
+}
+
+func (t *localTxTracker) list() []*types.Transaction {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	txs := make([]*types.Transaction, 0, len(t.txs))
+	for _, item := range t.txs {
+		txs = append(txs, item.tx)
+	}
+	return txs
+}
+
+// stalled returns the txs which are not committed within timeout, the tracked time of them is reset,
+// so a stalled tx is reported once per timeout
+func (t *localTxTracker) stalled(timeout time.Duration) []*types.Transaction {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := time.Now()
+	var txs []*types.Transaction
+	for _, item := range t.txs {
+		if now.Sub(item.since) >= timeout {
+			item.since = now
+			txs = append(txs, item.tx)
+		}
+	}
+	return txs
+}
+
+func (t *localTxTracker) len() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return len(t.txs)
+}
+
+// listenViewChangeToResubmit resubmits the tracked local txs once a view change is finished,
+// in-flight txs may be dropped during view change and would otherwise silently vanish
+func (n *Node) listenViewChangeToResubmit() {
+	ticker := time.NewTicker(statusCheckInterval)
+	defer ticker.Stop()
+
+	inViewChange := false
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-ticker.C:
+			switch n.n.Status().Status {
+			case rbft.InViewChange:
+				inViewChange = true
+			case rbft.Normal:
+				if inViewChange {
+					inViewChange = false
+					n.resubmitLocalTxs()
+				}
+			}
+		}
+	}
+}
+
+// resubmitLocalTxs re-proposes the tracked local txs which are missing from tx pool
+func (n *Node) resubmitLocalTxs() {
+	resubmitted, dropped := n.resubmitTxs(n.localTxs.list())
+	if resubmitted > 0 || dropped > 0 {
+		localTxResubmitCounter.Add(float64(resubmitted))
+		n.logger.Infof("Resubmit %d local txs after view change, drop %d", resubmitted, dropped)
+	}
+}
+
+// resubmitTxs re-proposes the txs which are missing from tx pool, the txs failed to resubmit are untracked
+func (n *Node) resubmitTxs(txs []*types.Transaction) (resubmitted int, dropped int) {
+	for _, tx := range txs {
+		txHash := tx.RbftGetTxHash()
+		if n.txpool.GetPendingTxByHash(txHash) != nil {
+			continue
+		}
+		// tx may be committed or invalid now(e.g. nonce too low), stop tracking it
+		if err := n.submitLocalTx(tx); err != nil {
+			n.localTxs.untrack(txHash)
+			n.logger.WithFields(logrus.Fields{"hash": txHash, "err": err}).Debug("Drop local tx from resubmission")
+			dropped++
+			continue
+		}
+		resubmitted++
+	}
+	return resubmitted, dropped
+}
+
+// proposalTracker remembers the pending proposals(batches) which are reported as timed out,
+// so each proposal is counted once no matter how long it stays pending
+type proposalTracker struct {
+	reported map[string]struct{}
+}
+
+func newProposalTracker() *proposalTracker {
+	return &proposalTracker{
+		reported: make(map[string]struct{}),
+	}
+}
+
+// timedOut returns the digests of the pending batches which exceed timeout for the first time,
+// the committed(no longer pending) batches are forgotten
+func (t *proposalTracker) timedOut(batches map[string]int64, timeout time.Duration, now time.Time) []string {
+	for digest := range t.reported {
+		if _, ok := batches[digest]; !ok {
+			delete(t.reported, digest)
+		}
+	}
+	var digests []string
+	for digest, timestamp := range batches {
+		if _, ok := t.reported[digest]; ok {
+			continue
+		}
+		if now.Sub(time.Unix(0, timestamp)) >= timeout {
+			t.reported[digest] = struct{}{}
+			digests = append(digests, digest)
+		}
+	}
+	return digests
+}
+
+// listenProposalTimeout reports the proposals(batches) which are not committed within the proposal timeout,
+// view change is handled by rbft, it only surfaces the stalled proposals and optionally resubmits the local txs
+// which are missing from tx pool. Each proposal is counted once, and the warning is logged at most once per timeout.
+func (n *Node) listenProposalTimeout() {
+	rbftConfig := n.config.Repo.ConsensusConfig.Rbft
+	timeout := rbftConfig.ProposalTimeout.ToDuration()
+	if timeout <= 0 {
+		return
+	}
+	ticker := time.NewTicker(statusCheckInterval)
+	defer ticker.Stop()
+
+	tracker := newProposalTracker()
+	var lastWarn time.Time
+	var unwarned []string
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+			if digests := tracker.timedOut(n.txpool.PendingBatches(), timeout, now); len(digests) > 0 {
+				proposalTimeoutCounter.Add(float64(len(digests)))
+				unwarned = append(unwarned, digests...)
+			}
+			if len(unwarned) > 0 && now.Sub(lastWarn) >= timeout {
+				n.logger.WithFields(logrus.Fields{
+					"count":   len(unwarned),
+					"pending": len(tracker.reported),
+					"digest":  unwarned[0],
+					"timeout": timeout,
+					"status":  n.n.Status().Status,
+				}).Warn("Proposals are not committed within proposal timeout")
+				lastWarn = now
+				unwarned = nil
+			}
+
+			// txs dropped in view change are resubmitted after view change
+			if !rbftConfig.ProposalTimeoutResubmit || n.n.Status().Status != rbft.Normal {
+				continue
+			}
+			if txs := n.localTxs.stalled(timeout); len(txs) > 0 {
+				resubmitted, dropped := n.resubmitTxs(txs)
+				if resubmitted > 0 || dropped > 0 {
+					n.logger.Infof("Resubmit %d local txs after proposal timeout, drop %d", resubmitted, dropped)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package rbft
+
+import (
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	rbft "github.com/axiomesh/axiom-bft"
+	"github.com/axiomesh/axiom-kit/types"
+	"github.com/axiomesh/axiom-ledger/internal/consensus/common"
+	"github.com/axiomesh/axiom-ledger/pkg/events"
+	"github.com/axiomesh/axiom-ledger/pkg/repo"
+)
+
+func TestLocalTxTracker(t *testing.T) {
+	ast := assert.New(t)
+	tracker := newLocalTxTracker()
+
+	signer, err := types.GenerateSigner()
+	ast.Nil(err)
+	var txs []*types.Transaction
+	for i := 0; i < 3; i++ {
+		tx, err := types.GenerateTransactionWithSigner(uint64(i), signer.Addr, big.NewInt(0), nil, signer)
+		ast.Nil(err)
+		ast.True(tracker.track(tx))
+		txs = append(txs, tx)
+	}
+	ast.Equal(3, tracker.len())
+
+	tracker.removeCommitted([]*events.TxPointer{{Hash: txs[0].GetHash(), Account: signer.Addr.String(), Nonce: 0}})
+	ast.Equal(2, tracker.len())
+
+	tracker.untrack(txs[1].RbftGetTxHash())
+	ast.Equal(1, tracker.len())
+	ast.Equal(txs[2].RbftGetTxHash(), tracker.list()[0].RbftGetTxHash())
+}
+
+func TestResubmitLocalTxsAfterViewChange(t *testing.T) {
+	ast := assert.New(t)
+	ctrl := gomock.NewController(t)
+	node := MockMinNode(ctrl, t)
+
+	oldInterval := statusCheckInterval
+	statusCheckInterval = 10 * time.Millisecond
+	defer func() {
+		statusCheckInterval = oldInterval
+	}()
+
+	var status atomic.Int64
+	status.Store(int64(rbft.InViewChange))
+	mockRbft := rbft.NewMockMinimalNode[types.Transaction, *types.Transaction](ctrl)
+	mockRbft.EXPECT().Status().DoAndReturn(func() rbft.NodeStatus {
+		return rbft.NodeStatus{Status: rbft.StatusType(status.Load())}
+	}).AnyTimes()
+	node.n = mockRbft
+
+	signer, err := types.GenerateSigner()
+	ast.Nil(err)
+	var txs []*types.Transaction
+	for i := 0; i < 3; i++ {
+		tx, err := types.GenerateTransactionWithSigner(uint64(i), signer.Addr, big.NewInt(0), nil, signer)
+		ast.Nil(err)
+		node.localTxs.track(tx)
+		txs = append(txs, tx)
+	}
+	// txs[0] is still in pool, txs[1] is dropped during view change, txs[2] is committed
+	err = node.txpool.AddLocalTx(txs[0])
+	ast.Nil(err)
+	node.localTxs.removeCommitted([]*events.TxPointer{{Hash: txs[2].GetHash(), Account: signer.Addr.String(), Nonce: 2}})
+
+	go node.listenNewTxToSubmit()
+	go node.listenViewChangeToResubmit()
+	defer node.cancel()
+
+	time.Sleep(5 * statusCheckInterval)
+	ast.Nil(node.txpool.GetPendingTxByHash(txs[1].RbftGetTxHash()))
+
+	// view change finished
+	status.Store(int64(rbft.Normal))
+	ast.Eventually(func() bool {
+		return node.txpool.GetPendingTxByHash(txs[1].RbftGetTxHash()) != nil
+	}, time.Second, statusCheckInterval)
+	ast.Nil(node.txpool.GetPendingTxByHash(txs[2].RbftGetTxHash()))
+	ast.Equal(2, node.localTxs.len())
+}
+
+func TestLocalTxTrackerStalled(t *testing.T) {
+	ast := assert.New(t)
+	tracker := newLocalTxTracker()
+
+	signer, err := types.GenerateSigner()
+	ast.Nil(err)
+	tx, err := types.GenerateTransactionWithSigner(0, signer.Addr, big.NewInt(0), nil, signer)
+	ast.Nil(err)
+	tracker.track(tx)
+
+	ast.Empty(tracker.stalled(time.Minute))
+	time.Sleep(20 * time.Millisecond)
+	stalled := tracker.stalled(10 * time.Millisecond)
+	ast.Equal(1, len(stalled))
+	ast.Equal(tx.RbftGetTxHash(), stalled[0].RbftGetTxHash())
+	// reported once per timeout
+	ast.Empty(tracker.stalled(10 * time.Millisecond))
+}
+
+func TestResubmitLocalTxsAfterProposalTimeout(t *testing.T) {
+	ast := assert.New(t)
+	ctrl := gomock.NewController(t)
+	node := MockMinNode(ctrl, t)
+	node.config.Repo.ConsensusConfig.Rbft.ProposalTimeout = repo.Duration(20 * time.Millisecond)
+	node.config.Repo.ConsensusConfig.Rbft.ProposalTimeoutResubmit = true
+
+	oldInterval := statusCheckInterval
+	statusCheckInterval = 10 * time.Millisecond
+	defer func() {
+		statusCheckInterval = oldInterval
+	}()
+
+	signer, err := types.GenerateSigner()
+	ast.Nil(err)
+	tx, err := types.GenerateTransactionWithSigner(0, signer.Addr, big.NewInt(0), nil, signer)
+	ast.Nil(err)
+	// the tx is dropped before it is committed
+	node.localTxs.track(tx)
+	// one proposal is pending for an hour, the other is just proposed
+	node.txpool = &pendingBatchesPool{
+		TxPool: node.txpool,
+		batches: map[string]int64{
+			"stalled": time.Now().Add(-time.Hour).UnixNano(),
+			"fresh":   time.Now().Add(time.Hour).UnixNano(),
+		},
+	}
+	before := testutil.ToFloat64(proposalTimeoutCounter)
+
+	go node.listenNewTxToSubmit()
+	go node.listenProposalTimeout()
+	defer node.cancel()
+
+	ast.Eventually(func() bool {
+		return node.txpool.GetPendingTxByHash(tx.RbftGetTxHash()) != nil
+	}, time.Second, statusCheckInterval)
+	ast.Equal(1, node.localTxs.len())
+
+	// the stalled proposal is counted once however long it is pending
+	time.Sleep(5 * statusCheckInterval)
+	ast.Equal(before+1, testutil.ToFloat64(proposalTimeoutCounter))
+}
+
+// pendingBatchesPool reports the given batches as pending
+type pendingBatchesPool struct {
+	common.TxPool
+	batches map[string]int64
+}
+
+func (p *pendingBatchesPool) PendingBatches() map[string]int64 {
+	return p.batches
+}
+
+func TestProposalTracker(t *testing.T) {
+	ast := assert.New(t)
+	tracker := newProposalTracker()
+	now := time.Now()
+	batches := map[string]int64{
+		"a": now.Add(-2 * time.Minute).UnixNano(),
+		"b": now.Add(-30 * time.Second).UnixNano(),
+	}
+
+	ast.Equal([]string{"a"}, tracker.timedOut(batches, time.Minute, now))
+	ast.Empty(tracker.timedOut(batches, time.Minute, now))
+	ast.Equal([]string{"b"}, tracker.timedOut(batches, time.Minute, now.Add(time.Minute)))
+
+	// committed batches are forgotten
+	delete(batches, "a")
+	ast.Empty(tracker.timedOut(batches, time.Minute, now.Add(time.Minute)))
+	ast.Equal(1, len(tracker.reported))
+}
@@ -151,7 +151,7 @@ func MockConsensusConfig(logger logrus.FieldLogger, ctrl *gomock.Controller, t *
 	conf.BlockSync = mockBlockSync
 
 	mockTxpool := mock_txpool.NewMockMinimalTxPool[types.Transaction, *types.Transaction](500, ctrl)
-	conf.TxPool = mockTxpool
+	conf.TxPool = common.NewMockTxPool(mockTxpool)
 
 	return conf, mockTxpool
 }
@@ -0,0 +1,235 @@
+package solo
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/axiomesh/axiom-kit/types"
+	"github.com/axiomesh/axiom-ledger/internal/components/timer"
+	"github.com/axiomesh/axiom-ledger/internal/consensus/common"
+)
+
+const (
+	recordTypeChainState = "chain_state"
+	recordTypeTx         = "tx"
+	recordTypeTimeout    = "timeout"
+	recordTypeGenBatch   = "gen_batch"
+	recordTypeSlot       = "slot"
+)
+
+// recordedEvent is a line of the event record file, query events(e.g. getLowWatermarkReq) are not recorded
+type recordedEvent struct {
+	Timestamp int64           `json:"timestamp"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+}
+
+type recordedChainState struct {
+	Height       uint64   `json:"height"`
+	BlockHash    string   `json:"block_hash"`
+	TxHashList   []string `json:"tx_hash_list"`
+	EpochChanged bool     `json:"epoch_changed"`
+}
+
+type recordedTx struct {
+	Hash  string `json:"hash"`
+	From  string `json:"from"`
+	Nonce uint64 `json:"nonce"`
+	// raw tx is required to replay the tx event
+	Raw []byte `json:"raw"`
+}
+
+type recordedGenBatch struct {
+	Typ int `json:"typ"`
+}
+
+type recordedSlot struct {
+	Boundary int64 `json:"boundary"`
+}
+
+const (
+	recordBufferSize = 64 * 1024
+	// recordFlushInterval bounds the events lost in a crash, the buffer is also flushed whenever it is full
+	recordFlushInterval = time.Second
+)
+
+// eventRecorder serializes consensus events handled by listenEvent to a file, it is only used by the event loop goroutine
+type eventRecorder struct {
+	file      *os.File
+	writer    *bufio.Writer
+	lastFlush time.Time
+}
+
+func newEventRecorder(repoRoot string, recordFile string) (*eventRecorder, error) {
+	if recordFile == "" {
+		return nil, nil
+	}
+	if !filepath.IsAbs(recordFile) {
+		recordFile = filepath.Join(repoRoot, recordFile)
+	}
+	if err := os.MkdirAll(filepath.Dir(recordFile), 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create event record dir")
+	}
+	f, err := os.OpenFile(recordFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open event record file")
+	}
+	return &eventRecorder{file: f, writer: bufio.NewWriterSize(f, recordBufferSize), lastFlush: time.Now()}, nil
+}
+
+func (r *eventRecorder) record(ev consensusEvent) error {
+	var (
+		typ  string
+		data any
+	)
+	switch e := ev.(type) {
+	case *chainState:
+		blockHash := ""
+		if e.BlockHash != nil {
+			blockHash = e.BlockHash.String()
+		}
+		txHashList := make([]string, len(e.TxHashList))
+		for i, h := range e.TxHashList {
+			txHashList[i] = h.String()
+		}
+		typ, data = recordTypeChainState, &recordedChainState{
+			Height:       e.Height,
+			BlockHash:    blockHash,
+			TxHashList:   txHashList,
+			EpochChanged: e.EpochChanged,
+		}
+	case *common.TxWithResp:
+		raw, err := e.Tx.RbftMarshal()
+		if err != nil {
+			return err
+		}
+		typ, data = recordTypeTx, &recordedTx{
+			Hash:  e.Tx.RbftGetTxHash(),
+			From:  e.Tx.RbftGetFrom(),
+			Nonce: e.Tx.RbftGetNonce(),
+			Raw:   raw,
+		}
+	case timer.TimeoutEvent:
+		typ, data = recordTypeTimeout, e
+	case *genBatchReq:
+		typ, data = recordTypeGenBatch, &recordedGenBatch{Typ: e.typ}
+	case *slotReq:
+		typ, data = recordTypeSlot, &recordedSlot{Boundary: e.boundary.UnixNano()}
+	default:
+		return nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	line, err := json.Marshal(&recordedEvent{Timestamp: now.UnixNano(), Type: typ, Data: raw})
+	if err != nil {
+		return err
+	}
+	if _, err = r.writer.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	// flush periodically rather than per event, the event loop handles timeout events regularly
+	if now.Sub(r.lastFlush) < recordFlushInterval {
+		return nil
+	}
+	r.lastFlush = now
+	return r.writer.Flush()
+}
+
+func (r *eventRecorder) close() error {
+	if err := r.writer.Flush(); err != nil {
+		return err
+	}
+	return r.file.Close()
+}
+
+func decodeRecordedEvent(rec *recordedEvent) (consensusEvent, error) {
+	switch rec.Type {
+	case recordTypeChainState:
+		e := &recordedChainState{}
+		if err := json.Unmarshal(rec.Data, e); err != nil {
+			return nil, err
+		}
+		state := &chainState{
+			Height:       e.Height,
+			TxHashList:   make([]*types.Hash, len(e.TxHashList)),
+			EpochChanged: e.EpochChanged,
+		}
+		if e.BlockHash != "" {
+			state.BlockHash = types.NewHashByStr(e.BlockHash)
+		}
+		for i, h := range e.TxHashList {
+			state.TxHashList[i] = types.NewHashByStr(h)
+		}
+		return state, nil
+	case recordTypeTx:
+		e := &recordedTx{}
+		if err := json.Unmarshal(rec.Data, e); err != nil {
+			return nil, err
+		}
+		tx := &types.Transaction{}
+		if err := tx.RbftUnmarshal(e.Raw); err != nil {
+			return nil, err
+		}
+		// responses are dropped in replay
+		return &common.TxWithResp{
+			Tx:      tx,
+			CheckCh: make(chan *common.TxResp, 1),
+			PoolCh:  make(chan *common.TxResp, 1),
+		}, nil
+	case recordTypeTimeout:
+		var e timer.TimeoutEvent
+		if err := json.Unmarshal(rec.Data, &e); err != nil {
+			return nil, err
+		}
+		return e, nil
+	case recordTypeGenBatch:
+		e := &recordedGenBatch{}
+		if err := json.Unmarshal(rec.Data, e); err != nil {
+			return nil, err
+		}
+		return &genBatchReq{typ: e.Typ}, nil
+	case recordTypeSlot:
+		e := &recordedSlot{}
+		if err := json.Unmarshal(rec.Data, e); err != nil {
+			return nil, err
+		}
+		return &slotReq{boundary: time.Unix(0, e.Boundary)}, nil
+	default:
+		return nil, fmt.Errorf("unknown recorded event type: %s", rec.Type)
+	}
+}
+
+// ReplayEvents feeds a recorded event log back through the event loop in order, it is used to reproduce
+// a consensus sequence for debugging, the node should be started from the same initial state as the recorded one.
+func (n *Node) ReplayEvents(input io.Reader) (int, error) {
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	count := 0
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		rec := &recordedEvent{}
+		if err := json.Unmarshal(scanner.Bytes(), rec); err != nil {
+			return count, errors.Wrapf(err, "failed to unmarshal recorded event %d", count)
+		}
+		ev, err := decodeRecordedEvent(rec)
+		if err != nil {
+			return count, errors.Wrapf(err, "failed to decode recorded event %d", count)
+		}
+		n.postMsg(ev)
+		count++
+	}
+	return count, scanner.Err()
+}
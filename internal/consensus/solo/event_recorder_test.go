@@ -0,0 +1,84 @@
+package solo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/axiomesh/axiom-kit/types"
+	"github.com/axiomesh/axiom-ledger/internal/consensus/common"
+)
+
+func TestEventRecorder_RecordAndReplay(t *testing.T) {
+	repoRoot := t.TempDir()
+	recorder, err := newEventRecorder(repoRoot, "")
+	require.Nil(t, err)
+	require.Nil(t, recorder)
+
+	recorder, err = newEventRecorder(repoRoot, "debug/events.log")
+	require.Nil(t, err)
+	require.NotNil(t, recorder)
+
+	tx, err := types.GenerateEmptyTransactionAndSigner()
+	require.Nil(t, err)
+	boundary := time.Unix(0, 2*int64(time.Second))
+	events := []consensusEvent{
+		&common.TxWithResp{Tx: tx},
+		common.Batch,
+		&genBatchReq{typ: 1},
+		&slotReq{boundary: boundary},
+		&chainState{Height: 1, BlockHash: types.NewHashByStr("0x123"), TxHashList: []*types.Hash{tx.GetHash()}, EpochChanged: true},
+		// query event is not recorded
+		&getLowWatermarkReq{Resp: make(chan uint64)},
+	}
+	for _, ev := range events {
+		require.Nil(t, recorder.record(ev))
+	}
+	require.Nil(t, recorder.close())
+
+	node, err := mockSoloNode(t, false)
+	require.Nil(t, err)
+	f, err := os.Open(filepath.Join(repoRoot, "debug/events.log"))
+	require.Nil(t, err)
+	defer f.Close()
+	count, err := node.ReplayEvents(f)
+	require.Nil(t, err)
+	require.Equal(t, 5, count)
+
+	txEv := (<-node.recvCh).(*common.TxWithResp)
+	require.Equal(t, tx.RbftGetTxHash(), txEv.Tx.RbftGetTxHash())
+	require.Equal(t, common.Batch, <-node.recvCh)
+	require.Equal(t, 1, (<-node.recvCh).(*genBatchReq).typ)
+	require.True(t, boundary.Equal((<-node.recvCh).(*slotReq).boundary))
+	state := (<-node.recvCh).(*chainState)
+	require.Equal(t, uint64(1), state.Height)
+	require.Equal(t, types.NewHashByStr("0x123").String(), state.BlockHash.String())
+	require.Equal(t, tx.GetHash().String(), state.TxHashList[0].String())
+	require.True(t, state.EpochChanged)
+}
+
+func TestEventRecorder_Flush(t *testing.T) {
+	recorder, err := newEventRecorder(t.TempDir(), "events.log")
+	require.Nil(t, err)
+	fileSize := func() int64 {
+		info, err := recorder.file.Stat()
+		require.Nil(t, err)
+		return info.Size()
+	}
+
+	// events are buffered within the flush interval
+	require.Nil(t, recorder.record(&genBatchReq{typ: 1}))
+	require.Zero(t, fileSize())
+
+	recorder.lastFlush = time.Now().Add(-recordFlushInterval)
+	require.Nil(t, recorder.record(&genBatchReq{typ: 2}))
+	size := fileSize()
+	require.NotZero(t, size)
+
+	require.Nil(t, recorder.record(&genBatchReq{typ: 3}))
+	require.Equal(t, size, fileSize())
+	require.Nil(t, recorder.close())
+}
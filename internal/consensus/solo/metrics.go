@@ -25,9 +25,30 @@ var (
 		},
 		[]string{"type"},
 	)
+
+	generatedBlockCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "axiom_ledger",
+			Subsystem: "solo",
+			Name:      "generated_block_total",
+			Help:      "the total number of generated blocks by reason",
+		},
+		[]string{"reason"},
+	)
+
+	skippedSlotCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "axiom_ledger",
+			Subsystem: "solo",
+			Name:      "skipped_slot_total",
+			Help:      "the total number of slot boundaries missed by the slot timer",
+		},
+	)
 )
 
 func init() {
 	prometheus.MustRegister(batchInterval)
 	prometheus.MustRegister(minBatchIntervalDuration)
+	prometheus.MustRegister(generatedBlockCounter)
+	prometheus.MustRegister(skippedSlotCounter)
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/gogo/protobuf/sortkeys"
+	"github.com/hashicorp/golang-lru/v2/expirable"
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
 	"github.com/sirupsen/logrus"
@@ -24,7 +25,7 @@ import (
 )
 
 func init() {
-	repo.Register(repo.ConsensusTypeSolo, false)
+	repo.Register(repo.ConsensusTypeSolo, repo.ConsensusCapabilities{})
 }
 
 type Node struct {
@@ -41,6 +42,18 @@ type Node struct {
 	txPreCheck   precheck.PreCheck
 	started      atomic.Bool
 	epcCnf       *epochConfig
+	// seenTxs caches the hashes of recently accepted txs to reject client retries, nil means disabled
+	seenTxs *expirable.LRU[string, struct{}]
+	// recorder records handled events for debugging, nil means disabled
+	recorder *eventRecorder
+	// lastCheckpoint is the height of the last reported checkpoint, blocks up to it are finalized
+	lastCheckpoint atomic.Uint64
+	// slot is the duration of block slots, 0 means blocks are produced by batch timers
+	slot time.Duration
+	// systemTxs maps block height to the queued system txs which are prepended to the block
+	systemTxs map[uint64][]*types.Transaction
+	// txCommitNotifier calls the tx commit observer off the consensus loop, nil means disabled
+	txCommitNotifier *common.TxCommitNotifier
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -50,12 +63,15 @@ type Node struct {
 }
 
 func NewNode(config *common.Config) (*Node, error) {
-	currentEpoch := config.ChainState.EpochInfo
+	currentEpoch, err := loadCurrentEpoch(config)
+	if err != nil {
+		return nil, err
+	}
 
 	epochConf := &epochConfig{
 		epochPeriod:         currentEpoch.EpochPeriod,
 		startBlock:          currentEpoch.StartBlock,
-		checkpoint:          currentEpoch.ConsensusParams.CheckpointPeriod,
+		checkpoint:          checkpointPeriodOrDefault(config.Logger, currentEpoch.ConsensusParams.CheckpointPeriod),
 		enableGenEmptyBlock: currentEpoch.ConsensusParams.EnableTimedGenEmptyBlock,
 	}
 
@@ -64,37 +80,52 @@ func NewNode(config *common.Config) (*Node, error) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	soloNode := &Node{
-		config:       config,
-		blockCh:      make(chan *txpool.RequestHashBatch[types.Transaction, *types.Transaction], maxChanSize),
-		commitC:      make(chan *common.CommitEvent, maxChanSize),
-		batchDigestM: make(map[uint64]string),
-		recvCh:       recvCh,
-		lastExec:     config.Applied,
-		txpool:       config.TxPool,
-		network:      config.Network,
-		ctx:          ctx,
-		cancel:       cancel,
-		txPreCheck:   precheck.NewTxPreCheckMgr(ctx, config),
-		epcCnf:       epochConf,
-		logger:       config.Logger,
+		config:           config,
+		blockCh:          make(chan *txpool.RequestHashBatch[types.Transaction, *types.Transaction], maxChanSize),
+		commitC:          make(chan *common.CommitEvent, maxChanSize),
+		batchDigestM:     make(map[uint64]string),
+		systemTxs:        make(map[uint64][]*types.Transaction),
+		recvCh:           recvCh,
+		lastExec:         config.Applied,
+		txpool:           config.TxPool,
+		network:          config.Network,
+		ctx:              ctx,
+		cancel:           cancel,
+		txPreCheck:       precheck.NewTxPreCheckMgr(ctx, config),
+		epcCnf:           epochConf,
+		logger:           config.Logger,
+		seenTxs:          newSeenTxCache(config.Repo.ConsensusConfig.Solo),
+		slot:             config.Repo.ConsensusConfig.Solo.SlotDuration.ToDuration(),
+		txCommitNotifier: common.NewTxCommitNotifier(ctx, config.TxCommitObserver, config.Logger),
 	}
+	soloNode.lastCheckpoint.Store(checkpointFloor(config.Applied, epochConf.checkpoint))
 	batchTimerMgr := &batchTimerManager{Timer: timer.NewTimerManager(config.Logger)}
 
-	err := batchTimerMgr.CreateTimer(common.Batch, config.Repo.ConsensusConfig.Solo.BatchTimeout.ToDuration(), soloNode.handleTimeoutEvent)
+	err = batchTimerMgr.CreateTimer(common.Batch, config.Repo.ConsensusConfig.Solo.BatchTimeout.ToDuration(), soloNode.handleTimeoutEvent)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 	err = batchTimerMgr.CreateTimer(common.NoTxBatch, config.Repo.ConsensusConfig.TimedGenBlock.NoTxBatchTimeout.ToDuration(), soloNode.handleTimeoutEvent)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 	soloNode.batchMgr = batchTimerMgr
+
+	// the recorder is opened after all fallible steps, so no error path leaks the record file
+	soloNode.recorder, err = newEventRecorder(config.Repo.RepoRoot, config.Repo.ConsensusConfig.Solo.EventRecordFile)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
 	soloNode.logger.Infof("SOLO lastExec = %d", soloNode.lastExec)
 	soloNode.logger.Infof("SOLO epoch period = %d", soloNode.epcCnf.epochPeriod)
 	soloNode.logger.Infof("SOLO checkpoint period = %d", soloNode.epcCnf.checkpoint)
 	soloNode.logger.Infof("SOLO enable gen empty block = %t", soloNode.epcCnf.enableGenEmptyBlock)
 	soloNode.logger.Infof("SOLO no-tx batch timeout = %v", config.Repo.ConsensusConfig.TimedGenBlock.NoTxBatchTimeout.ToDuration())
 	soloNode.logger.Infof("SOLO batch timeout = %v", config.Repo.ConsensusConfig.Solo.BatchTimeout.ToDuration())
+	soloNode.logger.Infof("SOLO slot duration = %v", soloNode.slot)
 	soloNode.logger.Infof("SOLO batch size = %d", config.GenesisEpochInfo.ConsensusParams.BlockMaxTxNum)
 	soloNode.logger.Infof("SOLO pool size = %d", config.Repo.ConsensusConfig.TxPool.PoolSize)
 	soloNode.logger.Infof("SOLO tolerance time = %v", config.Repo.ConsensusConfig.TxPool.ToleranceTime.ToDuration())
@@ -103,6 +134,33 @@ func NewNode(config *common.Config) (*Node, error) {
 	return soloNode, nil
 }
 
+// loadCurrentEpoch returns the current epoch info restored in chain state, if it is not available(e.g. the epoch
+// contract is not queryable yet when recovering from disk), fall back to the genesis epoch info instead of refusing to start.
+func loadCurrentEpoch(config *common.Config) (*types.EpochInfo, error) {
+	if config.ChainState.EpochInfo != nil {
+		return config.ChainState.EpochInfo, nil
+	}
+	if config.GenesisEpochInfo == nil {
+		return nil, errors.New("current epoch info and genesis epoch info are both unavailable")
+	}
+	config.Logger.Warning(`
+	+=================================================================+
+	|                                                                 |
+	|      current epoch info is unavailable, SOLO falls back to      |
+	|      genesis epoch info, check the epoch contract state!!!      |
+	|                                                                 |
+	+=================================================================+
+	`)
+	return config.GenesisEpochInfo, nil
+}
+
+func newSeenTxCache(cnf repo.Solo) *expirable.LRU[string, struct{}] {
+	if cnf.DedupCacheSize == 0 {
+		return nil
+	}
+	return expirable.NewLRU[string, struct{}](int(cnf.DedupCacheSize), nil, cnf.DedupTTL.ToDuration())
+}
+
 func (n *Node) GetLowWatermark() uint64 {
 	req := &getLowWatermarkReq{
 		Resp: make(chan uint64),
@@ -111,7 +169,105 @@ func (n *Node) GetLowWatermark() uint64 {
 	return <-req.Resp
 }
 
+// IsFinalized reports whether the block at height is covered by a reported checkpoint, an executed block
+// is not finalized until the checkpoint is reported.
+func (n *Node) IsFinalized(height uint64) bool {
+	return height <= n.lastCheckpoint.Load()
+}
+
+// checkpointPeriodOrDefault falls back to the default checkpoint period if period is 0(e.g. a bad epoch config),
+// otherwise the event loop would panic with a divide-by-zero on the next report state.
+func checkpointPeriodOrDefault(logger logrus.FieldLogger, period uint64) uint64 {
+	if period == 0 {
+		logger.WithField("default", defaultCheckpointPeriod).Error("Checkpoint period of epoch config is 0, fall back to the default")
+		return defaultCheckpointPeriod
+	}
+	return period
+}
+
+// checkpointFloor returns the height of the last checkpoint not higher than height
+func checkpointFloor(height uint64, checkpoint uint64) uint64 {
+	if checkpoint == 0 {
+		return height
+	}
+	return height - height%checkpoint
+}
+
+func (n *Node) CurrentEpoch() (*types.EpochInfo, error) {
+	if !n.started.Load() {
+		return n.currentEpoch()
+	}
+	req := &getCurrentEpochReq{
+		Resp: make(chan *types.EpochInfo, 1),
+	}
+	n.postMsg(req)
+	epochInfo := <-req.Resp
+	if epochInfo == nil {
+		return nil, errors.New("epoch info is not initialized")
+	}
+	return epochInfo, nil
+}
+
+// currentEpoch synthesizes the epoch info from epcCnf, which is what solo actually uses
+func (n *Node) currentEpoch() (*types.EpochInfo, error) {
+	if n.config.ChainState.EpochInfo == nil {
+		return nil, errors.New("epoch info is not initialized")
+	}
+	epochInfo := n.config.ChainState.EpochInfo.Clone()
+	epochInfo.StartBlock = n.epcCnf.startBlock
+	epochInfo.EpochPeriod = n.epcCnf.epochPeriod
+	epochInfo.ConsensusParams.CheckpointPeriod = n.epcCnf.checkpoint
+	epochInfo.ConsensusParams.EnableTimedGenEmptyBlock = n.epcCnf.enableGenEmptyBlock
+	return epochInfo, nil
+}
+
+// waitForPeers blocks until the number of connected validator peers reaches the configured threshold
+func (n *Node) waitForPeers() error {
+	threshold := n.config.Repo.ConsensusConfig.Solo.WaitForPeers
+	if threshold == 0 {
+		return nil
+	}
+	timeout := time.After(n.config.Repo.ConsensusConfig.Solo.WaitForPeersTimeout.ToDuration())
+	ticker := time.NewTicker(waitForPeersInterval)
+	defer ticker.Stop()
+	for {
+		connected := uint64(len(n.network.GetConnectedPeers(n.peerCandidates())))
+		if connected >= threshold {
+			n.logger.Infof("SOLO connected peers = %d, reach threshold %d", connected, threshold)
+			return nil
+		}
+		n.logger.Infof("SOLO waiting for peers, connected = %d, threshold = %d", connected, threshold)
+		select {
+		case <-n.ctx.Done():
+			return errors.New("consensus stopped while waiting for peers")
+		case <-timeout:
+			return fmt.Errorf("wait for peers timeout, connected = %d, threshold = %d", connected, threshold)
+		case <-ticker.C:
+		}
+	}
+}
+
+// peerCandidates returns the p2p ids of validators except self
+func (n *Node) peerCandidates() []string {
+	var peers []string
+	for _, v := range n.config.ChainState.ValidatorSet {
+		if n.config.ChainState.SelfNodeInfo != nil && v.ID == n.config.ChainState.SelfNodeInfo.ID {
+			continue
+		}
+		nodeInfo, err := n.config.ChainState.GetNodeInfo(v.ID)
+		if err != nil {
+			n.logger.Warnf("Get node info of %d failed: %v", v.ID, err)
+			continue
+		}
+		peers = append(peers, nodeInfo.P2PID)
+	}
+	return peers
+}
+
 func (n *Node) Start() error {
+	if err := n.waitForPeers(); err != nil {
+		return err
+	}
 	n.txpool.Init(txpool.ConsensusConfig{
 		NotifyGenerateBatchFn: n.notifyGenerateBatch,
 	})
@@ -119,19 +275,26 @@ func (n *Node) Start() error {
 	if err != nil {
 		return err
 	}
-	err = n.batchMgr.StartTimer(common.Batch)
-	if err != nil {
-		return err
-	}
-
-	if n.epcCnf.enableGenEmptyBlock && !n.batchMgr.IsTimerActive(common.NoTxBatch) {
-		err = n.batchMgr.StartTimer(common.NoTxBatch)
+	if n.slot > 0 {
+		go n.listenSlot()
+	} else {
+		err = n.batchMgr.StartTimer(common.Batch)
 		if err != nil {
 			return err
 		}
+
+		if n.epcCnf.enableGenEmptyBlock && !n.batchMgr.IsTimerActive(common.NoTxBatch) {
+			err = n.batchMgr.StartTimer(common.NoTxBatch)
+			if err != nil {
+				return err
+			}
+		}
 	}
 	n.txPreCheck.Start()
 	go n.listenEvent()
+	if interval := n.config.Repo.ConsensusConfig.Solo.BatchDigestSweepInterval.ToDuration(); interval > 0 {
+		go n.sweepBatchDigestsPeriodically(interval)
+	}
 	n.started.Store(true)
 	n.logger.Info("Consensus started")
 	return nil
@@ -143,6 +306,11 @@ func (n *Node) Stop() {
 }
 
 func (n *Node) Prepare(tx *types.Transaction) error {
+	txHash := tx.RbftGetTxHash()
+	if n.seenTxs != nil && n.seenTxs.Contains(txHash) {
+		common.TraceRejectedTx(common.RejectReasonDuplicate)
+		return errors.Wrap(common.ErrorDuplicateTx, txHash)
+	}
 	defer n.txFeed.Send([]*types.Transaction{tx})
 	if ready, status := n.getStatus(); !ready {
 		return fmt.Errorf("node get ready failed: %s", status)
@@ -162,6 +330,9 @@ func (n *Node) Prepare(tx *types.Transaction) error {
 	if !resp.Status {
 		return errors.Wrap(common.ErrorAddTxPool, resp.ErrorMsg)
 	}
+	if n.seenTxs != nil {
+		n.seenTxs.Add(txHash, struct{}{})
+	}
 	return nil
 }
 
@@ -177,6 +348,14 @@ func (n *Node) Status() (bool, string) {
 	return n.getStatus()
 }
 
+// Ready returns nil if the node is able to accept txs
+func (n *Node) Ready() error {
+	if !n.started.Load() {
+		return common.ErrorConsensusStart
+	}
+	return nil
+}
+
 func (n *Node) getStatus() (bool, string) {
 	if !n.started.Load() {
 		return false, common.ErrorConsensusStart.Error()
@@ -202,6 +381,233 @@ func (n *Node) ReportState(height uint64, blockHash *types.Hash, txPointerList [
 	n.postMsg(state)
 }
 
+// FastForward sets lastExec to the given height directly without replaying intermediate checkpoints,
+// it is used right after a snapshot restore, the height must be consistent with the restored chain state.
+func (n *Node) FastForward(height uint64, blockHash *types.Hash) error {
+	if !n.started.Load() {
+		return n.fastForward(height, blockHash)
+	}
+	req := &fastForwardReq{
+		height:    height,
+		blockHash: blockHash,
+		errC:      make(chan error, 1),
+	}
+	n.postMsg(req)
+	return <-req.errC
+}
+
+// removeBatchesUpTo removes batches whose height <= height from batchDigestM and txpool,
+// returns the removed digests sorted by height.
+func (n *Node) removeBatchesUpTo(height uint64) []string {
+	// flatten batchDigestM{<height:> <digest>} to []digest, sort by height
+	heightList := make([]uint64, 0)
+	for h := range n.batchDigestM {
+		if h <= height {
+			heightList = append(heightList, h)
+		}
+	}
+	sortkeys.Uint64s(heightList)
+	digestList := make([]string, len(heightList))
+	lo.ForEach(heightList, func(h uint64, index int) {
+		digestList[index] = n.batchDigestM[h]
+		delete(n.batchDigestM, h)
+	})
+	n.removeBatches(digestList)
+	return digestList
+}
+
+// removeBatches removes the batches from txpool in order, a huge digest list(e.g. after a delayed checkpoint)
+// is split into chunks so that the event loop of txpool is not blocked by a single huge removal.
+func (n *Node) removeBatches(digestList []string) {
+	if len(digestList) == 0 {
+		return
+	}
+	chunkSize := int(n.config.Repo.ConsensusConfig.Solo.RemoveBatchesChunkSize)
+	if chunkSize <= 0 || len(digestList) <= chunkSize {
+		n.txpool.RemoveBatches(digestList)
+		return
+	}
+	for _, chunk := range lo.Chunk(digestList, chunkSize) {
+		n.txpool.RemoveBatches(chunk)
+	}
+}
+
+// sweepBatchDigests removes batches below the last checkpoint of persisted chain meta, so that batchDigestM
+// does not grow when the checkpoint is not reported in time.
+func (n *Node) sweepBatchDigests() {
+	chainMeta := n.config.ChainState.ChainMeta
+	if chainMeta == nil || n.epcCnf.checkpoint == 0 {
+		return
+	}
+	lastCheckpoint := chainMeta.Height - chainMeta.Height%n.epcCnf.checkpoint
+	if digestList := n.removeBatchesUpTo(lastCheckpoint); len(digestList) != 0 {
+		n.logger.WithFields(logrus.Fields{
+			"checkpoint": lastCheckpoint,
+			"removed":    len(digestList),
+		}).Info("Sweep batch digests")
+	}
+}
+
+// Config returns the current effective consensus parameters, which reflect the epoch changes.
+func (n *Node) Config() *ConsensusRuntimeConfig {
+	if !n.started.Load() {
+		return n.runtimeConfig()
+	}
+	req := &getRuntimeConfigReq{
+		Resp: make(chan *ConsensusRuntimeConfig, 1),
+	}
+	n.postMsg(req)
+	return <-req.Resp
+}
+
+func (n *Node) runtimeConfig() *ConsensusRuntimeConfig {
+	cnf := &ConsensusRuntimeConfig{
+		BatchTimeout:        n.config.Repo.ConsensusConfig.Solo.BatchTimeout.ToDuration(),
+		NoTxBatchTimeout:    n.config.Repo.ConsensusConfig.TimedGenBlock.NoTxBatchTimeout.ToDuration(),
+		SlotDuration:        n.slot,
+		PoolSize:            n.config.Repo.ConsensusConfig.TxPool.PoolSize,
+		StartBlock:          n.epcCnf.startBlock,
+		EpochPeriod:         n.epcCnf.epochPeriod,
+		CheckpointPeriod:    n.epcCnf.checkpoint,
+		EnableGenEmptyBlock: n.epcCnf.enableGenEmptyBlock,
+	}
+	if epochInfo, err := n.currentEpoch(); err == nil {
+		cnf.BatchSize = epochInfo.ConsensusParams.BlockMaxTxNum
+	}
+	return cnf
+}
+
+// PendingBatchDigests returns the batches which have been generated but not removed by checkpoint, indexed by height.
+func (n *Node) PendingBatchDigests() map[uint64]string {
+	if !n.started.Load() {
+		return lo.Assign(n.batchDigestM)
+	}
+	req := &getPendingBatchDigestsReq{
+		Resp: make(chan map[uint64]string, 1),
+	}
+	n.postMsg(req)
+	return <-req.Resp
+}
+
+// ForceRemoveBatches removes the batches of given heights from batchDigestM and txpool, it is used to clean up
+// stuck batches manually, no batch is removed if any height is not pending.
+func (n *Node) ForceRemoveBatches(heights []uint64) error {
+	if !n.started.Load() {
+		return n.forceRemoveBatches(heights)
+	}
+	req := &forceRemoveBatchesReq{
+		heights: heights,
+		errC:    make(chan error, 1),
+	}
+	n.postMsg(req)
+	return <-req.errC
+}
+
+func (n *Node) forceRemoveBatches(heights []uint64) error {
+	for _, h := range heights {
+		if _, ok := n.batchDigestM[h]; !ok {
+			return fmt.Errorf("batch of height %d is not pending", h)
+		}
+	}
+
+	heightList := lo.Uniq(heights)
+	sortkeys.Uint64s(heightList)
+	digestList := make([]string, len(heightList))
+	lo.ForEach(heightList, func(h uint64, index int) {
+		digestList[index] = n.batchDigestM[h]
+		delete(n.batchDigestM, h)
+		n.logger.WithFields(logrus.Fields{
+			"height": h,
+			"digest": digestList[index],
+		}).Warning("Force remove batch")
+	})
+	n.removeBatches(digestList)
+	return nil
+}
+
+// QueueSystemTx injects a system tx(e.g. epoch reward distribution) into the block of given height,
+// it bypasses the txpool admission and is placed ahead of user txs, the height must not be generated yet.
+func (n *Node) QueueSystemTx(tx *types.Transaction, atHeight uint64) error {
+	if !n.started.Load() {
+		return n.queueSystemTx(tx, atHeight)
+	}
+	req := &queueSystemTxReq{
+		tx:       tx,
+		atHeight: atHeight,
+		errC:     make(chan error, 1),
+	}
+	n.postMsg(req)
+	return <-req.errC
+}
+
+func (n *Node) queueSystemTx(tx *types.Transaction, atHeight uint64) error {
+	if tx == nil {
+		return errors.New("system tx is nil")
+	}
+	if atHeight <= n.lastExec {
+		return fmt.Errorf("height %d has passed, current height %d", atHeight, n.lastExec)
+	}
+	n.systemTxs[atHeight] = append(n.systemTxs[atHeight], tx)
+	n.logger.WithFields(logrus.Fields{
+		"height": atHeight,
+		"hash":   tx.GetHash().String(),
+	}).Info("Queue system tx")
+	return nil
+}
+
+func (n *Node) fastForward(height uint64, blockHash *types.Hash) error {
+	if height < n.lastExec {
+		return fmt.Errorf("fast forward backwards: current height %d, target height %d", n.lastExec, height)
+	}
+	if chainMeta := n.config.ChainState.ChainMeta; chainMeta != nil {
+		if chainMeta.Height != height {
+			return fmt.Errorf("fast forward height %d is inconsistent with restored state height %d", height, chainMeta.Height)
+		}
+		if blockHash != nil && chainMeta.BlockHash != nil && blockHash.String() != chainMeta.BlockHash.String() {
+			return fmt.Errorf("fast forward block hash %s is inconsistent with restored state block hash %s", blockHash.String(), chainMeta.BlockHash.String())
+		}
+	}
+
+	// batches below the restored height have been committed, remove them from txpool
+	digestList := n.removeBatchesUpTo(height)
+	// the restored state is trusted as finalized
+	n.lastCheckpoint.Store(height)
+
+	if currentEpoch := n.config.ChainState.EpochInfo; currentEpoch != nil {
+		n.epcCnf.startBlock = currentEpoch.StartBlock
+		n.epcCnf.epochPeriod = currentEpoch.EpochPeriod
+		n.epcCnf.enableGenEmptyBlock = currentEpoch.ConsensusParams.EnableTimedGenEmptyBlock
+		n.epcCnf.checkpoint = checkpointPeriodOrDefault(n.logger, currentEpoch.ConsensusParams.CheckpointPeriod)
+	}
+
+	n.logger.WithFields(logrus.Fields{
+		"from":            n.lastExec,
+		"to":              height,
+		"removed batches": len(digestList),
+	}).Info("Fast forward")
+	n.lastExec = height
+	n.sweepSystemTxs(height)
+	return nil
+}
+
+// sweepSystemTxs drops the system txs queued at heights <= height, these heights will never be generated
+// (e.g. skipped by fast forward), so the txs would otherwise stay in systemTxs forever.
+func (n *Node) sweepSystemTxs(height uint64) {
+	for h, txs := range n.systemTxs {
+		if h > height {
+			continue
+		}
+		for _, tx := range txs {
+			n.logger.WithFields(logrus.Fields{
+				"height":         h,
+				"current height": height,
+				"hash":           tx.GetHash().String(),
+			}).Warning("Drop system tx queued at passed height")
+		}
+		delete(n.systemTxs, h)
+	}
+}
+
 func (n *Node) Quorum(_ uint64) uint64 {
 	return 1
 }
@@ -210,6 +616,8 @@ func (n *Node) SubscribeTxEvent(events chan<- []*types.Transaction) event.Subscr
 	return n.txFeed.Subscribe(events)
 }
 
+// SubscribeMockBlockEvent is a no-op feed in solo, every block generated by solo is executed by the executor,
+// so eth_subscribe("newHeads") is served by the executor's block feed, the same as rbft.
 func (n *Node) SubscribeMockBlockEvent(ch chan<- events.ExecutedEvent) event.Subscription {
 	return n.mockBlockFeed.Subscribe(ch)
 }
@@ -218,14 +626,38 @@ func (n *Node) SubmitTxsFromRemote(_ [][]byte) error {
 	return nil
 }
 
+// sweepBatchDigestsPeriodically posts sweep requests to the event loop, so that it never races with the checkpoint cleanup
+func (n *Node) sweepBatchDigestsPeriodically(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-ticker.C:
+			n.postMsg(&sweepBatchDigestsReq{})
+		}
+	}
+}
+
 func (n *Node) listenEvent() {
 	for {
 		select {
 		case <-n.ctx.Done():
+			if n.recorder != nil {
+				if err := n.recorder.close(); err != nil {
+					n.logger.Errorf("Close event recorder failed: %v", err)
+				}
+			}
 			n.logger.Info("----- Exit listen event -----")
 			return
 
 		case ev := <-n.recvCh:
+			if n.recorder != nil {
+				if err := n.recorder.record(ev); err != nil {
+					n.logger.Errorf("Record event failed: %v", err)
+				}
+			}
 			switch e := ev.(type) {
 			// handle report state
 			case *chainState:
@@ -234,23 +666,12 @@ func (n *Node) listenEvent() {
 						"height": e.Height,
 						"hash":   e.BlockHash.String(),
 					}).Info("Report checkpoint")
+					n.lastCheckpoint.Store(e.Height)
 
-					digestList := make([]string, len(n.batchDigestM))
-					// flatten batchDigestM{<height:> <digest>} to []digest, sort by height
-					heightList := make([]uint64, 0)
-					for h := range n.batchDigestM {
-						// remove batches which is less than current state height
-						if h <= e.Height {
-							heightList = append(heightList, h)
-						}
-					}
-					sortkeys.Uint64s(heightList)
-					lo.ForEach(heightList, func(height uint64, index int) {
-						digestList[index] = n.batchDigestM[height]
-						delete(n.batchDigestM, height)
-					})
+					// remove batches which is less than current state height
+					digestList := n.removeBatchesUpTo(e.Height)
 					n.logger.Debug("RemoveBatches", len(digestList), digestList)
-					n.txpool.RemoveBatches(digestList)
+					n.sweepSystemTxs(n.lastExec)
 				}
 
 				if e.EpochChanged {
@@ -259,9 +680,9 @@ func (n *Node) listenEvent() {
 					n.epcCnf.startBlock = currentEpoch.StartBlock
 					n.epcCnf.epochPeriod = currentEpoch.EpochPeriod
 					n.epcCnf.enableGenEmptyBlock = currentEpoch.ConsensusParams.EnableTimedGenEmptyBlock
-					n.epcCnf.checkpoint = currentEpoch.ConsensusParams.CheckpointPeriod
+					n.epcCnf.checkpoint = checkpointPeriodOrDefault(n.logger, currentEpoch.ConsensusParams.CheckpointPeriod)
 
-					if n.epcCnf.enableGenEmptyBlock && !n.batchMgr.IsTimerActive(common.NoTxBatch) {
+					if n.slot == 0 && n.epcCnf.enableGenEmptyBlock && !n.batchMgr.IsTimerActive(common.NoTxBatch) {
 						err := n.batchMgr.StartTimer(common.NoTxBatch)
 						if err != nil {
 							n.logger.WithFields(logrus.Fields{
@@ -296,14 +717,38 @@ func (n *Node) listenEvent() {
 
 			case *getLowWatermarkReq:
 				e.Resp <- n.lastExec
+			case *getCurrentEpochReq:
+				epochInfo, _ := n.currentEpoch()
+				e.Resp <- epochInfo
+			case *fastForwardReq:
+				e.errC <- n.fastForward(e.height, e.blockHash)
+			case *getRuntimeConfigReq:
+				e.Resp <- n.runtimeConfig()
+			case *getPendingBatchDigestsReq:
+				e.Resp <- lo.Assign(n.batchDigestM)
+			case *forceRemoveBatchesReq:
+				e.errC <- n.forceRemoveBatches(e.heights)
+			case *queueSystemTxReq:
+				e.errC <- n.queueSystemTx(e.tx, e.atHeight)
+			case *sweepBatchDigestsReq:
+				n.sweepBatchDigests()
+			case *slotReq:
+				if err := n.processSlot(e.boundary); err != nil {
+					n.logger.Errorf("Process slot failed: %v", err)
+				}
 			case *genBatchReq:
+				if n.slot > 0 {
+					// blocks are only sealed at slot boundaries
+					n.txpool.ReplyBatchSignal()
+					continue
+				}
 				n.batchMgr.StopTimer(common.Batch)
 				n.batchMgr.StopTimer(common.NoTxBatch)
 				batch, err := n.txpool.GenerateRequestBatch(e.typ)
 				if err != nil {
 					n.logger.Errorf("Generate batch failed: %v", err)
 				} else if batch != nil {
-					n.generateBlock(batch)
+					n.generateBlock(batch, common.GenReasonOf(e.typ))
 					// start no-tx batch timer when this node handle the last transaction
 					if n.epcCnf.enableGenEmptyBlock && !n.txpool.HasPendingRequestInPool() {
 						if err = n.batchMgr.RestartTimer(common.NoTxBatch); err != nil {
@@ -347,19 +792,18 @@ func (n *Node) processBatchTimeout(e timer.TimeoutEvent) error {
 				return err
 			}
 			if batch != nil {
-				now := time.Now().UnixNano()
-				if n.batchMgr.lastBatchTime != 0 {
-					interval := time.Duration(now - n.batchMgr.lastBatchTime).Seconds()
+				now := time.Now()
+				if interval, ok := n.batchIntervalSinceLast(now); ok {
 					batchInterval.WithLabelValues("timeout").Observe(interval)
 					if n.batchMgr.minTimeoutBatchTime == 0 || interval < n.batchMgr.minTimeoutBatchTime {
 						n.logger.Debugf("update min timeoutBatch Time[height:%d, interval:%f, lastBatchTime:%v]",
-							n.lastExec+1, interval, time.Unix(0, n.batchMgr.lastBatchTime))
+							n.lastExec+1, interval, n.batchMgr.lastBatchTime)
 						minBatchIntervalDuration.WithLabelValues("timeout").Set(interval)
 						n.batchMgr.minTimeoutBatchTime = interval
 					}
 				}
 				n.batchMgr.lastBatchTime = now
-				n.generateBlock(batch)
+				n.generateBlock(batch, common.GenReasonTimeout)
 				n.logger.Debugf("batch timeout, post proposal: [batchHash: %s]", batch.BatchHash)
 			}
 		}
@@ -383,31 +827,56 @@ func (n *Node) processBatchTimeout(e timer.TimeoutEvent) error {
 		}
 		if batch != nil {
 			n.logger.Debug("Prepare create empty block")
-			now := time.Now().UnixNano()
-			if n.batchMgr.lastBatchTime != 0 {
-				interval := time.Duration(now - n.batchMgr.lastBatchTime).Seconds()
+			now := time.Now()
+			if interval, ok := n.batchIntervalSinceLast(now); ok {
 				batchInterval.WithLabelValues("timeout_no_tx").Observe(interval)
 				if n.batchMgr.minNoTxTimeoutBatchTime == 0 || interval < n.batchMgr.minNoTxTimeoutBatchTime {
 					n.logger.Debugf("update min noTxTimeoutBatch Time[height:%d, interval:%f, lastBatchTime:%v]",
-						n.lastExec+1, interval, time.Unix(0, n.batchMgr.lastBatchTime))
+						n.lastExec+1, interval, n.batchMgr.lastBatchTime)
 					minBatchIntervalDuration.WithLabelValues("timeout_no_tx").Set(interval)
 					n.batchMgr.minNoTxTimeoutBatchTime = interval
 				}
 			}
 			n.batchMgr.lastBatchTime = now
 
-			n.generateBlock(batch)
+			n.generateBlock(batch, common.GenReasonNoTxTimeout)
 			n.logger.Debugf("batch no-tx timeout, post proposal: %v", batch)
 		}
 	}
 	return nil
 }
 
+// batchIntervalSinceLast returns the seconds since the last batch measured by the monotonic clock,
+// false is returned if there is no last batch or the interval is negative, which should not be recorded.
+func (n *Node) batchIntervalSinceLast(now time.Time) (float64, bool) {
+	last := n.batchMgr.lastBatchTime
+	if last.IsZero() {
+		return 0, false
+	}
+	if now.UnixNano() < last.UnixNano() {
+		// e.g. NTP correction, the monotonic interval is still valid
+		n.logger.WithFields(logrus.Fields{
+			"now":             now,
+			"last_batch_time": last,
+		}).Warning("Wall clock jumped backward")
+	}
+	interval := now.Sub(last)
+	if interval < 0 {
+		n.logger.WithFields(logrus.Fields{
+			"interval":        interval,
+			"last_batch_time": last,
+		}).Warning("Skip negative batch interval")
+		return 0, false
+	}
+	return interval.Seconds(), true
+}
+
 // Schedule to collect txs to the listenReadyBlock channel
-func (n *Node) generateBlock(batch *txpool.RequestHashBatch[types.Transaction, *types.Transaction]) {
+func (n *Node) generateBlock(batch *txpool.RequestHashBatch[types.Transaction, *types.Transaction], reason common.GenReason) {
 	n.logger.WithFields(logrus.Fields{
 		"batch_hash": batch.BatchHash,
 		"tx_count":   len(batch.TxList),
+		"reason":     reason,
 	}).Debugf("Receive proposal from txpool")
 
 	// genesis block
@@ -424,15 +893,22 @@ func (n *Node) generateBlock(batch *txpool.RequestHashBatch[types.Transaction, *
 		},
 		Transactions: batch.TxList,
 	}
+	if systemTxs, ok := n.systemTxs[nextBlock]; ok {
+		block.Transactions = append(systemTxs, batch.TxList...)
+		delete(n.systemTxs, nextBlock)
+	}
 	localList := make([]bool, len(batch.TxList))
 	for i := 0; i < len(batch.TxList); i++ {
 		localList[i] = true
 	}
 	executeEvent := &common.CommitEvent{
-		Block: block,
+		Block:     block,
+		GenReason: reason,
 	}
+	generatedBlockCounter.WithLabelValues(reason.String()).Inc()
 	n.batchDigestM[block.Height()] = batch.BatchHash
 	n.lastExec = nextBlock
+	n.txCommitNotifier.Notify(block)
 	n.commitC <- executeEvent
 	n.logger.Infof("======== Call execute, height=%d", n.lastExec)
 }
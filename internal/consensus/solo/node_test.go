@@ -2,11 +2,13 @@ package solo
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"math/big"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/golang-lru/v2/expirable"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -45,12 +47,13 @@ func TestNode_Start(t *testing.T) {
 			maxGasPrice := new(big.Int).Mul(big.NewInt(10000), big.NewInt(1e9))
 			return new(big.Int).Mul(big.NewInt(math.MaxInt64), maxGasPrice)
 		}),
-		common.WithTxPool(mock_txpool.NewMockMinimalTxPool[types.Transaction, *types.Transaction](500, mockCtl)),
+		common.WithTxPool(common.NewMockTxPool(mock_txpool.NewMockMinimalTxPool[types.Transaction, *types.Transaction](500, mockCtl))),
 	)
 	require.Nil(t, err)
 
 	solo, err := NewNode(config)
 	require.Nil(t, err)
+	require.ErrorIs(t, solo.Ready(), common.ErrorConsensusStart)
 
 	err = solo.Start()
 	require.Nil(t, err)
@@ -72,6 +75,7 @@ func TestNode_Start(t *testing.T) {
 			break
 		}
 	}
+	require.Nil(t, solo.Ready())
 
 	txSubscribeCh := make(chan []*types.Transaction, 1)
 	sub := solo.SubscribeTxEvent(txSubscribeCh)
@@ -122,6 +126,58 @@ func TestTimedBlock(t *testing.T) {
 	ast.NotNil(event2)
 	ast.Equal(len(event2.Block.Transactions), 0)
 	ast.Equal(uint64(1), event2.Block.Header.Number)
+	ast.Equal(common.GenReasonNoTxTimeout, event2.GenReason)
+}
+
+func TestNextSlotBoundary(t *testing.T) {
+	ast := assert.New(t)
+	slot := 2 * time.Second
+	ast.Equal(time.Unix(2, 0).UnixNano(), nextSlotBoundary(time.Unix(0, 1), slot).UnixNano())
+	ast.Equal(time.Unix(4, 0).UnixNano(), nextSlotBoundary(time.Unix(2, 0), slot).UnixNano())
+	ast.Equal(time.Unix(4, 0).UnixNano(), nextSlotBoundary(time.Unix(3, 999), slot).UnixNano())
+}
+
+func TestSlotBlock(t *testing.T) {
+	ast := assert.New(t)
+	node, err := mockSoloNode(t, true)
+	ast.Nil(err)
+	node.slot = 50 * time.Millisecond
+
+	err = node.Start()
+	ast.Nil(err)
+	defer node.Stop()
+	ast.False(node.batchMgr.IsTimerActive(common.Batch))
+	ast.False(node.batchMgr.IsTimerActive(common.NoTxBatch))
+
+	event1 := <-node.commitC
+	ast.Equal(len(event1.Block.Transactions), 0)
+	ast.Equal(common.GenReasonNoTxTimeout, event1.GenReason)
+	node.config.ChainState.ChainMeta.BlockHash = types.NewHashByStr("0xe9FC370DD36C9BD5f67cCfbc031C909F53A3d8bC7084C01362c55f2D42bA841c")
+
+	event2 := <-node.commitC
+	ast.Equal(event1.Block.Header.Number+1, event2.Block.Header.Number)
+	ast.Equal(common.GenReasonNoTxTimeout, event2.GenReason)
+}
+
+func TestNode_BatchIntervalSinceLast(t *testing.T) {
+	ast := assert.New(t)
+	node, err := mockSoloNode(t, false)
+	ast.Nil(err)
+
+	// no last batch
+	_, ok := node.batchIntervalSinceLast(time.Now())
+	ast.False(ok)
+
+	node.batchMgr.lastBatchTime = time.Now()
+	time.Sleep(10 * time.Millisecond)
+	interval, ok := node.batchIntervalSinceLast(time.Now())
+	ast.True(ok)
+	ast.GreaterOrEqual(interval, (10 * time.Millisecond).Seconds())
+
+	// the wall clock jumped backward and there is no monotonic clock reading to fall back
+	node.batchMgr.lastBatchTime = time.Now().Round(0).Add(time.Hour)
+	_, ok = node.batchIntervalSinceLast(time.Now().Round(0))
+	ast.False(ok)
 }
 
 func TestNode_ReportState(t *testing.T) {
@@ -169,6 +225,95 @@ func TestNode_ReportState(t *testing.T) {
 	ast.Equal(uint64(10), node.epcCnf.startBlock)
 }
 
+func TestNode_ZeroCheckpointPeriod(t *testing.T) {
+	ast := assert.New(t)
+	node, err := mockSoloNode(t, false)
+	ast.Nil(err)
+
+	err = node.Start()
+	ast.Nil(err)
+	defer node.Stop()
+
+	// epoch changed to a bad config at height 1
+	node.epcCnf.startBlock = 1
+	node.epcCnf.epochPeriod = 1
+	node.config.ChainState.EpochInfo = &types.EpochInfo{Epoch: 2, StartBlock: 2, EpochPeriod: 10}
+	node.ReportState(1, types.NewHashByStr("0x123"), []*events.TxPointer{}, nil, false)
+	node.GetLowWatermark()
+	ast.Equal(uint64(2), node.epcCnf.startBlock)
+	ast.Equal(uint64(defaultCheckpointPeriod), node.epcCnf.checkpoint)
+
+	// the next report state does not panic
+	node.batchDigestM[2] = "test"
+	node.ReportState(2, types.NewHashByStr("0x456"), []*events.TxPointer{}, nil, false)
+	node.GetLowWatermark()
+	ast.Equal(0, len(node.batchDigestM))
+	ast.True(node.IsFinalized(2))
+}
+
+func TestNode_Config(t *testing.T) {
+	ast := assert.New(t)
+	node, err := mockSoloNode(t, false)
+	ast.Nil(err)
+	node.epcCnf.checkpoint = 10
+
+	cnf := node.Config()
+	ast.Equal(node.config.Repo.ConsensusConfig.Solo.BatchTimeout.ToDuration(), cnf.BatchTimeout)
+	ast.Equal(node.config.Repo.ConsensusConfig.TxPool.PoolSize, cnf.PoolSize)
+	ast.Equal(uint64(10), cnf.CheckpointPeriod)
+
+	err = node.Start()
+	ast.Nil(err)
+	defer node.Stop()
+
+	// epoch changed at height 1
+	node.epcCnf.startBlock = 1
+	node.epcCnf.epochPeriod = 1
+	node.config.ChainState.EpochInfo = &types.EpochInfo{
+		Epoch:       2,
+		StartBlock:  2,
+		EpochPeriod: 100,
+		ConsensusParams: types.ConsensusParams{
+			CheckpointPeriod:         5,
+			BlockMaxTxNum:            7,
+			EnableTimedGenEmptyBlock: true,
+		},
+	}
+	node.ReportState(1, types.NewHashByStr("0x123"), []*events.TxPointer{}, nil, false)
+	cnf = node.Config()
+	ast.Equal(uint64(2), cnf.StartBlock)
+	ast.Equal(uint64(100), cnf.EpochPeriod)
+	ast.Equal(uint64(5), cnf.CheckpointPeriod)
+	ast.Equal(uint64(7), cnf.BatchSize)
+	ast.True(cnf.EnableGenEmptyBlock)
+}
+
+func TestNode_IsFinalized(t *testing.T) {
+	ast := assert.New(t)
+	node, err := mockSoloNode(t, false)
+	ast.Nil(err)
+
+	err = node.Start()
+	ast.Nil(err)
+	defer node.Stop()
+	node.epcCnf.checkpoint = 10
+	ast.True(node.IsFinalized(0))
+
+	node.ReportState(5, types.NewHashByStr("0x123"), []*events.TxPointer{}, nil, false)
+	// ensure last event(report state) had been processed
+	node.GetLowWatermark()
+	ast.False(node.IsFinalized(5), "executed block is not finalized before checkpoint")
+
+	node.ReportState(10, types.NewHashByStr("0x456"), []*events.TxPointer{}, nil, false)
+	node.GetLowWatermark()
+	ast.True(node.IsFinalized(5))
+	ast.True(node.IsFinalized(10))
+	ast.False(node.IsFinalized(11))
+
+	ast.Equal(uint64(20), checkpointFloor(25, 10))
+	ast.Equal(uint64(25), checkpointFloor(25, 0))
+}
+
 func prepareMultiTx(t *testing.T, count int) ([]*types.Transaction, *types.Signer) {
 	signer, err := types.GenerateSigner()
 	require.Nil(t, err)
@@ -331,3 +476,339 @@ func TestNode_Prepare(t *testing.T) {
 		ast.Equal(batchSize, len(block.Block.Transactions))
 	})
 }
+
+func TestNode_FastForward(t *testing.T) {
+	ast := assert.New(t)
+	node, err := mockSoloNode(t, false)
+	ast.Nil(err)
+
+	err = node.Start()
+	ast.Nil(err)
+	defer node.Stop()
+
+	node.batchDigestM[5] = "test5"
+	node.batchDigestM[12] = "test12"
+
+	// inconsistent with restored state
+	err = node.FastForward(10, types.NewHashByStr("0x123"))
+	ast.NotNil(err)
+
+	node.config.ChainState.ChainMeta = &types.ChainMeta{Height: 10, BlockHash: types.NewHashByStr("0x123")}
+	err = node.FastForward(10, types.NewHashByStr("0x456"))
+	ast.NotNil(err)
+
+	err = node.FastForward(10, types.NewHashByStr("0x123"))
+	ast.Nil(err)
+	ast.Equal(uint64(10), node.GetLowWatermark())
+	ast.Equal(1, len(node.batchDigestM))
+	ast.Equal("test12", node.batchDigestM[12])
+
+	// fast forward backwards
+	node.config.ChainState.ChainMeta = &types.ChainMeta{Height: 5, BlockHash: types.NewHashByStr("0x123")}
+	err = node.FastForward(5, types.NewHashByStr("0x123"))
+	ast.NotNil(err)
+	ast.Equal(uint64(10), node.GetLowWatermark())
+}
+
+func TestNode_ForceRemoveBatches(t *testing.T) {
+	ast := assert.New(t)
+	node, err := mockSoloNode(t, false)
+	ast.Nil(err)
+
+	err = node.Start()
+	ast.Nil(err)
+	defer node.Stop()
+
+	node.batchDigestM[5] = "test5"
+	node.batchDigestM[6] = "test6"
+	node.batchDigestM[7] = "test7"
+	ast.Equal(3, len(node.PendingBatchDigests()))
+
+	// height 8 is not pending, nothing is removed
+	err = node.ForceRemoveBatches([]uint64{5, 8})
+	ast.NotNil(err)
+	ast.Equal(3, len(node.PendingBatchDigests()))
+
+	err = node.ForceRemoveBatches([]uint64{7, 5, 5})
+	ast.Nil(err)
+	pending := node.PendingBatchDigests()
+	ast.Equal(1, len(pending))
+	ast.Equal("test6", pending[6])
+}
+
+func TestNode_QueueSystemTx(t *testing.T) {
+	ast := assert.New(t)
+	node, err := mockSoloNode(t, false)
+	ast.Nil(err)
+	node.config.ChainState.ChainMeta = &types.ChainMeta{Height: 1, BlockHash: types.NewHashByStr("0x123")}
+	node.lastExec = 1
+
+	systemTx1, err := types.GenerateEmptyTransactionAndSigner()
+	ast.Nil(err)
+	systemTx2, err := types.GenerateEmptyTransactionAndSigner()
+	ast.Nil(err)
+	userTx, err := types.GenerateEmptyTransactionAndSigner()
+	ast.Nil(err)
+
+	// height 1 has been generated
+	err = node.QueueSystemTx(systemTx1, 1)
+	ast.NotNil(err)
+	err = node.QueueSystemTx(systemTx1, 2)
+	ast.Nil(err)
+	err = node.QueueSystemTx(systemTx2, 2)
+	ast.Nil(err)
+
+	node.generateBlock(&txpool.RequestHashBatch[types.Transaction, *types.Transaction]{
+		BatchHash:  "batch2",
+		TxHashList: []string{userTx.RbftGetTxHash()},
+		TxList:     []*types.Transaction{userTx},
+		Timestamp:  time.Now().UnixNano(),
+	}, common.GenReasonSize)
+	commitEvent := <-node.commitC
+	ast.Equal(common.GenReasonSize, commitEvent.GenReason)
+	ast.Equal(uint64(2), commitEvent.Block.Height())
+	ast.Equal([]*types.Transaction{systemTx1, systemTx2, userTx}, commitEvent.Block.Transactions)
+	ast.Equal(0, len(node.systemTxs))
+}
+
+func TestNode_SweepSystemTxs(t *testing.T) {
+	ast := assert.New(t)
+	node, err := mockSoloNode(t, false)
+	ast.Nil(err)
+
+	systemTx, err := types.GenerateEmptyTransactionAndSigner()
+	ast.Nil(err)
+	for _, h := range []uint64{5, 10, 12, 25} {
+		err = node.QueueSystemTx(systemTx, h)
+		ast.Nil(err)
+	}
+
+	err = node.Start()
+	ast.Nil(err)
+	defer node.Stop()
+
+	// heights skipped by fast forward are dropped
+	node.config.ChainState.ChainMeta = &types.ChainMeta{Height: 10, BlockHash: types.NewHashByStr("0x123")}
+	err = node.FastForward(10, types.NewHashByStr("0x123"))
+	ast.Nil(err)
+	ast.Equal(2, len(node.systemTxs))
+	ast.Contains(node.systemTxs, uint64(12))
+	ast.Contains(node.systemTxs, uint64(25))
+
+	// heights passed when checkpoint is reported are dropped
+	node.lastExec = 20
+	node.epcCnf.checkpoint = 10
+	node.ReportState(20, types.NewHashByStr("0x123"), []*events.TxPointer{}, nil, false)
+	// ensure last event(report state) had been processed
+	node.GetLowWatermark()
+	ast.Equal(1, len(node.systemTxs))
+	ast.Contains(node.systemTxs, uint64(25))
+}
+
+func TestNode_TxCommitObserver(t *testing.T) {
+	ast := assert.New(t)
+	node, err := mockSoloNode(t, false)
+	ast.Nil(err)
+	node.config.ChainState.ChainMeta = &types.ChainMeta{Height: 1, BlockHash: types.NewHashByStr("0x123")}
+	node.lastExec = 1
+
+	tx1, err := types.GenerateEmptyTransactionAndSigner()
+	ast.Nil(err)
+	tx2, err := types.GenerateEmptyTransactionAndSigner()
+	ast.Nil(err)
+	batch := &txpool.RequestHashBatch[types.Transaction, *types.Transaction]{
+		BatchHash:  "batch2",
+		TxHashList: []string{tx1.RbftGetTxHash(), tx2.RbftGetTxHash()},
+		TxList:     []*types.Transaction{tx1, tx2},
+		Timestamp:  time.Now().UnixNano(),
+	}
+
+	// nil observer is skipped
+	node.generateBlock(batch, common.GenReasonSize)
+	<-node.commitC
+
+	infoC := make(chan common.TxCommitInfo, 2)
+	node.txCommitNotifier = common.NewTxCommitNotifier(node.ctx, func(info common.TxCommitInfo) {
+		infoC <- info
+	}, node.logger)
+	node.generateBlock(batch, common.GenReasonSize)
+	<-node.commitC
+	ast.Equal(common.TxCommitInfo{TxHash: tx1.RbftGetTxHash(), From: tx1.RbftGetFrom(), Nonce: tx1.RbftGetNonce(), Height: 3, Index: 0}, <-infoC)
+	ast.Equal(common.TxCommitInfo{TxHash: tx2.RbftGetTxHash(), From: tx2.RbftGetFrom(), Nonce: tx2.RbftGetNonce(), Height: 3, Index: 1}, <-infoC)
+}
+
+func TestNode_SweepBatchDigests(t *testing.T) {
+	ast := assert.New(t)
+	node, err := mockSoloNode(t, false)
+	ast.Nil(err)
+	node.config.Repo.ConsensusConfig.Solo.BatchDigestSweepInterval = repo.Duration(10 * time.Millisecond)
+	node.epcCnf.checkpoint = 10
+	node.batchDigestM[5] = "test5"
+	node.batchDigestM[10] = "test10"
+	node.batchDigestM[15] = "test15"
+	node.batchDigestM[25] = "test25"
+	// checkpoint 20 is persisted but not reported
+	node.config.ChainState.ChainMeta = &types.ChainMeta{Height: 25, BlockHash: types.NewHashByStr("0x123")}
+
+	err = node.Start()
+	ast.Nil(err)
+	defer node.Stop()
+
+	ast.Eventually(func() bool {
+		return len(node.PendingBatchDigests()) == 1
+	}, time.Second, 10*time.Millisecond)
+	ast.Equal("test25", node.PendingBatchDigests()[25])
+}
+
+func TestNode_RemoveBatchesInChunks(t *testing.T) {
+	ast := assert.New(t)
+	node, err := mockSoloNode(t, false)
+	ast.Nil(err)
+	node.config.Repo.ConsensusConfig.Solo.RemoveBatchesChunkSize = 10
+
+	mockCtl := gomock.NewController(t)
+	pool := mock_txpool.NewMockTxPool[types.Transaction, *types.Transaction](mockCtl)
+	node.txpool = pool
+	digests := func(from, to int) []string {
+		var list []string
+		for h := from; h <= to; h++ {
+			list = append(list, fmt.Sprintf("test%d", h))
+		}
+		return list
+	}
+	for h := 1; h <= 25; h++ {
+		node.batchDigestM[uint64(h)] = fmt.Sprintf("test%d", h)
+	}
+
+	// removed in height order, split into chunks
+	gomock.InOrder(
+		pool.EXPECT().RemoveBatches(digests(1, 10)).Times(1),
+		pool.EXPECT().RemoveBatches(digests(11, 20)).Times(1),
+		pool.EXPECT().RemoveBatches(digests(21, 22)).Times(1),
+	)
+	ast.Equal(digests(1, 22), node.removeBatchesUpTo(22))
+	ast.Equal(3, len(node.batchDigestM))
+
+	// no split
+	node.config.Repo.ConsensusConfig.Solo.RemoveBatchesChunkSize = 0
+	pool.EXPECT().RemoveBatches(digests(23, 25)).Times(1)
+	ast.Equal(digests(23, 25), node.removeBatchesUpTo(25))
+	ast.Equal(0, len(node.batchDigestM))
+}
+
+func TestNode_PrepareDuplicateTx(t *testing.T) {
+	ast := assert.New(t)
+	node, err := mockSoloNode(t, false)
+	ast.Nil(err)
+	node.seenTxs = expirable.NewLRU[string, struct{}](10, nil, 100*time.Millisecond)
+
+	err = node.Start()
+	ast.Nil(err)
+	defer node.Stop()
+
+	tx, err := types.GenerateEmptyTransactionAndSigner()
+	require.Nil(t, err)
+	txSubscribeCh := make(chan []*types.Transaction, 1)
+	sub := node.SubscribeTxEvent(txSubscribeCh)
+	defer sub.Unsubscribe()
+
+	err = node.Prepare(tx)
+	ast.Nil(err)
+	<-txSubscribeCh
+
+	err = node.Prepare(tx)
+	ast.ErrorIs(err, common.ErrorDuplicateTx)
+
+	// expired, the tx is checked by pre-check and txpool again
+	time.Sleep(150 * time.Millisecond)
+	err = node.Prepare(tx)
+	ast.NotErrorIs(err, common.ErrorDuplicateTx)
+}
+
+func TestNode_CurrentEpoch(t *testing.T) {
+	ast := assert.New(t)
+	node, err := mockSoloNode(t, false)
+	ast.Nil(err)
+
+	epochInfo, err := node.CurrentEpoch()
+	ast.Nil(err)
+	ast.Equal(node.config.ChainState.EpochInfo.Epoch, epochInfo.Epoch)
+	ast.Equal(node.epcCnf.startBlock, epochInfo.StartBlock)
+
+	err = node.Start()
+	ast.Nil(err)
+	defer node.Stop()
+
+	node.epcCnf.checkpoint = 5
+	epochInfo, err = node.CurrentEpoch()
+	ast.Nil(err)
+	ast.Equal(uint64(5), epochInfo.ConsensusParams.CheckpointPeriod)
+	ast.Equal(node.epcCnf.epochPeriod, epochInfo.EpochPeriod)
+}
+
+func TestNewNode_FallbackGenesisEpoch(t *testing.T) {
+	rep := repo.MockRepo(t)
+
+	mockCtl := gomock.NewController(t)
+	chainState := chainstate.NewMockChainState(rep.GenesisConfig, nil)
+	chainState.EpochInfo = nil
+	genesisEpoch := rep.GenesisConfig.EpochInfo.Clone()
+	genesisEpoch.EpochPeriod = 123
+	config, err := common.GenerateConfig(
+		common.WithChainState(chainState),
+		common.WithRepo(rep),
+		common.WithGenesisEpochInfo(genesisEpoch),
+		common.WithLogger(log.NewWithModule("consensus")),
+		common.WithApplied(0),
+		common.WithNetwork(mock_network.NewMockNetwork(mockCtl)),
+		common.WithTxPool(common.NewMockTxPool(mock_txpool.NewMockMinimalTxPool[types.Transaction, *types.Transaction](500, mockCtl))),
+	)
+	require.Nil(t, err)
+
+	solo, err := NewNode(config)
+	require.Nil(t, err)
+	require.Equal(t, uint64(123), solo.epcCnf.epochPeriod)
+
+	genesisEpoch.ConsensusParams.CheckpointPeriod = 0
+	solo, err = NewNode(config)
+	require.Nil(t, err)
+	require.Equal(t, uint64(defaultCheckpointPeriod), solo.epcCnf.checkpoint)
+
+	config.GenesisEpochInfo = nil
+	_, err = NewNode(config)
+	require.NotNil(t, err)
+}
+
+func TestNode_WaitForPeers(t *testing.T) {
+	node, err := mockSoloNode(t, false)
+	require.Nil(t, err)
+	mockNetwork := node.network.(*mock_network.MockNetwork)
+
+	t.Run("disabled", func(t *testing.T) {
+		node.config.Repo.ConsensusConfig.Solo.WaitForPeers = 0
+		require.Nil(t, node.waitForPeers())
+	})
+
+	t.Run("reach threshold", func(t *testing.T) {
+		node.config.Repo.ConsensusConfig.Solo.WaitForPeers = 1
+		node.config.Repo.ConsensusConfig.Solo.WaitForPeersTimeout = repo.Duration(5 * time.Second)
+		calls := 0
+		mockNetwork.EXPECT().GetConnectedPeers(gomock.Any()).DoAndReturn(func(peers []string) []string {
+			calls++
+			if calls < 2 {
+				return nil
+			}
+			return peers
+		}).Times(2)
+		require.Nil(t, node.waitForPeers())
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		node.config.Repo.ConsensusConfig.Solo.WaitForPeers = 1
+		node.config.Repo.ConsensusConfig.Solo.WaitForPeersTimeout = repo.Duration(100 * time.Millisecond)
+		mockNetwork.EXPECT().GetConnectedPeers(gomock.Any()).Return(nil).AnyTimes()
+		err := node.waitForPeers()
+		require.NotNil(t, err)
+		require.Contains(t, err.Error(), "timeout")
+	})
+}
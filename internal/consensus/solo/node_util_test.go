@@ -51,6 +51,7 @@ func mockSoloNode(t *testing.T, enableTimed bool) (*Node, error) {
 		txpool:       mockPool,
 		network:      mockNetwork,
 		batchDigestM: make(map[uint64]string),
+		systemTxs:    make(map[uint64][]*types.Transaction),
 		recvCh:       recvCh,
 		logger:       logger,
 		ctx:          ctx,
@@ -0,0 +1,72 @@
+package solo
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/axiomesh/axiom-kit/txpool"
+	"github.com/axiomesh/axiom-ledger/internal/consensus/common"
+)
+
+// nextSlotBoundary returns the first slot boundary after now, boundaries are aligned to unix time
+func nextSlotBoundary(now time.Time, slot time.Duration) time.Time {
+	return time.Unix(0, (now.UnixNano()/int64(slot)+1)*int64(slot))
+}
+
+// listenSlot posts a slot request to the event loop at every slot boundary. Each boundary is recomputed
+// from the wall clock, so the drift of timer does not accumulate. If the timer fires late(e.g. a busy node
+// or a clock jump), the missed boundaries are skipped rather than caught up, so blocks never come out back to back.
+func (n *Node) listenSlot() {
+	next := nextSlotBoundary(time.Now(), n.slot)
+	t := time.NewTimer(time.Until(next))
+	defer t.Stop()
+
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-t.C:
+			now := time.Now()
+			if missed := (now.UnixNano() - next.UnixNano()) / int64(n.slot); missed > 0 {
+				skippedSlotCounter.Add(float64(missed))
+				n.logger.WithFields(logrus.Fields{
+					"boundary": next,
+					"now":      now,
+					"missed":   missed,
+				}).Warning("Skip missed slots")
+			}
+			n.postMsg(&slotReq{boundary: next})
+			next = nextSlotBoundary(now, n.slot)
+			t.Reset(time.Until(next))
+		}
+	}
+}
+
+// processSlot seals the txs in pool at a slot boundary, an empty block is generated at an idle slot if
+// enableGenEmptyBlock is set.
+func (n *Node) processSlot(boundary time.Time) error {
+	typ, reason := txpool.GenBatchTimeoutEvent, common.GenReasonSlot
+	if !n.txpool.HasPendingRequestInPool() {
+		if !n.epcCnf.enableGenEmptyBlock {
+			return nil
+		}
+		typ, reason = txpool.GenBatchNoTxTimeoutEvent, common.GenReasonNoTxTimeout
+	}
+
+	batch, err := n.txpool.GenerateRequestBatch(typ)
+	if err != nil {
+		return err
+	}
+	if batch == nil {
+		return nil
+	}
+	now := time.Now()
+	if interval, ok := n.batchIntervalSinceLast(now); ok {
+		batchInterval.WithLabelValues("slot").Observe(interval)
+	}
+	n.batchMgr.lastBatchTime = now
+	n.generateBlock(batch, reason)
+	n.logger.Debugf("slot %v, post proposal: [batchHash: %s, reason: %s]", boundary, batch.BatchHash, reason)
+	return nil
+}
@@ -1,12 +1,19 @@
 package solo
 
 import (
+	"time"
+
 	"github.com/axiomesh/axiom-kit/types"
 	"github.com/axiomesh/axiom-ledger/internal/components/timer"
 )
 
 const (
 	maxChanSize = 1024
+
+	waitForPeersInterval = 500 * time.Millisecond
+
+	// defaultCheckpointPeriod is used when the checkpoint period of epoch config is 0
+	defaultCheckpointPeriod = 1
 )
 
 // consensusEvent is a type meant to clearly convey that the return type or parameter to a function will be supplied to/from an events.Manager
@@ -25,17 +32,75 @@ type getLowWatermarkReq struct {
 	Resp chan uint64
 }
 
+// getCurrentEpochReq is a type for request CurrentEpoch
+type getCurrentEpochReq struct {
+	Resp chan *types.EpochInfo
+}
+
+// fastForwardReq is a type for fast-forwarding lastExec after snapshot restore
+type fastForwardReq struct {
+	height    uint64
+	blockHash *types.Hash
+	errC      chan error
+}
+
+// getRuntimeConfigReq is a type for request Config
+type getRuntimeConfigReq struct {
+	Resp chan *ConsensusRuntimeConfig
+}
+
+// getPendingBatchDigestsReq is a type for request PendingBatchDigests
+type getPendingBatchDigestsReq struct {
+	Resp chan map[uint64]string
+}
+
+// forceRemoveBatchesReq is a type for removing stuck batches manually
+type forceRemoveBatchesReq struct {
+	heights []uint64
+	errC    chan error
+}
+
+// queueSystemTxReq is a type for injecting a system tx into the block of given height
+type queueSystemTxReq struct {
+	tx       *types.Transaction
+	atHeight uint64
+	errC     chan error
+}
+
+// sweepBatchDigestsReq is a type for removing batches below the last executed checkpoint periodically
+type sweepBatchDigestsReq struct{}
+
+// slotReq is a type for sealing the txs in pool at a slot boundary
+type slotReq struct {
+	boundary time.Time
+}
+
 type genBatchReq struct {
 	typ int
 }
 
 type batchTimerManager struct {
 	timer.Timer
-	lastBatchTime           int64
+	// lastBatchTime carries the monotonic clock reading, intervals are measured by it
+	lastBatchTime           time.Time
 	minTimeoutBatchTime     float64
 	minNoTxTimeoutBatchTime float64
 }
 
+// ConsensusRuntimeConfig is the effective consensus parameters of solo node,
+// the epoch related ones(batch size, checkpoint period, etc.) may change after epoch changes.
+type ConsensusRuntimeConfig struct {
+	BatchTimeout        time.Duration `json:"batch_timeout"`
+	NoTxBatchTimeout    time.Duration `json:"no_tx_batch_timeout"`
+	SlotDuration        time.Duration `json:"slot_duration"`
+	BatchSize           uint64        `json:"batch_size"`
+	PoolSize            uint64        `json:"pool_size"`
+	StartBlock          uint64        `json:"start_block"`
+	EpochPeriod         uint64        `json:"epoch_period"`
+	CheckpointPeriod    uint64        `json:"checkpoint_period"`
+	EnableGenEmptyBlock bool          `json:"enable_gen_empty_block"`
+}
+
 type epochConfig struct {
 	startBlock          uint64
 	epochPeriod         uint64
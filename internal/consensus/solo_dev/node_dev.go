@@ -1,6 +1,7 @@
 package solo_dev
 
 import (
+	"errors"
 	"sync"
 	"time"
 
@@ -19,7 +20,7 @@ const checkpoint = 10
 type GetAccountNonceFunc func(address *types.Address) uint64
 
 func init() {
-	repo.Register(repo.ConsensusTypeSoloDev, false)
+	repo.Register(repo.ConsensusTypeSoloDev, repo.ConsensusCapabilities{})
 }
 
 type NodeDev struct {
@@ -95,6 +96,18 @@ func (n *NodeDev) Status() (bool, string) {
 	return true, "normal"
 }
 
+func (n *NodeDev) Ready() error {
+	return nil
+}
+
+func (n *NodeDev) CurrentEpoch() (*types.EpochInfo, error) {
+	epochInfo := n.config.ChainState.EpochInfo
+	if epochInfo == nil {
+		return nil, errors.New("epoch info is not initialized")
+	}
+	return epochInfo.Clone(), nil
+}
+
 func (n *NodeDev) ReportState(height uint64, blockHash *types.Hash, txPointerList []*events.TxPointer, _ *common.Checkpoint, _ bool) {
 	if height%checkpoint == 0 {
 		n.logger.WithFields(logrus.Fields{
@@ -139,3 +152,11 @@ func (n *NodeDev) GetTotalPendingTxCount() uint64 {
 func (n *NodeDev) GetLowWatermark() uint64 {
 	return n.lastExec
 }
+
+// IsFinalized reports whether the block at height is executed, a dev node finalizes a block once it is executed.
+// Prepare holds the mutex until the block is persisted, so the block being executed is not reported as finalized.
+func (n *NodeDev) IsFinalized(height uint64) bool {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return height <= n.lastExec
+}
@@ -37,7 +37,9 @@ type BrokerAPI interface {
 	GetReceipts(blockNum uint64) ([]*types.Receipt, error)
 	GetViewStateLedger() ledger.StateLedger
 	GetEvm(mes *core.Message, vmConfig *vm.Config) (*vm.EVM, error)
-	ConsensusReady() (bool, string)
+	ConsensusReady() error
+	// TxPoolFull reports whether the tx pool is full, which is independent of the consensus state
+	TxPoolFull() bool
 
 	ChainConfig() *params.ChainConfig
 	StateAtTransaction(block *types.Block, txIndex int, reexec uint64) (*core.Message, vm.BlockContext, *ledger.StateLedger, error)
@@ -882,6 +882,44 @@ func (c *MockBrokerAPIStateAtTransactionCall) DoAndReturn(f func(*types.Block, i
 	return c
 }
 
+// TxPoolFull mocks base method.
+func (m *MockBrokerAPI) TxPoolFull() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxPoolFull")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// TxPoolFull indicates an expected call of TxPoolFull.
+func (mr *MockBrokerAPIMockRecorder) TxPoolFull() *MockBrokerAPITxPoolFullCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxPoolFull", reflect.TypeOf((*MockBrokerAPI)(nil).TxPoolFull))
+	return &MockBrokerAPITxPoolFullCall{Call: call}
+}
+
+// MockBrokerAPITxPoolFullCall wrap *gomock.Call
+type MockBrokerAPITxPoolFullCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockBrokerAPITxPoolFullCall) Return(arg0 bool) *MockBrokerAPITxPoolFullCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockBrokerAPITxPoolFullCall) Do(f func() bool) *MockBrokerAPITxPoolFullCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockBrokerAPITxPoolFullCall) DoAndReturn(f func() bool) *MockBrokerAPITxPoolFullCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // MockNetworkAPI is a mock of NetworkAPI interface.
 type MockNetworkAPI struct {
 	ctrl     *gomock.Controller
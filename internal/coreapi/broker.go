@@ -82,12 +82,20 @@ func (b *BrokerAPI) GetBlockTxList(height uint64) ([]*types.Transaction, error)
 	return b.axiomLedger.ViewLedger.ChainLedger.GetBlockTxList(height)
 }
 
-func (b *BrokerAPI) ConsensusReady() (bool, string) {
+func (b *BrokerAPI) ConsensusReady() error {
 	if b.axiomLedger.Repo.StartArgs.ReadonlyMode {
-		return true, "read-only mode"
+		return nil
 	}
 
-	return b.axiomLedger.Consensus.Status()
+	return b.axiomLedger.Consensus.Ready()
+}
+
+func (b *BrokerAPI) TxPoolFull() bool {
+	if b.axiomLedger.Repo.StartArgs.ReadonlyMode {
+		return false
+	}
+
+	return b.axiomLedger.TxPool.IsPoolFull()
 }
 
 func (b *BrokerAPI) GetViewStateLedger() ledger.StateLedger {
@@ -116,7 +124,7 @@ func (b *BrokerAPI) StateAtTransaction(block *types.Block, txIndex int, reexec u
 		return nil, vm.BlockContext{}, nil, fmt.Errorf("parent %#x not found", block.Header.ParentHash)
 	}
 
-	statedb, err := b.axiomLedger.ViewLedger.StateLedger.NewView(parentHeader, false)
+	statedb, err := b.axiomLedger.ViewLedger.StateLedger.NewLimitedView(parentHeader, false)
 	if err != nil {
 		return nil, vm.BlockContext{}, nil, fmt.Errorf("get target state error:%v", err)
 	}
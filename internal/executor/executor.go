@@ -3,6 +3,7 @@ package executor
 import (
 	"context"
 	"math/big"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/event"
@@ -44,6 +45,8 @@ type BlockExecutor struct {
 	logsFeed           event.Feed
 	ctx                context.Context
 	cancel             context.CancelFunc
+	// wg tracks the execute event loop, Stop waits for the in-flight block to be persisted
+	wg sync.WaitGroup
 
 	evm         *vm.EVM
 	evmChainCfg *params.ChainConfig
@@ -94,6 +97,7 @@ func New(rep *repo.Repo, ledger *ledger.Ledger, chainState *chainstate.ChainStat
 
 // Start starts executor
 func (exec *BlockExecutor) Start() error {
+	exec.wg.Add(1)
 	go exec.listenExecuteEvent()
 
 	exec.logger.WithFields(logrus.Fields{
@@ -107,6 +111,7 @@ func (exec *BlockExecutor) Start() error {
 // Stop stops executor
 func (exec *BlockExecutor) Stop() error {
 	exec.cancel()
+	exec.wg.Wait()
 
 	exec.logger.Info("BlockExecutor stopped")
 
@@ -152,6 +157,7 @@ func (exec *BlockExecutor) SubscribeLogsEvent(ch chan<- []*types.EvmLog) event.S
 }
 
 func (exec *BlockExecutor) listenExecuteEvent() {
+	defer exec.wg.Done()
 	for {
 		select {
 		case <-exec.ctx.Done():
@@ -41,6 +41,12 @@ func (axc *AXC) GenesisInit(genesis *repo.GenesisConfig) error {
 
 		totalSupply = totalSupply.Add(totalSupply, balance)
 	}
+	// balances of predeployed accounts are set by genesis alloc
+	for _, account := range genesis.Alloc {
+		if account != nil && account.Balance != nil {
+			totalSupply = totalSupply.Add(totalSupply, account.Balance.ToBigInt())
+		}
+	}
 	for _, nodeCfg := range genesis.Nodes {
 		if !nodeCfg.IsDataSyncer {
 			totalSupply = totalSupply.Add(totalSupply, nodeCfg.StakeNumber.ToBigInt())
@@ -2,9 +2,11 @@ package genesis
 
 import (
 	"encoding/json"
+	"fmt"
 	"math/big"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 
 	"github.com/axiomesh/axiom-kit/types"
 	"github.com/axiomesh/axiom-ledger/internal/executor/system"
@@ -30,6 +32,9 @@ func Initialize(genesis *repo.GenesisConfig, lg *ledger.Ledger) error {
 	if err != nil {
 		return err
 	}
+	if err := initializeAlloc(genesis.Alloc, lg.StateLedger); err != nil {
+		return err
+	}
 	lg.StateLedger.Finalise()
 
 	stateRoot, err := lg.StateLedger.Commit()
@@ -84,6 +89,36 @@ func initializeGenesisConfig(genesis *repo.GenesisConfig, lg ledger.StateLedger)
 	return nil
 }
 
+// initializeAlloc writes the predeployed accounts, the alloc is validated when genesis config is loaded
+func initializeAlloc(alloc map[string]*repo.GenesisAccount, lg ledger.StateLedger) error {
+	for addrStr, account := range alloc {
+		addr := types.NewAddress(ethcommon.HexToAddress(addrStr).Bytes())
+		if account.Balance != nil {
+			lg.SetBalance(addr, account.Balance.ToBigInt())
+		}
+		if account.Code != "" {
+			code, err := hexutil.Decode(account.Code)
+			if err != nil {
+				return fmt.Errorf("decode code of alloc %s: %w", addrStr, err)
+			}
+			lg.SetCode(addr, code)
+		}
+		for k, v := range account.Storage {
+			key, err := hexutil.Decode(k)
+			if err != nil {
+				return fmt.Errorf("decode storage key %s of alloc %s: %w", k, addrStr, err)
+			}
+			value, err := hexutil.Decode(v)
+			if err != nil {
+				return fmt.Errorf("decode storage value of %s of alloc %s: %w", k, addrStr, err)
+			}
+			// keep the same layout as the storage written by evm
+			lg.SetState(addr, ethcommon.BytesToHash(key).Bytes(), ethcommon.BytesToHash(value).Bytes())
+		}
+	}
+	return nil
+}
+
 // GetGenesisConfig retrieves the genesis configuration from the given ledger.
 func GetGenesisConfig(lg ledger.StateLedger) (*repo.GenesisConfig, error) {
 	account := lg.GetAccount(types.NewAddressByStr(common.ZeroAddress))
@@ -2,8 +2,10 @@ package genesis
 
 import (
 	"encoding/json"
+	"math/big"
 	"testing"
 
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 
@@ -30,6 +32,31 @@ func TestInitialize(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestInitializeAlloc(t *testing.T) {
+	rep := repo.MockRepo(t)
+	lg, err := ledger.NewMemory(rep)
+	assert.Nil(t, err)
+
+	contract := "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+	genesisConfig := repo.DefaultGenesisConfig()
+	genesisConfig.Alloc = map[string]*repo.GenesisAccount{
+		contract: {
+			Balance: types.CoinNumberByAxc(1),
+			Code:    "0x6080604052",
+			Storage: map[string]string{"0x01": "0x0a"},
+		},
+	}
+	err = Initialize(genesisConfig, lg)
+	assert.Nil(t, err)
+
+	addr := types.NewAddressByStr(contract)
+	assert.Equal(t, types.CoinNumberByAxc(1).ToBigInt(), lg.StateLedger.GetBalance(addr))
+	assert.Equal(t, []byte{0x60, 0x80, 0x60, 0x40, 0x52}, lg.StateLedger.GetCode(addr))
+	exist, value := lg.StateLedger.GetState(addr, ethcommon.BigToHash(big.NewInt(1)).Bytes())
+	assert.True(t, exist)
+	assert.Equal(t, ethcommon.BigToHash(big.NewInt(10)).Bytes(), value)
+}
+
 func TestGetGenesisConfig(t *testing.T) {
 	mockCtl := gomock.NewController(t)
 	chainLedger := mock_ledger.NewMockChainLedger(mockCtl)
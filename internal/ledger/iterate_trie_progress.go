@@ -0,0 +1,63 @@
+package ledger
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/axiomesh/axiom-kit/storage/kv"
+	"github.com/axiomesh/axiom-ledger/internal/ledger/utils"
+	"github.com/axiomesh/axiom-ledger/internal/storagemgr"
+)
+
+// The progress of IterateTrieResume is recorded in the target kv in the same batches as the trie nodes,
+// so the recorded progress never runs ahead of the persisted nodes. The jmt iterator can't be restarted from
+// the middle of a trie, so the progress is tracked per trie: an interrupted account trie is iterated again,
+// and each storage trie found by the account trie is recorded as a queue item until it is written.
+var (
+	iterateTrieRootKey     = []byte(utils.IterateTrieProgressKey + "root")
+	iterateTrieAccountKey  = []byte(utils.IterateTrieProgressKey + "account")
+	iterateTrieQueuePrefix = []byte(utils.IterateTrieProgressKey + "queue-")
+)
+
+// iterateTrieCheckpointInterval is the max interval between two commits of IterateTrieResume,
+// the batch is also committed whenever it exceeds the snapshot batch size
+var iterateTrieCheckpointInterval = 30 * time.Second
+
+type iterateTrieProgress struct {
+	started     bool
+	accountDone bool
+	// queue is the storage tries still waiting to be written
+	queue []common.Hash
+}
+
+func iterateTrieQueueKey(root common.Hash) []byte {
+	return append(bytes.Clone(iterateTrieQueuePrefix), root[:]...)
+}
+
+// loadIterateTrieProgress loads the progress recorded in s, started is false if there is no progress to resume from.
+func loadIterateTrieProgress(s kv.Storage, stateRoot common.Hash) (*iterateTrieProgress, error) {
+	progress := &iterateTrieProgress{}
+	root := s.Get(iterateTrieRootKey)
+	if root == nil {
+		return progress, nil
+	}
+	if common.BytesToHash(root) != stateRoot {
+		return nil, fmt.Errorf("iterate trie progress mismatch, expect state root %s, got %s", stateRoot, common.BytesToHash(root))
+	}
+	progress.started = true
+	progress.accountDone = s.Has(iterateTrieAccountKey)
+	if !progress.accountDone {
+		return progress, nil
+	}
+	err := storagemgr.IteratePrefix(s, iterateTrieQueuePrefix, func(_, v []byte) error {
+		progress.queue = append(progress.queue, common.BytesToHash(v))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load iterate trie progress failed: %w", err)
+	}
+	return progress, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/axiomesh/axiom-kit/jmt"
 	"github.com/axiomesh/axiom-kit/storage/kv"
 	"github.com/axiomesh/axiom-kit/types"
+	"github.com/axiomesh/axiom-ledger/internal/ledger/prune"
 )
 
 // ChainLedger handles block, transaction and receipt data.
@@ -88,21 +89,83 @@ type StateLedger interface {
 	// NewView get a view at specific block. We can enable snapshot if and only if the block were the latest block.
 	NewView(blockHeader *types.BlockHeader, enableSnapshot bool) (StateLedger, error)
 
+	// NewLimitedView is NewView bounded by ledger.max_concurrent_views, it is used by RPC queries,
+	// so that internal callers(e.g. executor and txpool) never compete with them for a view.
+	NewLimitedView(blockHeader *types.BlockHeader, enableSnapshot bool) (StateLedger, error)
+
 	IterateTrie(snapshotMeta *SnapshotMeta, kv kv.Storage, errC chan error)
 
+	// IterateTrieResume is IterateTrie which records its progress in kv, so that an interrupted
+	// iteration can be continued by calling it again with the same kv.
+	IterateTrieResume(snapshotMeta *SnapshotMeta, kv kv.Storage, errC chan error)
+
 	GetTrieSnapshotMeta() (*SnapshotMeta, error)
 
 	VerifyTrie(blockHeader *types.BlockHeader) (bool, error)
 
+	// VerifyTrieSampled verifies samples random root-to-leaf paths of the account trie instead of the whole trie.
+	VerifyTrieSampled(blockHeader *types.BlockHeader, samples int) (bool, error)
+
+	// GetTrieNode reads and decodes the trie node of the raw node key, it is a diagnostic primitive
+	// for investigating missing or corrupted trie nodes.
+	GetTrieNode(rawKey []byte) (types.Node, error)
+
 	Prove(rootHash common.Hash, key []byte) (*jmt.ProofResult, error)
 
 	GenerateSnapshot(blockHeader *types.BlockHeader, errC chan error)
 
+	// FlushCaches writes all pending trie writes held in caches into the backend, it should be called before
+	// GenerateSnapshot or IterateTrie, otherwise the snapshot may be generated from partially flushed state.
+	FlushCaches() error
+
+	// ServeSnapshotChunk serves a chunk of trie nodes and codes of the state at root after the cursor, nextCursor is
+	// empty if the whole state is served. It is the ledger primitive of snap-sync and is transport-agnostic.
+	ServeSnapshotChunk(root common.Hash, cursor []byte, maxBytes int) (chunk []byte, nextCursor []byte, err error)
+
+	// ApplySnapshotChunk authenticates a chunk served by ServeSnapshotChunk against root and writes it into the state
+	// storage, chunks should be applied in order and FinishSnapshotApply should be called after the last one.
+	ApplySnapshotChunk(root common.Hash, chunk []byte) error
+
+	// FinishSnapshotApply makes the applied state of blockHeader readable once it is complete and verified.
+	FinishSnapshotApply(blockHeader *types.BlockHeader) error
+
+	// DisableSnapshot detaches the state snapshot at runtime, all state reads go through the trie afterwards.
+	DisableSnapshot()
+
+	// EnableSnapshot attaches the state snapshot in storage at runtime, the snapshot must be up to date with the ledger.
+	EnableSnapshot(storage kv.Storage) error
+
+	// GetHistoryRange returns the range of blocks whose state can be viewed, it is [0, current height] if prune is disabled.
 	GetHistoryRange() (uint64, uint64)
 
 	CurrentBlockHeight() uint64
 
 	GetStateDelta(blockNumber uint64) *types.StateDelta
+
+	// ModifiedAccounts returns the addresses whose account or storage changed at the given block, the block must be
+	// within the state history range.
+	ModifiedAccounts(blockNumber uint64) ([]*types.Address, error)
+
+	// StorageAt reads a single storage slot at the state of target block without building a full view.
+	StorageAt(blockHeader *types.BlockHeader, addr *types.Address, key []byte) ([]byte, error)
+
+	// GetProof returns the proof of addr and its storage slots at the state of target block in the EIP-1186 shape,
+	// the block must be within the state history range.
+	GetProof(blockHeader *types.BlockHeader, addr *types.Address, storageKeys [][]byte) (*AccountProof, error)
+
+	// ContractStorageSize returns the number of storage entries of addr and the total size of their keys and values
+	// at the state of target block, the block must be within the state history range.
+	ContractStorageSize(blockHeader *types.BlockHeader, addr *types.Address) (entries uint64, bytes uint64, err error)
+
+	// ListContracts returns the addresses of all contract accounts at the state of target block.
+	ListContracts(blockHeader *types.BlockHeader) ([]*types.Address, error)
+
+	// IterateContracts calls fn with every contract account at the state of target block until fn returns false,
+	// it avoids holding all addresses in memory for a large state.
+	IterateContracts(blockHeader *types.BlockHeader, fn func(addr *types.Address) bool) error
+
+	// PruneTo prunes state history lower than targetHeight immediately, progress will be reported to progressC if not nil.
+	PruneTo(targetHeight uint64, progressC chan<- prune.PruneProgress) error
 }
 
 // StateAccessor manipulates the state data
@@ -113,6 +176,9 @@ type StateAccessor interface {
 	// GetAccount
 	GetAccount(*types.Address) IAccount
 
+	// GetAccounts gets accounts in batch, nil stands for a missing account
+	GetAccounts([]*types.Address) ([]IAccount, error)
+
 	// GetBalance
 	GetBalance(*types.Address) *big.Int
 
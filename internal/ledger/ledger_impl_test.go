@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	ethhexutil "github.com/ethereum/go-ethereum/common/hexutil"
@@ -19,6 +20,7 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/holiman/uint256"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -33,6 +35,7 @@ import (
 	"github.com/axiomesh/axiom-kit/storage/kv/leveldb"
 	"github.com/axiomesh/axiom-kit/storage/kv/pebble"
 	"github.com/axiomesh/axiom-kit/types"
+	"github.com/axiomesh/axiom-kit/types/pb"
 	"github.com/axiomesh/axiom-ledger/internal/ledger/prune"
 	"github.com/axiomesh/axiom-ledger/internal/ledger/snapshot"
 	"github.com/axiomesh/axiom-ledger/internal/ledger/utils"
@@ -620,6 +623,581 @@ func TestChainLedger_GetAccount(t *testing.T) {
 	}
 }
 
+func TestStateLedger_ConcurrentReadAccounts(t *testing.T) {
+	ledger, _ := initLedger(t, "", "pebble")
+	stateLedger := ledger.StateLedger.(*StateLedgerImpl)
+
+	stopC := make(chan struct{})
+	doneC := make(chan struct{})
+	go func() {
+		defer close(doneC)
+		for {
+			select {
+			case <-stopC:
+				return
+			default:
+				// at most the accounts of one block are cached
+				assert.LessOrEqual(t, stateLedger.CachedAccountNum(), 10)
+			}
+		}
+	}()
+
+	for i := 1; i <= 3; i++ {
+		for j := 0; j < 10; j++ {
+			addr := types.NewAddress(LeftPadBytes([]byte{byte(i), byte(j)}, 20))
+			stateLedger.SetBalance(addr, big.NewInt(int64(j)))
+		}
+		require.Equal(t, 10, stateLedger.CachedAccountNum())
+		stateLedger.blockHeight = uint64(i)
+		stateLedger.Finalise()
+		_, err := stateLedger.Commit()
+		require.Nil(t, err)
+	}
+	close(stopC)
+	<-doneC
+	require.Equal(t, 0, stateLedger.CachedAccountNum())
+}
+
+func TestStateLedger_StorageAt(t *testing.T) {
+	ledger, _ := initLedger(t, "", "pebble")
+	stateLedger := ledger.StateLedger.(*StateLedgerImpl)
+
+	addr := types.NewAddress(LeftPadBytes([]byte{1}, 20))
+	key := []byte{100, 100}
+	val1 := []byte{1}
+	val2 := []byte{2}
+
+	stateLedger.SetState(addr, key, val1)
+	stateLedger.blockHeight = 1
+	stateLedger.Finalise()
+	stateRoot1, err := stateLedger.Commit()
+	require.Nil(t, err)
+
+	stateLedger.SetState(addr, key, val2)
+	stateLedger.blockHeight = 2
+	stateLedger.Finalise()
+	stateRoot2, err := stateLedger.Commit()
+	require.Nil(t, err)
+
+	val, err := stateLedger.StorageAt(&types.BlockHeader{Number: 1, StateRoot: stateRoot1}, addr, key)
+	require.Nil(t, err)
+	require.Equal(t, val1, val)
+
+	val, err = stateLedger.StorageAt(&types.BlockHeader{Number: 2, StateRoot: stateRoot2}, addr, key)
+	require.Nil(t, err)
+	require.Equal(t, val2, val)
+
+	val, err = stateLedger.StorageAt(&types.BlockHeader{Number: 2, StateRoot: stateRoot2}, types.NewAddress(LeftPadBytes([]byte{2}, 20)), key)
+	require.Nil(t, err)
+	require.Nil(t, val)
+
+	_, err = stateLedger.StorageAt(&types.BlockHeader{Number: 2}, addr, key)
+	require.ErrorIs(t, err, ErrorNilStateRoot)
+}
+
+func TestStateLedger_ContractStorageSize(t *testing.T) {
+	ledger, _ := initLedger(t, "", "pebble")
+	stateLedger := ledger.StateLedger.(*StateLedgerImpl)
+
+	addr := types.NewAddress(LeftPadBytes([]byte{1}, 20))
+	stateLedger.SetState(addr, []byte("key1"), []byte("value1"))
+	stateLedger.SetState(addr, []byte("key2"), []byte("value22"))
+	stateLedger.blockHeight = 1
+	stateLedger.Finalise()
+	stateRoot1, err := stateLedger.Commit()
+	require.Nil(t, err)
+	header1 := &types.BlockHeader{Number: 1, StateRoot: stateRoot1}
+
+	keySize := len(utils.CompositeStorageKey(addr, []byte("key1")))
+	entries, size, err := stateLedger.ContractStorageSize(header1, addr)
+	require.Nil(t, err)
+	require.EqualValues(t, 2, entries)
+	require.EqualValues(t, 2*keySize+len("value1")+len("value22"), size)
+
+	// account without storage
+	entries, size, err = stateLedger.ContractStorageSize(header1, types.NewAddress(LeftPadBytes([]byte{2}, 20)))
+	require.Nil(t, err)
+	require.EqualValues(t, 0, entries)
+	require.EqualValues(t, 0, size)
+
+	stateLedger.SetState(addr, []byte("key3"), []byte("value3"))
+	stateLedger.blockHeight = 2
+	stateLedger.Finalise()
+	stateRoot2, err := stateLedger.Commit()
+	require.Nil(t, err)
+
+	entries, _, err = stateLedger.ContractStorageSize(&types.BlockHeader{Number: 2, StateRoot: stateRoot2}, addr)
+	require.Nil(t, err)
+	require.EqualValues(t, 3, entries)
+	entries, _, err = stateLedger.ContractStorageSize(header1, addr)
+	require.Nil(t, err)
+	require.EqualValues(t, 2, entries)
+
+	// out of history range
+	_, _, err = stateLedger.ContractStorageSize(&types.BlockHeader{Number: 100, StateRoot: stateRoot2}, addr)
+	require.NotNil(t, err)
+}
+
+func TestStateLedger_ListContracts(t *testing.T) {
+	ledger, _ := initLedger(t, "", "pebble")
+	stateLedger := ledger.StateLedger.(*StateLedgerImpl)
+
+	var contracts []*types.Address
+	for i := 1; i <= 10; i++ {
+		addr := types.NewAddress(LeftPadBytes([]byte{byte(i)}, 20))
+		stateLedger.SetBalance(addr, big.NewInt(int64(i)))
+		if i%3 == 0 {
+			stateLedger.SetCode(addr, []byte("contract code"))
+			stateLedger.SetState(addr, []byte("key"), []byte("value"))
+			contracts = append(contracts, addr)
+		}
+	}
+	stateLedger.blockHeight = 1
+	stateLedger.Finalise()
+	stateRoot, err := stateLedger.Commit()
+	require.Nil(t, err)
+	header := &types.BlockHeader{Number: 1, StateRoot: stateRoot}
+
+	list, err := stateLedger.ListContracts(header)
+	require.Nil(t, err)
+	require.ElementsMatch(t, contracts, list)
+
+	// stop iterating early
+	var visited []*types.Address
+	err = stateLedger.IterateContracts(header, func(addr *types.Address) bool {
+		visited = append(visited, addr)
+		return len(visited) < 2
+	})
+	require.Nil(t, err)
+	require.Len(t, visited, 2)
+
+	// out of history range
+	_, err = stateLedger.ListContracts(&types.BlockHeader{Number: 100, StateRoot: stateRoot})
+	require.NotNil(t, err)
+
+	_, err = stateLedger.ListContracts(&types.BlockHeader{Number: 1})
+	require.ErrorIs(t, err, ErrorNilStateRoot)
+}
+
+func TestStateLedger_SnapshotChunk(t *testing.T) {
+	rep := createMockRepo(t)
+	rep.Config.Ledger.EnablePrune = false
+	ledger, err := NewLedger(rep)
+	require.Nil(t, err)
+	stateLedger := ledger.StateLedger.(*StateLedgerImpl)
+
+	var accounts []*types.Address
+	for i := 1; i <= 20; i++ {
+		addr := types.NewAddress(LeftPadBytes([]byte{byte(i)}, 20))
+		stateLedger.SetBalance(addr, big.NewInt(int64(i)))
+		accounts = append(accounts, addr)
+	}
+	contract := types.NewAddress(LeftPadBytes([]byte{100}, 20))
+	code := []byte("contract code")
+	stateLedger.SetCode(contract, code)
+	for i := 0; i < 20; i++ {
+		stateLedger.SetState(contract, []byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i)))
+	}
+	stateLedger.blockHeight = 1
+	stateLedger.Finalise()
+	stateRoot, err := stateLedger.Commit()
+	require.Nil(t, err)
+	header := &types.BlockHeader{Number: 1, StateRoot: stateRoot}
+
+	target, err := stateLedger.NewView(header, false)
+	require.Nil(t, err)
+	targetImpl := target.(*StateLedgerImpl)
+	targetImpl.backend = kv.NewMemory()
+
+	// serve state in small chunks to cover resuming inside the storage trie
+	var cursor []byte
+	var chunks [][]byte
+	for {
+		chunk, next, err := stateLedger.ServeSnapshotChunk(stateRoot.ETHHash(), cursor, 256)
+		require.Nil(t, err)
+		chunks = append(chunks, chunk)
+		if len(next) == 0 {
+			break
+		}
+		cursor = next
+	}
+	require.Greater(t, len(chunks), 1)
+
+	for i, chunk := range chunks {
+		require.Nil(t, targetImpl.ApplySnapshotChunk(stateRoot.ETHHash(), chunk))
+		if i == 0 {
+			// a partially applied state is not readable
+			require.ErrorIs(t, targetImpl.FinishSnapshotApply(header), ErrIncompleteSnapshot)
+		}
+	}
+	require.Nil(t, targetImpl.backend.Get(stateRoot.ETHHash().Bytes()))
+	require.Nil(t, targetImpl.FinishSnapshotApply(header))
+	verify, err := target.VerifyTrie(header)
+	require.Nil(t, err)
+	require.True(t, verify)
+	for i, addr := range accounts {
+		require.EqualValues(t, i+1, target.GetBalance(addr).Uint64())
+	}
+	require.Equal(t, code, target.GetCode(contract))
+	exist, value := target.GetState(contract, []byte("key7"))
+	require.True(t, exist)
+	require.Equal(t, []byte("value7"), value)
+
+	err = targetImpl.ApplySnapshotChunk(stateRoot.ETHHash(), []byte{1, 2, 3})
+	require.ErrorIs(t, err, ErrInvalidSnapshotChunk)
+
+	// the first chunk must start with the mapping of the requested root
+	bad := kv.NewMemory()
+	targetImpl.backend = bad
+	otherRoot := common.Hash{1}
+	err = targetImpl.ApplySnapshotChunk(otherRoot, chunks[0])
+	require.ErrorIs(t, err, ErrInvalidSnapshotChunk)
+
+	// a tampered leaf mismatches the hash recorded by its parent, and nothing of its chunk is written
+	var tamperedKey []byte
+	for _, chunk := range chunks {
+		s := &pb.BytesSlice{}
+		require.Nil(t, s.UnmarshalVT(chunk))
+		for i := 0; i < len(s.Slice) && tamperedKey == nil; i += 3 {
+			if s.Slice[i][0] != snapEntryTrieNode {
+				continue
+			}
+			node, err := types.UnmarshalJMTNodeFromPb(s.Slice[i+2])
+			require.Nil(t, err)
+			if leaf, ok := node.(*types.LeafNode); ok {
+				leaf.Val = append([]byte{}, leaf.Val...)
+				leaf.Val[len(leaf.Val)-1] ^= 0xff
+				leaf.Hash = leaf.GetHash()
+				s.Slice[i+2] = leaf.Encode()
+				tamperedKey = s.Slice[i+1]
+			}
+		}
+		if tamperedKey == nil {
+			require.Nil(t, targetImpl.ApplySnapshotChunk(stateRoot.ETHHash(), chunk))
+			continue
+		}
+		raw, err := s.MarshalVT()
+		require.Nil(t, err)
+		err = targetImpl.ApplySnapshotChunk(stateRoot.ETHHash(), raw)
+		require.ErrorIs(t, err, ErrInvalidSnapshotChunk)
+		break
+	}
+	require.NotNil(t, tamperedKey)
+	require.Nil(t, bad.Get(tamperedKey))
+	require.ErrorIs(t, targetImpl.FinishSnapshotApply(header), ErrIncompleteSnapshot)
+	_, _, err = stateLedger.ServeSnapshotChunk(stateRoot.ETHHash(), []byte{1, 2, 3}, 256)
+	require.ErrorIs(t, err, ErrInvalidSnapshotCursor)
+	_, _, err = stateLedger.ServeSnapshotChunk(common.Hash{}, nil, 256)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStateLedger_FullArchive(t *testing.T) {
+	rep := createMockRepo(t)
+	rep.Config.Ledger.EnablePrune = false
+	ledger, err := NewLedger(rep)
+	require.Nil(t, err)
+	stateLedger := ledger.StateLedger.(*StateLedgerImpl)
+
+	addr := types.NewAddress(LeftPadBytes([]byte{1}, 20))
+	var headers []*types.BlockHeader
+	for height := uint64(1); height <= 3; height++ {
+		stateLedger.SetBalance(addr, new(big.Int).SetUint64(height))
+		stateLedger.blockHeight = height
+		stateLedger.Finalise()
+		stateRoot, err := stateLedger.Commit()
+		require.Nil(t, err)
+		headers = append(headers, &types.BlockHeader{Number: height, StateRoot: stateRoot})
+	}
+
+	minHeight, maxHeight := stateLedger.GetHistoryRange()
+	require.EqualValues(t, 0, minHeight)
+	require.EqualValues(t, 3, maxHeight)
+
+	for _, header := range headers {
+		view, err := stateLedger.NewView(header, false)
+		require.Nil(t, err)
+		require.Equal(t, new(big.Int).SetUint64(header.Number), view.GetBalance(addr))
+	}
+}
+
+func TestStateLedger_PruneHistoryMetrics(t *testing.T) {
+	ledger, _ := initLedger(t, "", "pebble")
+	stateLedger := ledger.StateLedger.(*StateLedgerImpl)
+
+	addr := types.NewAddress(LeftPadBytes([]byte{1}, 20))
+	for height := uint64(1); height <= 3; height++ {
+		stateLedger.SetBalance(addr, new(big.Int).SetUint64(height))
+		stateLedger.blockHeight = height
+		stateLedger.Finalise()
+		_, err := stateLedger.Commit()
+		require.Nil(t, err)
+	}
+
+	minHeight, maxHeight := stateLedger.GetHistoryRange()
+	require.EqualValues(t, minHeight, testutil.ToFloat64(pruneMinHeight))
+	require.EqualValues(t, maxHeight, testutil.ToFloat64(pruneMaxHeight))
+	require.EqualValues(t, maxHeight-minHeight, testutil.ToFloat64(pruneHistoryBlocks))
+}
+
+func TestStateLedger_GetTrieNode(t *testing.T) {
+	rep := createMockRepo(t)
+	rep.Config.Ledger.EnablePrune = false
+	ledger, err := NewLedger(rep)
+	require.Nil(t, err)
+	stateLedger := ledger.StateLedger.(*StateLedgerImpl)
+
+	for i := 1; i <= 20; i++ {
+		stateLedger.SetBalance(types.NewAddress(LeftPadBytes([]byte{byte(i)}, 20)), big.NewInt(int64(i)))
+	}
+	stateLedger.blockHeight = 1
+	stateLedger.Finalise()
+	stateRoot, err := stateLedger.Commit()
+	require.Nil(t, err)
+
+	rootNodeKey := stateLedger.backend.Get(stateRoot.ETHHash().Bytes())
+	require.NotNil(t, rootNodeKey)
+	node, err := stateLedger.GetTrieNode(rootNodeKey)
+	require.Nil(t, err)
+	root, ok := node.(*types.InternalNode)
+	require.True(t, ok)
+	require.Equal(t, stateRoot.ETHHash(), root.GetHash())
+
+	// follow a child to a leaf
+	nk := types.DecodeNodeKey(rootNodeKey)
+	for slot, child := range root.Children {
+		if child == nil || !child.Leaf {
+			continue
+		}
+		childKey := &types.NodeKey{Version: child.Version, Type: nk.Type, Path: append(append([]byte{}, nk.Path...), byte(slot))}
+		node, err = stateLedger.GetTrieNode(childKey.Encode())
+		require.Nil(t, err)
+		leaf, ok := node.(*types.LeafNode)
+		require.True(t, ok)
+		require.NotEmpty(t, leaf.Key)
+		break
+	}
+
+	missing := &types.NodeKey{Version: 100, Type: nk.Type, Path: []byte{1, 2, 3}}
+	_, err = stateLedger.GetTrieNode(missing.Encode())
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = stateLedger.GetTrieNode([]byte{1, 2, 3})
+	require.NotNil(t, err)
+}
+
+func TestStateLedger_DisableAndEnableSnapshot(t *testing.T) {
+	ledger, _ := initLedger(t, "", "pebble")
+	stateLedger := ledger.StateLedger.(*StateLedgerImpl)
+
+	addr := types.NewAddress(LeftPadBytes([]byte{1}, 20))
+	key := []byte{100, 100}
+
+	stateLedger.SetState(addr, key, []byte{1})
+	stateLedger.blockHeight = 1
+	stateLedger.Finalise()
+	_, err := stateLedger.Commit()
+	require.Nil(t, err)
+
+	oldSnapshot := stateLedger.snapshot
+	require.NotNil(t, oldSnapshot)
+	stateLedger.DisableSnapshot()
+	require.Nil(t, stateLedger.snapshot)
+	_, err = oldSnapshot.Account(addr)
+	require.ErrorIs(t, err, snapshot.ErrorDetached)
+	_, err = oldSnapshot.Storage(addr, key)
+	require.ErrorIs(t, err, snapshot.ErrorDetached)
+
+	// state is read through trie
+	stateLedger.SetState(addr, key, []byte{2})
+	stateLedger.blockHeight = 2
+	stateLedger.Finalise()
+	_, err = stateLedger.Commit()
+	require.Nil(t, err)
+	stateLedger.accounts = make(map[string]IAccount)
+	exist, val := stateLedger.GetState(addr, key)
+	require.True(t, exist)
+	require.Equal(t, []byte{2}, val)
+
+	errC := make(chan error, 1)
+	failures := testutil.ToFloat64(snapshotGenerationFailureCounter)
+	stateLedger.GenerateSnapshot(&types.BlockHeader{Number: 2, StateRoot: &types.Hash{}}, errC)
+	require.ErrorIs(t, <-errC, ErrorSnapshotDisabled)
+	require.Equal(t, failures+1, testutil.ToFloat64(snapshotGenerationFailureCounter))
+
+	// snapshot is stale
+	staleStorage := kv.NewMemory()
+	staleStorage.Put(utils.CompositeKey(utils.SnapshotKey, utils.MaxHeightStr), utils.MarshalUint64(1))
+	err = stateLedger.EnableSnapshot(staleStorage)
+	require.NotNil(t, err)
+	require.Nil(t, stateLedger.snapshot)
+
+	snapshotStorage := kv.NewMemory()
+	snapshotStorage.Put(utils.CompositeKey(utils.SnapshotKey, utils.MaxHeightStr), utils.MarshalUint64(2))
+	err = stateLedger.EnableSnapshot(snapshotStorage)
+	require.Nil(t, err)
+	require.NotNil(t, stateLedger.snapshot)
+}
+
+func TestStateLedger_SwapSnapshotConcurrently(t *testing.T) {
+	ledger, _ := initLedger(t, "", "pebble")
+	stateLedger := ledger.StateLedger.(*StateLedgerImpl)
+
+	addr := types.NewAddress(LeftPadBytes([]byte{1}, 20))
+	stateLedger.SetBalance(addr, big.NewInt(1))
+	stateLedger.blockHeight = 1
+	stateLedger.Finalise()
+	stateRoot, err := stateLedger.Commit()
+	require.Nil(t, err)
+	header := &types.BlockHeader{Number: 1, StateRoot: stateRoot}
+
+	snapshotStorage := kv.NewMemory()
+	snapshotStorage.Put(utils.CompositeKey(utils.SnapshotKey, utils.MaxHeightStr), utils.MarshalUint64(1))
+
+	stopC := make(chan struct{})
+	doneC := make(chan struct{})
+	go func() {
+		defer close(doneC)
+		for {
+			select {
+			case <-stopC:
+				return
+			default:
+				view, err := stateLedger.NewView(header, true)
+				assert.Nil(t, err)
+				view.GetBalance(addr)
+			}
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		stateLedger.DisableSnapshot()
+		require.Nil(t, stateLedger.EnableSnapshot(snapshotStorage))
+	}
+	close(stopC)
+	<-doneC
+	require.NotNil(t, stateLedger.getSnapshot())
+}
+
+func TestStateLedger_NewViewAfterCommit(t *testing.T) {
+	ledger, _ := initLedger(t, "", "pebble")
+	stateLedger := ledger.StateLedger.(*StateLedgerImpl)
+
+	addr := types.NewAddress(LeftPadBytes([]byte{1}, 20))
+	stateLedger.SetBalance(addr, big.NewInt(1))
+	stateLedger.blockHeight = 1
+	stateLedger.Finalise()
+	stateRoot1, err := stateLedger.Commit()
+	require.Nil(t, err)
+	header1 := &types.BlockHeader{Number: 1, StateRoot: stateRoot1}
+
+	view, err := stateLedger.NewView(header1, true)
+	require.Nil(t, err)
+	require.NotNil(t, view.(*StateLedgerImpl).snapshot)
+	require.Equal(t, big.NewInt(1), view.GetBalance(addr))
+
+	stateLedger.SetBalance(addr, big.NewInt(2))
+	stateLedger.blockHeight = 2
+	stateLedger.Finalise()
+	_, err = stateLedger.Commit()
+	require.Nil(t, err)
+
+	// snapshot has passed block 1, the view reads through trie
+	view, err = stateLedger.NewView(header1, true)
+	require.Nil(t, err)
+	require.Nil(t, view.(*StateLedgerImpl).snapshot)
+	require.Equal(t, big.NewInt(1), view.GetBalance(addr))
+
+	// NewView waits for the in-flight commit
+	stateLedger.commitLock.Lock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := stateLedger.NewView(header1, true)
+		require.Nil(t, err)
+	}()
+	select {
+	case <-done:
+		t.Fatal("NewView should wait for the in-flight commit")
+	case <-time.After(50 * time.Millisecond):
+	}
+	stateLedger.commitLock.Unlock()
+	<-done
+}
+
+func TestStateLedger_NewViewConcurrencyLimit(t *testing.T) {
+	rep := createMockRepo(t)
+	rep.Config.Ledger.MaxConcurrentViews = 1
+	ledger, err := NewLedger(rep)
+	require.Nil(t, err)
+	stateLedger := ledger.StateLedger.(*StateLedgerImpl)
+
+	addr := types.NewAddress(LeftPadBytes([]byte{1}, 20))
+	stateLedger.SetBalance(addr, big.NewInt(1))
+	stateLedger.blockHeight = 1
+	stateLedger.Finalise()
+	stateRoot, err := stateLedger.Commit()
+	require.Nil(t, err)
+	header := &types.BlockHeader{Number: 1, StateRoot: stateRoot}
+
+	// slot is released after the view is built
+	view, err := stateLedger.NewLimitedView(header, false)
+	require.Nil(t, err)
+	require.Equal(t, big.NewInt(1), view.GetBalance(addr))
+	_, err = view.NewLimitedView(header, false)
+	require.Nil(t, err)
+
+	// the only slot is taken by an in-flight RPC view creation
+	require.Nil(t, stateLedger.acquireView())
+	_, err = stateLedger.NewLimitedView(header, false)
+	require.ErrorIs(t, err, ErrTooManyViews)
+
+	// internal callers(e.g. executor) still get a view while RPC holds every slot
+	view, err = stateLedger.NewView(header, false)
+	require.Nil(t, err)
+	require.Equal(t, big.NewInt(1), view.GetBalance(addr))
+
+	// waiting view gets the slot once it is released
+	go func() {
+		time.Sleep(newViewWaitTimeout / 4)
+		stateLedger.releaseView()
+	}()
+	_, err = stateLedger.NewLimitedView(header, false)
+	require.Nil(t, err)
+}
+
+func TestStateLedger_ModifiedAccounts(t *testing.T) {
+	ledger, _ := initLedger(t, "", "pebble")
+	stateLedger := ledger.StateLedger.(*StateLedgerImpl)
+
+	addr1 := types.NewAddress(LeftPadBytes([]byte{1}, 20))
+	addr2 := types.NewAddress(LeftPadBytes([]byte{2}, 20))
+
+	stateLedger.SetBalance(addr1, big.NewInt(100))
+	stateLedger.blockHeight = 1
+	stateLedger.Finalise()
+	_, err := stateLedger.Commit()
+	require.Nil(t, err)
+
+	stateLedger.SetState(addr2, []byte{100}, []byte{1})
+	stateLedger.blockHeight = 2
+	stateLedger.Finalise()
+	_, err = stateLedger.Commit()
+	require.Nil(t, err)
+
+	addrs, err := stateLedger.ModifiedAccounts(1)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(addrs))
+	require.Equal(t, addr1.String(), addrs[0].String())
+
+	addrs, err = stateLedger.ModifiedAccounts(2)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(addrs))
+	require.Equal(t, addr2.String(), addrs[0].String())
+
+	_, err = stateLedger.ModifiedAccounts(3)
+	require.NotNil(t, err)
+}
+
 func TestChainLedger_GetCode(t *testing.T) {
 	testcase := map[string]struct {
 		kvType string
@@ -2592,6 +3170,183 @@ func TestStateLedger_IterateStorageTrie(t *testing.T) {
 	})
 }
 
+func TestStateLedger_IterateTrieResume(t *testing.T) {
+	lg, _ := initLedger(t, "", "pebble")
+	sl := lg.StateLedger.(*StateLedgerImpl)
+
+	account1 := types.NewAddressByStr("dac17f958d2ee523a2206206994597c13d831ec7")
+	account2 := types.NewAddressByStr("0000000000000000000000000000000000000007")
+
+	sl.blockHeight = 1
+	sl.SetState(account1, []byte("k1"), []byte("v1"))
+	sl.SetCode(account1, []byte("code1"))
+	sl.SetState(account2, []byte("k1"), []byte("v1"))
+	sl.Finalise()
+	stateRoot1, err := sl.Commit()
+	assert.Nil(t, err)
+
+	header := &types.BlockHeader{Number: 1, StateRoot: stateRoot1}
+	storageRoot1 := sl.GetOrCreateAccount(account1).GetStorageRoot()
+	storageRoot2 := sl.GetOrCreateAccount(account2).GetStorageRoot()
+
+	assertNoProgress := func(t *testing.T, s kv.Storage) {
+		assert.False(t, s.Has(iterateTrieRootKey))
+		assert.False(t, s.Has(iterateTrieAccountKey))
+		assert.False(t, s.Prefix(iterateTrieQueuePrefix).Next())
+	}
+
+	t.Run("iterate with checkpoints", func(t *testing.T) {
+		s := kv.NewMemory()
+		errC := make(chan error)
+		go sl.IterateTrieResume(&SnapshotMeta{BlockHeader: header}, s, errC)
+		assert.Nil(t, <-errC)
+		assertNoProgress(t, s)
+
+		sl1, err := sl.NewView(header, false)
+		assert.Nil(t, err)
+		sl1.(*StateLedgerImpl).backend = s
+		sl1.(*StateLedgerImpl).refreshAccountTrie(header.StateRoot)
+		verify, err := sl1.VerifyTrie(header)
+		assert.Nil(t, err)
+		assert.True(t, verify)
+	})
+
+	t.Run("resume from interrupted iteration", func(t *testing.T) {
+		// the account trie and the storage trie of account1 have been written before interruption
+		s := kv.NewMemory()
+		s.Put(iterateTrieRootKey, stateRoot1.ETHHash().Bytes())
+		s.Put(iterateTrieAccountKey, []byte{1})
+		s.Put(iterateTrieQueueKey(storageRoot2), storageRoot2.Bytes())
+
+		errC := make(chan error)
+		go sl.IterateTrieResume(&SnapshotMeta{BlockHeader: header}, s, errC)
+		assert.Nil(t, <-errC)
+
+		assert.False(t, s.Has(stateRoot1.ETHHash().Bytes()))
+		assert.False(t, s.Has(storageRoot1.Bytes()))
+		assert.True(t, s.Has(storageRoot2.Bytes()))
+		assert.True(t, s.Has([]byte(utils.SnapshotMetaKey)))
+		assertNoProgress(t, s)
+	})
+
+	t.Run("resume from interrupted account trie", func(t *testing.T) {
+		// the account trie is iterated again and finds all storage tries
+		s := kv.NewMemory()
+		s.Put(iterateTrieRootKey, stateRoot1.ETHHash().Bytes())
+		s.Put(iterateTrieQueueKey(storageRoot1), storageRoot1.Bytes())
+
+		errC := make(chan error)
+		go sl.IterateTrieResume(&SnapshotMeta{BlockHeader: header}, s, errC)
+		assert.Nil(t, <-errC)
+
+		assert.True(t, s.Has(stateRoot1.ETHHash().Bytes()))
+		assert.True(t, s.Has(storageRoot1.Bytes()))
+		assert.True(t, s.Has(storageRoot2.Bytes()))
+		assertNoProgress(t, s)
+	})
+
+	t.Run("progress of another state root", func(t *testing.T) {
+		s := kv.NewMemory()
+		s.Put(iterateTrieRootKey, common.Hash{1}.Bytes())
+
+		errC := make(chan error)
+		go sl.IterateTrieResume(&SnapshotMeta{BlockHeader: header}, s, errC)
+		assert.NotNil(t, <-errC)
+	})
+}
+
+func TestStateLedger_GetAccounts(t *testing.T) {
+	lg, _ := initLedger(t, "", "pebble")
+	sl := lg.StateLedger.(*StateLedgerImpl)
+	sl.DisableSnapshot()
+
+	addrs := make([]*types.Address, 50)
+	for i := range addrs {
+		addrs[i] = types.NewAddress(LeftPadBytes([]byte{byte(i + 1)}, 20))
+		sl.SetBalance(addrs[i], big.NewInt(int64(i+1)))
+	}
+	sl.SetCode(addrs[0], []byte("code"))
+	sl.blockHeight = 1
+	sl.Finalise()
+	_, err := sl.Commit()
+	require.Nil(t, err)
+
+	missing := types.NewAddress(LeftPadBytes([]byte{0xff}, 20))
+	query := append([]*types.Address{missing, addrs[3]}, addrs...)
+
+	// read through trie
+	sl.accounts = make(map[string]IAccount)
+	accounts, err := sl.GetAccounts(query)
+	require.Nil(t, err)
+	require.Len(t, accounts, len(query))
+	require.Nil(t, accounts[0])
+	require.Equal(t, big.NewInt(4), accounts[1].GetBalance())
+	require.Equal(t, []byte("code"), accounts[2].Code())
+	for i, addr := range addrs {
+		require.Equal(t, big.NewInt(int64(i+1)), accounts[i+2].GetBalance())
+		require.Equal(t, addr.String(), accounts[i+2].GetAddress().String())
+	}
+
+	// consistent with GetAccount
+	sl.accounts = make(map[string]IAccount)
+	for i, addr := range query {
+		account := sl.GetAccount(addr)
+		if account == nil {
+			require.Nil(t, accounts[i])
+			continue
+		}
+		require.Equal(t, account.GetBalance(), accounts[i].GetBalance())
+		require.Equal(t, account.GetNonce(), accounts[i].GetNonce())
+		require.Equal(t, account.Code(), accounts[i].Code())
+	}
+
+	// served from cache
+	cached, err := sl.GetAccounts(addrs[:2])
+	require.Nil(t, err)
+	require.Equal(t, sl.accounts[addrs[0].String()], cached[0])
+	require.Equal(t, sl.accounts[addrs[1].String()], cached[1])
+
+	// read through account trie cache, prune cache is bypassed so that every node must come from trie cache
+	warmAccountTrieCache(t, sl)
+	pruneCache := sl.pruneCache
+	sl.pruneCache = nil
+	sl.accountTrieCache.ResetCounterMetrics()
+	sl.accounts = make(map[string]IAccount)
+	warmed, err := sl.GetAccounts(addrs)
+	sl.pruneCache = pruneCache
+	require.Nil(t, err)
+	for i := range addrs {
+		require.Equal(t, big.NewInt(int64(i+1)), warmed[i].GetBalance())
+	}
+	metrics := sl.accountTrieCache.ExportMetrics()
+	require.True(t, metrics.CacheHitCounter > 0)
+	require.Zero(t, metrics.CacheMissCounter)
+}
+
+// warmAccountTrieCache puts all account trie nodes below root into account trie cache, as the pruner does when
+// flushing trie nodes into kv
+func warmAccountTrieCache(t testing.TB, sl *StateLedgerImpl) {
+	var warm func(node types.Node, path []byte)
+	warm = func(node types.Node, path []byte) {
+		n, ok := node.(*types.InternalNode)
+		if !ok {
+			return
+		}
+		for nibble, child := range n.Children {
+			if child == nil {
+				continue
+			}
+			childPath := append(append([]byte{}, path...), byte(nibble))
+			nk := &types.NodeKey{Version: child.Version, Path: childPath, Type: []byte{}}
+			childNode, err := sl.getAccountTrieNode(nk)
+			require.Nil(t, err)
+			sl.accountTrieCache.Set(nk.Encode(), childNode.Encode())
+			warm(childNode, childPath)
+		}
+	}
+	warm(sl.accountTrie.Root(), nil)
+}
+
 func TestStateLedger_GetTrieSnapshotMeta(t *testing.T) {
 	lg, _ := initLedger(t, "", "pebble")
 	sl := lg.StateLedger.(*StateLedgerImpl)
@@ -2971,6 +3726,32 @@ func TestStateLedger_TrieProof(t *testing.T) {
 		assert.Nil(t, err)
 		assert.False(t, verify)
 	})
+
+	t.Run("verify proof without ledger", func(t *testing.T) {
+		key := utils.CompositeAccountKey(account1)
+		account1Proof, err := sl.Prove(stateRoot2.ETHHash(), key)
+		assert.Nil(t, err)
+		value, err := VerifyProof(stateRoot2.ETHHash(), key, account1Proof)
+		assert.Nil(t, err)
+		assert.Equal(t, account1Proof.Value, value)
+		acc := &types.InnerAccount{Balance: big.NewInt(0)}
+		assert.Nil(t, acc.Unmarshal(value))
+		assert.Equal(t, uint64(12), acc.Nonce)
+
+		// proof of another root
+		_, err = VerifyProof(stateRoot1.ETHHash(), key, account1Proof)
+		assert.ErrorIs(t, err, ErrorInvalidProof)
+
+		// tampered value
+		tampered := &jmt.ProofResult{Key: account1Proof.Key, Value: account1Proof.Value, Proof: append([][]byte{}, account1Proof.Proof...)}
+		leaf := &types.LeafNode{Key: key, Val: []byte("fake")}
+		tampered.Proof[len(tampered.Proof)-1] = leaf.Encode()
+		_, err = VerifyProof(stateRoot2.ETHHash(), key, tampered)
+		assert.ErrorIs(t, err, ErrorInvalidProof)
+
+		_, err = VerifyProof(stateRoot2.ETHHash(), key, &jmt.ProofResult{})
+		assert.ErrorIs(t, err, ErrorInvalidProof)
+	})
 }
 
 func TestStateLedger_RPCGetProof(t *testing.T) {
@@ -3108,6 +3889,149 @@ func TestStateLedger_RPCGetProof(t *testing.T) {
 	})
 }
 
+func TestStateLedger_GetProof(t *testing.T) {
+	lg, _ := initLedger(t, "", "pebble")
+	sl := lg.StateLedger.(*StateLedgerImpl)
+
+	account1 := types.NewAddress(LeftPadBytes([]byte{101}, 20))
+	account2 := types.NewAddress(LeftPadBytes([]byte{102}, 20))
+	absentAccount := types.NewAddress(LeftPadBytes([]byte{109}, 20))
+	key1 := crypto1.Keccak256Hash([]byte("key1"))
+	absentKey := crypto1.Keccak256Hash([]byte("absent"))
+
+	sl.blockHeight = 1
+	sl.SetBalance(account1, new(big.Int).SetInt64(101))
+	sl.SetNonce(account1, 3)
+	sl.SetCode(account2, []byte("code2"))
+	sl.SetState(account2, key1[:], []byte("val1"))
+	sl.Finalise()
+	stateRoot1, err := sl.Commit()
+	assert.Nil(t, err)
+	header := &types.BlockHeader{Number: 1, StateRoot: stateRoot1}
+
+	proof, err := sl.GetProof(header, account1, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(101), proof.Balance.Int64())
+	assert.Equal(t, uint64(3), proof.Nonce)
+	assert.Equal(t, common.Hash{}, proof.StorageRoot)
+	assert.Empty(t, proof.StorageProof)
+	value, err := VerifyProof(stateRoot1.ETHHash(), utils.CompositeAccountKey(account1), &jmt.ProofResult{Proof: proof.AccountProof})
+	assert.Nil(t, err)
+	assert.NotNil(t, value)
+
+	proof, err = sl.GetProof(header, account2, [][]byte{key1[:], absentKey[:]})
+	assert.Nil(t, err)
+	assert.NotEqual(t, common.Hash{}, proof.StorageRoot)
+	assert.Equal(t, crypto1.Keccak256([]byte("code2")), proof.CodeHash)
+	assert.Equal(t, 2, len(proof.StorageProof))
+	value, err = VerifyProof(proof.StorageRoot, utils.CompositeStorageKey(account2, key1[:]), &jmt.ProofResult{Proof: proof.StorageProof[0].Proof})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("val1"), value)
+	assert.Equal(t, []byte("val1"), proof.StorageProof[0].Value)
+	assert.Nil(t, proof.StorageProof[1].Value)
+	value, err = VerifyProof(proof.StorageRoot, utils.CompositeStorageKey(account2, absentKey[:]), &jmt.ProofResult{Proof: proof.StorageProof[1].Proof})
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+
+	// absent account gets a proof of absence
+	proof, err = sl.GetProof(header, absentAccount, [][]byte{key1[:]})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), proof.Balance.Int64())
+	assert.Nil(t, proof.StorageProof[0].Value)
+	value, err = VerifyProof(stateRoot1.ETHHash(), utils.CompositeAccountKey(absentAccount), &jmt.ProofResult{Proof: proof.AccountProof})
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+
+	_, err = sl.GetProof(&types.BlockHeader{Number: 1}, account2, nil)
+	assert.ErrorIs(t, err, ErrorNilStateRoot)
+}
+
+func TestVerifyProof_Absence(t *testing.T) {
+	lg, _ := initLedger(t, "", "pebble")
+	sl := lg.StateLedger.(*StateLedgerImpl)
+
+	// account1 and account2 share a long prefix, account3 is the only key under its first nibble
+	account1 := types.NewAddress(LeftPadBytes([]byte{101}, 20))
+	account2 := types.NewAddress(LeftPadBytes([]byte{102}, 20))
+	account3 := types.NewAddress(append([]byte{0x10}, make([]byte, 19)...))
+	sl.blockHeight = 1
+	sl.SetBalance(account1, big.NewInt(1))
+	sl.SetBalance(account2, big.NewInt(2))
+	sl.SetBalance(account3, big.NewInt(3))
+	sl.Finalise()
+	stateRoot1, err := sl.Commit()
+	assert.Nil(t, err)
+	header := &types.BlockHeader{Number: 1, StateRoot: stateRoot1}
+
+	proveAbsent := func(addr *types.Address) (*jmt.ProofResult, types.Node) {
+		res, err := sl.GetProof(header, addr, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), res.Balance.Int64())
+		last, err := types.UnmarshalJMTNodeFromPb(res.AccountProof[len(res.AccountProof)-1])
+		assert.Nil(t, err)
+		return &jmt.ProofResult{Proof: res.AccountProof}, last
+	}
+
+	t.Run("path ends at an empty slot", func(t *testing.T) {
+		absent := types.NewAddress(LeftPadBytes([]byte{109}, 20))
+		key := utils.CompositeAccountKey(absent)
+		proof, last := proveAbsent(absent)
+		_, ok := last.(*types.InternalNode)
+		assert.True(t, ok)
+
+		value, err := VerifyProof(stateRoot1.ETHHash(), key, proof)
+		assert.Nil(t, err)
+		assert.Nil(t, value)
+
+		// nodes beyond the empty slot
+		extended := &jmt.ProofResult{Proof: append(append([][]byte{}, proof.Proof...), proof.Proof[len(proof.Proof)-1])}
+		_, err = VerifyProof(stateRoot1.ETHHash(), key, extended)
+		assert.ErrorIs(t, err, ErrorInvalidProof)
+
+		// the path of another key
+		_, err = VerifyProof(stateRoot1.ETHHash(), utils.CompositeAccountKey(account1), proof)
+		assert.ErrorIs(t, err, ErrorInvalidProof)
+	})
+
+	t.Run("path ends at the leaf of another key", func(t *testing.T) {
+		absent := types.NewAddress(append([]byte{0x11}, make([]byte, 19)...))
+		key := utils.CompositeAccountKey(absent)
+		proof, last := proveAbsent(absent)
+		leaf, ok := last.(*types.LeafNode)
+		assert.True(t, ok)
+		assert.Equal(t, utils.CompositeAccountKey(account3), leaf.Key)
+
+		value, err := VerifyProof(stateRoot1.ETHHash(), key, proof)
+		assert.Nil(t, err)
+		assert.Nil(t, value)
+
+		// forged leaf of another key
+		forged := &jmt.ProofResult{Proof: append([][]byte{}, proof.Proof...)}
+		fake := &types.LeafNode{Key: utils.CompositeAccountKey(account1), Val: []byte("fake")}
+		forged.Proof[len(forged.Proof)-1] = fake.Encode()
+		_, err = VerifyProof(stateRoot1.ETHHash(), key, forged)
+		assert.ErrorIs(t, err, ErrorInvalidProof)
+	})
+
+	t.Run("proof of another root", func(t *testing.T) {
+		absent := types.NewAddress(LeftPadBytes([]byte{109}, 20))
+		proof, _ := proveAbsent(absent)
+		sl.blockHeight = 2
+		sl.SetBalance(account1, big.NewInt(11))
+		sl.Finalise()
+		stateRoot2, err := sl.Commit()
+		assert.Nil(t, err)
+
+		_, err = VerifyProof(stateRoot2.ETHHash(), utils.CompositeAccountKey(absent), proof)
+		assert.ErrorIs(t, err, ErrorInvalidProof)
+
+		// truncated path stops at an internal node with a present child
+		truncated := &jmt.ProofResult{Proof: proof.Proof[:len(proof.Proof)-1]}
+		_, err = VerifyProof(stateRoot1.ETHHash(), utils.CompositeAccountKey(absent), truncated)
+		assert.ErrorIs(t, err, ErrorInvalidProof)
+	})
+}
+
 func TestLedger_NewView(t *testing.T) {
 	lg, _ := initLedger(t, "", "pebble")
 	sl := lg.StateLedger.(*StateLedgerImpl)
@@ -3153,6 +4077,15 @@ func TestLedger_NewView(t *testing.T) {
 		assert.Equal(t, []byte("val1"), a2k1)
 		assert.Equal(t, code1[:], lg1.GetCode(contractAccount))
 	})
+
+	t.Run("new view with missing state root", func(t *testing.T) {
+		header := &types.BlockHeader{
+			Number:    0,
+			StateRoot: types.NewHashByStr("0x13f13807cd76d356488030eff6a27e3f07cb97ba6a455d1175bbfe5644617f89"),
+		}
+		_, err := sl.NewView(header, false)
+		assert.ErrorIs(t, err, jmt.ErrorNotFound)
+	})
 }
 
 type mockAccountResult struct {
@@ -3282,6 +4215,76 @@ func mockGetProof(address common.Address, storageKeys []string, stateLedger Stat
 	return ret, nil
 }
 
+func TestTrieVerifier(t *testing.T) {
+	lg, _ := initLedger(t, "", "pebble")
+	sl := lg.StateLedger.(*StateLedgerImpl)
+	sl.blockHeight = 0
+	account := types.NewAddress(LeftPadBytes([]byte{100}, 20))
+	sl.SetState(account, []byte("a"), []byte("b"))
+	sl.SetBalance(account, big.NewInt(100))
+	sl.Finalise()
+	stateRoot, err := sl.Commit()
+	require.Nil(t, err)
+	lg.PersistBlockData(genBlockData(0, stateRoot))
+
+	var failed *types.BlockHeader
+	verifier := NewTrieVerifier(lg, time.Hour, 0, log.NewWithModule("ledger"), func(header *types.BlockHeader, err error) {
+		failed = header
+	})
+	require.True(t, verifier.verifyLatest())
+	require.Equal(t, float64(0), testutil.ToFloat64(trieLastVerifiedHeight))
+	require.Nil(t, failed)
+	sampled := NewTrieVerifier(lg, time.Hour, 4, log.NewWithModule("ledger"), func(header *types.BlockHeader, err error) {
+		failed = header
+	})
+	require.True(t, sampled.verifyLatest())
+	require.Nil(t, failed)
+
+	// the state of the latest block is missing
+	failures := testutil.ToFloat64(trieVerifyFailureCounter)
+	lg.PersistBlockData(genBlockData(1, types.NewHashByStr("0x1234")))
+	require.False(t, verifier.verifyLatest())
+	require.Equal(t, failures+1, testutil.ToFloat64(trieVerifyFailureCounter))
+	require.NotNil(t, failed)
+	require.Equal(t, uint64(1), failed.Number)
+}
+
+func TestStateLedger_VerifyTrieSampled(t *testing.T) {
+	lg, _ := initLedger(t, "", "pebble")
+	sl := lg.StateLedger.(*StateLedgerImpl)
+	sl.blockHeight = 1
+	for i := 0; i < 3; i++ {
+		sl.SetBalance(types.NewAddress(LeftPadBytes([]byte{byte(101 + i)}, 20)), big.NewInt(100))
+	}
+	sl.Finalise()
+	stateRoot, err := sl.Commit()
+	require.Nil(t, err)
+	header := &types.BlockHeader{Number: 1, StateRoot: stateRoot}
+
+	verified, err := sl.VerifyTrieSampled(header, 8)
+	require.Nil(t, err)
+	require.True(t, verified)
+
+	_, err = sl.VerifyTrieSampled(&types.BlockHeader{Number: 1}, 8)
+	require.ErrorIs(t, err, ErrorNilStateRoot)
+
+	// replace every child of the root with a node of wrong hash, so that any sample hits the corruption
+	sl.pruneCache = nil
+	root := sl.accountTrie.Root().(*types.InternalNode)
+	for nibble, child := range root.Children {
+		if child == nil {
+			continue
+		}
+		nk := &types.NodeKey{Version: child.Version, Path: []byte{byte(nibble)}, Type: []byte{}}
+		corrupted := &types.LeafNode{Key: []byte{byte(nibble)}, Val: []byte("corrupted"), Hash: common.Hash{1}}
+		sl.backend.Put(nk.Encode(), corrupted.Encode())
+		sl.accountTrieCache.Set(nk.Encode(), corrupted.Encode())
+	}
+	verified, err = sl.VerifyTrieSampled(header, 1)
+	require.Nil(t, err)
+	require.False(t, verified)
+}
+
 func genBlockData(height uint64, stateRoot *types.Hash) *BlockData {
 	return &BlockData{
 		Block: &types.Block{
@@ -3339,3 +4342,79 @@ func RightPadBytes(slice []byte, l int) []byte {
 func newSnapshot(rep *repo.Repo) *snapshot.Snapshot {
 	return snapshot.NewSnapshot(rep, kv.NewMemory(), log.NewWithModule("snapshot_test"))
 }
+
+func BenchmarkStateLedger_GetAccounts(b *testing.B) {
+	rep := repo.MockRepo(b)
+	l, err := NewLedger(rep)
+	require.Nil(b, err)
+	stateLedger := l.StateLedger.(*StateLedgerImpl)
+	stateLedger.DisableSnapshot()
+
+	addrs := make([]*types.Address, 100)
+	for i := range addrs {
+		addrs[i] = types.NewAddress(crypto1.Keccak256([]byte{byte(i)})[:20])
+		stateLedger.SetBalance(addrs[i], big.NewInt(int64(i+1)))
+	}
+	stateLedger.blockHeight = 1
+	stateLedger.Finalise()
+	_, err = stateLedger.Commit()
+	require.Nil(b, err)
+	// compare with trie nodes served from a warm account trie cache, which is the common case for both paths
+	warmAccountTrieCache(b, stateLedger)
+
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			stateLedger.accounts = make(map[string]IAccount)
+			_, err := stateLedger.GetAccounts(addrs)
+			require.Nil(b, err)
+		}
+	})
+	b.Run("separate", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			stateLedger.accounts = make(map[string]IAccount)
+			for _, addr := range addrs {
+				stateLedger.GetAccount(addr)
+			}
+		}
+	})
+}
+
+func BenchmarkStateLedger_CommitWithTriePreloadWorkers(b *testing.B) {
+	const (
+		accountNum = 16
+		keyNum     = 1000
+	)
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("workers-%d", workers), func(b *testing.B) {
+			rep := repo.MockRepo(b)
+			rep.Config.Ledger.EnablePreload = true
+			rep.Config.Ledger.TriePreloadWorkers = workers
+			l, err := NewLedger(rep)
+			require.Nil(b, err)
+			stateLedger := l.StateLedger.(*StateLedgerImpl)
+
+			addrs := make([]*types.Address, accountNum)
+			for i := range addrs {
+				addrs[i] = types.NewAddress(LeftPadBytes([]byte{byte(i + 1)}, 20))
+			}
+			commit := func(height uint64) {
+				for _, addr := range addrs {
+					for k := 0; k < keyNum; k++ {
+						stateLedger.SetState(addr, []byte(fmt.Sprintf("key-%d", k)), []byte(fmt.Sprintf("value-%d-%d", height, k)))
+					}
+				}
+				stateLedger.blockHeight = height
+				stateLedger.Finalise()
+				_, err := stateLedger.Commit()
+				require.Nil(b, err)
+			}
+			// storage tries are only preloaded when they are not empty
+			commit(1)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				commit(uint64(i + 2))
+			}
+		})
+	}
+}
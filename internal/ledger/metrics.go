@@ -108,6 +108,55 @@ var (
 		Help:      "The total latency of get a transaction from db",
 		Buckets:   prometheus.ExponentialBuckets(0.00001, 2, 10),
 	})
+
+	pruneHistoryBlocks = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "axiom_ledger",
+		Subsystem: "ledger",
+		Name:      "prune_history_blocks",
+		Help:      "The width of state history window that can be viewed (max height - min height)",
+	})
+
+	pruneMinHeight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "axiom_ledger",
+		Subsystem: "ledger",
+		Name:      "prune_min_height",
+		Help:      "The min block height of state history window",
+	})
+
+	pruneMaxHeight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "axiom_ledger",
+		Subsystem: "ledger",
+		Name:      "prune_max_height",
+		Help:      "The max block height of state history window",
+	})
+
+	newViewRejectedCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "axiom_ledger",
+		Subsystem: "ledger",
+		Name:      "new_view_rejected_total",
+		Help:      "The total number of NewView rejected for too many concurrent views",
+	})
+
+	trieVerifyFailureCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "axiom_ledger",
+		Subsystem: "ledger",
+		Name:      "trie_verify_failures_total",
+		Help:      "The total number of state trie verifications failed in background",
+	})
+
+	trieLastVerifiedHeight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "axiom_ledger",
+		Subsystem: "ledger",
+		Name:      "trie_last_verified_height",
+		Help:      "The height of the last block whose state trie is verified in background",
+	})
+
+	snapshotGenerationFailureCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "axiom_ledger",
+		Subsystem: "ledger",
+		Name:      "snapshot_generation_failures_total",
+		Help:      "The total number of failed snapshot generations",
+	})
 )
 
 func init() {
@@ -125,4 +174,11 @@ func init() {
 	prometheus.MustRegister(storageTrieCacheSize)
 	prometheus.MustRegister(getTransactionCounter)
 	prometheus.MustRegister(getTransactionDuration)
+	prometheus.MustRegister(pruneHistoryBlocks)
+	prometheus.MustRegister(pruneMinHeight)
+	prometheus.MustRegister(pruneMaxHeight)
+	prometheus.MustRegister(newViewRejectedCounter)
+	prometheus.MustRegister(trieVerifyFailureCounter)
+	prometheus.MustRegister(trieLastVerifiedHeight)
+	prometheus.MustRegister(snapshotGenerationFailureCounter)
 }
@@ -17,6 +17,7 @@ import (
 	kv "github.com/axiomesh/axiom-kit/storage/kv"
 	types "github.com/axiomesh/axiom-kit/types"
 	ledger "github.com/axiomesh/axiom-ledger/internal/ledger"
+	prune "github.com/axiomesh/axiom-ledger/internal/ledger/prune"
 	common "github.com/ethereum/go-ethereum/common"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -1013,6 +1014,44 @@ func (c *StateLedgerAddressInAccessListCall) DoAndReturn(f func(types.Address) b
 	return c
 }
 
+// ApplySnapshotChunk mocks base method.
+func (m *MockStateLedger) ApplySnapshotChunk(root common.Hash, chunk []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplySnapshotChunk", root, chunk)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplySnapshotChunk indicates an expected call of ApplySnapshotChunk.
+func (mr *MockStateLedgerMockRecorder) ApplySnapshotChunk(root, chunk any) *StateLedgerApplySnapshotChunkCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplySnapshotChunk", reflect.TypeOf((*MockStateLedger)(nil).ApplySnapshotChunk), root, chunk)
+	return &StateLedgerApplySnapshotChunkCall{Call: call}
+}
+
+// StateLedgerApplySnapshotChunkCall wrap *gomock.Call
+type StateLedgerApplySnapshotChunkCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StateLedgerApplySnapshotChunkCall) Return(arg0 error) *StateLedgerApplySnapshotChunkCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StateLedgerApplySnapshotChunkCall) Do(f func(common.Hash, []byte) error) *StateLedgerApplySnapshotChunkCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StateLedgerApplySnapshotChunkCall) DoAndReturn(f func(common.Hash, []byte) error) *StateLedgerApplySnapshotChunkCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Clear mocks base method.
 func (m *MockStateLedger) Clear() {
 	m.ctrl.T.Helper()
@@ -1160,6 +1199,46 @@ func (c *StateLedgerCommitCall) DoAndReturn(f func() (*types.Hash, error)) *Stat
 	return c
 }
 
+// ContractStorageSize mocks base method.
+func (m *MockStateLedger) ContractStorageSize(blockHeader *types.BlockHeader, addr *types.Address) (uint64, uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContractStorageSize", blockHeader, addr)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(uint64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ContractStorageSize indicates an expected call of ContractStorageSize.
+func (mr *MockStateLedgerMockRecorder) ContractStorageSize(blockHeader, addr any) *StateLedgerContractStorageSizeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContractStorageSize", reflect.TypeOf((*MockStateLedger)(nil).ContractStorageSize), blockHeader, addr)
+	return &StateLedgerContractStorageSizeCall{Call: call}
+}
+
+// StateLedgerContractStorageSizeCall wrap *gomock.Call
+type StateLedgerContractStorageSizeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StateLedgerContractStorageSizeCall) Return(entries, bytes uint64, err error) *StateLedgerContractStorageSizeCall {
+	c.Call = c.Call.Return(entries, bytes, err)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StateLedgerContractStorageSizeCall) Do(f func(*types.BlockHeader, *types.Address) (uint64, uint64, error)) *StateLedgerContractStorageSizeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StateLedgerContractStorageSizeCall) DoAndReturn(f func(*types.BlockHeader, *types.Address) (uint64, uint64, error)) *StateLedgerContractStorageSizeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // CurrentBlockHeight mocks base method.
 func (m *MockStateLedger) CurrentBlockHeight() uint64 {
 	m.ctrl.T.Helper()
@@ -1198,6 +1277,42 @@ func (c *StateLedgerCurrentBlockHeightCall) DoAndReturn(f func() uint64) *StateL
 	return c
 }
 
+// DisableSnapshot mocks base method.
+func (m *MockStateLedger) DisableSnapshot() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DisableSnapshot")
+}
+
+// DisableSnapshot indicates an expected call of DisableSnapshot.
+func (mr *MockStateLedgerMockRecorder) DisableSnapshot() *StateLedgerDisableSnapshotCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableSnapshot", reflect.TypeOf((*MockStateLedger)(nil).DisableSnapshot))
+	return &StateLedgerDisableSnapshotCall{Call: call}
+}
+
+// StateLedgerDisableSnapshotCall wrap *gomock.Call
+type StateLedgerDisableSnapshotCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StateLedgerDisableSnapshotCall) Return() *StateLedgerDisableSnapshotCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StateLedgerDisableSnapshotCall) Do(f func()) *StateLedgerDisableSnapshotCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StateLedgerDisableSnapshotCall) DoAndReturn(f func()) *StateLedgerDisableSnapshotCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Empty mocks base method.
 func (m *MockStateLedger) Empty(arg0 *types.Address) bool {
 	m.ctrl.T.Helper()
@@ -1236,6 +1351,44 @@ func (c *StateLedgerEmptyCall) DoAndReturn(f func(*types.Address) bool) *StateLe
 	return c
 }
 
+// EnableSnapshot mocks base method.
+func (m *MockStateLedger) EnableSnapshot(storage kv.Storage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableSnapshot", storage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableSnapshot indicates an expected call of EnableSnapshot.
+func (mr *MockStateLedgerMockRecorder) EnableSnapshot(storage any) *StateLedgerEnableSnapshotCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableSnapshot", reflect.TypeOf((*MockStateLedger)(nil).EnableSnapshot), storage)
+	return &StateLedgerEnableSnapshotCall{Call: call}
+}
+
+// StateLedgerEnableSnapshotCall wrap *gomock.Call
+type StateLedgerEnableSnapshotCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StateLedgerEnableSnapshotCall) Return(arg0 error) *StateLedgerEnableSnapshotCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StateLedgerEnableSnapshotCall) Do(f func(kv.Storage) error) *StateLedgerEnableSnapshotCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StateLedgerEnableSnapshotCall) DoAndReturn(f func(kv.Storage) error) *StateLedgerEnableSnapshotCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Exist mocks base method.
 func (m *MockStateLedger) Exist(arg0 *types.Address) bool {
 	m.ctrl.T.Helper()
@@ -1310,6 +1463,82 @@ func (c *StateLedgerFinaliseCall) DoAndReturn(f func()) *StateLedgerFinaliseCall
 	return c
 }
 
+// FinishSnapshotApply mocks base method.
+func (m *MockStateLedger) FinishSnapshotApply(blockHeader *types.BlockHeader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishSnapshotApply", blockHeader)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishSnapshotApply indicates an expected call of FinishSnapshotApply.
+func (mr *MockStateLedgerMockRecorder) FinishSnapshotApply(blockHeader any) *StateLedgerFinishSnapshotApplyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishSnapshotApply", reflect.TypeOf((*MockStateLedger)(nil).FinishSnapshotApply), blockHeader)
+	return &StateLedgerFinishSnapshotApplyCall{Call: call}
+}
+
+// StateLedgerFinishSnapshotApplyCall wrap *gomock.Call
+type StateLedgerFinishSnapshotApplyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StateLedgerFinishSnapshotApplyCall) Return(arg0 error) *StateLedgerFinishSnapshotApplyCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StateLedgerFinishSnapshotApplyCall) Do(f func(*types.BlockHeader) error) *StateLedgerFinishSnapshotApplyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StateLedgerFinishSnapshotApplyCall) DoAndReturn(f func(*types.BlockHeader) error) *StateLedgerFinishSnapshotApplyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// FlushCaches mocks base method.
+func (m *MockStateLedger) FlushCaches() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlushCaches")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FlushCaches indicates an expected call of FlushCaches.
+func (mr *MockStateLedgerMockRecorder) FlushCaches() *StateLedgerFlushCachesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlushCaches", reflect.TypeOf((*MockStateLedger)(nil).FlushCaches))
+	return &StateLedgerFlushCachesCall{Call: call}
+}
+
+// StateLedgerFlushCachesCall wrap *gomock.Call
+type StateLedgerFlushCachesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StateLedgerFlushCachesCall) Return(arg0 error) *StateLedgerFlushCachesCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StateLedgerFlushCachesCall) Do(f func() error) *StateLedgerFlushCachesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StateLedgerFlushCachesCall) DoAndReturn(f func() error) *StateLedgerFlushCachesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GenerateSnapshot mocks base method.
 func (m *MockStateLedger) GenerateSnapshot(blockHeader *types.BlockHeader, errC chan error) {
 	m.ctrl.T.Helper()
@@ -1384,6 +1613,45 @@ func (c *StateLedgerGetAccountCall) DoAndReturn(f func(*types.Address) ledger.IA
 	return c
 }
 
+// GetAccounts mocks base method.
+func (m *MockStateLedger) GetAccounts(arg0 []*types.Address) ([]ledger.IAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccounts", arg0)
+	ret0, _ := ret[0].([]ledger.IAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccounts indicates an expected call of GetAccounts.
+func (mr *MockStateLedgerMockRecorder) GetAccounts(arg0 any) *StateLedgerGetAccountsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccounts", reflect.TypeOf((*MockStateLedger)(nil).GetAccounts), arg0)
+	return &StateLedgerGetAccountsCall{Call: call}
+}
+
+// StateLedgerGetAccountsCall wrap *gomock.Call
+type StateLedgerGetAccountsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StateLedgerGetAccountsCall) Return(arg0 []ledger.IAccount, arg1 error) *StateLedgerGetAccountsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StateLedgerGetAccountsCall) Do(f func([]*types.Address) ([]ledger.IAccount, error)) *StateLedgerGetAccountsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StateLedgerGetAccountsCall) DoAndReturn(f func([]*types.Address) ([]ledger.IAccount, error)) *StateLedgerGetAccountsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetBalance mocks base method.
 func (m *MockStateLedger) GetBalance(arg0 *types.Address) *big.Int {
 	m.ctrl.T.Helper()
@@ -1727,6 +1995,45 @@ func (c *StateLedgerGetOrCreateAccountCall) DoAndReturn(f func(*types.Address) l
 	return c
 }
 
+// GetProof mocks base method.
+func (m *MockStateLedger) GetProof(blockHeader *types.BlockHeader, addr *types.Address, storageKeys [][]byte) (*ledger.AccountProof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProof", blockHeader, addr, storageKeys)
+	ret0, _ := ret[0].(*ledger.AccountProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProof indicates an expected call of GetProof.
+func (mr *MockStateLedgerMockRecorder) GetProof(blockHeader, addr, storageKeys any) *StateLedgerGetProofCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProof", reflect.TypeOf((*MockStateLedger)(nil).GetProof), blockHeader, addr, storageKeys)
+	return &StateLedgerGetProofCall{Call: call}
+}
+
+// StateLedgerGetProofCall wrap *gomock.Call
+type StateLedgerGetProofCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StateLedgerGetProofCall) Return(arg0 *ledger.AccountProof, arg1 error) *StateLedgerGetProofCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StateLedgerGetProofCall) Do(f func(*types.BlockHeader, *types.Address, [][]byte) (*ledger.AccountProof, error)) *StateLedgerGetProofCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StateLedgerGetProofCall) DoAndReturn(f func(*types.BlockHeader, *types.Address, [][]byte) (*ledger.AccountProof, error)) *StateLedgerGetProofCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetRefund mocks base method.
 func (m *MockStateLedger) GetRefund() uint64 {
 	m.ctrl.T.Helper()
@@ -1842,6 +2149,45 @@ func (c *StateLedgerGetStateDeltaCall) DoAndReturn(f func(uint64) *types.StateDe
 	return c
 }
 
+// GetTrieNode mocks base method.
+func (m *MockStateLedger) GetTrieNode(rawKey []byte) (types.Node, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrieNode", rawKey)
+	ret0, _ := ret[0].(types.Node)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrieNode indicates an expected call of GetTrieNode.
+func (mr *MockStateLedgerMockRecorder) GetTrieNode(rawKey any) *StateLedgerGetTrieNodeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrieNode", reflect.TypeOf((*MockStateLedger)(nil).GetTrieNode), rawKey)
+	return &StateLedgerGetTrieNodeCall{Call: call}
+}
+
+// StateLedgerGetTrieNodeCall wrap *gomock.Call
+type StateLedgerGetTrieNodeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StateLedgerGetTrieNodeCall) Return(arg0 types.Node, arg1 error) *StateLedgerGetTrieNodeCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StateLedgerGetTrieNodeCall) Do(f func([]byte) (types.Node, error)) *StateLedgerGetTrieNodeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StateLedgerGetTrieNodeCall) DoAndReturn(f func([]byte) (types.Node, error)) *StateLedgerGetTrieNodeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetTrieSnapshotMeta mocks base method.
 func (m *MockStateLedger) GetTrieSnapshotMeta() (*ledger.SnapshotMeta, error) {
 	m.ctrl.T.Helper()
@@ -1919,6 +2265,44 @@ func (c *StateLedgerHasSelfDestructedCall) DoAndReturn(f func(*types.Address) bo
 	return c
 }
 
+// IterateContracts mocks base method.
+func (m *MockStateLedger) IterateContracts(blockHeader *types.BlockHeader, fn func(*types.Address) bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IterateContracts", blockHeader, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IterateContracts indicates an expected call of IterateContracts.
+func (mr *MockStateLedgerMockRecorder) IterateContracts(blockHeader, fn any) *StateLedgerIterateContractsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IterateContracts", reflect.TypeOf((*MockStateLedger)(nil).IterateContracts), blockHeader, fn)
+	return &StateLedgerIterateContractsCall{Call: call}
+}
+
+// StateLedgerIterateContractsCall wrap *gomock.Call
+type StateLedgerIterateContractsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StateLedgerIterateContractsCall) Return(arg0 error) *StateLedgerIterateContractsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StateLedgerIterateContractsCall) Do(f func(*types.BlockHeader, func(*types.Address) bool) error) *StateLedgerIterateContractsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StateLedgerIterateContractsCall) DoAndReturn(f func(*types.BlockHeader, func(*types.Address) bool) error) *StateLedgerIterateContractsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // IterateTrie mocks base method.
 func (m *MockStateLedger) IterateTrie(snapshotMeta *ledger.SnapshotMeta, kv kv.Storage, errC chan error) {
 	m.ctrl.T.Helper()
@@ -1955,6 +2339,159 @@ func (c *StateLedgerIterateTrieCall) DoAndReturn(f func(*ledger.SnapshotMeta, kv
 	return c
 }
 
+// IterateTrieResume mocks base method.
+func (m *MockStateLedger) IterateTrieResume(snapshotMeta *ledger.SnapshotMeta, kv kv.Storage, errC chan error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "IterateTrieResume", snapshotMeta, kv, errC)
+}
+
+// IterateTrieResume indicates an expected call of IterateTrieResume.
+func (mr *MockStateLedgerMockRecorder) IterateTrieResume(snapshotMeta, kv, errC any) *StateLedgerIterateTrieResumeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IterateTrieResume", reflect.TypeOf((*MockStateLedger)(nil).IterateTrieResume), snapshotMeta, kv, errC)
+	return &StateLedgerIterateTrieResumeCall{Call: call}
+}
+
+// StateLedgerIterateTrieResumeCall wrap *gomock.Call
+type StateLedgerIterateTrieResumeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StateLedgerIterateTrieResumeCall) Return() *StateLedgerIterateTrieResumeCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StateLedgerIterateTrieResumeCall) Do(f func(*ledger.SnapshotMeta, kv.Storage, chan error)) *StateLedgerIterateTrieResumeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StateLedgerIterateTrieResumeCall) DoAndReturn(f func(*ledger.SnapshotMeta, kv.Storage, chan error)) *StateLedgerIterateTrieResumeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListContracts mocks base method.
+func (m *MockStateLedger) ListContracts(blockHeader *types.BlockHeader) ([]*types.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListContracts", blockHeader)
+	ret0, _ := ret[0].([]*types.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListContracts indicates an expected call of ListContracts.
+func (mr *MockStateLedgerMockRecorder) ListContracts(blockHeader any) *StateLedgerListContractsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListContracts", reflect.TypeOf((*MockStateLedger)(nil).ListContracts), blockHeader)
+	return &StateLedgerListContractsCall{Call: call}
+}
+
+// StateLedgerListContractsCall wrap *gomock.Call
+type StateLedgerListContractsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StateLedgerListContractsCall) Return(arg0 []*types.Address, arg1 error) *StateLedgerListContractsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StateLedgerListContractsCall) Do(f func(*types.BlockHeader) ([]*types.Address, error)) *StateLedgerListContractsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StateLedgerListContractsCall) DoAndReturn(f func(*types.BlockHeader) ([]*types.Address, error)) *StateLedgerListContractsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ModifiedAccounts mocks base method.
+func (m *MockStateLedger) ModifiedAccounts(blockNumber uint64) ([]*types.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ModifiedAccounts", blockNumber)
+	ret0, _ := ret[0].([]*types.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ModifiedAccounts indicates an expected call of ModifiedAccounts.
+func (mr *MockStateLedgerMockRecorder) ModifiedAccounts(blockNumber any) *StateLedgerModifiedAccountsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModifiedAccounts", reflect.TypeOf((*MockStateLedger)(nil).ModifiedAccounts), blockNumber)
+	return &StateLedgerModifiedAccountsCall{Call: call}
+}
+
+// StateLedgerModifiedAccountsCall wrap *gomock.Call
+type StateLedgerModifiedAccountsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StateLedgerModifiedAccountsCall) Return(arg0 []*types.Address, arg1 error) *StateLedgerModifiedAccountsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StateLedgerModifiedAccountsCall) Do(f func(uint64) ([]*types.Address, error)) *StateLedgerModifiedAccountsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StateLedgerModifiedAccountsCall) DoAndReturn(f func(uint64) ([]*types.Address, error)) *StateLedgerModifiedAccountsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// NewLimitedView mocks base method.
+func (m *MockStateLedger) NewLimitedView(blockHeader *types.BlockHeader, enableSnapshot bool) (ledger.StateLedger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewLimitedView", blockHeader, enableSnapshot)
+	ret0, _ := ret[0].(ledger.StateLedger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewLimitedView indicates an expected call of NewLimitedView.
+func (mr *MockStateLedgerMockRecorder) NewLimitedView(blockHeader, enableSnapshot any) *StateLedgerNewLimitedViewCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewLimitedView", reflect.TypeOf((*MockStateLedger)(nil).NewLimitedView), blockHeader, enableSnapshot)
+	return &StateLedgerNewLimitedViewCall{Call: call}
+}
+
+// StateLedgerNewLimitedViewCall wrap *gomock.Call
+type StateLedgerNewLimitedViewCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StateLedgerNewLimitedViewCall) Return(arg0 ledger.StateLedger, arg1 error) *StateLedgerNewLimitedViewCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StateLedgerNewLimitedViewCall) Do(f func(*types.BlockHeader, bool) (ledger.StateLedger, error)) *StateLedgerNewLimitedViewCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StateLedgerNewLimitedViewCall) DoAndReturn(f func(*types.BlockHeader, bool) (ledger.StateLedger, error)) *StateLedgerNewLimitedViewCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // NewView mocks base method.
 func (m *MockStateLedger) NewView(blockHeader *types.BlockHeader, enableSnapshot bool) (ledger.StateLedger, error) {
 	m.ctrl.T.Helper()
@@ -2069,6 +2606,44 @@ func (c *StateLedgerProveCall) DoAndReturn(f func(common.Hash, []byte) (*jmt.Pro
 	return c
 }
 
+// PruneTo mocks base method.
+func (m *MockStateLedger) PruneTo(targetHeight uint64, progressC chan<- prune.PruneProgress) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneTo", targetHeight, progressC)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PruneTo indicates an expected call of PruneTo.
+func (mr *MockStateLedgerMockRecorder) PruneTo(targetHeight, progressC any) *StateLedgerPruneToCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneTo", reflect.TypeOf((*MockStateLedger)(nil).PruneTo), targetHeight, progressC)
+	return &StateLedgerPruneToCall{Call: call}
+}
+
+// StateLedgerPruneToCall wrap *gomock.Call
+type StateLedgerPruneToCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StateLedgerPruneToCall) Return(arg0 error) *StateLedgerPruneToCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StateLedgerPruneToCall) Do(f func(uint64, chan<- prune.PruneProgress) error) *StateLedgerPruneToCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StateLedgerPruneToCall) DoAndReturn(f func(uint64, chan<- prune.PruneProgress) error) *StateLedgerPruneToCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // RevertToSnapshot mocks base method.
 func (m *MockStateLedger) RevertToSnapshot(arg0 int) {
 	m.ctrl.T.Helper()
@@ -2217,6 +2792,46 @@ func (c *StateLedgerSelfdestruct6780Call) DoAndReturn(f func(*types.Address)) *S
 	return c
 }
 
+// ServeSnapshotChunk mocks base method.
+func (m *MockStateLedger) ServeSnapshotChunk(root common.Hash, cursor []byte, maxBytes int) ([]byte, []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ServeSnapshotChunk", root, cursor, maxBytes)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ServeSnapshotChunk indicates an expected call of ServeSnapshotChunk.
+func (mr *MockStateLedgerMockRecorder) ServeSnapshotChunk(root, cursor, maxBytes any) *StateLedgerServeSnapshotChunkCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServeSnapshotChunk", reflect.TypeOf((*MockStateLedger)(nil).ServeSnapshotChunk), root, cursor, maxBytes)
+	return &StateLedgerServeSnapshotChunkCall{Call: call}
+}
+
+// StateLedgerServeSnapshotChunkCall wrap *gomock.Call
+type StateLedgerServeSnapshotChunkCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StateLedgerServeSnapshotChunkCall) Return(chunk, nextCursor []byte, err error) *StateLedgerServeSnapshotChunkCall {
+	c.Call = c.Call.Return(chunk, nextCursor, err)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StateLedgerServeSnapshotChunkCall) Do(f func(common.Hash, []byte, int) ([]byte, []byte, error)) *StateLedgerServeSnapshotChunkCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StateLedgerServeSnapshotChunkCall) DoAndReturn(f func(common.Hash, []byte, int) ([]byte, []byte, error)) *StateLedgerServeSnapshotChunkCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetBalance mocks base method.
 func (m *MockStateLedger) SetBalance(arg0 *types.Address, arg1 *big.Int) {
 	m.ctrl.T.Helper()
@@ -2474,6 +3089,45 @@ func (c *StateLedgerSnapshotCall) DoAndReturn(f func() int) *StateLedgerSnapshot
 	return c
 }
 
+// StorageAt mocks base method.
+func (m *MockStateLedger) StorageAt(blockHeader *types.BlockHeader, addr *types.Address, key []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StorageAt", blockHeader, addr, key)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StorageAt indicates an expected call of StorageAt.
+func (mr *MockStateLedgerMockRecorder) StorageAt(blockHeader, addr, key any) *StateLedgerStorageAtCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StorageAt", reflect.TypeOf((*MockStateLedger)(nil).StorageAt), blockHeader, addr, key)
+	return &StateLedgerStorageAtCall{Call: call}
+}
+
+// StateLedgerStorageAtCall wrap *gomock.Call
+type StateLedgerStorageAtCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StateLedgerStorageAtCall) Return(arg0 []byte, arg1 error) *StateLedgerStorageAtCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StateLedgerStorageAtCall) Do(f func(*types.BlockHeader, *types.Address, []byte) ([]byte, error)) *StateLedgerStorageAtCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StateLedgerStorageAtCall) DoAndReturn(f func(*types.BlockHeader, *types.Address, []byte) ([]byte, error)) *StateLedgerStorageAtCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SubBalance mocks base method.
 func (m *MockStateLedger) SubBalance(arg0 *types.Address, arg1 *big.Int) {
 	m.ctrl.T.Helper()
@@ -2585,6 +3239,45 @@ func (c *StateLedgerVerifyTrieCall) DoAndReturn(f func(*types.BlockHeader) (bool
 	return c
 }
 
+// VerifyTrieSampled mocks base method.
+func (m *MockStateLedger) VerifyTrieSampled(blockHeader *types.BlockHeader, samples int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyTrieSampled", blockHeader, samples)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyTrieSampled indicates an expected call of VerifyTrieSampled.
+func (mr *MockStateLedgerMockRecorder) VerifyTrieSampled(blockHeader, samples any) *StateLedgerVerifyTrieSampledCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyTrieSampled", reflect.TypeOf((*MockStateLedger)(nil).VerifyTrieSampled), blockHeader, samples)
+	return &StateLedgerVerifyTrieSampledCall{Call: call}
+}
+
+// StateLedgerVerifyTrieSampledCall wrap *gomock.Call
+type StateLedgerVerifyTrieSampledCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StateLedgerVerifyTrieSampledCall) Return(arg0 bool, arg1 error) *StateLedgerVerifyTrieSampledCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StateLedgerVerifyTrieSampledCall) Do(f func(*types.BlockHeader, int) (bool, error)) *StateLedgerVerifyTrieSampledCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StateLedgerVerifyTrieSampledCall) DoAndReturn(f func(*types.BlockHeader, int) (bool, error)) *StateLedgerVerifyTrieSampledCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Version mocks base method.
 func (m *MockStateLedger) Version() uint64 {
 	m.ctrl.T.Helper()
@@ -3053,6 +3746,45 @@ func (c *StateAccessorGetAccountCall) DoAndReturn(f func(*types.Address) ledger.
 	return c
 }
 
+// GetAccounts mocks base method.
+func (m *MockStateAccessor) GetAccounts(arg0 []*types.Address) ([]ledger.IAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccounts", arg0)
+	ret0, _ := ret[0].([]ledger.IAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccounts indicates an expected call of GetAccounts.
+func (mr *MockStateAccessorMockRecorder) GetAccounts(arg0 any) *StateAccessorGetAccountsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccounts", reflect.TypeOf((*MockStateAccessor)(nil).GetAccounts), arg0)
+	return &StateAccessorGetAccountsCall{Call: call}
+}
+
+// StateAccessorGetAccountsCall wrap *gomock.Call
+type StateAccessorGetAccountsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StateAccessorGetAccountsCall) Return(arg0 []ledger.IAccount, arg1 error) *StateAccessorGetAccountsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StateAccessorGetAccountsCall) Do(f func([]*types.Address) ([]ledger.IAccount, error)) *StateAccessorGetAccountsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StateAccessorGetAccountsCall) DoAndReturn(f func([]*types.Address) ([]ledger.IAccount, error)) *StateAccessorGetAccountsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetBalance mocks base method.
 func (m *MockStateAccessor) GetBalance(arg0 *types.Address) *big.Int {
 	m.ctrl.T.Helper()
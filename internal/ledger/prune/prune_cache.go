@@ -52,6 +52,8 @@ const (
 var (
 	ErrorRollbackToHigherNumber = errors.New("rollback PruneCache to higher blockchain height")
 	ErrorRollbackTooMuch        = errors.New("rollback PruneCache too much block")
+	ErrorPruneToHigherNumber    = errors.New("prune PruneCache to higher blockchain height")
+	ErrorPruneBelowMinHeight    = errors.New("prune PruneCache below pruned min height")
 )
 
 func (s *states) rebuildAllKeyMap() {
@@ -216,6 +218,36 @@ func (tc *PruneCache) Rollback(height uint64, persist bool) error {
 	return nil
 }
 
+// PruneTo prunes all state whose block number is lower than targetHeight immediately,
+// progress will be reported to progressC if it is not nil.
+func (tc *PruneCache) PruneTo(targetHeight uint64, progressC chan<- PruneProgress) error {
+	minHeight, maxHeight := tc.GetRange()
+
+	tc.logger.Infof("[PruneCache-PruneTo] minHeight=%v, maxHeight=%v, targetHeight=%v", minHeight, maxHeight, targetHeight)
+
+	if targetHeight > maxHeight {
+		return ErrorPruneToHigherNumber
+	}
+	if targetHeight < minHeight {
+		return ErrorPruneBelowMinHeight
+	}
+
+	req := &pruneReq{
+		targetHeight: targetHeight,
+		progressC:    progressC,
+		errC:         make(chan error, 1),
+	}
+	tc.prunner.pruneReqC <- req
+	return <-req.errC
+}
+
+// Flush writes all trie nodes held only in prune cache into kv, no state history is pruned.
+func (tc *PruneCache) Flush() error {
+	errC := make(chan error, 1)
+	tc.prunner.flushReqC <- errC
+	return <-errC
+}
+
 func (tc *PruneCache) GetRange() (uint64, uint64) {
 	minHeight := uint64(0)
 	maxHeight := uint64(0)
@@ -425,6 +425,168 @@ func TestPruningFlushByMaxFlushTime(t *testing.T) {
 	require.Equal(t, 10, len(tc.states.diffs))
 }
 
+func TestPruneTo(t *testing.T) {
+	logger := log.NewWithModule("prune_test")
+	pStateStorage := kv.NewMemory()
+
+	accountTrieCache := storagemgr.NewCacheWrapper(32, true)
+	storageTrieCache := storagemgr.NewCacheWrapper(32, true)
+	tc := NewPruneCache(createMockRepo(t), pStateStorage, accountTrieCache, storageTrieCache, logger)
+	batch := pStateStorage.NewBatch()
+
+	for i := 0; i < 5; i++ {
+		trieJournal := &types.StateDelta{
+			Journal: []*types.TrieJournal{
+				{
+					RootHash:    common.HexToHash("0x4d5e855f8fb3fe5ed1eb123d4feb2a8f96b025fca63a19f02b8727d3d4f8ef28"),
+					RootNodeKey: &types.NodeKey{Version: uint64(i + 1), Path: []byte("path"), Type: []byte("type")},
+					DirtySet: map[string]types.Node{
+						"k" + strconv.Itoa(i+1): makeLeafNode("v" + strconv.Itoa(i+1)),
+					},
+				},
+			},
+		}
+		tc.Update(batch, uint64(i+1), trieJournal)
+	}
+	batch.Commit()
+	require.Nil(t, tc.ledgerStorage.Get([]byte("k1"))) // not flush
+
+	err := tc.PruneTo(6, nil)
+	require.ErrorIs(t, err, ErrorPruneToHigherNumber)
+
+	progressC := make(chan PruneProgress, 10)
+	err = tc.PruneTo(4, progressC)
+	require.Nil(t, err)
+	close(progressC)
+	var last PruneProgress
+	for progress := range progressC {
+		require.Equal(t, uint64(4), progress.TargetHeight)
+		last = progress
+	}
+	require.True(t, last.Done)
+	require.Equal(t, uint64(3), last.PrunedHeight)
+
+	for i := 1; i <= 3; i++ {
+		require.Equal(t, makeLeafNode("v"+strconv.Itoa(i)).Encode(), tc.ledgerStorage.Get([]byte("k"+strconv.Itoa(i)))) // flushed
+	}
+	require.Nil(t, tc.ledgerStorage.Get([]byte("k4")))
+	require.Equal(t, 2, len(tc.states.diffs))
+	minHeight, maxHeight := tc.GetRange()
+	require.Equal(t, uint64(4), minHeight)
+	require.Equal(t, uint64(5), maxHeight)
+
+	err = tc.PruneTo(3, nil)
+	require.ErrorIs(t, err, ErrorPruneBelowMinHeight)
+}
+
+func TestPruneCacheFlush(t *testing.T) {
+	logger := log.NewWithModule("prune_test")
+	pStateStorage := kv.NewMemory()
+
+	accountTrieCache := storagemgr.NewCacheWrapper(32, true)
+	storageTrieCache := storagemgr.NewCacheWrapper(32, true)
+	tc := NewPruneCache(createMockRepo(t), pStateStorage, accountTrieCache, storageTrieCache, logger)
+	batch := pStateStorage.NewBatch()
+
+	update := func(i int) {
+		trieJournal := &types.StateDelta{
+			Journal: []*types.TrieJournal{
+				{
+					RootHash:    common.HexToHash("0x4d5e855f8fb3fe5ed1eb123d4feb2a8f96b025fca63a19f02b8727d3d4f8ef28"),
+					RootNodeKey: &types.NodeKey{Version: uint64(i), Path: []byte("path"), Type: []byte("type")},
+					DirtySet: map[string]types.Node{
+						"k" + strconv.Itoa(i): makeLeafNode("v" + strconv.Itoa(i)),
+					},
+					Type: TypeAccount,
+				},
+			},
+		}
+		if i > 1 {
+			// the node written in the previous block is stale
+			trieJournal.Journal[0].PruneSet = map[string]struct{}{"k" + strconv.Itoa(i-1): {}}
+		}
+		tc.Update(batch, uint64(i), trieJournal)
+	}
+	for i := 1; i <= 3; i++ {
+		update(i)
+	}
+	batch.Commit()
+	require.Nil(t, tc.ledgerStorage.Get([]byte("k3"))) // not flush
+
+	err := tc.Flush()
+	require.Nil(t, err)
+	// only the latest node is persisted, history is not pruned
+	require.Nil(t, tc.ledgerStorage.Get([]byte("k1")))
+	require.Nil(t, tc.ledgerStorage.Get([]byte("k2")))
+	require.Equal(t, makeLeafNode("v3").Encode(), tc.ledgerStorage.Get([]byte("k3")))
+	require.Equal(t, 3, len(tc.states.diffs))
+
+	batch = pStateStorage.NewBatch()
+	for i := 4; i <= 5; i++ {
+		update(i)
+	}
+	batch.Commit()
+
+	// k3 is persisted in advance, it must be removed although it is added and pruned in the flush range
+	err = tc.PruneTo(5, nil)
+	require.Nil(t, err)
+	for i := 1; i <= 3; i++ {
+		require.Nil(t, tc.ledgerStorage.Get([]byte("k"+strconv.Itoa(i))))
+	}
+	require.Equal(t, makeLeafNode("v4").Encode(), tc.ledgerStorage.Get([]byte("k4")))
+	require.Equal(t, 1, len(tc.states.diffs))
+}
+
+func TestPruneCacheFlushRestart(t *testing.T) {
+	logger := log.NewWithModule("prune_test")
+	pStateStorage := kv.NewMemory()
+	rep := createMockRepo(t)
+
+	update := func(tc *PruneCache, batch kv.Batch, i int) {
+		trieJournal := &types.StateDelta{
+			Journal: []*types.TrieJournal{
+				{
+					RootHash:    common.HexToHash("0x4d5e855f8fb3fe5ed1eb123d4feb2a8f96b025fca63a19f02b8727d3d4f8ef28"),
+					RootNodeKey: &types.NodeKey{Version: uint64(i), Path: []byte("path"), Type: []byte("type")},
+					DirtySet: map[string]types.Node{
+						"k" + strconv.Itoa(i): makeLeafNode("v" + strconv.Itoa(i)),
+					},
+					Type: TypeAccount,
+				},
+			},
+		}
+		if i > 1 {
+			trieJournal.Journal[0].PruneSet = map[string]struct{}{"k" + strconv.Itoa(i-1): {}}
+		}
+		tc.Update(batch, uint64(i), trieJournal)
+	}
+
+	tc := NewPruneCache(rep, pStateStorage, storagemgr.NewCacheWrapper(32, true), storagemgr.NewCacheWrapper(32, true), logger)
+	batch := pStateStorage.NewBatch()
+	for i := 1; i <= 3; i++ {
+		update(tc, batch, i)
+	}
+	batch.Commit()
+	require.Nil(t, tc.Flush())
+	require.Equal(t, makeLeafNode("v3").Encode(), pStateStorage.Get([]byte("k3")))
+
+	// restart, the diffs are rebuilt from prune journal
+	tc = NewPruneCache(rep, pStateStorage, storagemgr.NewCacheWrapper(32, true), storagemgr.NewCacheWrapper(32, true), logger)
+	require.Nil(t, tc.Rollback(3, false))
+	batch = pStateStorage.NewBatch()
+	for i := 4; i <= 5; i++ {
+		update(tc, batch, i)
+	}
+	batch.Commit()
+
+	// k3 persisted before restart is still removed
+	require.Nil(t, tc.PruneTo(5, nil))
+	for i := 1; i <= 3; i++ {
+		require.Nil(t, pStateStorage.Get([]byte("k"+strconv.Itoa(i))))
+	}
+	require.Equal(t, makeLeafNode("v4").Encode(), pStateStorage.Get([]byte("k4")))
+}
+
 func TestPruneCacheNil(t *testing.T) {
 	rep := createMockRepo(t)
 	rep.Config.Ledger.EnablePrune = false
@@ -23,6 +23,30 @@ type prunner struct {
 	logger logrus.FieldLogger
 
 	lastPruneTime time.Time
+
+	pruneReqC chan *pruneReq
+	flushReqC chan chan error
+
+	// pending flush data, only accessed by pruning goroutine
+	pendingBatch                             kv.Batch
+	from, to                                 uint64 // block range
+	accountTriePruneSet, storageTriePruneSet map[string]struct{}
+	accountTrieWriteSet, storageTrieWriteSet map[string][]byte
+	pendingFlushBlockNum, pendingFlushSize   int
+}
+
+// PruneProgress reports the progress of a manual prune.
+type PruneProgress struct {
+	TargetHeight  uint64 // prune state whose block number < TargetHeight
+	PrunedHeight  uint64 // the latest block whose state has been collected
+	PendingBlocks int    // number of blocks collected and waiting for flushing
+	Done          bool
+}
+
+type pruneReq struct {
+	targetHeight uint64
+	progressC    chan<- PruneProgress
+	errC         chan error
 }
 
 var (
@@ -34,7 +58,7 @@ var (
 )
 
 func NewPrunner(rep *repo.Repo, ledgerStorage kv.Storage, accountTrieCache *storagemgr.CacheWrapper, storageTrieCache *storagemgr.CacheWrapper, states *states, logger logrus.FieldLogger) *prunner {
-	return &prunner{
+	p := &prunner{
 		rep:                  rep,
 		ledgerStorageBackend: ledgerStorage,
 		accountTrieCache:     accountTrieCache,
@@ -42,7 +66,11 @@ func NewPrunner(rep *repo.Repo, ledgerStorage kv.Storage, accountTrieCache *stor
 		states:               states,
 		logger:               logger,
 		lastPruneTime:        time.Now(),
+		pruneReqC:            make(chan *pruneReq),
+		flushReqC:            make(chan chan error),
 	}
+	p.resetPending()
+	return p
 }
 
 func (p *prunner) pruning() {
@@ -52,122 +80,226 @@ func (p *prunner) pruning() {
 		reserve = p.rep.Config.Ledger.StateLedgerReservedHistoryBlockNum
 	}
 
-	var (
-		ticker                                   = time.NewTicker(checkFlushTimeInterval)
-		pendingBatch                             = p.ledgerStorageBackend.NewBatch()
-		from, to                                 = uint64(0), uint64(0) // block range
-		accountTriePruneSet, storageTriePruneSet = make(map[string]struct{}), make(map[string]struct{})
-		accountTrieWriteSet, storageTrieWriteSet = make(map[string][]byte), make(map[string][]byte)
-		pendingFlushBlockNum, pendingFlushSize   = 0, 0
-	)
-
-	for range ticker.C {
-		p.states.lock.RLock()
-		if len(p.states.diffs) <= reserve || pendingFlushBlockNum > len(p.states.diffs)-reserve {
+	ticker := time.NewTicker(checkFlushTimeInterval)
+	for {
+		select {
+		case <-ticker.C:
+			p.states.lock.RLock()
+			if len(p.states.diffs) <= reserve || p.pendingFlushBlockNum > len(p.states.diffs)-reserve {
+				p.states.lock.RUnlock()
+				continue
+			}
+			p.collect(len(p.states.diffs)-reserve, nil)
 			p.states.lock.RUnlock()
-			continue
+
+			if time.Since(p.lastPruneTime) < maxFlushTimeInterval && p.pendingFlushBlockNum < maxFlushBlockNum &&
+				p.pendingFlushSize < maxFlushBatchSizeThreshold {
+				continue
+			}
+			p.flush(0)
+
+		case req := <-p.pruneReqC:
+			req.errC <- p.pruneTo(req)
+
+		case errC := <-p.flushReqC:
+			p.persistDiffs()
+			errC <- nil
 		}
+	}
+}
 
-		pendingStales := p.states.diffs[pendingFlushBlockNum : len(p.states.diffs)-reserve]
-		if len(pendingStales) > 0 {
-			if from == 0 {
-				from = pendingStales[0].height
+// pruneTo collects all diffs whose block number < targetHeight and flushes them immediately.
+func (p *prunner) pruneTo(req *pruneReq) error {
+	p.states.lock.RLock()
+	end := p.pendingFlushBlockNum
+	for end < len(p.states.diffs) && p.states.diffs[end].height < req.targetHeight {
+		end++
+	}
+	p.collect(end, req)
+	p.states.lock.RUnlock()
+
+	p.flush(req.targetHeight)
+	sendPruneProgress(req.progressC, PruneProgress{
+		TargetHeight: req.targetHeight,
+		PrunedHeight: req.targetHeight - 1,
+		Done:         true,
+	})
+	return nil
+}
+
+// collect merges diffs in range [pendingFlushBlockNum, end) into the pending flush sets,
+// the caller must hold the read lock of states.
+func (p *prunner) collect(end int, req *pruneReq) {
+	pendingStales := p.states.diffs[p.pendingFlushBlockNum:end]
+	if len(pendingStales) == 0 {
+		return
+	}
+	if p.from == 0 {
+		p.from = pendingStales[0].height
+	}
+	p.to = pendingStales[len(pendingStales)-1].height
+
+	// merge prune set and write set, reduce duplicated entries
+	for _, diff := range pendingStales {
+		// handle account trie cache
+		for k, v := range diff.accountDiff {
+			if v == nil {
+				p.accountTriePruneSet[k] = struct{}{}
+				p.pendingFlushSize += len(k)
+			} else {
+				blob := v.Encode()
+				p.accountTrieWriteSet[k] = blob
+				p.pendingFlushSize += len(k) + len(blob)
 			}
-			to = pendingStales[len(pendingStales)-1].height
-
-			// merge prune set and write set, reduce duplicated entries
-			for _, diff := range pendingStales {
-				// handle account trie cache
-				for k, v := range diff.accountDiff {
-					if v == nil {
-						accountTriePruneSet[k] = struct{}{}
-						pendingFlushSize += len(k)
-					} else {
-						blob := v.Encode()
-						accountTrieWriteSet[k] = blob
-						pendingFlushSize += len(k) + len(blob)
-					}
-				}
-				// handle storage trie cache
-				for k, v := range diff.storageDiff {
-					if v == nil {
-						storageTriePruneSet[k] = struct{}{}
-						pendingFlushSize += len(k)
-					} else {
-						blob := v.Encode()
-						storageTrieWriteSet[k] = blob
-						pendingFlushSize += len(k) + len(blob)
-					}
-				}
-				pendingBatch.Delete(utils.CompositeKey(utils.PruneJournalKey, diff.height))
+		}
+		// handle storage trie cache
+		for k, v := range diff.storageDiff {
+			if v == nil {
+				p.storageTriePruneSet[k] = struct{}{}
+				p.pendingFlushSize += len(k)
+			} else {
+				blob := v.Encode()
+				p.storageTrieWriteSet[k] = blob
+				p.pendingFlushSize += len(k) + len(blob)
 			}
-			pendingFlushBlockNum += len(pendingStales)
 		}
-		p.states.lock.RUnlock()
+		p.pendingBatch.Delete(utils.CompositeKey(utils.PruneJournalKey, diff.height))
+		p.pendingFlushBlockNum++
+
+		if req != nil {
+			sendPruneProgress(req.progressC, PruneProgress{
+				TargetHeight:  req.targetHeight,
+				PrunedHeight:  diff.height,
+				PendingBlocks: p.pendingFlushBlockNum,
+			})
+		}
+	}
+}
+
+// persistDiffs flushes collected data, then writes the dirty trie nodes of the remaining diffs into kv without pruning,
+// the remaining diffs are kept in states so that they can still be pruned later.
+func (p *prunner) persistDiffs() {
+	if p.pendingFlushBlockNum > 0 {
+		p.flush(0)
+	}
 
-		if time.Since(p.lastPruneTime) < maxFlushTimeInterval && pendingFlushBlockNum < maxFlushBlockNum &&
-			pendingFlushSize < maxFlushBatchSizeThreshold {
+	p.states.lock.RLock()
+	finalState := make(map[string]types.Node)
+	for _, diff := range p.states.diffs {
+		for k, v := range diff.accountDiff {
+			finalState[k] = v
+		}
+		for k, v := range diff.storageDiff {
+			finalState[k] = v
+		}
+	}
+	batch := p.ledgerStorageBackend.NewBatch()
+	count := 0
+	for k, v := range finalState {
+		if v == nil {
 			continue
 		}
+		batch.Put([]byte(k), v.Encode())
+		count++
+	}
+	p.states.lock.RUnlock()
 
-		// The moment we update trie cache, other goroutine may read prune cache at the same time.
-		// But we don't need to lock here, because the jmt.getNode logic will always try from prune cache first,
-		// and we can ensure that the data we update will occur in prune cache.
+	current := time.Now()
+	batch.Commit()
+	p.logger.Infof("[Prune] persist %v dirty trie nodes, time = %v", count, time.Since(current))
+}
 
-		// update account trie cache
-		for k, v := range accountTrieWriteSet {
-			if _, has := accountTriePruneSet[k]; !has {
-				pendingBatch.Put([]byte(k), v)
-				p.accountTrieCache.Set([]byte(k), v)
-			}
+// flush writes all pending data into kv and removes flushed diffs from states.
+// If minHeight is greater than the flushed range, it will be used as the new min height of prune journal.
+func (p *prunner) flush(minHeight uint64) {
+	// The moment we update trie cache, other goroutine may read prune cache at the same time.
+	// But we don't need to lock here, because the jmt.getNode logic will always try from prune cache first,
+	// and we can ensure that the data we update will occur in prune cache.
+
+	// update account trie cache
+	for k, v := range p.accountTrieWriteSet {
+		if _, has := p.accountTriePruneSet[k]; !has {
+			p.pendingBatch.Put([]byte(k), v)
+			p.accountTrieCache.Set([]byte(k), v)
 		}
-		for k := range accountTriePruneSet {
-			if _, has := accountTrieWriteSet[k]; !has {
-				pendingBatch.Delete([]byte(k))
-				p.accountTrieCache.Del([]byte(k))
-			}
+	}
+	for k := range p.accountTriePruneSet {
+		if _, has := p.accountTrieWriteSet[k]; !has || p.isPersisted(k) {
+			p.pendingBatch.Delete([]byte(k))
+			p.accountTrieCache.Del([]byte(k))
 		}
+	}
 
-		// update storage trie cache
-		for k, v := range storageTrieWriteSet {
-			if _, has := storageTriePruneSet[k]; !has {
-				pendingBatch.Put([]byte(k), v)
-				p.storageTrieCache.Set([]byte(k), v)
-			}
+	// update storage trie cache
+	for k, v := range p.storageTrieWriteSet {
+		if _, has := p.storageTriePruneSet[k]; !has {
+			p.pendingBatch.Put([]byte(k), v)
+			p.storageTrieCache.Set([]byte(k), v)
 		}
-		for k := range storageTriePruneSet {
-			if _, has := storageTrieWriteSet[k]; !has {
-				pendingBatch.Delete([]byte(k))
-				p.storageTrieCache.Del([]byte(k))
-			}
+	}
+	for k := range p.storageTriePruneSet {
+		if _, has := p.storageTrieWriteSet[k]; !has || p.isPersisted(k) {
+			p.pendingBatch.Delete([]byte(k))
+			p.storageTrieCache.Del([]byte(k))
 		}
+	}
 
-		pendingBatch.Put(utils.CompositeKey(utils.PruneJournalKey, utils.MinHeightStr), utils.MarshalUint64(to+1))
+	if p.pendingFlushBlockNum > 0 && p.to+1 > minHeight {
+		minHeight = p.to + 1
+	}
+	if minHeight > 0 {
+		p.pendingBatch.Put(utils.CompositeKey(utils.PruneJournalKey, utils.MinHeightStr), utils.MarshalUint64(minHeight))
+	}
 
-		current := time.Now()
-		pendingBatch.Commit()
-		p.logger.Infof("[Prune] prune state from block %v to block %v, total size (bytes) = %v, time = %v", from, to, pendingFlushSize, time.Since(current))
+	current := time.Now()
+	p.pendingBatch.Commit()
+	p.logger.Infof("[Prune] prune state from block %v to block %v, total size (bytes) = %v, time = %v", p.from, p.to, p.pendingFlushSize, time.Since(current))
 
-		// reset states diff
-		p.states.lock.Lock()
-		stales := p.states.diffs[:pendingFlushBlockNum]
-		for _, d := range stales {
-			for _, node := range d.accountDiff {
-				types.RecycleTrieNode(node)
-			}
-			for _, node := range d.storageDiff {
-				types.RecycleTrieNode(node)
-			}
+	// reset states diff
+	p.states.lock.Lock()
+	stales := p.states.diffs[:p.pendingFlushBlockNum]
+	for _, d := range stales {
+		for _, node := range d.accountDiff {
+			types.RecycleTrieNode(node)
+		}
+		for _, node := range d.storageDiff {
+			types.RecycleTrieNode(node)
 		}
-		p.states.diffs = p.states.diffs[pendingFlushBlockNum:]
-		p.states.rebuildAllKeyMap()
-		p.states.lock.Unlock()
-
-		pendingBatch.Reset()
-		from, to = 0, 0
-		p.lastPruneTime = time.Now()
-		accountTriePruneSet, storageTriePruneSet = make(map[string]struct{}), make(map[string]struct{})
-		accountTrieWriteSet, storageTrieWriteSet = make(map[string][]byte), make(map[string][]byte)
-		pendingFlushBlockNum, pendingFlushSize = 0, 0
+	}
+	p.states.diffs = p.states.diffs[p.pendingFlushBlockNum:]
+	p.states.rebuildAllKeyMap()
+	p.states.lock.Unlock()
+
+	p.lastPruneTime = time.Now()
+	p.resetPending()
+}
+
+// isPersisted reports whether the node k added and pruned in the flush range was written into kv by persistDiffs,
+// such node must be deleted from kv. Nodes in unflushed diffs are only written into kv by persistDiffs, so kv itself
+// is checked instead of keeping a record in memory, which would be lost on restart.
+func (p *prunner) isPersisted(k string) bool {
+	return p.ledgerStorageBackend.Has([]byte(k))
+}
+
+func (p *prunner) resetPending() {
+	if p.pendingBatch == nil {
+		p.pendingBatch = p.ledgerStorageBackend.NewBatch()
+	} else {
+		p.pendingBatch.Reset()
+	}
+	p.from, p.to = 0, 0
+	p.accountTriePruneSet, p.storageTriePruneSet = make(map[string]struct{}), make(map[string]struct{})
+	p.accountTrieWriteSet, p.storageTrieWriteSet = make(map[string][]byte), make(map[string][]byte)
+	p.pendingFlushBlockNum, p.pendingFlushSize = 0, 0
+}
+
+// sendPruneProgress never blocks the prunner, progress will be dropped if the receiver is not ready.
+func sendPruneProgress(progressC chan<- PruneProgress, progress PruneProgress) {
+	if progressC == nil {
+		return
+	}
+	select {
+	case progressC <- progress:
+	default:
 	}
 }
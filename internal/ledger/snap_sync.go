@@ -0,0 +1,483 @@
+package ledger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/axiomesh/axiom-kit/types"
+	"github.com/axiomesh/axiom-kit/types/pb"
+	"github.com/axiomesh/axiom-ledger/internal/ledger/utils"
+)
+
+var (
+	ErrInvalidSnapshotChunk  = errors.New("invalid snapshot chunk")
+	ErrInvalidSnapshotCursor = errors.New("invalid snapshot cursor")
+	ErrIncompleteSnapshot    = errors.New("incomplete snapshot")
+)
+
+// emptyCodeHash is the code hash of an account without code
+var emptyCodeHash = crypto.Keccak256(nil)
+
+// kinds of the entries in a snapshot chunk
+const (
+	snapEntryTrieRoot byte = iota + 1 // trie root hash -> root node key
+	snapEntryTrieNode                 // node key -> trie node
+	snapEntryCode                     // code key -> contract code
+)
+
+// snapChunk collects the entries of a snapshot chunk, every entry is encoded as [kind, key, value]
+type snapChunk struct {
+	entries  [][]byte
+	size     int
+	maxBytes int
+}
+
+func (c *snapChunk) put(kind byte, key, value []byte) {
+	c.entries = append(c.entries, []byte{kind}, key, value)
+	c.size += 1 + len(key) + len(value)
+}
+
+func (c *snapChunk) full() bool {
+	return c.size >= c.maxBytes
+}
+
+// snapCursor is the position of the last served trie node, trie nodes are served in path order(pre-order), and
+// the storage trie of a contract account is served right after the account leaf.
+type snapCursor struct {
+	// accountPath is the path of the last served account trie node, nil means nothing is served
+	accountPath []byte
+	// storagePath is the path of the last served storage trie node of the account at accountPath,
+	// nil means the storage trie is finished
+	storagePath []byte
+}
+
+func decodeSnapCursor(raw []byte) (*snapCursor, error) {
+	if len(raw) == 0 {
+		return &snapCursor{}, nil
+	}
+	s := &pb.BytesSlice{}
+	if err := s.UnmarshalVT(raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshotCursor, err)
+	}
+	switch len(s.Slice) {
+	case 1:
+		return &snapCursor{accountPath: nonNilBytes(s.Slice[0])}, nil
+	case 2:
+		return &snapCursor{accountPath: nonNilBytes(s.Slice[0]), storagePath: nonNilBytes(s.Slice[1])}, nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected length %d", ErrInvalidSnapshotCursor, len(s.Slice))
+	}
+}
+
+func (c *snapCursor) encode() ([]byte, error) {
+	s := &pb.BytesSlice{Slice: [][]byte{c.accountPath}}
+	if c.storagePath != nil {
+		s.Slice = append(s.Slice, c.storagePath)
+	}
+	return s.MarshalVT()
+}
+
+// nonNilBytes distinguishes the root path(empty) from an unset path(nil)
+func nonNilBytes(b []byte) []byte {
+	if b == nil {
+		return []byte{}
+	}
+	return b
+}
+
+// skipSnapPath reports whether the subtree at path has been served entirely before the cursor after
+func skipSnapPath(path, after []byte) bool {
+	return after != nil && bytes.Compare(path, after) < 0 && !bytes.HasPrefix(after, path)
+}
+
+// ServeSnapshotChunk serves the trie nodes and contract codes of the state at root in a deterministic order,
+// starting after the cursor returned by the previous call(empty for the first call). The chunk stops growing once
+// it exceeds maxBytes, and an empty nextCursor means the whole state is served. It is transport-agnostic, the
+// chunks should be applied by ApplySnapshotChunk in order. Trie nodes held in prune cache are served as well,
+// but root mappings are read from storage, so FlushCaches should be called before serving a recent state.
+//
+// Trie nodes are served rather than leaves only, because the hash of a jmt internal node commits to the versions
+// of its children, so the state root can not be rebuilt from the leaves.
+func (l *StateLedgerImpl) ServeSnapshotChunk(root common.Hash, cursor []byte, maxBytes int) ([]byte, []byte, error) {
+	if maxBytes <= 0 {
+		return nil, nil, fmt.Errorf("invalid max bytes %d", maxBytes)
+	}
+	cur, err := decodeSnapCursor(cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rawRootNodeKey := l.backend.Get(root[:])
+	if rawRootNodeKey == nil {
+		return nil, nil, fmt.Errorf("state root %v: %w", root, ErrNotFound)
+	}
+	chunk := &snapChunk{maxBytes: maxBytes}
+	if cur.accountPath == nil {
+		chunk.put(snapEntryTrieRoot, root[:], rawRootNodeKey)
+	}
+
+	w := &snapWalker{l: l, chunk: chunk, cursor: cur}
+	finished, err := w.walkAccount(types.DecodeNodeKey(rawRootNodeKey))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	raw, err := (&pb.BytesSlice{Slice: chunk.entries}).MarshalVT()
+	if err != nil {
+		return nil, nil, err
+	}
+	if finished {
+		return raw, nil, nil
+	}
+	nextCursor, err := w.next.encode()
+	if err != nil {
+		return nil, nil, err
+	}
+	return raw, nextCursor, nil
+}
+
+// snapApplier tracks the state applied by ApplySnapshotChunk. Nodes are served in pre-order, so the parent of a node
+// is always applied before it, and every node is checked on arrival against the hash recorded by its parent, which
+// chains up to the trusted state root. The root mappings, which make the state readable, are staged in memory until
+// FinishSnapshotApply verifies the whole trie.
+type snapApplier struct {
+	root common.Hash
+	// roots maps the state root and the storage roots announced by account leaves to their root node keys,
+	// a nil value means the root is announced but not received yet
+	roots map[common.Hash][]byte
+	// expected maps the encoded key of a node announced by its parent to the hash of the node
+	expected map[string]common.Hash
+	// codes holds the code keys announced by account leaves but not received yet
+	codes map[string]struct{}
+}
+
+func newSnapApplier(root common.Hash) *snapApplier {
+	return &snapApplier{
+		root:     root,
+		roots:    map[common.Hash][]byte{root: nil},
+		expected: make(map[string]common.Hash),
+		codes:    make(map[string]struct{}),
+	}
+}
+
+func (a *snapApplier) applyRoot(key, value []byte) error {
+	if len(key) != common.HashLength || len(value) < 9 {
+		return fmt.Errorf("%w: invalid trie root entry", ErrInvalidSnapshotChunk)
+	}
+	rootHash := common.BytesToHash(key)
+	if _, ok := a.roots[rootHash]; !ok {
+		return fmt.Errorf("%w: unexpected trie root %v", ErrInvalidSnapshotChunk, rootHash)
+	}
+	a.roots[rootHash] = value
+	// the hash of the root node is the root hash
+	a.expected[string(value)] = rootHash
+	return nil
+}
+
+func (a *snapApplier) applyNode(key, value []byte) error {
+	if len(key) < 9 {
+		return fmt.Errorf("%w: invalid trie node key", ErrInvalidSnapshotChunk)
+	}
+	want, ok := a.expected[string(key)]
+	if !ok {
+		return fmt.Errorf("%w: unexpected trie node %x", ErrInvalidSnapshotChunk, key)
+	}
+	node, err := types.UnmarshalJMTNodeFromPb(value)
+	if err != nil {
+		return fmt.Errorf("%w: invalid trie node: %v", ErrInvalidSnapshotChunk, err)
+	}
+	if node == nil || node.GetHash() != want {
+		return fmt.Errorf("%w: trie node %x mismatches the hash %v recorded by its parent", ErrInvalidSnapshotChunk, key, want)
+	}
+	delete(a.expected, string(key))
+
+	nk := types.DecodeNodeKey(key)
+	switch n := node.(type) {
+	case *types.InternalNode:
+		for slot, child := range n.Children {
+			if child == nil {
+				continue
+			}
+			childPath := make([]byte, len(nk.Path), len(nk.Path)+1)
+			copy(childPath, nk.Path)
+			childPath = append(childPath, byte(slot))
+			childKey := &types.NodeKey{Version: child.Version, Type: nk.Type, Path: childPath}
+			a.expected[string(childKey.Encode())] = child.Hash
+		}
+	case *types.LeafNode:
+		// storage trie leaves carry no further references
+		if len(nk.Type) != 0 {
+			return nil
+		}
+		acc := &types.InnerAccount{Balance: big.NewInt(0)}
+		if err := acc.Unmarshal(n.Val); err != nil {
+			return fmt.Errorf("%w: invalid account: %v", ErrInvalidSnapshotChunk, err)
+		}
+		if acc.StorageRoot != (common.Hash{}) {
+			if _, ok := a.roots[acc.StorageRoot]; !ok {
+				a.roots[acc.StorageRoot] = nil
+			}
+		}
+		if len(acc.CodeHash) != 0 && !bytes.Equal(acc.CodeHash, emptyCodeHash) {
+			a.codes[string(utils.CompositeCodeKey(types.NewAddress(types.HexToBytes(n.Key)), acc.CodeHash))] = struct{}{}
+		}
+	}
+	return nil
+}
+
+func (a *snapApplier) applyCode(key, value []byte) error {
+	if len(key) != common.AddressLength+common.HashLength || !bytes.Equal(key[common.AddressLength:], crypto.Keccak256(value)) {
+		return fmt.Errorf("%w: code hash mismatch", ErrInvalidSnapshotChunk)
+	}
+	if _, ok := a.codes[string(key)]; !ok {
+		return fmt.Errorf("%w: unexpected code %x", ErrInvalidSnapshotChunk, key)
+	}
+	delete(a.codes, string(key))
+	return nil
+}
+
+// missing returns the number of announced entries not received yet
+func (a *snapApplier) missing() int {
+	n := len(a.expected) + len(a.codes)
+	for _, raw := range a.roots {
+		if raw == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// ApplySnapshotChunk writes a chunk served by ServeSnapshotChunk of the state at root into the state storage.
+// Chunks must be applied in order from one goroutine, every trie node and code is authenticated against the
+// trusted root before it is written, an entry which is not announced by an applied parent is rejected. The state
+// stays unreadable until FinishSnapshotApply is called after the last chunk. Applying a chunk of another root
+// restarts the application.
+func (l *StateLedgerImpl) ApplySnapshotChunk(root common.Hash, chunk []byte) error {
+	s := &pb.BytesSlice{}
+	if err := s.UnmarshalVT(chunk); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshotChunk, err)
+	}
+	if len(s.Slice)%3 != 0 {
+		return fmt.Errorf("%w: unexpected length %d", ErrInvalidSnapshotChunk, len(s.Slice))
+	}
+	if l.snapApplier == nil || l.snapApplier.root != root {
+		l.snapApplier = newSnapApplier(root)
+	}
+	a := l.snapApplier
+
+	batch := l.backend.NewBatch()
+	for i := 0; i < len(s.Slice); i += 3 {
+		kind, key, value := s.Slice[i], s.Slice[i+1], s.Slice[i+2]
+		if len(kind) != 1 {
+			return fmt.Errorf("%w: invalid entry kind", ErrInvalidSnapshotChunk)
+		}
+		switch kind[0] {
+		case snapEntryTrieRoot:
+			if err := a.applyRoot(key, value); err != nil {
+				return err
+			}
+			// staged until the trie is verified
+			continue
+		case snapEntryTrieNode:
+			if err := a.applyNode(key, value); err != nil {
+				return err
+			}
+		case snapEntryCode:
+			if err := a.applyCode(key, value); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%w: unknown entry kind %d", ErrInvalidSnapshotChunk, kind[0])
+		}
+		batch.Put(key, value)
+	}
+	batch.Commit()
+	l.logger.Debugf("[ApplySnapshotChunk] root: %v, entries: %d", root, len(s.Slice)/3)
+	return nil
+}
+
+// FinishSnapshotApply checks that every announced entry of the state of blockHeader is applied, then writes the
+// staged root mappings and verifies the whole trie. The root mappings are removed again if the verification fails,
+// so a partially or wrongly applied state never becomes readable.
+func (l *StateLedgerImpl) FinishSnapshotApply(blockHeader *types.BlockHeader) error {
+	if blockHeader.StateRoot == nil {
+		return ErrorNilStateRoot
+	}
+	a := l.snapApplier
+	if a == nil || a.root != blockHeader.StateRoot.ETHHash() {
+		return fmt.Errorf("%w: no chunk of state root %v is applied", ErrIncompleteSnapshot, blockHeader.StateRoot)
+	}
+	if missing := a.missing(); missing > 0 {
+		return fmt.Errorf("%w: %d entries are missing", ErrIncompleteSnapshot, missing)
+	}
+
+	batch := l.backend.NewBatch()
+	for rootHash, raw := range a.roots {
+		batch.Put(rootHash[:], raw)
+	}
+	batch.Commit()
+	verified, err := l.VerifyTrie(blockHeader)
+	if err == nil && !verified {
+		err = errors.New("state trie verification failed")
+	}
+	if err != nil {
+		batch = l.backend.NewBatch()
+		for rootHash := range a.roots {
+			batch.Delete(rootHash[:])
+		}
+		batch.Commit()
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshotChunk, err)
+	}
+	l.snapApplier = nil
+	return l.refreshAccountTrie(blockHeader.StateRoot)
+}
+
+type snapWalker struct {
+	l      *StateLedgerImpl
+	chunk  *snapChunk
+	cursor *snapCursor
+	// next is the cursor of the next chunk, it is set when the chunk is full
+	next *snapCursor
+}
+
+// walkAccount serves the account trie nodes under nk after the cursor, it returns false if the chunk is full.
+func (w *snapWalker) walkAccount(nk *types.NodeKey) (bool, error) {
+	node, blob, err := w.l.getTrieNode(nk)
+	if err != nil {
+		return false, err
+	}
+	after := w.cursor.accountPath
+	served := after == nil || bytes.Compare(nk.Path, after) > 0
+
+	if leaf, ok := node.(*types.LeafNode); ok {
+		var storageAfter []byte
+		switch {
+		case served:
+			w.chunk.put(snapEntryTrieNode, nk.Encode(), blob)
+		case bytes.Equal(nk.Path, after) && w.cursor.storagePath != nil:
+			// resume the storage trie of the last served account
+			storageAfter = w.cursor.storagePath
+		default:
+			return true, nil
+		}
+		finished, err := w.walkContract(nk.Path, leaf, storageAfter)
+		if err != nil || !finished {
+			return false, err
+		}
+		if w.chunk.full() {
+			w.next = &snapCursor{accountPath: nk.Path}
+			return false, nil
+		}
+		return true, nil
+	}
+
+	if served {
+		w.chunk.put(snapEntryTrieNode, nk.Encode(), blob)
+		if w.chunk.full() {
+			w.next = &snapCursor{accountPath: nk.Path}
+			return false, nil
+		}
+	}
+	internal, ok := node.(*types.InternalNode)
+	if !ok {
+		// empty trie
+		return true, nil
+	}
+	return w.walkChildren(nk, internal, after, w.walkAccount)
+}
+
+// walkContract serves the code and storage trie of the contract account, storage trie nodes are served after
+// storageAfter if it is not nil.
+func (w *snapWalker) walkContract(accountPath []byte, leaf *types.LeafNode, storageAfter []byte) (bool, error) {
+	acc := &types.InnerAccount{Balance: big.NewInt(0)}
+	if err := acc.Unmarshal(leaf.Val); err != nil {
+		return false, err
+	}
+	if storageAfter == nil && len(acc.CodeHash) != 0 && !bytes.Equal(acc.CodeHash, emptyCodeHash) {
+		codeKey := utils.CompositeCodeKey(types.NewAddress(types.HexToBytes(leaf.Key)), acc.CodeHash)
+		if code := w.l.backend.Get(codeKey); len(code) > 0 {
+			w.chunk.put(snapEntryCode, codeKey, code)
+		}
+	}
+	if acc.StorageRoot == (common.Hash{}) {
+		return true, nil
+	}
+
+	rawRootNodeKey := w.l.backend.Get(acc.StorageRoot[:])
+	if rawRootNodeKey == nil {
+		return false, fmt.Errorf("storage root %v: %w", acc.StorageRoot, ErrNotFound)
+	}
+	if storageAfter == nil {
+		w.chunk.put(snapEntryTrieRoot, acc.StorageRoot[:], rawRootNodeKey)
+	}
+
+	sw := &snapStorageWalker{snapWalker: w, accountPath: accountPath, after: storageAfter}
+	return sw.walk(types.DecodeNodeKey(rawRootNodeKey))
+}
+
+type snapStorageWalker struct {
+	*snapWalker
+	accountPath []byte
+	after       []byte
+}
+
+// walk serves the storage trie nodes under nk after the cursor, it returns false if the chunk is full.
+func (w *snapStorageWalker) walk(nk *types.NodeKey) (bool, error) {
+	node, blob, err := w.l.getTrieNode(nk)
+	if err != nil {
+		return false, err
+	}
+	if w.after == nil || bytes.Compare(nk.Path, w.after) > 0 {
+		w.chunk.put(snapEntryTrieNode, nk.Encode(), blob)
+		if w.chunk.full() {
+			w.next = &snapCursor{accountPath: w.accountPath, storagePath: nk.Path}
+			return false, nil
+		}
+	}
+	internal, ok := node.(*types.InternalNode)
+	if !ok {
+		return true, nil
+	}
+	return w.walkChildren(nk, internal, w.after, w.walk)
+}
+
+func (w *snapWalker) walkChildren(nk *types.NodeKey, node *types.InternalNode, after []byte, walk func(*types.NodeKey) (bool, error)) (bool, error) {
+	for slot, child := range node.Children {
+		if child == nil {
+			continue
+		}
+		childPath := make([]byte, len(nk.Path), len(nk.Path)+1)
+		copy(childPath, nk.Path)
+		childPath = append(childPath, byte(slot))
+		if skipSnapPath(childPath, after) {
+			continue
+		}
+		finished, err := walk(&types.NodeKey{Version: child.Version, Type: nk.Type, Path: childPath})
+		if err != nil || !finished {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// getTrieNode reads a trie node from prune cache or storage
+func (l *StateLedgerImpl) getTrieNode(nk *types.NodeKey) (types.Node, []byte, error) {
+	k := nk.Encode()
+	if l.pruneCache != nil && l.pruneCache.Enable() {
+		if node, ok := l.pruneCache.Get(nk.Version, k); ok {
+			return node, node.Encode(), nil
+		}
+	}
+	// the root node of an empty trie is nil
+	blob := l.backend.Get(k)
+	node, err := types.UnmarshalJMTNodeFromPb(blob)
+	if err != nil {
+		return nil, nil, err
+	}
+	return node, blob, nil
+}
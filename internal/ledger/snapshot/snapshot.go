@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math/big"
 	"sync"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
 
@@ -24,6 +25,9 @@ type Snapshot struct {
 
 	lock sync.RWMutex
 
+	// detached snapshot is no longer updated by ledger, reads from it are rejected
+	detached atomic.Bool
+
 	logger logrus.FieldLogger
 }
 
@@ -31,6 +35,7 @@ var (
 	ErrorRollbackToHigherNumber  = errors.New("rollback snapshot to higher blockchain height")
 	ErrorRollbackTooMuch         = errors.New("rollback snapshot too much block")
 	ErrorRemoveJournalOutOfRange = errors.New("remove snapshot journal out of range")
+	ErrorDetached                = errors.New("snapshot is detached from ledger")
 )
 
 // maxBatchSize defines the maximum size of the data in single batch write operation, which is 64 MB.
@@ -71,7 +76,15 @@ func (snap *Snapshot) RemoveJournalsBeforeBlock(height uint64) error {
 	return nil
 }
 
+// Detach marks the snapshot as no longer maintained, so that views and accounts still holding it fall back to trie.
+func (snap *Snapshot) Detach() {
+	snap.detached.Store(true)
+}
+
 func (snap *Snapshot) Account(addr *types.Address) (*types.InnerAccount, error) {
+	if snap.detached.Load() {
+		return nil, ErrorDetached
+	}
 	snap.lock.RLock()
 	defer snap.lock.RUnlock()
 
@@ -108,6 +121,9 @@ func (snap *Snapshot) Account(addr *types.Address) (*types.InnerAccount, error)
 }
 
 func (snap *Snapshot) Storage(addr *types.Address, key []byte) ([]byte, error) {
+	if snap.detached.Load() {
+		return nil, ErrorDetached
+	}
 	snap.lock.RLock()
 	defer snap.lock.RUnlock()
 
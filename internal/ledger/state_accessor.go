@@ -2,12 +2,16 @@ package ledger
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math/big"
 	"sort"
 	"sync"
 	"time"
 
+	"github.com/Rican7/retry"
+	"github.com/Rican7/retry/backoff"
+	"github.com/Rican7/retry/strategy"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/sirupsen/logrus"
@@ -23,14 +27,19 @@ var _ StateLedger = (*StateLedgerImpl)(nil)
 
 const MinJournalHeight = 10
 
+var (
+	refreshAccountTrieRetryLimit    uint = 5
+	refreshAccountTrieRetryInterval      = 20 * time.Millisecond
+)
+
 // GetOrCreateAccount get the account, if not exist, create a new account
 func (l *StateLedgerImpl) GetOrCreateAccount(addr *types.Address) IAccount {
 	account := l.GetAccount(addr)
 	if account == nil {
-		account = NewAccount(l.blockHeight, l.backend, l.storageTrieCache, l.pruneCache, addr, l.changer, l.snapshot)
+		account = NewAccount(l.blockHeight, l.backend, l.storageTrieCache, l.pruneCache, addr, l.changer, l.getSnapshot())
 		account.SetCreated(true)
 		l.changer.append(createObjectChange{account: addr})
-		l.accounts[addr.String()] = account
+		l.storeAccount(addr.String(), account)
 		l.logger.Debugf("[GetOrCreateAccount] create account, addr: %v", addr)
 	} else {
 		l.logger.Debugf("[GetOrCreateAccount] get account, addr: %v", addr)
@@ -49,21 +58,13 @@ func (l *StateLedgerImpl) GetAccount(address *types.Address) IAccount {
 		return value
 	}
 
-	account := NewAccount(l.blockHeight, l.backend, l.storageTrieCache, l.pruneCache, address, l.changer, l.snapshot)
-
 	// try getting account from snapshot first
-	if l.snapshot != nil {
-		if innerAccount, err := l.snapshot.Account(address); err == nil {
+	if snap := l.getSnapshot(); snap != nil {
+		if innerAccount, err := snap.Account(address); err == nil {
 			if innerAccount == nil {
 				return nil
 			}
-			account.originAccount = innerAccount
-			if !bytes.Equal(innerAccount.CodeHash, nil) {
-				code := l.backend.Get(utils.CompositeCodeKey(account.Addr, account.originAccount.CodeHash))
-				account.originCode = code
-				account.dirtyCode = code
-			}
-			l.accounts[addr] = account
+			account := l.loadAccount(address, innerAccount)
 			l.logger.Debugf("[GetAccount] get account from snapshot, addr: %v, account: %v", addr, account)
 			return account
 		}
@@ -76,16 +77,11 @@ func (l *StateLedgerImpl) GetAccount(address *types.Address) IAccount {
 	}
 
 	if rawAccount != nil {
-		account.originAccount = &types.InnerAccount{Balance: big.NewInt(0)}
-		if err := account.originAccount.Unmarshal(rawAccount); err != nil {
+		innerAccount := &types.InnerAccount{Balance: big.NewInt(0)}
+		if err := innerAccount.Unmarshal(rawAccount); err != nil {
 			panic(err)
 		}
-		if !bytes.Equal(account.originAccount.CodeHash, nil) {
-			code := l.backend.Get(utils.CompositeCodeKey(account.Addr, account.originAccount.CodeHash))
-			account.originCode = code
-			account.dirtyCode = code
-		}
-		l.accounts[addr] = account
+		account := l.loadAccount(address, innerAccount)
 		l.logger.Debugf("[GetAccount] get from account trie，addr: %v, account: %v", addr, account)
 		return account
 	}
@@ -93,9 +89,131 @@ func (l *StateLedgerImpl) GetAccount(address *types.Address) IAccount {
 	return nil
 }
 
+// GetAccounts gets accounts of addrs in batch, the result is aligned with addrs and nil stands for a missing account.
+// Accounts missed by cache and snapshot are resolved from account trie in a single traversal, so that the trie nodes
+// shared by their paths are read only once.
+func (l *StateLedgerImpl) GetAccounts(addrs []*types.Address) ([]IAccount, error) {
+	res := make([]IAccount, len(addrs))
+	// composite account key -> indexes of addrs
+	pending := make(map[string][]int)
+	var keys [][]byte
+	snap := l.getSnapshot()
+	for i, address := range addrs {
+		if value, ok := l.accounts[address.String()]; ok {
+			res[i] = value
+			continue
+		}
+		if snap != nil {
+			if innerAccount, err := snap.Account(address); err == nil {
+				if innerAccount != nil {
+					res[i] = l.loadAccount(address, innerAccount)
+				}
+				continue
+			}
+		}
+		key := utils.CompositeAccountKey(address)
+		if _, ok := pending[string(key)]; !ok {
+			keys = append(keys, key)
+		}
+		pending[string(key)] = append(pending[string(key)], i)
+	}
+	if len(keys) == 0 {
+		return res, nil
+	}
+
+	rawAccounts, err := l.getAccountTrieValues(keys)
+	if err != nil {
+		return nil, err
+	}
+	for key, rawAccount := range rawAccounts {
+		indexes := pending[key]
+		innerAccount := &types.InnerAccount{Balance: big.NewInt(0)}
+		if err := innerAccount.Unmarshal(rawAccount); err != nil {
+			return nil, fmt.Errorf("unmarshal account %v: %w", addrs[indexes[0]], err)
+		}
+		account := l.loadAccount(addrs[indexes[0]], innerAccount)
+		for _, i := range indexes {
+			res[i] = account
+		}
+	}
+	return res, nil
+}
+
+// getAccountTrieValues looks up keys in account trie by descending from the root once, keys sharing a path prefix
+// are grouped so that each trie node on the way is read at most once. Absent keys are left out of the result.
+func (l *StateLedgerImpl) getAccountTrieValues(keys [][]byte) (map[string][]byte, error) {
+	values := make(map[string][]byte, len(keys))
+	var walk func(node types.Node, next int, keys [][]byte) error
+	walk = func(node types.Node, next int, keys [][]byte) error {
+		switch n := node.(type) {
+		case *types.InternalNode:
+			var groups [16][][]byte
+			for _, key := range keys {
+				if next < len(key) && n.Children[key[next]] != nil {
+					groups[key[next]] = append(groups[key[next]], key)
+				}
+			}
+			for nibble, group := range groups {
+				if len(group) == 0 {
+					continue
+				}
+				nk := &types.NodeKey{Version: n.Children[nibble].Version, Path: group[0][:next+1], Type: []byte{}}
+				child, err := l.getAccountTrieNode(nk)
+				if err != nil {
+					return fmt.Errorf("get account trie node %v: %w", nk, err)
+				}
+				if err := walk(child, next+1, group); err != nil {
+					return err
+				}
+			}
+		case *types.LeafNode:
+			for _, key := range keys {
+				if bytes.Equal(n.Key, key) {
+					values[string(key)] = n.Val
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(l.accountTrie.Root(), 0, keys); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// getAccountTrieNode reads an account trie node in the same order as the jmt reader: prune cache, account trie cache
+// and then storage, so the batch lookup benefits from the trie cache like the per-account path does.
+func (l *StateLedgerImpl) getAccountTrieNode(nk *types.NodeKey) (types.Node, error) {
+	k := nk.Encode()
+	if l.pruneCache != nil && l.pruneCache.Enable() {
+		if node, ok := l.pruneCache.Get(nk.Version, k); ok {
+			return node, nil
+		}
+	}
+	if l.accountTrieCache != nil && l.accountTrieCache.Enable() {
+		if blob, ok := l.accountTrieCache.Get(k); ok {
+			return types.UnmarshalJMTNodeFromPb(blob)
+		}
+	}
+	return types.UnmarshalJMTNodeFromPb(l.backend.Get(k))
+}
+
+// loadAccount builds the account of address from its origin state, loads its code and puts it into cache
+func (l *StateLedgerImpl) loadAccount(address *types.Address, innerAccount *types.InnerAccount) *SimpleAccount {
+	account := NewAccount(l.blockHeight, l.backend, l.storageTrieCache, l.pruneCache, address, l.changer, l.getSnapshot())
+	account.originAccount = innerAccount
+	if !bytes.Equal(innerAccount.CodeHash, nil) {
+		code := l.backend.Get(utils.CompositeCodeKey(account.Addr, account.originAccount.CodeHash))
+		account.originCode = code
+		account.dirtyCode = code
+	}
+	l.storeAccount(address.String(), account)
+	return account
+}
+
 // nolint
 func (l *StateLedgerImpl) setAccount(account IAccount) {
-	l.accounts[account.GetAddress().String()] = account
+	l.storeAccount(account.GetAddress().String(), account)
 	l.logger.Debugf("[Revert setAccount] addr: %v, account: %v", account.GetAddress(), account)
 }
 
@@ -207,7 +325,9 @@ func (l *StateLedgerImpl) SetNonce(addr *types.Address, nonce uint64) {
 }
 
 func (l *StateLedgerImpl) Clear() {
+	l.accountsLock.Lock()
 	l.accounts = make(map[string]IAccount)
+	l.accountsLock.Unlock()
 }
 
 // collectDirtyData gets dirty accounts and snapshot journals
@@ -236,6 +356,9 @@ func (l *StateLedgerImpl) Commit() (*types.Hash, error) {
 	l.logger.Debugf("==================[Commit-Start]==================")
 	defer l.logger.Debugf("==================[Commit-End]==================")
 
+	l.commitLock.Lock()
+	defer l.commitLock.Unlock()
+
 	storagemgr.ExportCachedStorageMetrics()
 	defer func() {
 		ExportTriePreloaderMetrics()
@@ -375,14 +498,14 @@ func (l *StateLedgerImpl) Commit() (*types.Hash, error) {
 	}).Info("[StateLedger-Commit] Flush pruneCache and trie rootHash entries into kv")
 
 	current = time.Now()
-	if l.snapshot != nil {
-		size, err := l.snapshot.Update(height, journals, destructSet, accountSet, storageSet)
+	if snap := l.getSnapshot(); snap != nil {
+		size, err := snap.Update(height, journals, destructSet, accountSet, storageSet)
 		if err != nil {
 			return nil, fmt.Errorf("update snapshot error: %w", err)
 		}
 
 		if height > l.getJnlHeightSize() {
-			if err := l.snapshot.RemoveJournalsBeforeBlock(height - l.getJnlHeightSize()); err != nil {
+			if err := snap.RemoveJournalsBeforeBlock(height - l.getJnlHeightSize()); err != nil {
 				return nil, fmt.Errorf("remove journals before block %d failed: %w", height-l.getJnlHeightSize(), err)
 			}
 		}
@@ -416,8 +539,8 @@ func (l *StateLedgerImpl) RollbackState(height uint64, stateRoot *types.Hash) er
 	l.changer.reset()
 
 	// rollback snapshots
-	if l.snapshot != nil {
-		if err := l.snapshot.Rollback(height); err != nil {
+	if snap := l.getSnapshot(); snap != nil {
+		if err := snap.Rollback(height); err != nil {
 			return err
 		}
 	}
@@ -428,9 +551,7 @@ func (l *StateLedgerImpl) RollbackState(height uint64, stateRoot *types.Hash) er
 			return err
 		}
 	}
-	l.refreshAccountTrie(stateRoot)
-
-	return nil
+	return l.refreshAccountTrie(stateRoot)
 }
 
 func (l *StateLedgerImpl) SelfDestruct(addr *types.Address) bool {
@@ -563,7 +684,13 @@ func (l *StateLedgerImpl) AddPreimage(hash types.Hash, preimage []byte) {
 func (l *StateLedgerImpl) PrepareBlock(lastStateRoot *types.Hash, currentExecutingHeight uint64) {
 	l.logs = newEvmLogs()
 	l.blockHeight = currentExecutingHeight
-	l.refreshAccountTrie(lastStateRoot)
+	if err := l.refreshAccountTrie(lastStateRoot); err != nil {
+		l.logger.WithFields(logrus.Fields{
+			"lastStateRoot": lastStateRoot,
+			"currentHeight": l.blockHeight,
+			"err":           err.Error(),
+		}).Errorf("load account trie from db error")
+	}
 	storagemgr.ResetCachedStorageMetrics()
 	ResetTriePreloaderMetrics()
 	l.resetMetrics()
@@ -571,13 +698,13 @@ func (l *StateLedgerImpl) PrepareBlock(lastStateRoot *types.Hash, currentExecuti
 }
 
 func (l *StateLedgerImpl) resetMetrics() {
-	l.snapshot.ResetMetrics()
+	l.getSnapshot().ResetMetrics()
 	l.accountTrieCache.ResetCounterMetrics()
 	l.storageTrieCache.ResetCounterMetrics()
 }
 
 func (l *StateLedgerImpl) exportMetrics() {
-	l.snapshot.ExportMetrics()
+	l.getSnapshot().ExportMetrics()
 
 	accountTrieCacheMetrics := l.accountTrieCache.ExportMetrics()
 	accountTrieCacheMissCounterPerBlock.Set(float64(accountTrieCacheMetrics.CacheMissCounter))
@@ -588,9 +715,16 @@ func (l *StateLedgerImpl) exportMetrics() {
 	storageTrieCacheMissCounterPerBlock.Set(float64(storageTrieCacheMetrics.CacheMissCounter))
 	storageTrieCacheHitCounterPerBlock.Set(float64(storageTrieCacheMetrics.CacheHitCounter))
 	storageTrieCacheSize.Set(float64(storageTrieCacheMetrics.CacheSize / 1024 / 1024))
+
+	minHeight, maxHeight := l.GetHistoryRange()
+	pruneMinHeight.Set(float64(minHeight))
+	pruneMaxHeight.Set(float64(maxHeight))
+	if maxHeight >= minHeight {
+		pruneHistoryBlocks.Set(float64(maxHeight - minHeight))
+	}
 }
 
-func (l *StateLedgerImpl) refreshAccountTrie(lastStateRoot *types.Hash) {
+func (l *StateLedgerImpl) refreshAccountTrie(lastStateRoot *types.Hash) error {
 	if lastStateRoot == nil || lastStateRoot.ETHHash() == (common.Hash{}) {
 		// dummy state
 		rootHash := crypto.Keccak256Hash([]byte{})
@@ -612,21 +746,31 @@ func (l *StateLedgerImpl) refreshAccountTrie(lastStateRoot *types.Hash) {
 
 		trie, _ := jmt.New(rootHash, l.backend, l.accountTrieCache, l.pruneCache, l.logger)
 		l.accountTrie = trie
-		l.triePreloader = newTriePreloaderManager(l.logger, l.backend, l.storageTrieCache, l.pruneCache)
-		return
-	}
-
-	trie, err := jmt.New(lastStateRoot.ETHHash(), l.backend, l.accountTrieCache, l.pruneCache, l.logger)
-	if err != nil {
-		l.logger.WithFields(logrus.Fields{
-			"lastStateRoot": lastStateRoot,
-			"currentHeight": l.blockHeight,
-			"err":           err.Error(),
-		}).Errorf("load account trie from db error")
-		return
+		l.triePreloader = newTriePreloaderManager(l.logger, l.backend, l.storageTrieCache, l.pruneCache, l.repo.Config.Ledger.TriePreloadWorkers)
+		return nil
+	}
+
+	// the root node of a just-committed block may not be visible yet (e.g. flushing or compacting),
+	// so retry a few times before reporting the missing root
+	var trie *jmt.JMT
+	var loadErr error
+	_ = retry.Retry(func(attempt uint) error {
+		trie, loadErr = jmt.New(lastStateRoot.ETHHash(), l.backend, l.accountTrieCache, l.pruneCache, l.logger)
+		if errors.Is(loadErr, jmt.ErrorNotFound) {
+			l.logger.WithFields(logrus.Fields{
+				"lastStateRoot": lastStateRoot,
+				"attempt":       attempt,
+			}).Warn("account trie root not found, retry")
+			return loadErr
+		}
+		return nil
+	}, strategy.Limit(refreshAccountTrieRetryLimit), strategy.Backoff(backoff.Linear(refreshAccountTrieRetryInterval)))
+	if loadErr != nil {
+		return fmt.Errorf("load account trie of root %v: %w", lastStateRoot, loadErr)
 	}
 	l.accountTrie = trie
-	l.triePreloader = newTriePreloaderManager(l.logger, l.backend, l.storageTrieCache, l.pruneCache)
+	l.triePreloader = newTriePreloaderManager(l.logger, l.backend, l.storageTrieCache, l.pruneCache, l.repo.Config.Ledger.TriePreloadWorkers)
+	return nil
 }
 
 func (l *StateLedgerImpl) AddLog(log *types.EvmLog) {
@@ -126,7 +126,7 @@ type (
 )
 
 func (ch createObjectChange) revert(l *StateLedgerImpl) {
-	delete(l.accounts, ch.account.String())
+	l.deleteAccount(ch.account.String())
 }
 
 func (ch createObjectChange) dirtied() *types.Address {
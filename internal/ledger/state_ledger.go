@@ -1,17 +1,21 @@
 package ledger
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
+	"math/rand"
 	"path"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/sirupsen/logrus"
 
 	"github.com/axiomesh/axiom-bft/common/consensus"
+	"github.com/axiomesh/axiom-kit/hexutil"
 	"github.com/axiomesh/axiom-kit/jmt"
 	"github.com/axiomesh/axiom-kit/storage/kv"
 	"github.com/axiomesh/axiom-kit/types"
@@ -28,8 +32,16 @@ var _ StateLedger = (*StateLedgerImpl)(nil)
 
 var (
 	ErrorRollbackToHigherNumber = errors.New("rollback to higher blockchain height")
+	ErrorPruneDisabled          = errors.New("state pruning is disabled")
+	ErrorSnapshotDisabled       = errors.New("state snapshot is disabled")
+	ErrTooManyViews             = errors.New("too many concurrent state views")
+	ErrorInvalidProof           = errors.New("state proof is invalid")
+	ErrorNilStateRoot           = errors.New("block header has no state root")
 )
 
+// newViewWaitTimeout is how long NewView waits for a free slot when the concurrent views reach the limit
+var newViewWaitTimeout = 100 * time.Millisecond
+
 // maxBatchSize defines the maximum size of the data in single batch write operation, which is 64 MB.
 const maxBatchSize = 64 * 1024 * 1024
 
@@ -55,6 +67,10 @@ type StateLedgerImpl struct {
 	thash         *types.Hash
 	txIndex       int
 
+	// accounts is owned by the goroutine executing blocks, which may read it without lock.
+	// Any write must hold accountsLock, and readers from other goroutines must hold its read lock.
+	accountsLock sync.RWMutex
+
 	validRevisions []revision
 	nextRevisionId int
 	changer        *stateChanger
@@ -64,7 +80,20 @@ type StateLedgerImpl struct {
 	refund     uint64
 	logs       *evmLogs
 
-	snapshot *snapshot.Snapshot
+	// snapshot can be swapped by DisableSnapshot and EnableSnapshot at runtime, which hold the write lock of
+	// snapshotLock, readers must get it by getSnapshot
+	snapshot     *snapshot.Snapshot
+	snapshotLock sync.RWMutex
+
+	// commitLock is shared by the ledger and its views, Commit holds the write lock so that
+	// NewView waits for the in-flight commit instead of reading partially written state
+	commitLock *sync.RWMutex
+
+	// viewLimiter is shared by the ledger and its views to bound the concurrent NewView, nil means unlimited
+	viewLimiter chan struct{}
+
+	// snapApplier tracks the snapshot chunks applied so far, see ApplySnapshotChunk
+	snapApplier *snapApplier
 
 	transientStorage transientStorage
 }
@@ -135,11 +164,26 @@ func (m *SnapshotMeta) Unmarshal(data []byte) error {
 // NewView get a view at specific block. We can enable snapshot if and only if the block were the latest block.
 func (l *StateLedgerImpl) NewView(blockHeader *types.BlockHeader, enableSnapshot bool) (StateLedger, error) {
 	l.logger.Debugf("[NewView] height: %v, stateRoot: %v", blockHeader.Number, blockHeader.StateRoot)
-	if l.repo.Config.Ledger.EnablePrune {
-		min, max := l.GetHistoryRange()
-		if blockHeader.Number < min || blockHeader.Number > max {
-			return nil, fmt.Errorf("history at target block %v is invalid, the valid range is from %v to %v", blockHeader.Number, min, max)
-		}
+	return l.newView(blockHeader, enableSnapshot)
+}
+
+// NewLimitedView is NewView bounded by the concurrent view limit, it fails with ErrTooManyViews if no slot is freed in time.
+func (l *StateLedgerImpl) NewLimitedView(blockHeader *types.BlockHeader, enableSnapshot bool) (StateLedger, error) {
+	l.logger.Debugf("[NewLimitedView] height: %v, stateRoot: %v", blockHeader.Number, blockHeader.StateRoot)
+	if err := l.acquireView(); err != nil {
+		return nil, err
+	}
+	defer l.releaseView()
+	return l.newView(blockHeader, enableSnapshot)
+}
+
+func (l *StateLedgerImpl) newView(blockHeader *types.BlockHeader, enableSnapshot bool) (StateLedger, error) {
+	// wait for the in-flight commit, so the view reflects all committed state at the header
+	l.commitLock.RLock()
+	defer l.commitLock.RUnlock()
+
+	if err := l.checkHistoryRange(blockHeader.Number); err != nil {
+		return nil, err
 	}
 
 	lg := &StateLedgerImpl{
@@ -156,15 +200,68 @@ func (l *StateLedgerImpl) NewView(blockHeader *types.BlockHeader, enableSnapshot
 		accessList:       NewAccessList(),
 		logs:             newEvmLogs(),
 		blockHeight:      blockHeader.Number,
+		commitLock:       l.commitLock,
+		viewLimiter:      l.viewLimiter,
+	}
+	// snapshot only holds the latest state, it is not used if it has not reached or has passed the header
+	if snap := l.getSnapshot(); enableSnapshot && snap != nil {
+		if _, snapshotHeight := snap.GetJournalRange(); snapshotHeight == blockHeader.Number {
+			lg.snapshot = snap
+		} else {
+			l.logger.Debugf("[NewView] snapshot height %v is inconsistent with view height %v, read through trie", snapshotHeight, blockHeader.Number)
+		}
 	}
-	if enableSnapshot {
-		lg.snapshot = l.snapshot
+	if err := lg.refreshAccountTrie(blockHeader.StateRoot); err != nil {
+		return nil, err
 	}
-	lg.refreshAccountTrie(blockHeader.StateRoot)
 	return lg, nil
 }
 
+// acquireView takes a slot of concurrent view creation, it fails with ErrTooManyViews if no slot is freed in time
+func (l *StateLedgerImpl) acquireView() error {
+	if l.viewLimiter == nil {
+		return nil
+	}
+	select {
+	case l.viewLimiter <- struct{}{}:
+		return nil
+	default:
+	}
+
+	timer := time.NewTimer(newViewWaitTimeout)
+	defer timer.Stop()
+	select {
+	case l.viewLimiter <- struct{}{}:
+		return nil
+	case <-timer.C:
+		newViewRejectedCounter.Inc()
+		return ErrTooManyViews
+	}
+}
+
+func (l *StateLedgerImpl) releaseView() {
+	if l.viewLimiter != nil {
+		<-l.viewLimiter
+	}
+}
+
+// checkHistoryRange checks whether the state at target block is still available after pruning
+func (l *StateLedgerImpl) checkHistoryRange(blockNumber uint64) error {
+	if l.repo.Config.Ledger.EnablePrune {
+		min, max := l.GetHistoryRange()
+		if blockNumber < min || blockNumber > max {
+			return fmt.Errorf("history at target block %v is invalid, the valid range is from %v to %v", blockNumber, min, max)
+		}
+	}
+	return nil
+}
+
+// GetHistoryRange returns the range of blocks whose state is kept, a full-archive node(prune disabled) keeps
+// the state of all blocks.
 func (l *StateLedgerImpl) GetHistoryRange() (uint64, uint64) {
+	if !l.pruneCache.Enable() {
+		return 0, l.blockHeight
+	}
 	return l.pruneCache.GetRange()
 }
 
@@ -172,6 +269,69 @@ func (l *StateLedgerImpl) GetStateDelta(blockNumber uint64) *types.StateDelta {
 	return l.pruneCache.GetStateDelta(blockNumber)
 }
 
+// ModifiedAccounts returns the addresses whose account leaf was written at the given block, storage changes are
+// included since they update the storage root of account. Accounts removed from the trie are not reported.
+func (l *StateLedgerImpl) ModifiedAccounts(blockNumber uint64) ([]*types.Address, error) {
+	if !l.pruneCache.Enable() {
+		return nil, ErrorPruneDisabled
+	}
+	if err := l.checkHistoryRange(blockNumber); err != nil {
+		return nil, err
+	}
+	stateDelta := l.pruneCache.GetStateDelta(blockNumber)
+	if stateDelta == nil {
+		return nil, fmt.Errorf("state delta at block %v is not found", blockNumber)
+	}
+
+	seen := make(map[string]struct{})
+	var addrs []*types.Address
+	for _, journal := range stateDelta.Journal {
+		if journal.Type != prune.TypeAccount {
+			continue
+		}
+		for _, node := range journal.DirtySet {
+			leaf, ok := node.(*types.LeafNode)
+			if !ok {
+				continue
+			}
+			addr := hexutil.DecodeFromNibbles(leaf.Key)
+			if _, ok := seen[addr]; ok {
+				continue
+			}
+			seen[addr] = struct{}{}
+			addrs = append(addrs, types.NewAddressByStr(addr))
+		}
+	}
+	return addrs, nil
+}
+
+func (l *StateLedgerImpl) PruneTo(targetHeight uint64, progressC chan<- prune.PruneProgress) error {
+	if !l.pruneCache.Enable() {
+		return ErrorPruneDisabled
+	}
+	return l.pruneCache.PruneTo(targetHeight, progressC)
+}
+
+// CachedAccountNum returns the number of accounts cached during executing current block,
+// it is safe to be called concurrently with block execution.
+func (l *StateLedgerImpl) CachedAccountNum() int {
+	l.accountsLock.RLock()
+	defer l.accountsLock.RUnlock()
+	return len(l.accounts)
+}
+
+func (l *StateLedgerImpl) storeAccount(addr string, account IAccount) {
+	l.accountsLock.Lock()
+	l.accounts[addr] = account
+	l.accountsLock.Unlock()
+}
+
+func (l *StateLedgerImpl) deleteAccount(addr string) {
+	l.accountsLock.Lock()
+	delete(l.accounts, addr)
+	l.accountsLock.Unlock()
+}
+
 func (l *StateLedgerImpl) Finalise() {
 	for _, account := range l.accounts {
 		keys := account.Finalise()
@@ -187,6 +347,17 @@ func (l *StateLedgerImpl) Finalise() {
 
 // IterateTrie iterate the whole account trie and all contract storage tries of target block, and store them in kv.
 func (l *StateLedgerImpl) IterateTrie(snapshotMeta *SnapshotMeta, kv kv.Storage, errC chan error) {
+	errC <- l.iterateTrie(snapshotMeta, kv, false)
+}
+
+// IterateTrieResume is IterateTrie with checkpoints, the progress is recorded in kv with the trie nodes,
+// so an interrupted iteration skips the written storage tries when it is called again with the same kv.
+// The progress is removed once the iteration succeeds.
+func (l *StateLedgerImpl) IterateTrieResume(snapshotMeta *SnapshotMeta, kv kv.Storage, errC chan error) {
+	errC <- l.iterateTrie(snapshotMeta, kv, true)
+}
+
+func (l *StateLedgerImpl) iterateTrie(snapshotMeta *SnapshotMeta, kv kv.Storage, resume bool) error {
 	stateRoot := snapshotMeta.BlockHeader.StateRoot.ETHHash()
 	l.logger.Infof("[IterateTrie] blockhash: %v, rootHash: %v", snapshotMeta.BlockHeader.Hash(), stateRoot)
 	batch := kv.NewBatch()
@@ -194,13 +365,32 @@ func (l *StateLedgerImpl) IterateTrie(snapshotMeta *SnapshotMeta, kv kv.Storage,
 	// in validate node, we should rebuild prune cache before iterate trie
 	if l.pruneCache != nil {
 		if err := l.pruneCache.Rollback(snapshotMeta.BlockHeader.Number, false); err != nil {
-			errC <- err
+			return err
 		}
 	}
-	batch.Put(utils.CompositeKey(utils.PruneJournalKey, utils.MinHeightStr), utils.MarshalUint64(snapshotMeta.BlockHeader.Number))
-	batch.Put(utils.CompositeKey(utils.PruneJournalKey, utils.MaxHeightStr), utils.MarshalUint64(snapshotMeta.BlockHeader.Number))
 
+	progress := &iterateTrieProgress{}
+	if resume {
+		var err error
+		if progress, err = loadIterateTrieProgress(kv, stateRoot); err != nil {
+			return err
+		}
+	}
 	queue := []common.Hash{stateRoot}
+	if progress.started {
+		if progress.accountDone {
+			queue = progress.queue
+		}
+		l.logger.Infof("[IterateTrie] resume, account trie finished: %v, remaining storage tries: %v", progress.accountDone, len(progress.queue))
+	} else {
+		batch.Put(utils.CompositeKey(utils.PruneJournalKey, utils.MinHeightStr), utils.MarshalUint64(snapshotMeta.BlockHeader.Number))
+		batch.Put(utils.CompositeKey(utils.PruneJournalKey, utils.MaxHeightStr), utils.MarshalUint64(snapshotMeta.BlockHeader.Number))
+		if resume {
+			batch.Put(iterateTrieRootKey, stateRoot[:])
+		}
+	}
+
+	lastCheckpoint := time.Now()
 	for len(queue) > 0 {
 		trieRoot := queue[0]
 		iter := jmt.NewIterator(trieRoot, l.backend, l.pruneCache, 10000, 300*time.Second)
@@ -213,13 +403,12 @@ func (l *StateLedgerImpl) IterateTrie(snapshotMeta *SnapshotMeta, kv kv.Storage,
 				if err == jmt.ErrorNoMoreData {
 					break
 				} else {
-					errC <- err
-					return
+					return err
 				}
 			}
 			batch.Put(node.RawKey, node.RawValue)
 			// data size exceed threshold, flush to disk
-			if batch.Size() > maxBatchSize {
+			if batch.Size() > l.snapshotBatchSize() {
 				batch.Commit()
 				batch.Reset()
 				l.logger.Infof("[IterateTrie] write batch periodically")
@@ -236,25 +425,52 @@ func (l *StateLedgerImpl) IterateTrie(snapshotMeta *SnapshotMeta, kv kv.Storage,
 					batch.Put(codeKey, l.backend.Get(codeKey))
 					// prepare storage trie root
 					queue = append(queue, acc.StorageRoot)
+					if resume {
+						batch.Put(iterateTrieQueueKey(acc.StorageRoot), acc.StorageRoot[:])
+					}
 				}
 			}
 		}
 		queue = queue[1:]
 		l.logger.Infof("[IterateTrie] trieRoot=%v, rootNodeKey from kv=%v", trieRoot, l.backend.Get(trieRoot[:]))
 		batch.Put(trieRoot[:], l.backend.Get(trieRoot[:]))
+
+		if resume {
+			// the trie is recorded as finished in the same batch as its nodes
+			if trieRoot == stateRoot {
+				batch.Put(iterateTrieAccountKey, []byte{1})
+			} else {
+				batch.Delete(iterateTrieQueueKey(trieRoot))
+			}
+			if time.Since(lastCheckpoint) >= iterateTrieCheckpointInterval {
+				batch.Commit()
+				batch.Reset()
+				lastCheckpoint = time.Now()
+			}
+		}
 	}
 
 	snapshotMetaBytes, err := snapshotMeta.Marshal()
 	if err != nil {
-		errC <- err
-		return
+		return err
 	}
 	batch.Put([]byte(utils.SnapshotMetaKey), snapshotMetaBytes)
+	if resume {
+		batch.Delete(iterateTrieRootKey)
+		batch.Delete(iterateTrieAccountKey)
+	}
 
 	batch.Commit()
 	l.logger.Infof("[IterateTrie] iterate trie successfully")
+	return nil
+}
 
-	errC <- nil
+// snapshotBatchSize returns the batch size threshold of GenerateSnapshot and IterateTrie
+func (l *StateLedgerImpl) snapshotBatchSize() int {
+	if size := l.repo.Config.Ledger.SnapshotBatchSizeMegabytes; size > 0 {
+		return size * 1024 * 1024
+	}
+	return maxBatchSize
 }
 
 func (l *StateLedgerImpl) GetTrieSnapshotMeta() (*SnapshotMeta, error) {
@@ -270,21 +486,45 @@ func (l *StateLedgerImpl) GetTrieSnapshotMeta() (*SnapshotMeta, error) {
 	return snapshotMeta, nil
 }
 
-// GenerateSnapshot generate the snapshot by iterating state trie leaves.
+// FlushCaches writes trie nodes held only in memory into the backend, the account/storage trie caches and the
+// cached backend are write-through and need no flush. Only committed state is flushed, so it should be called after
+// the target block is committed and before GenerateSnapshot or IterateTrie.
+func (l *StateLedgerImpl) FlushCaches() error {
+	if !l.pruneCache.Enable() {
+		return nil
+	}
+	l.logger.Infof("[FlushCaches] flush prune cache")
+	return l.pruneCache.Flush()
+}
+
+// GenerateSnapshot generate the snapshot by iterating state trie leaves, failures are counted by
+// snapshot_generation_failures_total and handled by the caller according to ledger.snapshot_failure_policy.
 func (l *StateLedgerImpl) GenerateSnapshot(blockHeader *types.BlockHeader, errC chan error) {
+	err := l.generateSnapshot(blockHeader)
+	if err != nil {
+		snapshotGenerationFailureCounter.Inc()
+		l.logger.Errorf("[GenerateSnapshot] generate snapshot failed: %v", err)
+	}
+	errC <- err
+}
+
+func (l *StateLedgerImpl) generateSnapshot(blockHeader *types.BlockHeader) error {
 	stateRoot := blockHeader.StateRoot.ETHHash()
 	l.logger.Infof("[GenerateSnapshot] blockNum: %v, blockhash: %v, rootHash: %v", blockHeader.Number, blockHeader.Hash(), stateRoot)
+	snap := l.getSnapshot()
+	if snap == nil {
+		return ErrorSnapshotDisabled
+	}
 
 	// in validate node, we should rebuild prune cache before iterate trie
 	if l.repo.Config.Ledger.EnablePrune {
 		if err := l.pruneCache.Rollback(blockHeader.Number, false); err != nil {
-			errC <- err
-			return
+			return err
 		}
 	}
 
 	queue := []common.Hash{stateRoot}
-	batch := l.snapshot.Batch()
+	batch := snap.Batch()
 	for len(queue) > 0 {
 		trieRoot := queue[0]
 		iter := jmt.NewIterator(trieRoot, l.backend, l.pruneCache, 10000, 300*time.Second)
@@ -297,13 +537,12 @@ func (l *StateLedgerImpl) GenerateSnapshot(blockHeader *types.BlockHeader, errC
 				if err == jmt.ErrorNoMoreData {
 					break
 				} else {
-					errC <- err
-					return
+					return err
 				}
 			}
 			batch.Put(node.LeafKey, node.LeafValue)
 			// data size exceed threshold, flush to disk
-			if batch.Size() > maxBatchSize {
+			if batch.Size() > l.snapshotBatchSize() {
 				batch.Commit()
 				batch.Reset()
 				l.logger.Infof("[GenerateSnapshot] write batch periodically")
@@ -326,8 +565,7 @@ func (l *StateLedgerImpl) GenerateSnapshot(blockHeader *types.BlockHeader, errC
 	batch.Put(utils.CompositeKey(utils.SnapshotKey, utils.MaxHeightStr), utils.MarshalUint64(blockHeader.Number))
 	batch.Commit()
 	l.logger.Infof("[GenerateSnapshot] generate snapshot successfully")
-
-	errC <- nil
+	return nil
 }
 
 func (l *StateLedgerImpl) VerifyTrie(blockHeader *types.BlockHeader) (bool, error) {
@@ -336,6 +574,81 @@ func (l *StateLedgerImpl) VerifyTrie(blockHeader *types.BlockHeader) (bool, erro
 	return jmt.VerifyTrie(blockHeader.StateRoot.ETHHash(), l.backend, l.pruneCache)
 }
 
+// VerifyTrieSampled verifies samples random paths from the state root to the leaves of the account trie, the hash of
+// every node on a path is checked against the child hash recorded by its parent. It reads only a few nodes per sample,
+// so it can run on a fast cadence on large states where a full VerifyTrie takes hours.
+func (l *StateLedgerImpl) VerifyTrieSampled(blockHeader *types.BlockHeader, samples int) (bool, error) {
+	if blockHeader.StateRoot == nil {
+		return false, ErrorNilStateRoot
+	}
+	rootHash := blockHeader.StateRoot.ETHHash()
+	trie, err := jmt.New(rootHash, l.backend, l.accountTrieCache, l.pruneCache, l.logger)
+	if err != nil {
+		return false, err
+	}
+	root := trie.Root()
+	if root == nil {
+		return false, jmt.ErrorNodeMissing
+	}
+	if root.GetHash() != rootHash {
+		l.logger.Errorf("[VerifyTrieSampled] root node hash %v mismatches state root %v", root.GetHash(), rootHash)
+		return false, nil
+	}
+
+	for i := 0; i < samples; i++ {
+		node := root
+		var path []byte
+		for {
+			n, ok := node.(*types.InternalNode)
+			if !ok {
+				break
+			}
+			nibbles := make([]int, 0, types.TrieDegree)
+			for nibble, child := range n.Children {
+				if child != nil {
+					nibbles = append(nibbles, nibble)
+				}
+			}
+			if len(nibbles) == 0 {
+				break
+			}
+			nibble := nibbles[rand.Intn(len(nibbles))]
+			child := n.Children[nibble]
+			path = append(path, byte(nibble))
+			next, err := l.getAccountTrieNode(&types.NodeKey{Version: child.Version, Path: path, Type: []byte{}})
+			if err != nil {
+				return false, err
+			}
+			if next == nil {
+				return false, jmt.ErrorNodeMissing
+			}
+			if next.GetHash() != child.Hash {
+				l.logger.Errorf("[VerifyTrieSampled] node at path %x version %d has hash %v, expected %v", path, child.Version, next.GetHash(), child.Hash)
+				return false, nil
+			}
+			node = next
+		}
+	}
+	return true, nil
+}
+
+// GetTrieNode reads the trie node of rawKey(an encoded NodeKey) from prune cache or storage. The returned node is
+// either a *types.InternalNode whose Children hold the hash and version of each child, or a *types.LeafNode.
+func (l *StateLedgerImpl) GetTrieNode(rawKey []byte) (types.Node, error) {
+	// version(8 bytes) + type length(1 byte) + type + path
+	if len(rawKey) < 9 || len(rawKey) < 9+int(rawKey[8]) {
+		return nil, fmt.Errorf("invalid trie node key %x", rawKey)
+	}
+	node, _, err := l.getTrieNode(types.DecodeNodeKey(rawKey))
+	if err != nil {
+		return nil, fmt.Errorf("decode trie node %x: %w", rawKey, err)
+	}
+	if node == nil {
+		return nil, fmt.Errorf("trie node %x: %w", rawKey, ErrNotFound)
+	}
+	return node, nil
+}
+
 func (l *StateLedgerImpl) Prove(rootHash common.Hash, key []byte) (*jmt.ProofResult, error) {
 	var trie *jmt.JMT
 	if rootHash == (common.Hash{}) {
@@ -349,10 +662,321 @@ func (l *StateLedgerImpl) Prove(rootHash common.Hash, key []byte) (*jmt.ProofRes
 	return trie.Prove(key)
 }
 
+// VerifyProof checks a proof generated by Prove against a known trie root without any ledger, so that it can be used
+// by light clients. It returns the proven value of key, or nil if the proof shows that key is absent from the trie.
+func VerifyProof(rootHash common.Hash, key []byte, proof *jmt.ProofResult) ([]byte, error) {
+	if proof == nil || len(proof.Proof) == 0 {
+		return nil, fmt.Errorf("%w: empty merkle path", ErrorInvalidProof)
+	}
+	last, err := types.UnmarshalJMTNodeFromPb(proof.Proof[len(proof.Proof)-1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: decode last node: %v", ErrorInvalidProof, err)
+	}
+	if leaf, ok := last.(*types.LeafNode); ok && bytes.Equal(leaf.Key, key) {
+		ok, err := jmt.VerifyProof(rootHash, &jmt.ProofResult{Key: key, Value: leaf.Val, Proof: proof.Proof})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrorInvalidProof, err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: merkle path does not match root", ErrorInvalidProof)
+		}
+		return leaf.Val, nil
+	}
+
+	// jmt only verifies the proof of a present key
+	if err := verifyAbsence(rootHash, key, proof.Proof); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// verifyAbsence checks that the merkle path of key ends at an empty slot or at the leaf of another key.
+func verifyAbsence(rootHash common.Hash, key []byte, path [][]byte) error {
+	expected := rootHash
+	for level, raw := range path {
+		node, err := types.UnmarshalJMTNodeFromPb(raw)
+		if err != nil {
+			return fmt.Errorf("%w: decode node at level %d: %v", ErrorInvalidProof, level, err)
+		}
+		switch n := node.(type) {
+		case *types.InternalNode:
+			if n.GetHash() != expected {
+				return fmt.Errorf("%w: hash mismatch at level %d", ErrorInvalidProof, level)
+			}
+			if level >= len(key) || int(key[level]) >= len(n.Children) {
+				return fmt.Errorf("%w: key does not match merkle path at level %d", ErrorInvalidProof, level)
+			}
+			child := n.Children[key[level]]
+			if child == nil {
+				if level != len(path)-1 {
+					return fmt.Errorf("%w: unexpected nodes after level %d", ErrorInvalidProof, level)
+				}
+				return nil
+			}
+			expected = child.Hash
+		case *types.LeafNode:
+			if n.GetHash() != expected {
+				return fmt.Errorf("%w: hash mismatch at level %d", ErrorInvalidProof, level)
+			}
+			if level != len(path)-1 {
+				return fmt.Errorf("%w: unexpected nodes after leaf", ErrorInvalidProof)
+			}
+			return nil
+		default:
+			return fmt.Errorf("%w: empty node at level %d", ErrorInvalidProof, level)
+		}
+	}
+	return fmt.Errorf("%w: merkle path does not end at a leaf", ErrorInvalidProof)
+}
+
+// StorageAt reads a single storage slot at the state of target block,
+// it only opens the account trie and the storage trie of target account instead of building a full view.
+func (l *StateLedgerImpl) StorageAt(blockHeader *types.BlockHeader, addr *types.Address, key []byte) ([]byte, error) {
+	storageRoot, err := l.storageRootAt(blockHeader, addr)
+	if err != nil {
+		return nil, err
+	}
+	if storageRoot == (common.Hash{}) {
+		return nil, nil
+	}
+
+	storageTrie, err := jmt.New(storageRoot, l.backend, l.storageTrieCache, l.pruneCache, l.logger)
+	if err != nil {
+		return nil, fmt.Errorf("load storage trie of root %v: %w", storageRoot, err)
+	}
+	return storageTrie.Get(utils.CompositeStorageKey(addr, key))
+}
+
+// AccountProof is the proof of an account and its storage slots in the EIP-1186 shape,
+// proofs are the encoded trie nodes from root to leaf.
+type AccountProof struct {
+	Address      *types.Address
+	AccountProof [][]byte
+	Balance      *big.Int
+	Nonce        uint64
+	CodeHash     []byte
+	StorageRoot  common.Hash
+	StorageProof []*StorageProof
+}
+
+// StorageProof is the proof of a storage slot, Value is nil if the slot is absent.
+type StorageProof struct {
+	Key   []byte
+	Value []byte
+	Proof [][]byte
+}
+
+// GetProof proves addr and its storage slots at the state of target block, absent account or slots get a proof of
+// absence. Like StorageAt, it only opens the account trie and the storage trie of target account.
+func (l *StateLedgerImpl) GetProof(blockHeader *types.BlockHeader, addr *types.Address, storageKeys [][]byte) (*AccountProof, error) {
+	if blockHeader.StateRoot == nil {
+		return nil, ErrorNilStateRoot
+	}
+	if err := l.checkHistoryRange(blockHeader.Number); err != nil {
+		return nil, err
+	}
+
+	accountTrie, err := jmt.New(blockHeader.StateRoot.ETHHash(), l.backend, l.accountTrieCache, l.pruneCache, l.logger)
+	if err != nil {
+		return nil, fmt.Errorf("load account trie of root %v: %w", blockHeader.StateRoot, err)
+	}
+	accountProof, err := l.proveKey(accountTrie, blockHeader.StateRoot.ETHHash(), utils.CompositeAccountKey(addr))
+	if err != nil {
+		return nil, fmt.Errorf("prove account %v: %w", addr, err)
+	}
+	res := &AccountProof{
+		Address:      addr,
+		AccountProof: accountProof.Proof,
+		Balance:      big.NewInt(0),
+		StorageProof: make([]*StorageProof, 0, len(storageKeys)),
+	}
+	if len(accountProof.Value) > 0 {
+		innerAccount := &types.InnerAccount{Balance: big.NewInt(0)}
+		if err := innerAccount.Unmarshal(accountProof.Value); err != nil {
+			return nil, err
+		}
+		res.Balance = innerAccount.Balance
+		res.Nonce = innerAccount.Nonce
+		res.CodeHash = innerAccount.CodeHash
+		res.StorageRoot = innerAccount.StorageRoot
+	}
+
+	var storageTrie *jmt.JMT
+	if res.StorageRoot != (common.Hash{}) {
+		storageTrie, err = jmt.New(res.StorageRoot, l.backend, l.storageTrieCache, l.pruneCache, l.logger)
+		if err != nil {
+			return nil, fmt.Errorf("load storage trie of root %v: %w", res.StorageRoot, err)
+		}
+	}
+	for _, key := range storageKeys {
+		// account without storage has no storage trie to prove against
+		if storageTrie == nil {
+			res.StorageProof = append(res.StorageProof, &StorageProof{Key: key})
+			continue
+		}
+		storageProof, err := l.proveKey(storageTrie, res.StorageRoot, utils.CompositeStorageKey(addr, key))
+		if err != nil {
+			return nil, fmt.Errorf("prove storage %x of account %v: %w", key, addr, err)
+		}
+		res.StorageProof = append(res.StorageProof, &StorageProof{Key: key, Value: storageProof.Value, Proof: storageProof.Proof})
+	}
+	return res, nil
+}
+
+// proveKey proves key by trie, jmt fails to prove an absent key, so the merkle path to the position of key is
+// collected instead, which can be checked by VerifyProof as a proof of absence.
+func (l *StateLedgerImpl) proveKey(trie *jmt.JMT, rootHash common.Hash, key []byte) (*jmt.ProofResult, error) {
+	proof, err := trie.Prove(key)
+	if !errors.Is(err, jmt.ErrorInvalidPath) {
+		return proof, err
+	}
+
+	proof = &jmt.ProofResult{Key: key}
+	rawRootNodeKey := l.backend.Get(rootHash[:])
+	if rawRootNodeKey == nil {
+		return nil, fmt.Errorf("trie root %v: %w", rootHash, ErrNotFound)
+	}
+	nk := types.DecodeNodeKey(rawRootNodeKey)
+	for next := 0; next <= len(key); next++ {
+		node, blob, err := l.getTrieNode(nk)
+		if err != nil {
+			return nil, err
+		}
+		proof.Proof = append(proof.Proof, blob)
+		internal, ok := node.(*types.InternalNode)
+		if !ok || next == len(key) || internal.Children[key[next]] == nil {
+			break
+		}
+		nk = &types.NodeKey{Version: internal.Children[key[next]].Version, Type: nk.Type, Path: key[:next+1]}
+	}
+	return proof, nil
+}
+
+// ContractStorageSize iterates the storage trie of addr at the state of target block, and returns the number of
+// storage entries and the total size of their keys and values. Account without storage returns zero.
+func (l *StateLedgerImpl) ContractStorageSize(blockHeader *types.BlockHeader, addr *types.Address) (uint64, uint64, error) {
+	storageRoot, err := l.storageRootAt(blockHeader, addr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if storageRoot == (common.Hash{}) {
+		return 0, 0, nil
+	}
+
+	var entries, size uint64
+	iter := jmt.NewIterator(storageRoot, l.backend, l.pruneCache, 10000, 300*time.Second)
+	go iter.IterateLeaf()
+	for {
+		node, err := iter.Next()
+		if err != nil {
+			if err == jmt.ErrorNoMoreData {
+				break
+			}
+			return 0, 0, fmt.Errorf("iterate storage trie of root %v: %w", storageRoot, err)
+		}
+		entries++
+		size += uint64(len(node.LeafKey) + len(node.LeafValue))
+	}
+	return entries, size, nil
+}
+
+// ListContracts returns the addresses of all contract accounts(accounts with storage) at the state of target block,
+// IterateContracts should be used instead for a large state.
+func (l *StateLedgerImpl) ListContracts(blockHeader *types.BlockHeader) ([]*types.Address, error) {
+	var contracts []*types.Address
+	err := l.IterateContracts(blockHeader, func(addr *types.Address) bool {
+		contracts = append(contracts, addr)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return contracts, nil
+}
+
+// IterateContracts iterates the leaves of the account trie at the state of target block and calls fn with the address
+// of every contract account(account with storage), the iteration stops once fn returns false.
+func (l *StateLedgerImpl) IterateContracts(blockHeader *types.BlockHeader, fn func(addr *types.Address) bool) error {
+	if blockHeader.StateRoot == nil {
+		return ErrorNilStateRoot
+	}
+	if err := l.checkHistoryRange(blockHeader.Number); err != nil {
+		return err
+	}
+
+	stateRoot := blockHeader.StateRoot.ETHHash()
+	iter := jmt.NewIterator(stateRoot, l.backend, l.pruneCache, 10000, 300*time.Second)
+	go iter.IterateLeaf()
+	for {
+		node, err := iter.Next()
+		if err != nil {
+			if err == jmt.ErrorNoMoreData {
+				return nil
+			}
+			return fmt.Errorf("iterate account trie of root %v: %w", stateRoot, err)
+		}
+		acc := &types.InnerAccount{Balance: big.NewInt(0)}
+		if err := acc.Unmarshal(node.LeafValue); err != nil {
+			return err
+		}
+		if acc.StorageRoot == (common.Hash{}) {
+			continue
+		}
+		if !fn(types.NewAddress(types.HexToBytes(node.LeafKey))) {
+			// the iterator may have finished already, in which case Stop panics, so drain it to let it exit
+			go drainIterator(iter)
+			return nil
+		}
+	}
+}
+
+func drainIterator(iter *jmt.Iterator) {
+	for {
+		if _, err := iter.Next(); err != nil {
+			return
+		}
+	}
+}
+
+// storageRootAt returns the storage root of addr at the state of target block, an empty hash is returned if
+// the account does not exist or has no storage.
+func (l *StateLedgerImpl) storageRootAt(blockHeader *types.BlockHeader, addr *types.Address) (common.Hash, error) {
+	if blockHeader.StateRoot == nil {
+		return common.Hash{}, ErrorNilStateRoot
+	}
+	if err := l.checkHistoryRange(blockHeader.Number); err != nil {
+		return common.Hash{}, err
+	}
+
+	accountTrie, err := jmt.New(blockHeader.StateRoot.ETHHash(), l.backend, l.accountTrieCache, l.pruneCache, l.logger)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("load account trie of root %v: %w", blockHeader.StateRoot, err)
+	}
+	rawAccount, err := accountTrie.Get(utils.CompositeAccountKey(addr))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if rawAccount == nil {
+		return common.Hash{}, nil
+	}
+
+	innerAccount := &types.InnerAccount{Balance: big.NewInt(0)}
+	if err := innerAccount.Unmarshal(rawAccount); err != nil {
+		return common.Hash{}, err
+	}
+	return innerAccount.StorageRoot, nil
+}
+
 func newStateLedger(rep *repo.Repo, stateStorage, snapshotStorage kv.Storage) (StateLedger, error) {
 	stateCachedStorage := storagemgr.NewCachedStorage(stateStorage, 128).(*storagemgr.CachedStorage)
-	accountTrieCache := storagemgr.NewCacheWrapper(rep.Config.Ledger.StateLedgerAccountTrieCacheMegabytesLimit, true)
-	storageTrieCache := storagemgr.NewCacheWrapper(rep.Config.Ledger.StateLedgerStorageTrieCacheMegabytesLimit, true)
+	accountTrieCache, err := storagemgr.NewCacheWrapperWithPolicy(rep.Config.Ledger.StateLedgerAccountTrieCacheMegabytesLimit, rep.Config.Ledger.StateLedgerTrieCachePolicy, true)
+	if err != nil {
+		return nil, fmt.Errorf("create account trie cache: %w", err)
+	}
+	storageTrieCache, err := storagemgr.NewCacheWrapperWithPolicy(rep.Config.Ledger.StateLedgerStorageTrieCacheMegabytesLimit, rep.Config.Ledger.StateLedgerTrieCachePolicy, true)
+	if err != nil {
+		return nil, fmt.Errorf("create storage trie cache: %w", err)
+	}
 
 	trieIndexerKv, err := storagemgr.OpenWithMetrics(repo.GetStoragePath(rep.RepoRoot, storagemgr.TrieIndexer), storagemgr.TrieIndexer)
 	if err != nil {
@@ -372,13 +996,19 @@ func newStateLedger(rep *repo.Repo, stateStorage, snapshotStorage kv.Storage) (S
 		changer:          newChanger(),
 		accessList:       NewAccessList(),
 		logs:             newEvmLogs(),
+		commitLock:       &sync.RWMutex{},
+	}
+	if limit := rep.Config.Ledger.MaxConcurrentViews; limit > 0 {
+		ledger.viewLimiter = make(chan struct{}, limit)
 	}
 
 	if snapshotStorage != nil {
 		ledger.snapshot = snapshot.NewSnapshot(rep, snapshotStorage, ledger.logger)
 	}
 
-	ledger.refreshAccountTrie(nil)
+	if err := ledger.refreshAccountTrie(nil); err != nil {
+		return nil, err
+	}
 
 	return ledger, nil
 }
@@ -406,6 +1036,43 @@ func NewStateLedger(rep *repo.Repo, storageDir string) (StateLedger, error) {
 	return newStateLedger(rep, stateStorage, snapshotStorage)
 }
 
+// DisableSnapshot detaches the snapshot at runtime, all state reads go through the trie afterwards.
+// Views and accounts created before still hold the old snapshot, but it is detached and reads from it fall back to trie.
+// It is safe to be called concurrently with NewView, but it must be called when no block is executing.
+func (l *StateLedgerImpl) DisableSnapshot() {
+	l.snapshotLock.Lock()
+	defer l.snapshotLock.Unlock()
+	if l.snapshot == nil {
+		return
+	}
+	l.snapshot.Detach()
+	l.snapshot = nil
+	l.logger.Infof("[DisableSnapshot] snapshot is disabled at height %v", l.blockHeight)
+}
+
+// EnableSnapshot attaches the snapshot in storage at runtime, the snapshot must be up to date with the latest
+// committed block, a stale one is rejected. It must be called when no block is executing.
+func (l *StateLedgerImpl) EnableSnapshot(storage kv.Storage) error {
+	snap := snapshot.NewSnapshot(l.repo, storage, l.logger)
+	if _, maxHeight := snap.GetJournalRange(); maxHeight != l.blockHeight {
+		return fmt.Errorf("snapshot height %d is inconsistent with ledger height %d", maxHeight, l.blockHeight)
+	}
+	l.snapshotLock.Lock()
+	defer l.snapshotLock.Unlock()
+	if l.snapshot != nil {
+		l.snapshot.Detach()
+	}
+	l.snapshot = snap
+	l.logger.Infof("[EnableSnapshot] snapshot is enabled at height %v", l.blockHeight)
+	return nil
+}
+
+func (l *StateLedgerImpl) getSnapshot() *snapshot.Snapshot {
+	l.snapshotLock.RLock()
+	defer l.snapshotLock.RUnlock()
+	return l.snapshot
+}
+
 func (l *StateLedgerImpl) SetTxContext(thash *types.Hash, ti int) {
 	l.thash = thash
 	l.txIndex = ti
@@ -2,6 +2,7 @@ package ledger
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/prometheus/client_golang/prometheus"
@@ -12,8 +13,8 @@ import (
 )
 
 var (
-	triePreloadHitCountPerBlock  int
-	triePreloadMissCountPerBlock int
+	triePreloadHitCountPerBlock  atomic.Int64
+	triePreloadMissCountPerBlock atomic.Int64
 
 	triePreloadHitCounterPerBlock = prometheus.NewGauge(prometheus.GaugeOpts{
 		Namespace: "axiom_ledger",
@@ -43,13 +44,13 @@ func init() {
 }
 
 func ExportTriePreloaderMetrics() {
-	triePreloadHitCounterPerBlock.Set(float64(triePreloadHitCountPerBlock))
-	triePreloadMissCounterPerBlock.Set(float64(triePreloadMissCountPerBlock))
+	triePreloadHitCounterPerBlock.Set(float64(triePreloadHitCountPerBlock.Load()))
+	triePreloadMissCounterPerBlock.Set(float64(triePreloadMissCountPerBlock.Load()))
 }
 
 func ResetTriePreloaderMetrics() {
-	triePreloadHitCountPerBlock = 0
-	triePreloadMissCountPerBlock = 0
+	triePreloadHitCountPerBlock.Store(0)
+	triePreloadMissCountPerBlock.Store(0)
 }
 
 // triePreloaderManager manage lifecycle of all trie preloaders
@@ -59,17 +60,24 @@ type triePreloaderManager struct {
 	pruneCache jmt.PruneCache
 	trieCache  jmt.TrieCache
 
+	// number of goroutines loading keys of a single trie
+	workers int
+
 	loaders map[string]*triePreloader
 
 	wg sync.WaitGroup
 }
 
-func newTriePreloaderManager(logger logrus.FieldLogger, db kv.Storage, trieCache jmt.TrieCache, pruneCache jmt.PruneCache) *triePreloaderManager {
+func newTriePreloaderManager(logger logrus.FieldLogger, db kv.Storage, trieCache jmt.TrieCache, pruneCache jmt.PruneCache, workers int) *triePreloaderManager {
+	if workers < 1 {
+		workers = 1
+	}
 	return &triePreloaderManager{
 		logger:     logger,
 		db:         db,
 		pruneCache: pruneCache,
 		trieCache:  trieCache,
+		workers:    workers,
 		loaders:    make(map[string]*triePreloader),
 		wg:         sync.WaitGroup{},
 	}
@@ -100,7 +108,7 @@ func (tp *triePreloaderManager) preload(rootHash common.Hash, keys [][]byte) {
 	}
 	loader := tp.loaders[trieKey]
 	if loader == nil {
-		loader = newPreloader(tp.logger, tp.db, tp.trieCache, tp.pruneCache, rootHash, &tp.wg)
+		loader = newPreloader(tp.logger, tp.db, tp.trieCache, tp.pruneCache, rootHash, tp.workers, &tp.wg)
 		tp.loaders[trieKey] = loader
 		tp.wg.Add(1)
 		go loader.loop()
@@ -130,20 +138,24 @@ type triePreloader struct {
 	rootHash    common.Hash
 	trie        *jmt.JMT
 	preloadKeys [][]byte
-	cached      map[string]struct{}
+	workers     int
+
+	cachedLock sync.Mutex
+	cached     map[string]struct{}
 
 	lock sync.Mutex
 	wg   *sync.WaitGroup
 	wake chan struct{}
 }
 
-func newPreloader(logger logrus.FieldLogger, db kv.Storage, trieCache jmt.TrieCache, pruneCache jmt.PruneCache, rootHash common.Hash, wg *sync.WaitGroup) *triePreloader {
+func newPreloader(logger logrus.FieldLogger, db kv.Storage, trieCache jmt.TrieCache, pruneCache jmt.PruneCache, rootHash common.Hash, workers int, wg *sync.WaitGroup) *triePreloader {
 	sp := preloaderPool.Get().(*triePreloader)
 	sp.logger = logger
 	sp.db = db
 	sp.rootHash = rootHash
 	sp.pruneCache = pruneCache
 	sp.trieCache = trieCache
+	sp.workers = workers
 	sp.wg = wg
 
 	sp.wake = make(chan struct{}, 1)
@@ -165,6 +177,7 @@ func (loader *triePreloader) reset() {
 	loader.rootHash = common.Hash{}
 	loader.trie = nil
 	loader.preloadKeys = loader.preloadKeys[:0]
+	loader.workers = 0
 	loader.wake = make(chan struct{}, 1)
 	loader.cached = make(map[string]struct{})
 	loader.wg = nil
@@ -188,21 +201,48 @@ func (loader *triePreloader) loop() {
 		loader.preloadKeys = nil
 		loader.lock.Unlock()
 
-		for _, key := range preloadKeys {
-			if _, ok := loader.cached[string(key)]; !ok {
-				// haven't preload
-				_, err := loader.trie.Get(key)
-				if err != nil {
-					loader.logger.Errorf("Load trie node error, key: %s", key)
-					continue
-				}
-				loader.cached[string(key)] = struct{}{}
-				triePreloadMissCountPerBlock++
-			} else {
-				triePreloadHitCountPerBlock++
-			}
+		workers := min(loader.workers, len(preloadKeys))
+		if workers <= 1 {
+			loader.load(preloadKeys)
+			return
 		}
 
+		// split keys into contiguous chunks, trie.Get is read only and safe to be called concurrently
+		var wg sync.WaitGroup
+		chunkSize := (len(preloadKeys) + workers - 1) / workers
+		for start := 0; start < len(preloadKeys); start += chunkSize {
+			end := min(start+chunkSize, len(preloadKeys))
+			wg.Add(1)
+			go func(keys [][]byte) {
+				defer wg.Done()
+				loader.load(keys)
+			}(preloadKeys[start:end])
+		}
+		wg.Wait()
+
 		return
 	}
 }
+
+func (loader *triePreloader) load(keys [][]byte) {
+	for _, key := range keys {
+		loader.cachedLock.Lock()
+		_, ok := loader.cached[string(key)]
+		loader.cachedLock.Unlock()
+		if ok {
+			triePreloadHitCountPerBlock.Add(1)
+			continue
+		}
+
+		// haven't preload
+		_, err := loader.trie.Get(key)
+		if err != nil {
+			loader.logger.Errorf("Load trie node error, key: %s", key)
+			continue
+		}
+		loader.cachedLock.Lock()
+		loader.cached[string(key)] = struct{}{}
+		loader.cachedLock.Unlock()
+		triePreloadMissCountPerBlock.Add(1)
+	}
+}
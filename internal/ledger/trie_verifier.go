@@ -0,0 +1,98 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/axiomesh/axiom-kit/types"
+)
+
+// TrieVerifier verifies the state trie of the latest block periodically in background, so that a silently corrupted
+// state is detected before it is propagated. Verifications never overlap, the next one is scheduled after the
+// previous one finishes, which bounds the extra IO of walking the whole trie. With samples > 0 only that many random
+// root-to-leaf paths are checked each time.
+type TrieVerifier struct {
+	ledger    *Ledger
+	interval  time.Duration
+	samples   int
+	logger    logrus.FieldLogger
+	onFailure func(header *types.BlockHeader, err error)
+}
+
+func NewTrieVerifier(ledger *Ledger, interval time.Duration, samples int, logger logrus.FieldLogger, onFailure func(header *types.BlockHeader, err error)) *TrieVerifier {
+	return &TrieVerifier{
+		ledger:    ledger,
+		interval:  interval,
+		samples:   samples,
+		logger:    logger,
+		onFailure: onFailure,
+	}
+}
+
+// Run verifies the state trie every interval until ctx is done
+func (v *TrieVerifier) Run(ctx context.Context) {
+	t := time.NewTimer(v.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			v.verifyLatest()
+			t.Reset(v.interval)
+		}
+	}
+}
+
+// verifyLatest verifies the state trie of the latest block, it returns false if the trie is corrupted
+func (v *TrieVerifier) verifyLatest() bool {
+	height := v.ledger.ChainLedger.GetChainMeta().Height
+	header, err := v.ledger.ChainLedger.GetBlockHeader(height)
+	if err != nil {
+		v.logger.WithFields(logrus.Fields{
+			"height": height,
+			"err":    err,
+		}).Warn("Get block header for trie verification failed")
+		return true
+	}
+
+	start := time.Now()
+	var verified bool
+	if v.samples > 0 {
+		verified, err = v.ledger.StateLedger.VerifyTrieSampled(header, v.samples)
+	} else {
+		verified, err = v.ledger.StateLedger.VerifyTrie(header)
+	}
+	if !verified || err != nil {
+		// the state may be pruned during a long verification, which is not a corruption
+		if minHeight, _ := v.ledger.StateLedger.GetHistoryRange(); height < minHeight {
+			v.logger.WithField("height", height).Info("State is pruned during trie verification, skip")
+			return true
+		}
+		if err == nil {
+			err = errors.New("state root mismatch")
+		}
+		trieVerifyFailureCounter.Inc()
+		v.logger.WithFields(logrus.Fields{
+			"height":     height,
+			"state_root": header.StateRoot,
+			"err":        err,
+		}).Error("State trie verification failed, the state storage may be corrupted")
+		if v.onFailure != nil {
+			v.onFailure(header, err)
+		}
+		return false
+	}
+
+	trieLastVerifiedHeight.Set(float64(height))
+	v.logger.WithFields(logrus.Fields{
+		"height":  height,
+		"samples": v.samples,
+		"cost":    time.Since(start),
+	}).Info("State trie verified")
+	return true
+}
@@ -21,6 +21,8 @@ const (
 	RollbackBlockKey   = "rollback-block"
 	RollbackStateKey   = "rollback-state"
 	TrieNodeIndexKey   = "tni-"
+
+	IterateTrieProgressKey = "iterate-trie-progress-"
 )
 
 const (
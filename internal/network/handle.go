@@ -16,6 +16,14 @@ func (swarm *networkImpl) handleMessage(s network.Stream, data []byte) {
 		return
 	}
 
+	if !swarm.limiter.Allow(m.Type) {
+		// rate limit exceeded, refuse to handle the message
+		swarm.logger.WithFields(logrus.Fields{
+			"type": m.Type.String(),
+		}).Warn("Received too many messages, rate limiting in effect")
+		return
+	}
+
 	handler, ok := swarm.msgHandlers.Load(m.Type)
 	if !ok {
 		swarm.logger.WithFields(logrus.Fields{
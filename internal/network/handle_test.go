@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/axiomesh/axiom-kit/types/pb"
+	"github.com/axiomesh/axiom-ledger/pkg/repo"
 	p2p "github.com/axiomesh/axiom-p2p"
 )
 
@@ -55,4 +56,32 @@ func TestHandleMessage(t *testing.T) {
 		swarms[0].handleMessage(nil, req)
 		require.Equal(t, 0, len(stateResponse), "should not return response with wrong message type")
 	})
+
+	t.Run("handle message over rate limit", func(t *testing.T) {
+		limiter, err := newMsgLimiter(repo.P2PLimiter{
+			Enable: true,
+			Limit:  0,
+			Burst:  0,
+			MsgTypes: map[string]repo.P2PMsgLimit{
+				"SYNC_STATE_REQUEST": {Limit: 0, Burst: 1},
+			},
+		})
+		require.Nil(t, err)
+		swarms[1].limiter = limiter
+		defer func() { swarms[1].limiter = nil }()
+
+		msg := &pb.Message{
+			Type: pb.Message_SYNC_STATE_REQUEST,
+			Data: []byte("request aaa"),
+		}
+		req, err := msg.MarshalVT()
+		require.Nil(t, err)
+		go swarms[1].handleMessage(nil, req)
+		<-doneCh
+		require.Equal(t, 1, len(stateResponse))
+
+		// the bucket of message type is used up
+		swarms[1].handleMessage(nil, req)
+		require.Equal(t, 1, len(stateResponse))
+	})
 }
@@ -0,0 +1,89 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+
+	"github.com/axiomesh/axiom-kit/types/pb"
+	"github.com/axiomesh/axiom-ledger/pkg/repo"
+	network "github.com/axiomesh/axiom-p2p"
+)
+
+// msgLimiter limits received network messages with a token bucket per message type,
+// types without override share the global bucket. Messages received by pipes(e.g. block sync)
+// bypass handleMessage, so they are limited by the bucket of their pipe.
+type msgLimiter struct {
+	global *rate.Limiter
+	typed  map[pb.Message_Type]*rate.Limiter
+	pipes  map[string]*rate.Limiter
+}
+
+func newMsgLimiter(cnf repo.P2PLimiter) (*msgLimiter, error) {
+	if !cnf.Enable {
+		return nil, nil
+	}
+	l := &msgLimiter{
+		global: rate.NewLimiter(rate.Limit(cnf.Limit), int(cnf.Burst)),
+		typed:  make(map[pb.Message_Type]*rate.Limiter, len(cnf.MsgTypes)),
+		pipes:  make(map[string]*rate.Limiter, len(cnf.Pipes)),
+	}
+	for name, limit := range cnf.MsgTypes {
+		// viper lowercases map keys
+		typ, ok := pb.Message_Type_value[strings.ToUpper(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown message type %s in p2p.limiter.msg_types", name)
+		}
+		l.typed[pb.Message_Type(typ)] = rate.NewLimiter(rate.Limit(limit.Limit), int(limit.Burst))
+	}
+	for pipeID, limit := range cnf.Pipes {
+		l.pipes[strings.ToLower(pipeID)] = rate.NewLimiter(rate.Limit(limit.Limit), int(limit.Burst))
+	}
+	return l, nil
+}
+
+// wrapPipe limits the messages received by the pipe if it is listed in p2p.limiter.pipes
+func (l *msgLimiter) wrapPipe(pipeID string, pipe network.Pipe, logger logrus.FieldLogger) network.Pipe {
+	if l == nil {
+		return pipe
+	}
+	limiter, ok := l.pipes[strings.ToLower(pipeID)]
+	if !ok {
+		return pipe
+	}
+	return &limitedPipe{Pipe: pipe, limiter: limiter, logger: logger}
+}
+
+// Allow reports whether a message of typ may be handled now, nil limiter allows all messages
+func (l *msgLimiter) Allow(typ pb.Message_Type) bool {
+	if l == nil {
+		return true
+	}
+	if limiter, ok := l.typed[typ]; ok {
+		return limiter.Allow()
+	}
+	return l.global.Allow()
+}
+
+// limitedPipe drops the received messages beyond the rate of its bucket, the sender retries on timeout
+type limitedPipe struct {
+	network.Pipe
+	limiter *rate.Limiter
+	logger  logrus.FieldLogger
+}
+
+func (p *limitedPipe) Receive(ctx context.Context) *network.PipeMsg {
+	for {
+		msg := p.Pipe.Receive(ctx)
+		if msg == nil || p.limiter.Allow() {
+			return msg
+		}
+		p.logger.WithFields(logrus.Fields{
+			"pipe": p.Pipe.String(),
+			"from": msg.From,
+		}).Warn("Received too many pipe messages, rate limiting in effect")
+	}
+}
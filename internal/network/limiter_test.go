@@ -0,0 +1,114 @@
+package network
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/axiomesh/axiom-kit/log"
+	"github.com/axiomesh/axiom-kit/types/pb"
+	"github.com/axiomesh/axiom-ledger/pkg/repo"
+	network "github.com/axiomesh/axiom-p2p"
+)
+
+func TestMsgLimiter(t *testing.T) {
+	// disabled
+	l, err := newMsgLimiter(repo.P2PLimiter{Enable: false, Limit: 1, Burst: 1})
+	require.Nil(t, err)
+	require.Nil(t, l)
+	require.True(t, l.Allow(pb.Message_SYNC_BLOCK_REQUEST))
+
+	_, err = newMsgLimiter(repo.P2PLimiter{
+		Enable:   true,
+		MsgTypes: map[string]repo.P2PMsgLimit{"push_txs": {Limit: 1, Burst: 1}},
+	})
+	require.ErrorContains(t, err, "push_txs")
+
+	l, err = newMsgLimiter(repo.P2PLimiter{
+		Enable: true,
+		Limit:  1,
+		Burst:  1,
+		MsgTypes: map[string]repo.P2PMsgLimit{
+			// viper lowercases map keys
+			"sync_state_response": {Limit: 1, Burst: 3},
+		},
+	})
+	require.Nil(t, err)
+
+	// fallback to global bucket
+	require.True(t, l.Allow(pb.Message_SYNC_STATE_REQUEST))
+	require.False(t, l.Allow(pb.Message_SYNC_STATE_REQUEST))
+	require.False(t, l.Allow(pb.Message_FETCH_EPOCH_STATE_REQUEST))
+
+	// typed bucket is independent of global bucket
+	for i := 0; i < 3; i++ {
+		require.True(t, l.Allow(pb.Message_SYNC_STATE_RESPONSE))
+	}
+	require.False(t, l.Allow(pb.Message_SYNC_STATE_RESPONSE))
+}
+
+// queuePipe receives the queued messages in order, then nil
+type queuePipe struct {
+	network.Pipe
+	msgs []*network.PipeMsg
+}
+
+func (p *queuePipe) String() string {
+	return "queue"
+}
+
+func (p *queuePipe) Receive(ctx context.Context) *network.PipeMsg {
+	if len(p.msgs) == 0 {
+		return nil
+	}
+	msg := p.msgs[0]
+	p.msgs = p.msgs[1:]
+	return msg
+}
+
+func TestMsgLimiter_Pipe(t *testing.T) {
+	l, err := newMsgLimiter(repo.P2PLimiter{
+		Enable: true,
+		Limit:  10000,
+		Burst:  10000,
+		Pipes: map[string]repo.P2PMsgLimit{
+			"sync_block_pipe_v1_response": {Limit: 1, Burst: 2},
+		},
+	})
+	require.Nil(t, err)
+
+	newSyncResponses := func() *queuePipe {
+		p := &queuePipe{}
+		for i := 0; i < 3; i++ {
+			data, err := (&pb.Message{Type: pb.Message_SYNC_BLOCK_RESPONSE, Data: []byte{byte(i)}}).MarshalVT()
+			require.Nil(t, err)
+			p.msgs = append(p.msgs, &network.PipeMsg{From: "peer", Data: data})
+		}
+		return p
+	}
+	logger := log.NewWithModule("network")
+
+	// pipes not listed are not limited
+	unlimited := l.wrapPipe("sync_chain_data_pipe_v1_response", newSyncResponses(), logger)
+	for i := 0; i < 3; i++ {
+		require.NotNil(t, unlimited.Receive(context.Background()))
+	}
+
+	// sync responses beyond the burst are dropped
+	limited := l.wrapPipe("sync_block_pipe_v1_response", newSyncResponses(), logger)
+	for i := 0; i < 2; i++ {
+		msg := limited.Receive(context.Background())
+		require.NotNil(t, msg)
+		m := &pb.Message{}
+		require.Nil(t, m.UnmarshalVT(msg.Data))
+		require.Equal(t, pb.Message_SYNC_BLOCK_RESPONSE, m.Type)
+		require.Equal(t, []byte{byte(i)}, m.Data)
+	}
+	require.Nil(t, limited.Receive(context.Background()))
+
+	// nil limiter does not wrap
+	var disabled *msgLimiter
+	p := newSyncResponses()
+	require.Equal(t, network.Pipe(p), disabled.wrapPipe("sync_block_pipe_v1_response", p, logger))
+}
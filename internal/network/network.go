@@ -69,6 +69,9 @@ type networkImpl struct {
 	gater  connmgr.ConnectionGater
 	network.PipeManager
 
+	// limiter limits the messages handled by handleMessage, nil means unlimited
+	limiter *msgLimiter
+
 	msgHandlers sync.Map // map[pb.Message_Type]MessageHandler
 }
 
@@ -76,6 +79,15 @@ func New(repoConfig *repo.Repo, logger logrus.FieldLogger) (Network, error) {
 	return newNetworkImpl(repoConfig, logger)
 }
 
+// CreatePipe creates the pipe of pipeID, whose received messages are limited if it is listed in p2p.limiter.pipes
+func (swarm *networkImpl) CreatePipe(ctx context.Context, pipeID string) (network.Pipe, error) {
+	pipe, err := swarm.PipeManager.CreatePipe(ctx, pipeID)
+	if err != nil {
+		return nil, err
+	}
+	return swarm.limiter.wrapPipe(pipeID, pipe, swarm.logger), nil
+}
+
 func newNetworkImpl(rep *repo.Repo, logger logrus.FieldLogger) (*networkImpl, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	swarm := &networkImpl{repo: rep, logger: logger, ctx: ctx, cancel: cancel}
@@ -95,6 +107,12 @@ func (swarm *networkImpl) init() error {
 		}
 	}
 
+	limiter, err := newMsgLimiter(swarm.repo.Config.P2P.Limiter)
+	if err != nil {
+		return err
+	}
+	swarm.limiter = limiter
+
 	var securityType network.SecurityType
 	switch swarm.repo.Config.P2P.Security {
 	case repo.P2PSecurityTLS:
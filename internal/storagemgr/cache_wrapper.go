@@ -1,11 +1,15 @@
 package storagemgr
 
 import (
+	"fmt"
+
 	"github.com/VictoriaMetrics/fastcache"
+
+	"github.com/axiomesh/axiom-ledger/pkg/repo"
 )
 
 type CacheWrapper struct {
-	cache *fastcache.Cache
+	cache cacheBackend
 
 	metrics *CacheMetrics
 
@@ -20,15 +24,34 @@ type CacheMetrics struct {
 }
 
 func NewCacheWrapper(megabytesLimit int, enableMetric bool) *CacheWrapper {
+	c, _ := NewCacheWrapperWithPolicy(megabytesLimit, repo.CachePolicyFastcache, enableMetric)
+	return c
+}
+
+// NewCacheWrapperWithPolicy creates a cache wrapper with the given eviction policy (fastcache, lru or lfu).
+func NewCacheWrapperWithPolicy(megabytesLimit int, policy string, enableMetric bool) (*CacheWrapper, error) {
 	if megabytesLimit <= 0 {
 		megabytesLimit = 128
 	}
+	maxBytes := megabytesLimit * 1024 * 1024
+
+	var cache cacheBackend
+	switch policy {
+	case repo.CachePolicyFastcache, "":
+		cache = fastcache.New(maxBytes)
+	case repo.CachePolicyLRU:
+		cache = newLRUCache(maxBytes)
+	case repo.CachePolicyLFU:
+		cache = newLFUCache(maxBytes)
+	default:
+		return nil, fmt.Errorf("unknown cache policy %s, expect fastcache, lru or lfu", policy)
+	}
 
 	return &CacheWrapper{
-		cache:        fastcache.New(megabytesLimit * 1024 * 1024),
+		cache:        cache,
 		metrics:      &CacheMetrics{},
 		enableMetric: enableMetric,
-	}
+	}, nil
 }
 
 func (c *CacheWrapper) ResetCounterMetrics() {
@@ -1,12 +1,119 @@
 package storagemgr
 
 import (
+	"encoding/binary"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/require"
 	"golang.org/x/exp/rand"
+
+	"github.com/axiomesh/axiom-ledger/pkg/repo"
 )
 
+func TestCacheWrapperPolicy(t *testing.T) {
+	_, err := NewCacheWrapperWithPolicy(1, "unknown", false)
+	require.NotNil(t, err)
+
+	for _, policy := range []string{repo.CachePolicyFastcache, repo.CachePolicyLRU, repo.CachePolicyLFU} {
+		t.Run(policy, func(t *testing.T) {
+			c, err := NewCacheWrapperWithPolicy(1, policy, true)
+			require.Nil(t, err)
+
+			c.Set([]byte("k1"), []byte("v1"))
+			v, ok := c.Get([]byte("k1"))
+			require.True(t, ok)
+			require.Equal(t, []byte("v1"), v)
+			require.True(t, c.Has([]byte("k1")))
+
+			c.Del([]byte("k1"))
+			_, ok = c.Get([]byte("k1"))
+			require.False(t, ok)
+
+			c.Set([]byte("k2"), []byte("v2"))
+			c.Reset()
+			require.False(t, c.Has([]byte("k2")))
+		})
+	}
+}
+
+func TestLRUCacheEviction(t *testing.T) {
+	c := newLRUCache(8)
+	c.Set([]byte("a"), []byte("111"))
+	c.Set([]byte("b"), []byte("222"))
+	// touch a, so b is the least recently used one
+	_, ok := c.HasGet(nil, []byte("a"))
+	require.True(t, ok)
+	c.Set([]byte("c"), []byte("333"))
+	require.True(t, c.Has([]byte("a")))
+	require.False(t, c.Has([]byte("b")))
+	require.True(t, c.Has([]byte("c")))
+
+	// too large entry will be ignored
+	c.Set([]byte("d"), []byte("123456789"))
+	require.False(t, c.Has([]byte("d")))
+}
+
+func TestLFUCacheEviction(t *testing.T) {
+	c := newLFUCache(8)
+	c.Set([]byte("a"), []byte("111"))
+	c.Set([]byte("b"), []byte("222"))
+	for i := 0; i < 3; i++ {
+		_, ok := c.HasGet(nil, []byte("b"))
+		require.True(t, ok)
+	}
+	_, ok := c.HasGet(nil, []byte("a"))
+	require.True(t, ok)
+	// a is more recently used but less frequently used than b
+	c.Set([]byte("c"), []byte("333"))
+	require.False(t, c.Has([]byte("a")))
+	require.True(t, c.Has([]byte("b")))
+	require.True(t, c.Has([]byte("c")))
+
+	c.Del([]byte("c"))
+	c.Set([]byte("d"), []byte("444"))
+	c.Set([]byte("e"), []byte("555"))
+	require.True(t, c.Has([]byte("b")))
+	require.False(t, c.Has([]byte("d")))
+	require.True(t, c.Has([]byte("e")))
+}
+
+// BenchmarkCacheWrapperPolicyHitRate compares hit rates of different policies
+// under a skewed (zipf) access distribution, which simulates hot contracts with a long tail.
+func BenchmarkCacheWrapperPolicyHitRate(b *testing.B) {
+	const (
+		keyNum    = 1 << 20
+		valueSize = 100
+	)
+	value := make([]byte, valueSize)
+
+	for _, policy := range []string{repo.CachePolicyFastcache, repo.CachePolicyLRU, repo.CachePolicyLFU} {
+		b.Run(policy, func(b *testing.B) {
+			// cache can only hold a small part of keys
+			c, err := NewCacheWrapperWithPolicy(16, policy, true)
+			require.Nil(b, err)
+			zipf := rand.NewZipf(rand.New(rand.NewSource(1)), 1.1, 1, keyNum-1)
+			key := make([]byte, 8)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				binary.BigEndian.PutUint64(key, zipf.Uint64())
+				if _, ok := c.Get(key); !ok {
+					c.Set(key, value)
+				}
+			}
+			b.StopTimer()
+
+			metrics := c.ExportMetrics()
+			total := metrics.CacheHitCounter + metrics.CacheMissCounter
+			if total > 0 {
+				b.ReportMetric(float64(metrics.CacheHitCounter)/float64(total)*100, "hit%")
+			}
+			b.Logf("policy=%s, hit=%d, miss=%d", policy, metrics.CacheHitCounter, metrics.CacheMissCounter)
+		})
+	}
+}
+
 func TestCacheWrapperSpaceAmplify(t *testing.T) {
 	// size := atomic.Uint64{}
 	// cache := NewCacheWrapper(1024, true)
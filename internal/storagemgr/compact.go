@@ -0,0 +1,114 @@
+package storagemgr
+
+import (
+	"bytes"
+
+	pebbledb "github.com/cockroachdb/pebble"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/axiomesh/axiom-ledger/pkg/repo"
+)
+
+var ErrCompactionNotSupported = errors.New("compaction is not supported")
+
+// compactor is implemented by the kv storage which supports manual compaction while it is opened
+type compactor interface {
+	Compact(start, end []byte) error
+}
+
+// Compact manually compacts the key range [start, end) of the storage at path to reclaim space of deleted keys,
+// the full range is compacted if start or end is nil.
+// An unopened storage is opened exclusively by the default kv type and closed after compaction,
+// an opened storage is compacted only if its backend supports compaction while it is opened.
+func Compact(path string, start, end []byte) (err error) {
+	c, closeFn, err := openCompactor(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := closeFn(); err == nil {
+			err = closeErr
+		}
+	}()
+	return c.Compact(start, end)
+}
+
+// openCompactor resolves the compactor of path under the global lock, the lock is released before compaction
+// so that other storages can be opened or closed meanwhile. The returned function closes the storage opened
+// for compaction.
+func openCompactor(path string) (compactor, func() error, error) {
+	globalStorageMgr.lock.Lock()
+	defer globalStorageMgr.lock.Unlock()
+
+	if s, ok := globalStorageMgr.storages[path]; ok {
+		c, ok := s.(compactor)
+		if !ok {
+			return nil, nil, errors.Wrapf(ErrCompactionNotSupported, "storage %s is in use", path)
+		}
+		return c, func() error { return nil }, nil
+	}
+
+	if err := checkStorageEngine(globalStorageMgr.defaultKVType, path); err != nil {
+		return nil, nil, err
+	}
+	switch globalStorageMgr.defaultKVType {
+	case repo.KVStorageTypePebble:
+		opts := defaultPebbleOptions.Clone()
+		opts.ErrorIfNotExists = true
+		if err := setPebbleWALOptions(opts, path); err != nil {
+			return nil, nil, err
+		}
+		db, err := pebbledb.Open(path, opts)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to open pebble %s", path)
+		}
+		return &pebbleCompactor{db: db}, db.Close, nil
+	case repo.KVStorageTypeLeveldb:
+		db, err := leveldb.OpenFile(path, &opt.Options{ErrorIfMissing: true})
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to open leveldb %s", path)
+		}
+		return &leveldbCompactor{db: db}, db.Close, nil
+	default:
+		return nil, nil, errors.Wrapf(ErrCompactionNotSupported, "kv type %q", globalStorageMgr.defaultKVType)
+	}
+}
+
+type pebbleCompactor struct {
+	db *pebbledb.DB
+}
+
+func (c *pebbleCompactor) Compact(start, end []byte) error {
+	if end == nil {
+		// pebble requires an explicit upper bound, use the successor of the last key
+		it, err := c.db.NewIter(nil)
+		if err != nil {
+			return err
+		}
+		if it.Last() {
+			end = append(bytes.Clone(it.Key()), 0)
+		}
+		if err := it.Close(); err != nil {
+			return err
+		}
+		if end == nil {
+			// empty db
+			return nil
+		}
+	}
+	if bytes.Compare(start, end) >= 0 {
+		return nil
+	}
+	return c.db.Compact(start, end, true)
+}
+
+type leveldbCompactor struct {
+	db *leveldb.DB
+}
+
+func (c *leveldbCompactor) Compact(start, end []byte) error {
+	return c.db.CompactRange(util.Range{Start: start, Limit: end})
+}
@@ -0,0 +1,74 @@
+package storagemgr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/axiomesh/axiom-ledger/pkg/repo"
+)
+
+// engineFileName records the kv engine which created the storage directory
+const engineFileName = "KV_ENGINE"
+
+var ErrStorageEngineMismatch = errors.New("storage engine mismatch")
+
+// checkStorageEngine returns ErrStorageEngineMismatch if the storage at p was created by another kv engine,
+// a new or empty directory passes the check.
+func checkStorageEngine(typ string, p string) error {
+	existing, err := detectStorageEngine(p)
+	if err != nil {
+		return err
+	}
+	if existing != "" && existing != typ {
+		return errors.Wrapf(ErrStorageEngineMismatch, "storage %s is created by %s, but configured kv type is %s", p, existing, typ)
+	}
+	return nil
+}
+
+// detectStorageEngine reads the engine file of p, storages created before the engine file was introduced
+// are detected by the on-disk layout: pebble writes OPTIONS files while leveldb does not.
+func detectStorageEngine(p string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(p, engineFileName))
+	if err == nil {
+		return strings.TrimSpace(string(data)), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", errors.Wrapf(err, "failed to read engine file of storage %s", p)
+	}
+
+	entries, err := os.ReadDir(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "failed to read storage dir %s", p)
+	}
+	hasCurrent := false
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), "OPTIONS-") {
+			return repo.KVStorageTypePebble, nil
+		}
+		if e.Name() == "CURRENT" {
+			hasCurrent = true
+		}
+	}
+	if hasCurrent {
+		return repo.KVStorageTypeLeveldb, nil
+	}
+	return "", nil
+}
+
+// writeStorageEngine records typ as the engine of the storage at p, it should be called after the storage is opened
+func writeStorageEngine(typ string, p string) error {
+	enginePath := filepath.Join(p, engineFileName)
+	if _, err := os.Stat(enginePath); err == nil {
+		return nil
+	}
+	if err := os.WriteFile(enginePath, []byte(typ), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write engine file of storage %s", p)
+	}
+	return nil
+}
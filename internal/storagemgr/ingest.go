@@ -0,0 +1,62 @@
+package storagemgr
+
+import (
+	pebbledb "github.com/cockroachdb/pebble"
+	"github.com/pkg/errors"
+
+	"github.com/axiomesh/axiom-ledger/pkg/repo"
+)
+
+var ErrIngestNotSupported = errors.New("sst ingestion is not supported")
+
+// IngestSST atomically adds the externally built sst files to the storage at path, it is much faster than
+// writing keys by batch when loading a large state(e.g. applying a snapshot). The sst files must be sorted,
+// their key ranges must not overlap each other, and they are moved(or linked) into the storage on success.
+// Only pebble supports ingestion, and only a closed storage can be ingested: it is opened exclusively and
+// closed after ingestion, ingesting a storage which is in use returns ErrIngestNotSupported.
+func IngestSST(path string, sstFiles []string) error {
+	if len(sstFiles) == 0 {
+		return nil
+	}
+
+	globalStorageMgr.lock.Lock()
+	defer globalStorageMgr.lock.Unlock()
+
+	if _, ok := globalStorageMgr.storages[path]; ok {
+		return errors.Wrapf(ErrIngestNotSupported, "storage %s is in use", path)
+	}
+
+	if err := checkStorageEngine(globalStorageMgr.defaultKVType, path); err != nil {
+		return err
+	}
+	switch globalStorageMgr.defaultKVType {
+	case repo.KVStorageTypePebble:
+		return ingestPebble(path, sstFiles)
+	default:
+		return errors.Wrapf(ErrIngestNotSupported, "kv type %q", globalStorageMgr.defaultKVType)
+	}
+}
+
+func ingestPebble(path string, sstFiles []string) (err error) {
+	opts := defaultPebbleOptions.Clone()
+	if err := setPebbleWALOptions(opts, path); err != nil {
+		return err
+	}
+	db, err := pebbledb.Open(path, opts)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open pebble %s", path)
+	}
+	defer func() {
+		if closeErr := db.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	if err := writeStorageEngine(repo.KVStorageTypePebble, path); err != nil {
+		return err
+	}
+	if err := db.Ingest(sstFiles); err != nil {
+		return errors.Wrapf(err, "failed to ingest sst files into %s", path)
+	}
+	return nil
+}
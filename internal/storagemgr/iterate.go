@@ -0,0 +1,39 @@
+package storagemgr
+
+import (
+	"bytes"
+
+	"github.com/axiomesh/axiom-kit/storage/kv"
+)
+
+// IteratePrefix calls fn with every key/value pair whose key begins with prefix in key order,
+// the iteration stops at the first error returned by fn and the error is returned.
+// k and v may be reused by the backend, fn should copy them if they are retained after the call.
+func IteratePrefix(s kv.Storage, prefix []byte, fn func(k, v []byte) error) error {
+	it := s.Iterator(prefix, prefixUpperBound(prefix))
+	for it.Next() {
+		k := it.Key()
+		// the backend should have stopped at the upper bound, double check in case of a backend ignoring it
+		if !bytes.HasPrefix(k, prefix) {
+			break
+		}
+		if err := fn(k, it.Value()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prefixUpperBound returns the smallest key greater than all keys beginning with prefix,
+// nil means no upper bound(e.g. the prefix is empty or all 0xff).
+func prefixUpperBound(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] < 0xff {
+			limit := make([]byte, i+1)
+			copy(limit, prefix)
+			limit[i]++
+			return limit
+		}
+	}
+	return nil
+}
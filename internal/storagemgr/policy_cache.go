@@ -0,0 +1,274 @@
+package storagemgr
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/VictoriaMetrics/fastcache"
+)
+
+// cacheBackend is the underlying kv cache used by CacheWrapper, the method set is the same as fastcache.Cache.
+type cacheBackend interface {
+	HasGet(dst, k []byte) ([]byte, bool)
+
+	Has(k []byte) bool
+
+	Set(k, v []byte)
+
+	Del(k []byte)
+
+	Reset()
+
+	UpdateStats(s *fastcache.Stats)
+}
+
+type cacheEntry struct {
+	key   string
+	value []byte
+	freq  uint64
+}
+
+func (e *cacheEntry) size() int {
+	return len(e.key) + len(e.value)
+}
+
+// lruCache is a size-bounded cache which evicts the least recently used entry first.
+type lruCache struct {
+	lock     sync.Mutex
+	maxBytes int
+	size     int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+func newLRUCache(maxBytes int) *lruCache {
+	return &lruCache{
+		maxBytes: maxBytes,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *lruCache) HasGet(dst, k []byte) ([]byte, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.items[string(k)]
+	if !ok {
+		return dst, false
+	}
+	c.ll.MoveToFront(elem)
+	return append(dst, elem.Value.(*cacheEntry).value...), true
+}
+
+func (c *lruCache) Has(k []byte) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	_, ok := c.items[string(k)]
+	return ok
+}
+
+func (c *lruCache) Set(k, v []byte) {
+	entry := &cacheEntry{key: string(k), value: append([]byte{}, v...)}
+	if entry.size() > c.maxBytes {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.items[entry.key]; ok {
+		c.size -= elem.Value.(*cacheEntry).size()
+		c.ll.Remove(elem)
+	}
+	for c.size+entry.size() > c.maxBytes {
+		oldest := c.ll.Back()
+		if oldest == nil {
+			break
+		}
+		c.removeElement(oldest)
+	}
+	c.items[entry.key] = c.ll.PushFront(entry)
+	c.size += entry.size()
+}
+
+func (c *lruCache) Del(k []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.items[string(k)]; ok {
+		c.removeElement(elem)
+	}
+}
+
+func (c *lruCache) Reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+	c.size = 0
+}
+
+func (c *lruCache) UpdateStats(s *fastcache.Stats) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	s.EntriesCount += uint64(len(c.items))
+	s.BytesSize += uint64(c.size)
+	s.MaxBytesSize += uint64(c.maxBytes)
+}
+
+func (c *lruCache) removeElement(elem *list.Element) {
+	entry := c.ll.Remove(elem).(*cacheEntry)
+	delete(c.items, entry.key)
+	c.size -= entry.size()
+}
+
+// lfuCache is a size-bounded cache which evicts the least frequently used entry first,
+// entries with the same frequency are evicted in LRU order.
+type lfuCache struct {
+	lock     sync.Mutex
+	maxBytes int
+	size     int
+	minFreq  uint64
+	items    map[string]*list.Element
+	freqs    map[uint64]*list.List
+}
+
+func newLFUCache(maxBytes int) *lfuCache {
+	return &lfuCache{
+		maxBytes: maxBytes,
+		items:    make(map[string]*list.Element),
+		freqs:    make(map[uint64]*list.List),
+	}
+}
+
+func (c *lfuCache) HasGet(dst, k []byte) ([]byte, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.items[string(k)]
+	if !ok {
+		return dst, false
+	}
+	elem = c.touch(elem)
+	return append(dst, elem.Value.(*cacheEntry).value...), true
+}
+
+func (c *lfuCache) Has(k []byte) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	_, ok := c.items[string(k)]
+	return ok
+}
+
+func (c *lfuCache) Set(k, v []byte) {
+	value := append([]byte{}, v...)
+	if len(k)+len(value) > c.maxBytes {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.items[string(k)]; ok {
+		entry := elem.Value.(*cacheEntry)
+		c.size += len(value) - len(entry.value)
+		entry.value = value
+		c.touch(elem)
+		c.evict()
+		return
+	}
+
+	entry := &cacheEntry{key: string(k), value: value, freq: 1}
+	c.size += entry.size()
+	c.evict()
+	c.items[entry.key] = c.bucket(1).PushFront(entry)
+	c.minFreq = 1
+}
+
+func (c *lfuCache) Del(k []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.items[string(k)]; ok {
+		c.removeElement(elem)
+	}
+}
+
+func (c *lfuCache) Reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.items = make(map[string]*list.Element)
+	c.freqs = make(map[uint64]*list.List)
+	c.size = 0
+	c.minFreq = 0
+}
+
+func (c *lfuCache) UpdateStats(s *fastcache.Stats) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	s.EntriesCount += uint64(len(c.items))
+	s.BytesSize += uint64(c.size)
+	s.MaxBytesSize += uint64(c.maxBytes)
+}
+
+func (c *lfuCache) bucket(freq uint64) *list.List {
+	l, ok := c.freqs[freq]
+	if !ok {
+		l = list.New()
+		c.freqs[freq] = l
+	}
+	return l
+}
+
+// touch moves the entry to the bucket of next frequency and returns the new element.
+func (c *lfuCache) touch(elem *list.Element) *list.Element {
+	entry := elem.Value.(*cacheEntry)
+	l := c.freqs[entry.freq]
+	l.Remove(elem)
+	if l.Len() == 0 {
+		delete(c.freqs, entry.freq)
+		if c.minFreq == entry.freq {
+			c.minFreq++
+		}
+	}
+	entry.freq++
+	newElem := c.bucket(entry.freq).PushFront(entry)
+	c.items[entry.key] = newElem
+	return newElem
+}
+
+// evict removes entries until the cache size is under the limit, the caller must hold the lock.
+func (c *lfuCache) evict() {
+	for c.size > c.maxBytes && len(c.items) > 0 {
+		l, ok := c.freqs[c.minFreq]
+		if !ok {
+			// minFreq may be stale after deletion, find the real one
+			c.minFreq = 0
+			for freq := range c.freqs {
+				if c.minFreq == 0 || freq < c.minFreq {
+					c.minFreq = freq
+				}
+			}
+			l = c.freqs[c.minFreq]
+		}
+		c.removeElement(l.Back())
+	}
+}
+
+func (c *lfuCache) removeElement(elem *list.Element) {
+	entry := elem.Value.(*cacheEntry)
+	l := c.freqs[entry.freq]
+	l.Remove(elem)
+	if l.Len() == 0 {
+		delete(c.freqs, entry.freq)
+	}
+	delete(c.items, entry.key)
+	c.size -= entry.size()
+}
@@ -2,12 +2,15 @@ package storagemgr
 
 import (
 	"fmt"
+	"path/filepath"
 	"runtime"
 	"sync"
+	"time"
 
 	pebbledb "github.com/cockroachdb/pebble"
 	"github.com/cockroachdb/pebble/bloom"
 	"github.com/prometheus/common/model"
+	"github.com/sirupsen/logrus"
 
 	"github.com/axiomesh/axiom-kit/storage/kv"
 	"github.com/axiomesh/axiom-kit/storage/kv/leveldb"
@@ -51,6 +54,10 @@ type storageMgr struct {
 	storages          map[string]kv.Storage
 	defaultKVType     string
 	lock              *sync.Mutex
+
+	// pebble WAL options, see repo.Pebble
+	walDir             string
+	walMinSyncInterval time.Duration
 }
 
 var defaultPebbleOptions = &pebbledb.Options{
@@ -75,6 +82,47 @@ var defaultPebbleOptions = &pebbledb.Options{
 	},
 }
 
+// pebbleOptions merges the overrides of the component over the default pebble options
+func pebbleOptions(component string, overrides map[string]repo.PebbleOverride) *pebbledb.Options {
+	opts := defaultPebbleOptions.Clone()
+	override, ok := overrides[component]
+	if !ok {
+		return opts
+	}
+	if override.MaxOpenFiles != 0 {
+		opts.MaxOpenFiles = override.MaxOpenFiles
+	}
+	if override.MemTableSize != 0 {
+		opts.MemTableSize = uint64(override.MemTableSize * 1024 * 1024)
+	}
+	if override.MemTableStopWritesThreshold != 0 {
+		opts.MemTableStopWritesThreshold = override.MemTableStopWritesThreshold
+	}
+	if override.LBaseMaxSize != 0 {
+		opts.LBaseMaxBytes = override.LBaseMaxSize * 1024 * 1024
+	}
+	if override.L0CompactionFileThreshold != 0 {
+		opts.L0CompactionFileThreshold = override.L0CompactionFileThreshold
+	}
+	return opts
+}
+
+// setPebbleWALOptions places the WAL of the pebble storage at p under the configured WAL dir,
+// the storage path is mirrored under it, so that storages never share a WAL directory
+func setPebbleWALOptions(opts *pebbledb.Options, p string) error {
+	if globalStorageMgr.walDir != "" {
+		absPath, err := filepath.Abs(p)
+		if err != nil {
+			return err
+		}
+		opts.WALDir = filepath.Join(globalStorageMgr.walDir, absPath)
+	}
+	if interval := globalStorageMgr.walMinSyncInterval; interval > 0 {
+		opts.WALMinSyncInterval = func() time.Duration { return interval }
+	}
+	return nil
+}
+
 func (m *storageMgr) open(typ string, p string, metricsPrefixName string) (kv.Storage, error) {
 	builder, ok := m.storageBuilderMap[typ]
 	if !ok {
@@ -86,15 +134,49 @@ func (m *storageMgr) open(typ string, p string, metricsPrefixName string) (kv.St
 func Initialize(repoConfig *repo.Config) error {
 	storageConfig := repoConfig.Storage
 	globalStorageMgr.storageBuilderMap[repo.KVStorageTypeLeveldb] = func(p string, _ string) (kv.Storage, error) {
-		return leveldb.New(p, nil)
+		if err := checkStorageEngine(repo.KVStorageTypeLeveldb, p); err != nil {
+			return nil, err
+		}
+		s, err := leveldb.New(p, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeStorageEngine(repo.KVStorageTypeLeveldb, p); err != nil {
+			_ = s.Close()
+			return nil, err
+		}
+		return s, nil
 	}
 	globalStorageMgr.storageBuilderMap[repo.KVStorageTypePebble] = func(p string, metricsPrefixName string) (kv.Storage, error) {
+		if err := checkStorageEngine(repo.KVStorageTypePebble, p); err != nil {
+			return nil, err
+		}
 		defaultPebbleOptions.Cache = pebbledb.NewCache(storageConfig.KVCacheSize * 1024 * 1024)
 		defaultPebbleOptions.MemTableSize = uint64(storageConfig.Pebble.MemTableSize * 1024 * 1024) // The size of single memory table
 		defaultPebbleOptions.MemTableStopWritesThreshold = storageConfig.Pebble.MemTableStopWritesThreshold
 		defaultPebbleOptions.MaxOpenFiles = storageConfig.Pebble.MaxOpenFiles
 		defaultPebbleOptions.L0CompactionFileThreshold = storageConfig.Pebble.L0CompactionFileThreshold
 		defaultPebbleOptions.LBaseMaxBytes = storageConfig.Pebble.LBaseMaxSize * 1024 * 1024
+		component := metricsPrefixName
+		if component == "" {
+			component = filepath.Base(p)
+		}
+		opts := pebbleOptions(component, storageConfig.PebbleOverrides)
+		if storageConfig.Pebble.WriteStallDetection {
+			opts.EventListener = newWriteStallListener(component)
+		}
+		if err := setPebbleWALOptions(opts, p); err != nil {
+			return nil, err
+		}
+		loggers.Logger(loggers.Storage).WithFields(logrus.Fields{
+			"component":                       component,
+			"max_open_files":                  opts.MaxOpenFiles,
+			"memtable_size":                   opts.MemTableSize,
+			"mem_table_stop_writes_threshold": opts.MemTableStopWritesThreshold,
+			"lbase_max_size":                  opts.LBaseMaxBytes,
+			"l0_compaction_file_threshold":    opts.L0CompactionFileThreshold,
+			"wal_dir":                         opts.WALDir,
+		}).Info("Pebble effective options")
 		namespace := "axiom_ledger"
 		subsystem := "ledger"
 		var metricOpts []pebble.MetricsOption
@@ -105,13 +187,23 @@ func Initialize(repoConfig *repo.Config) error {
 				pebble.WithWalWriteThroughput(namespace, subsystem, metricsPrefixName),
 				pebble.WithEffectiveWriteThroughput(namespace, subsystem, metricsPrefixName))
 		}
-		return pebble.New(p, defaultPebbleOptions, &pebbledb.WriteOptions{Sync: storageConfig.Sync}, loggers.Logger(loggers.Storage), metricOpts...)
+		s, err := pebble.New(p, opts, &pebbledb.WriteOptions{Sync: storageConfig.Sync}, loggers.Logger(loggers.Storage), metricOpts...)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeStorageEngine(repo.KVStorageTypePebble, p); err != nil {
+			_ = s.Close()
+			return nil, err
+		}
+		return s, nil
 	}
 	_, ok := globalStorageMgr.storageBuilderMap[storageConfig.KvType]
 	if !ok {
 		return fmt.Errorf("unknow kv type %s, expect leveldb or pebble", storageConfig.KvType)
 	}
 	globalStorageMgr.defaultKVType = storageConfig.KvType
+	globalStorageMgr.walDir = storageConfig.Pebble.WALDir
+	globalStorageMgr.walMinSyncInterval = storageConfig.Pebble.WALMinSyncInterval.ToDuration()
 	return nil
 }
 
@@ -126,6 +218,43 @@ func OpenWithMetrics(p string, uniqueMetricsPrefixName string) (kv.Storage, erro
 	return OpenSpecifyType(globalStorageMgr.defaultKVType, p, uniqueMetricsPrefixName)
 }
 
+// CloseAll closes all storages opened by the manager, it should be called at last in shutdown
+// after all writers are stopped. Closing continues on error and the first error is returned.
+func CloseAll() error {
+	globalStorageMgr.lock.Lock()
+	defer globalStorageMgr.lock.Unlock()
+	var firstErr error
+	for p, s := range globalStorageMgr.storages {
+		if err := s.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("close storage %s failed: %w", p, err)
+		}
+		delete(globalStorageMgr.storages, p)
+	}
+	return firstErr
+}
+
+// SwapStorage replaces the storage opened at path p with newStorage and returns the old one, which is used to switch
+// to a migrated backend(e.g. leveldb -> pebble) online. Only the following Open calls get newStorage, the holders of
+// the old handle keep using it, so the caller must ensure that there is no in-flight write to the old storage during
+// the swap(newStorage has caught up with it) and close the old storage once its readers are drained.
+func SwapStorage(p string, newStorage kv.Storage) (kv.Storage, error) {
+	if newStorage == nil {
+		return nil, fmt.Errorf("swap storage %s: new storage is nil", p)
+	}
+	globalStorageMgr.lock.Lock()
+	defer globalStorageMgr.lock.Unlock()
+	old, ok := globalStorageMgr.storages[p]
+	if !ok {
+		return nil, fmt.Errorf("swap storage %s: storage is not opened", p)
+	}
+	if old == newStorage {
+		return nil, fmt.Errorf("swap storage %s: new storage is the active one", p)
+	}
+	globalStorageMgr.storages[p] = newStorage
+	loggers.Logger(loggers.Storage).WithField("path", p).Info("Swap storage")
+	return old, nil
+}
+
 func OpenSpecifyType(typ string, p string, metricName string) (kv.Storage, error) {
 	globalStorageMgr.lock.Lock()
 	defer globalStorageMgr.lock.Unlock()
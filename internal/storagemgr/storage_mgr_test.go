@@ -1,10 +1,22 @@
 package storagemgr
 
 import (
+	"fmt"
+	"path/filepath"
 	"testing"
+	"time"
 
+	pebbledb "github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/objstorage/objstorageprovider"
+	"github.com/cockroachdb/pebble/sstable"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 
+	"github.com/axiomesh/axiom-kit/storage/kv"
+	"github.com/axiomesh/axiom-kit/storage/kv/leveldb"
+	"github.com/axiomesh/axiom-kit/storage/kv/pebble"
 	"github.com/axiomesh/axiom-ledger/pkg/repo"
 )
 
@@ -46,3 +58,343 @@ func TestGet(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteStallListener(t *testing.T) {
+	listener := newWriteStallListener("test_component")
+	before := testutil.ToFloat64(writeStallCounter.WithLabelValues("test_component"))
+	listener.WriteStallBegin(pebbledb.WriteStallBeginInfo{Reason: "memtable count limit reached"})
+	listener.WriteStallEnd()
+	require.Equal(t, before+1, testutil.ToFloat64(writeStallCounter.WithLabelValues("test_component")))
+}
+
+func TestPebbleOptionsOverride(t *testing.T) {
+	repoConfig := &repo.Config{Storage: repo.Storage{
+		KvType:      repo.KVStorageTypePebble,
+		KVCacheSize: repo.KVStorageCacheSize,
+		Pebble: repo.Pebble{
+			MaxOpenFiles:              1000,
+			MemTableSize:              16,
+			LBaseMaxSize:              64,
+			L0CompactionFileThreshold: 500,
+		},
+		PebbleOverrides: map[string]repo.PebbleOverride{
+			Ledger: {MemTableSize: 256, L0CompactionFileThreshold: 1000},
+		},
+	}, Monitor: repo.Monitor{Enable: false}}
+	require.Nil(t, Initialize(repoConfig))
+
+	s, err := OpenWithMetrics(repo.GetStoragePath(t.TempDir(), Ledger), Ledger)
+	require.Nil(t, err)
+	require.NotNil(t, s)
+
+	opts := pebbleOptions(Ledger, repoConfig.Storage.PebbleOverrides)
+	require.EqualValues(t, 256*1024*1024, opts.MemTableSize)
+	require.Equal(t, 1000, opts.L0CompactionFileThreshold)
+	require.Equal(t, 1000, opts.MaxOpenFiles)
+	require.EqualValues(t, 64*1024*1024, opts.LBaseMaxBytes)
+
+	opts = pebbleOptions(BlockChain, repoConfig.Storage.PebbleOverrides)
+	require.EqualValues(t, 16*1024*1024, opts.MemTableSize)
+	require.Equal(t, 500, opts.L0CompactionFileThreshold)
+
+	// the components accepted by config validation are the kv storages
+	require.ElementsMatch(t, []string{BlockChain, Ledger, Indexer, Snapshot, Consensus, Epoch, TxPool, Sync, TrieIndexer}, repo.PebbleOverrideComponents)
+}
+
+func TestPebbleWALDir(t *testing.T) {
+	walDir := t.TempDir()
+	repoConfig := &repo.Config{Storage: repo.Storage{
+		KvType:      repo.KVStorageTypePebble,
+		Sync:        true,
+		KVCacheSize: repo.KVStorageCacheSize,
+		Pebble: repo.Pebble{
+			MaxOpenFiles:       1000,
+			MemTableSize:       16,
+			WALDir:             walDir,
+			WALMinSyncInterval: repo.Duration(time.Millisecond),
+		},
+	}, Monitor: repo.Monitor{Enable: false}}
+	require.Nil(t, Initialize(repoConfig))
+	defer func() {
+		repoConfig.Storage.Pebble.WALDir = ""
+		repoConfig.Storage.Pebble.WALMinSyncInterval = 0
+		require.Nil(t, Initialize(repoConfig))
+	}()
+
+	p := repo.GetStoragePath(t.TempDir(), Ledger)
+	s, err := OpenSpecifyType(repo.KVStorageTypePebble, p, "")
+	require.Nil(t, err)
+	s.Put([]byte("key"), []byte("value"))
+	require.Nil(t, s.Close())
+
+	absPath, err := filepath.Abs(p)
+	require.Nil(t, err)
+	walFiles, err := filepath.Glob(filepath.Join(walDir, absPath, "*.log"))
+	require.Nil(t, err)
+	require.NotEmpty(t, walFiles)
+	sstWalFiles, err := filepath.Glob(filepath.Join(p, "*.log"))
+	require.Nil(t, err)
+	require.Empty(t, sstWalFiles)
+
+	// the WAL is replayed from the WAL dir on reopen
+	delete(globalStorageMgr.storages, p)
+	s, err = OpenSpecifyType(repo.KVStorageTypePebble, p, "")
+	require.Nil(t, err)
+	require.Equal(t, []byte("value"), s.Get([]byte("key")))
+	require.Nil(t, s.Close())
+}
+
+func TestCompact(t *testing.T) {
+	testcase := map[string]struct {
+		kvType string
+		open   func(p string) (kv.Storage, error)
+	}{
+		"leveldb": {kvType: "leveldb", open: func(p string) (kv.Storage, error) { return leveldb.New(p, nil) }},
+		"pebble": {kvType: "pebble", open: func(p string) (kv.Storage, error) {
+			return pebble.New(p, &pebbledb.Options{}, pebbledb.NoSync, logrus.New())
+		}},
+	}
+	for name, tc := range testcase {
+		t.Run(name, func(t *testing.T) {
+			repoConfig := &repo.Config{Storage: repo.Storage{
+				KvType:      tc.kvType,
+				KVCacheSize: repo.KVStorageCacheSize,
+				Pebble:      repo.DefaultConfig().Storage.Pebble,
+			}, Monitor: repo.Monitor{Enable: false}}
+			require.Nil(t, Initialize(repoConfig))
+
+			p := filepath.Join(t.TempDir(), "compact")
+			s, err := tc.open(p)
+			require.Nil(t, err)
+			for i := 0; i < 100; i++ {
+				s.Put([]byte(fmt.Sprintf("key%03d", i)), []byte("value"))
+			}
+			for i := 0; i < 50; i++ {
+				s.Delete([]byte(fmt.Sprintf("key%03d", i)))
+			}
+			require.Nil(t, s.Close())
+
+			require.Nil(t, Compact(p, nil, nil))
+			require.Nil(t, Compact(p, []byte("key050"), []byte("key080")))
+
+			s, err = tc.open(p)
+			require.Nil(t, err)
+			require.Nil(t, s.Get([]byte("key000")))
+			require.Equal(t, []byte("value"), s.Get([]byte("key099")))
+			require.Nil(t, s.Close())
+
+			err = Compact(filepath.Join(t.TempDir(), "not_exist"), nil, nil)
+			require.NotNil(t, err)
+
+			// the global lock is not held during compaction
+			c, closeFn, err := openCompactor(p)
+			require.Nil(t, err)
+			require.True(t, globalStorageMgr.lock.TryLock())
+			globalStorageMgr.lock.Unlock()
+			require.Nil(t, c.Compact(nil, nil))
+			require.Nil(t, closeFn())
+		})
+	}
+
+	t.Run("opened storage", func(t *testing.T) {
+		p := filepath.Join(t.TempDir(), "opened")
+		_, err := OpenSpecifyType("", p, "")
+		require.Nil(t, err)
+		err = Compact(p, nil, nil)
+		require.ErrorIs(t, err, ErrCompactionNotSupported)
+	})
+}
+
+func TestIngestSST(t *testing.T) {
+	writeSST := func(t *testing.T, p string, from, to int) {
+		f, err := vfs.Default.Create(p)
+		require.Nil(t, err)
+		w := sstable.NewWriter(objstorageprovider.NewFileWritable(f), sstable.WriterOptions{})
+		for i := from; i < to; i++ {
+			require.Nil(t, w.Set([]byte(fmt.Sprintf("key%03d", i)), []byte("value")))
+		}
+		require.Nil(t, w.Close())
+	}
+
+	t.Run("pebble", func(t *testing.T) {
+		repoConfig := &repo.Config{Storage: repo.Storage{
+			KvType:      repo.KVStorageTypePebble,
+			KVCacheSize: repo.KVStorageCacheSize,
+			Pebble:      repo.DefaultConfig().Storage.Pebble,
+		}, Monitor: repo.Monitor{Enable: false}}
+		require.Nil(t, Initialize(repoConfig))
+
+		p := filepath.Join(t.TempDir(), "ingest")
+		sstDir := t.TempDir()
+		sst1, sst2 := filepath.Join(sstDir, "1.sst"), filepath.Join(sstDir, "2.sst")
+		writeSST(t, sst1, 0, 50)
+		writeSST(t, sst2, 50, 100)
+		require.Nil(t, IngestSST(p, []string{sst1, sst2}))
+
+		s, err := pebble.New(p, &pebbledb.Options{}, pebbledb.NoSync, logrus.New())
+		require.Nil(t, err)
+		require.Equal(t, []byte("value"), s.Get([]byte("key000")))
+		require.Equal(t, []byte("value"), s.Get([]byte("key099")))
+		require.Nil(t, s.Close())
+
+		err = IngestSST(p, []string{filepath.Join(sstDir, "not_exist.sst")})
+		require.NotNil(t, err)
+
+		// the storage in use cannot be ingested
+		opened, err := Open(p)
+		require.Nil(t, err)
+		sst3 := filepath.Join(sstDir, "3.sst")
+		writeSST(t, sst3, 100, 110)
+		err = IngestSST(p, []string{sst3})
+		require.ErrorIs(t, err, ErrIngestNotSupported)
+		require.Nil(t, opened.Close())
+	})
+
+	t.Run("leveldb", func(t *testing.T) {
+		repoConfig := &repo.Config{Storage: repo.Storage{
+			KvType:      repo.KVStorageTypeLeveldb,
+			KVCacheSize: repo.KVStorageCacheSize,
+		}, Monitor: repo.Monitor{Enable: false}}
+		require.Nil(t, Initialize(repoConfig))
+
+		p := filepath.Join(t.TempDir(), "ingest")
+		sst := filepath.Join(t.TempDir(), "1.sst")
+		writeSST(t, sst, 0, 10)
+		err := IngestSST(p, []string{sst})
+		require.ErrorIs(t, err, ErrIngestNotSupported)
+	})
+}
+
+func TestIteratePrefix(t *testing.T) {
+	pebbleStorage, err := pebble.New(t.TempDir(), &pebbledb.Options{}, pebbledb.NoSync, logrus.New())
+	require.Nil(t, err)
+	defer pebbleStorage.Close()
+
+	testcase := map[string]kv.Storage{
+		"memory": kv.NewMemory(),
+		"pebble": pebbleStorage,
+	}
+	for name, s := range testcase {
+		t.Run(name, func(t *testing.T) {
+			for _, k := range []string{"a", "a.", "a/1", "a/2", "a/3", "a0", "b/1"} {
+				s.Put([]byte(k), []byte("v"+k))
+			}
+			s.Put([]byte{0xff, 0xff}, []byte("max"))
+			s.Put([]byte{0xff, 0xff, 0x01}, []byte("max1"))
+
+			var keys []string
+			err := IteratePrefix(s, []byte("a/"), func(k, v []byte) error {
+				require.Equal(t, "v"+string(k), string(v))
+				keys = append(keys, string(k))
+				return nil
+			})
+			require.Nil(t, err)
+			require.Equal(t, []string{"a/1", "a/2", "a/3"}, keys)
+
+			// prefix without upper bound
+			var values []string
+			err = IteratePrefix(s, []byte{0xff, 0xff}, func(k, v []byte) error {
+				values = append(values, string(v))
+				return nil
+			})
+			require.Nil(t, err)
+			require.Equal(t, []string{"max", "max1"}, values)
+
+			// error of fn stops the iteration
+			stopErr := fmt.Errorf("stop")
+			count := 0
+			err = IteratePrefix(s, []byte("a/"), func(k, v []byte) error {
+				count++
+				if string(k) == "a/2" {
+					return stopErr
+				}
+				return nil
+			})
+			require.ErrorIs(t, err, stopErr)
+			require.Equal(t, 2, count)
+		})
+	}
+}
+
+func TestCloseAll(t *testing.T) {
+	dir := t.TempDir()
+	repoConfig := &repo.Config{Storage: repo.Storage{
+		KvType:      repo.KVStorageTypeLeveldb,
+		KVCacheSize: repo.KVStorageCacheSize,
+	}, Monitor: repo.Monitor{Enable: false}}
+	require.Nil(t, Initialize(repoConfig))
+
+	p := filepath.Join(dir, "close_all")
+	s, err := Open(p)
+	require.Nil(t, err)
+	s.Put([]byte("key"), []byte("value"))
+	require.Nil(t, CloseAll())
+
+	// the closed storage is reopened instead of returning the closed one
+	s, err = Open(p)
+	require.Nil(t, err)
+	require.Equal(t, []byte("value"), s.Get([]byte("key")))
+	require.Nil(t, CloseAll())
+}
+
+func TestSwapStorage(t *testing.T) {
+	dir := t.TempDir()
+	repoConfig := &repo.Config{Storage: repo.Storage{
+		KvType:      repo.KVStorageTypeLeveldb,
+		KVCacheSize: repo.KVStorageCacheSize,
+	}, Monitor: repo.Monitor{Enable: false}}
+	require.Nil(t, Initialize(repoConfig))
+
+	p := filepath.Join(dir, "swap")
+	_, err := SwapStorage(p, kv.NewMemory())
+	require.NotNil(t, err, "storage is not opened")
+
+	s, err := Open(p)
+	require.Nil(t, err)
+	s.Put([]byte("key"), []byte("old"))
+	_, err = SwapStorage(p, nil)
+	require.NotNil(t, err)
+	_, err = SwapStorage(p, s)
+	require.NotNil(t, err)
+
+	newStorage := kv.NewMemory()
+	newStorage.Put([]byte("key"), []byte("new"))
+	old, err := SwapStorage(p, newStorage)
+	require.Nil(t, err)
+	require.Equal(t, s, old)
+	require.Nil(t, old.Close())
+
+	s, err = Open(p)
+	require.Nil(t, err)
+	require.Equal(t, []byte("new"), s.Get([]byte("key")))
+	require.Nil(t, CloseAll())
+}
+
+func TestStorageEngineMismatch(t *testing.T) {
+	repoConfig := &repo.Config{Storage: repo.Storage{
+		KvType:      repo.KVStorageTypeLeveldb,
+		KVCacheSize: repo.KVStorageCacheSize,
+		Pebble:      repo.DefaultConfig().Storage.Pebble,
+	}, Monitor: repo.Monitor{Enable: false}}
+	require.Nil(t, Initialize(repoConfig))
+
+	p := filepath.Join(t.TempDir(), "leveldb")
+	_, err := OpenSpecifyType(repo.KVStorageTypeLeveldb, p, "")
+	require.Nil(t, err)
+	require.Nil(t, CloseAll())
+	_, err = OpenSpecifyType(repo.KVStorageTypePebble, p, "")
+	require.ErrorIs(t, err, ErrStorageEngineMismatch)
+	_, err = OpenSpecifyType(repo.KVStorageTypeLeveldb, p, "")
+	require.Nil(t, err)
+	require.Nil(t, CloseAll())
+
+	// storage created without engine file is detected by the on-disk layout
+	p = filepath.Join(t.TempDir(), "legacy_pebble")
+	s, err := pebble.New(p, &pebbledb.Options{}, pebbledb.NoSync, logrus.New())
+	require.Nil(t, err)
+	require.Nil(t, s.Close())
+	_, err = OpenSpecifyType(repo.KVStorageTypeLeveldb, p, "")
+	require.ErrorIs(t, err, ErrStorageEngineMismatch)
+	err = Compact(p, nil, nil)
+	require.ErrorIs(t, err, ErrStorageEngineMismatch)
+}
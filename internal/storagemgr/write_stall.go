@@ -0,0 +1,46 @@
+package storagemgr
+
+import (
+	"sync/atomic"
+	"time"
+
+	pebbledb "github.com/cockroachdb/pebble"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/axiomesh/axiom-ledger/pkg/loggers"
+)
+
+var writeStallCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "axiom_ledger",
+	Subsystem: "storage",
+	Name:      "write_stall_total",
+	Help:      "The total number of pebble write stalls",
+}, []string{"component"})
+
+func init() {
+	prometheus.MustRegister(writeStallCounter)
+}
+
+// newWriteStallListener counts and logs pebble write stalls of the component,
+// a write stall usually means compaction can not keep up with writes.
+func newWriteStallListener(component string) *pebbledb.EventListener {
+	logger := loggers.Logger(loggers.Storage)
+	var stallBeginTime atomic.Int64
+	return &pebbledb.EventListener{
+		WriteStallBegin: func(info pebbledb.WriteStallBeginInfo) {
+			writeStallCounter.WithLabelValues(component).Inc()
+			stallBeginTime.Store(time.Now().UnixNano())
+			logger.WithFields(logrus.Fields{
+				"component": component,
+				"reason":    info.Reason,
+			}).Warn("Pebble write stall begin")
+		},
+		WriteStallEnd: func() {
+			logger.WithFields(logrus.Fields{
+				"component": component,
+				"duration":  time.Since(time.Unix(0, stallBeginTime.Load())),
+			}).Warn("Pebble write stall end")
+		},
+	}
+}
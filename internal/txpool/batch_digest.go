@@ -0,0 +1,57 @@
+package txpool
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	commonpool "github.com/axiomesh/axiom-kit/txpool"
+	"github.com/axiomesh/axiom-kit/types"
+	"github.com/axiomesh/axiom-ledger/pkg/repo"
+)
+
+// newBatchHasher returns the hash constructor of the batch digest algorithm, empty means md5
+func newBatchHasher(algo string) (func() hash.Hash, error) {
+	switch algo {
+	case "", repo.BatchDigestMD5:
+		return md5.New, nil
+	case repo.BatchDigestKeccak256:
+		return func() hash.Hash { return crypto.NewKeccakState() }, nil
+	case repo.BatchDigestSha256:
+		return sha256.New, nil
+	default:
+		return nil, fmt.Errorf("unsupported batch digest algorithm %q, expect one of %s, %s, %s",
+			algo, repo.BatchDigestMD5, repo.BatchDigestKeccak256, repo.BatchDigestSha256)
+	}
+}
+
+func batchDigestAlgoName(algo string) string {
+	if algo == "" {
+		return repo.BatchDigestMD5
+	}
+	return algo
+}
+
+// generateBatchHash has the same layout as RequestHashBatch.GenerateBatchHash(tx hashes followed by timestamp),
+// only the hash function is configurable, so md5 produces the same digest as before.
+func (p *txPoolImpl[T, Constraint]) generateBatchHash(batch *commonpool.RequestHashBatch[T, Constraint]) string {
+	return digestBatch[T, Constraint](p.batchHasher, batch)
+}
+
+func digestBatch[T any, Constraint types.TXConstraint[T]](newHasher func() hash.Hash, batch *commonpool.RequestHashBatch[T, Constraint]) string {
+	h := newHasher()
+	for _, txHash := range batch.TxHashList {
+		_, _ = h.Write([]byte(txHash))
+	}
+	if batch.Timestamp > 0 {
+		b := make([]byte, 8)
+		binary.LittleEndian.PutUint64(b, uint64(batch.Timestamp))
+		_, _ = h.Write(b)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
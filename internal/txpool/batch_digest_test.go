@@ -0,0 +1,62 @@
+package txpool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	commonpool "github.com/axiomesh/axiom-kit/txpool"
+	"github.com/axiomesh/axiom-kit/types"
+	"github.com/axiomesh/axiom-ledger/internal/chainstate"
+	"github.com/axiomesh/axiom-ledger/pkg/repo"
+)
+
+func TestBatchDigest(t *testing.T) {
+	ast := assert.New(t)
+	s, err := types.GenerateSigner()
+	ast.Nil(err)
+	txs := constructTxs(s, 3)
+	batch := &commonpool.RequestHashBatch[types.Transaction, *types.Transaction]{
+		TxList:     txs,
+		TxHashList: []string{txs[0].RbftGetTxHash(), txs[1].RbftGetTxHash(), txs[2].RbftGetTxHash()},
+		Timestamp:  time.Now().UnixNano(),
+	}
+
+	// md5 is compatible with the digest of axiom-kit
+	hasher, err := newBatchHasher("")
+	ast.Nil(err)
+	ast.Equal(batch.GenerateBatchHash(), digestBatch[types.Transaction, *types.Transaction](hasher, batch))
+
+	hasher, err = newBatchHasher(repo.BatchDigestKeccak256)
+	ast.Nil(err)
+	keccak := digestBatch[types.Transaction, *types.Transaction](hasher, batch)
+	ast.Equal(64, len(keccak))
+	ast.Equal(keccak, digestBatch[types.Transaction, *types.Transaction](hasher, batch))
+
+	hasher, err = newBatchHasher(repo.BatchDigestSha256)
+	ast.Nil(err)
+	sha := digestBatch[types.Transaction, *types.Transaction](hasher, batch)
+	ast.Equal(64, len(sha))
+	ast.NotEqual(keccak, sha)
+
+	_, err = newBatchHasher("sha1")
+	ast.NotNil(err)
+
+	t.Run("pool uses configured digest", func(t *testing.T) {
+		ast := assert.New(t)
+		r := repo.MockRepo(t)
+		chainState := chainstate.NewMockChainState(r.GenesisConfig, nil)
+		chainState.EpochInfo.FinanceParams.MinGasPrice = types.CoinNumberByMol(0)
+
+		conf := NewMockTxPoolConfig(t)
+		conf.BatchDigestAlgo = "sha1"
+		_, err := newTxPoolImpl[types.Transaction, *types.Transaction](conf, chainState)
+		ast.NotNil(err)
+
+		conf.BatchDigestAlgo = repo.BatchDigestSha256
+		pool, err := newTxPoolImpl[types.Transaction, *types.Transaction](conf, chainState)
+		ast.Nil(err)
+		ast.Equal(sha, pool.generateBatchHash(batch))
+	})
+}
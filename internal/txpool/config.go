@@ -16,14 +16,28 @@ type Config struct {
 	ToleranceNonceGap      uint64
 	ToleranceTime          time.Duration
 	ToleranceRemoveTime    time.Duration
+	TxMaxAge               time.Duration
 	CleanEmptyAccountTime  time.Duration
 	RotateTxLocalsInterval time.Duration
+	MaxLoadingRecordTxs    uint64
 	GetAccountNonce        GetAccountNonceFunc
 	GetAccountBalance      GetAccountBalanceFunc
 	EnableLocalsPersist    bool
 	PriceLimit             uint64
 	PriceBump              uint64
 	GenerateBatchType      string
+	// TxRecordsDir is the directory of local tx records file, empty means the default txpool storage path
+	TxRecordsDir string
+	// HighWatermarkPercent is the percentage of pool size crossing which fires an early warning, 0 means disabled
+	HighWatermarkPercent uint64
+	// LowWatermarkPercent is the percentage of pool size dropping below which fires a recovery
+	LowWatermarkPercent uint64
+	// BatchDigestAlgo is the hash function of batch digest from genesis, empty means md5
+	BatchDigestAlgo string
+	// MaxAccountsPerBatch is the max number of distinct sender accounts in a batch, 0 means unlimited
+	MaxAccountsPerBatch uint64
+	// EvictByFee evicts the cheapest tx for a higher fee tx when the pool is full, instead of rejecting it
+	EvictByFee bool
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -47,6 +61,9 @@ func (c *Config) sanitize() {
 	if c.RotateTxLocalsInterval == 0 {
 		c.RotateTxLocalsInterval = DefaultRotateTxLocalsInterval
 	}
+	if c.MaxLoadingRecordTxs == 0 {
+		c.MaxLoadingRecordTxs = DefaultMaxLoadingRecordTxs
+	}
 
 	if c.GenerateBatchType != repo.GenerateBatchByTime && c.GenerateBatchType != repo.GenerateBatchByGasPrice {
 		c.GenerateBatchType = repo.GenerateBatchByTime
@@ -54,4 +71,10 @@ func (c *Config) sanitize() {
 	if c.PriceBump < DefaultPriceBump {
 		c.PriceBump = DefaultPriceBump
 	}
+	if c.HighWatermarkPercent > 100 {
+		c.HighWatermarkPercent = 100
+	}
+	if c.LowWatermarkPercent >= c.HighWatermarkPercent {
+		c.LowWatermarkPercent = c.HighWatermarkPercent * DefaultLowWatermarkPercent / DefaultHighWatermarkPercent
+	}
 }
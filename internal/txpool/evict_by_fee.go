@@ -0,0 +1,181 @@
+package txpool
+
+import (
+	"container/heap"
+	"math/big"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/axiomesh/axiom-kit/types"
+)
+
+// admitByFee adds the over space txs by evicting cheaper txs in pool, each tx is validated before evicting,
+// so a tx which would be rejected anyway never evicts a valid pool tx. The txs failed to get room are returned
+// as still over space.
+func (p *txPoolImpl[T, Constraint]) admitByFee(overSpaceTxs []*T, add func(tx *T)) []*T {
+	evictor := p.newFeeEvictor()
+	rejected := make([]*T, 0)
+	for _, tx := range overSpaceTxs {
+		if p.makeRoomByFee(tx, evictor) {
+			add(tx)
+		} else {
+			rejected = append(rejected, tx)
+		}
+	}
+	return rejected
+}
+
+// makeRoomByFee reports whether tx can be added to the full pool. A valid replacement takes no more room,
+// otherwise the cheapest evictable tx is evicted if tx pays more.
+func (p *txPoolImpl[T, Constraint]) makeRoomByFee(tx *T, evictor *feeEvictor[T, Constraint]) bool {
+	needReplace, err := p.checkTx(tx)
+	if err != nil {
+		return false
+	}
+	return needReplace || evictor.evictFor(tx)
+}
+
+// evictableTail returns the tail(highest nonce) tx of account if it can be evicted. Only the tail tx can be evicted,
+// so that the rest txs of the account keep executable, local txs and batched txs are never evicted.
+func (p *txPoolImpl[T, Constraint]) evictableTail(account string) *internalTransaction[T, Constraint] {
+	list, ok := p.txStore.allTxs[account]
+	if !ok {
+		return nil
+	}
+	tail := list.index.data.Max()
+	if tail == nil {
+		return nil
+	}
+	poolTx := list.items[tail.(*sortedNonceKey).nonce]
+	if poolTx == nil || poolTx.local {
+		return nil
+	}
+	if _, ok := p.txStore.batchedTxs[txPointer{account: account, nonce: poolTx.getNonce()}]; ok {
+		return nil
+	}
+	return poolTx
+}
+
+// evictTailHeap is a min-heap of evictable account tails ordered by gas price
+type evictTailHeap[T any, Constraint types.TXConstraint[T]] []*internalTransaction[T, Constraint]
+
+func (h evictTailHeap[T, Constraint]) Len() int { return len(h) }
+
+func (h evictTailHeap[T, Constraint]) Less(i, j int) bool {
+	return h[i].getGasPrice().Cmp(h[j].getGasPrice()) < 0
+}
+
+func (h evictTailHeap[T, Constraint]) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *evictTailHeap[T, Constraint]) Push(x any) {
+	*h = append(*h, x.(*internalTransaction[T, Constraint]))
+}
+
+func (h *evictTailHeap[T, Constraint]) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil // avoid memory leak
+	*h = old[:n-1]
+	return item
+}
+
+// feeEvictor evicts pool txs for the incoming txs of a single event. The evictable account tails are collected into
+// a min-heap on the first eviction, so each eviction costs O(log accounts) instead of a scan of all accounts.
+// The heap is not kept across events, entries changed by the txs added in the same event are checked when popped.
+type feeEvictor[T any, Constraint types.TXConstraint[T]] struct {
+	pool  *txPoolImpl[T, Constraint]
+	tails *evictTailHeap[T, Constraint]
+}
+
+func (p *txPoolImpl[T, Constraint]) newFeeEvictor() *feeEvictor[T, Constraint] {
+	return &feeEvictor[T, Constraint]{pool: p}
+}
+
+func (e *feeEvictor[T, Constraint]) init() {
+	tails := make(evictTailHeap[T, Constraint], 0, len(e.pool.txStore.allTxs))
+	for account := range e.pool.txStore.allTxs {
+		if tail := e.pool.evictableTail(account); tail != nil {
+			tails = append(tails, tail)
+		}
+	}
+	heap.Init(&tails)
+	e.tails = &tails
+}
+
+// evictFor evicts the cheapest evictable tx if its gas price is lower than the incoming tx,
+// txs of the incoming tx's account are never evicted.
+func (e *feeEvictor[T, Constraint]) evictFor(incoming *T) bool {
+	if e.tails == nil {
+		e.init()
+	}
+	from := Constraint(incoming).RbftGetFrom()
+	incomingPrice := Constraint(incoming).RbftGetGasPrice()
+
+	var skipped []*internalTransaction[T, Constraint]
+	defer func() {
+		for _, tx := range skipped {
+			heap.Push(e.tails, tx)
+		}
+	}()
+	for e.tails.Len() > 0 {
+		victim := (*e.tails)[0]
+		account := victim.getAccount()
+		if current := e.pool.evictableTail(account); current != victim {
+			// the tail of account has changed since the heap was built
+			heap.Pop(e.tails)
+			if current != nil {
+				heap.Push(e.tails, current)
+			}
+			continue
+		}
+		if account == from {
+			skipped = append(skipped, heap.Pop(e.tails).(*internalTransaction[T, Constraint]))
+			continue
+		}
+		if victim.getGasPrice().Cmp(incomingPrice) >= 0 {
+			return false
+		}
+		heap.Pop(e.tails)
+		if !e.pool.evictTx(victim, incomingPrice) {
+			return false
+		}
+		if next := e.pool.evictableTail(account); next != nil {
+			heap.Push(e.tails, next)
+		}
+		return true
+	}
+	return false
+}
+
+// evictTx removes victim from pool and reverts the pending nonce of its account
+func (p *txPoolImpl[T, Constraint]) evictTx(victim *internalTransaction[T, Constraint], incomingPrice *big.Int) bool {
+	account := victim.getAccount()
+	list := p.txStore.allTxs[account]
+	priority := victim.getNonce() < p.txStore.nonceCache.getPendingNonce(account)
+	if p.enablePricePriority && priority {
+		p.txStore.priorityByPrice.removeTxBehindNonce(victim)
+	}
+	if err := p.cleanTxsByAccount(account, list, []*internalTransaction[T, Constraint]{victim}, true); err != nil {
+		p.logger.Errorf("cleanTxsByAccount failed: %s", err)
+		return false
+	}
+	if priority {
+		if p.txStore.priorityNonBatchSize == 0 {
+			p.logger.Error("decrease nonBatchSize error, actual size 0")
+		} else {
+			p.decreasePriorityNonBatchSize(1)
+		}
+	}
+	p.revertPendingNonce(&txPointer{account: account, nonce: victim.getNonce()}, make(map[string]uint64))
+
+	p.logger.WithFields(logrus.Fields{
+		"account":        account,
+		"nonce":          victim.getNonce(),
+		"gas_price":      victim.getGasPrice(),
+		"incoming_price": incomingPrice,
+	}).Debug("Evict tx by fee")
+	traceRemovedTx("evictedByFee", 1)
+	evictedByFeeNum.Inc()
+	return true
+}
@@ -10,6 +10,10 @@ func (p *txPoolImpl[T, Constraint]) handleRemoveTimeout(timeoutEvent timer.Timeo
 		ev = &removeTxsEvent{
 			EventType: timeoutTxsEvent,
 		}
+	case ExpireTx:
+		ev = &removeTxsEvent{
+			EventType: expiredTxsEvent,
+		}
 	case CleanEmptyAccount:
 		ev = &localEvent{
 			EventType: gcAccountEvent,
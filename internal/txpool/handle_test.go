@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/axiomesh/axiom-kit/types"
@@ -97,6 +98,34 @@ func TestHandleRemoveTimeoutEvent(t *testing.T) {
 		pool.Stop()
 	})
 
+	t.Run("remove expired txs", func(t *testing.T) {
+		ast := assert.New(t)
+		pool := mockTxPoolImpl[types.Transaction, *types.Transaction](t)
+		pool.txMaxAge = 10 * time.Millisecond
+		err := pool.Start()
+		ast.Nil(err)
+
+		s, err := types.GenerateSigner()
+		ast.Nil(err)
+		pool.AddRemoteTxs(constructTxs(s, 3))
+
+		// wait to ensure that txs had been expired
+		time.Sleep(12 * time.Millisecond)
+		s2, err := types.GenerateSigner()
+		ast.Nil(err)
+		pool.AddRemoteTxs(constructTxs(s2, 2))
+		ast.Equal(uint64(5), pool.GetTotalPendingTxCount())
+		ast.Equal(uint64(3), pool.GetPendingTxCountByAccount(s.Addr.String()))
+
+		before := testutil.ToFloat64(expiredTxNum)
+		pool.handleRemoveTimeout(ExpireTx)
+		ast.Equal(uint64(2), pool.GetTotalPendingTxCount(), "only fresh txs are left")
+		ast.Equal(uint64(0), pool.GetPendingTxCountByAccount(s.Addr.String()))
+		ast.Equal(uint64(2), pool.GetPendingTxCountByAccount(s2.Addr.String()))
+		ast.Equal(float64(3), testutil.ToFloat64(expiredTxNum)-before)
+		pool.Stop()
+	})
+
 	t.Run("rotate tx locals", func(t *testing.T) {
 		ast := assert.New(t)
 		pool := mockTxPoolImpl[types.Transaction, *types.Transaction](t)
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/axiomesh/axiom-ledger/internal/consensus/common"
 )
 
 func (p *txPoolImpl[T, Constraint]) setFull() {
@@ -30,9 +32,9 @@ func (p *txPoolImpl[T, Constraint]) setNoPendingRequest() {
 	p.statusMgr.Off(HasPendingRequest)
 }
 
+// traceRejectTx records the rejected tx in the counter shared with consensus
 func traceRejectTx(reason string) {
-	rejectTxNum.With(prometheus.Labels{"reason": reason}).Inc()
-	rejectTxNum.With(prometheus.Labels{"reason": "all"}).Inc()
+	common.TraceRejectedTx(reason)
 }
 
 func traceRemovedTx(reason string, count int) {
@@ -48,3 +50,14 @@ func traceProcessEvent(event string, duration time.Duration) {
 func tracePersistRecords(duration time.Duration) {
 	insertRecordDuration.Observe(duration.Seconds())
 }
+
+// traceRecordsWritten records the number of tx records written by insert or rotate and the bytes appended
+func traceRecordsWritten(typ string, count int, size int64) {
+	persistRecordNum.With(prometheus.Labels{"type": typ}).Add(float64(count))
+	persistRecordNum.With(prometheus.Labels{"type": "all"}).Add(float64(count))
+	recordsFileSize.Add(float64(size))
+}
+
+func traceRecordsFileSize(size int64) {
+	recordsFileSize.Set(float64(size))
+}
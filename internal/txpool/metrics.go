@@ -37,13 +37,20 @@ var (
 			Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 10),
 		},
 	)
-	rejectTxNum = prometheus.NewCounterVec(
+	persistRecordNum = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: "txpool",
-			Name:      "reject_tx_counter",
-			Help:      "the total number of rejected transactions",
+			Name:      "persist_record_counter",
+			Help:      "the total number of persisted local tx records",
+		},
+		[]string{"type"},
+	)
+	recordsFileSize = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "txpool",
+			Name:      "records_file_size_bytes",
+			Help:      "the size of local tx records file",
 		},
-		[]string{"reason"},
 	)
 	removeTxNum = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -53,14 +60,48 @@ var (
 		},
 		[]string{"reason"},
 	)
+	expiredTxNum = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "txpool",
+			Name:      "expired_total",
+			Help:      "the total number of transactions which evicted for exceeding the max age",
+		},
+	)
+	evictedByFeeNum = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "txpool",
+			Name:      "evicted_by_fee_total",
+			Help:      "the total number of transactions which evicted by higher fee transactions when the pool is full",
+		},
+	)
+	highWatermark = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "txpool",
+			Name:      "high_watermark",
+			Help:      "1 if the number of transactions crossed the high watermark and has not dropped below the low watermark",
+		},
+	)
+	highWatermarkEventNum = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "txpool",
+			Name:      "high_watermark_event_total",
+			Help:      "the total number of crossing the high watermark(crossed) and dropping below the low watermark(recovered)",
+		},
+		[]string{"type"},
+	)
 )
 
 func init() {
 	prometheus.MustRegister(processEventDuration)
 	prometheus.MustRegister(insertRecordDuration)
+	prometheus.MustRegister(persistRecordNum)
+	prometheus.MustRegister(recordsFileSize)
 	prometheus.MustRegister(poolTxNum)
 	prometheus.MustRegister(readyTxNum)
-	prometheus.MustRegister(rejectTxNum)
 	prometheus.MustRegister(removeTxNum)
 	prometheus.MustRegister(queueTxNum)
+	prometheus.MustRegister(expiredTxNum)
+	prometheus.MustRegister(evictedByFeeNum)
+	prometheus.MustRegister(highWatermark)
+	prometheus.MustRegister(highWatermarkEventNum)
 }
@@ -2,8 +2,10 @@ package txpool
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path"
@@ -12,6 +14,8 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/axiomesh/axiom-kit/types"
+	"github.com/axiomesh/axiom-ledger/internal/storagemgr"
+	"github.com/axiomesh/axiom-ledger/pkg/repo"
 )
 
 // devNull mimic the behavior of the Unix /dev/null.
@@ -23,8 +27,60 @@ const (
 	TxRecordsBatchSize   = 1000
 	TxRecordsFile        = "tx_records.pb"
 	DecodeTxRecordsFile  = "decode_tx_records.json"
+
+	// TxRecordsVersion is the format version of tx records file written by rotate,
+	// the files without header are version 0, which has the same record format as version 1
+	TxRecordsVersion uint8 = 1
 )
 
+// txRecordsMagic is the beginning of the header of tx records file, the header has the same size as the length prefix
+// of a record, the magic decoded as a length is too large to be a tx, so a headerless file can not be mistaken for it.
+var txRecordsMagic = [TxRecordPrefixLength - 1]byte{'A', 'X', 'T', 'X', 'R', 'E', 'C'}
+
+func txRecordsHeader() []byte {
+	return append(txRecordsMagic[:], TxRecordsVersion)
+}
+
+// initTxRecordsFile creates the tx records file if it does not exist, and writes the header if the file is empty,
+// so the records appended before the first rotation are in the current format as well
+func initTxRecordsFile(filePath string) error {
+	f, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() > 0 {
+		return nil
+	}
+	_, err = f.Write(txRecordsHeader())
+	return err
+}
+
+// readTxRecordsVersion skips the header of the tx records file and returns the format version,
+// 0 is returned for a headerless file
+func readTxRecordsVersion(buf *bufio.Reader) (uint8, error) {
+	header, err := buf.Peek(TxRecordPrefixLength)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if !bytes.Equal(header[:len(txRecordsMagic)], txRecordsMagic[:]) {
+		return 0, nil
+	}
+	version := header[len(txRecordsMagic)]
+	if version > TxRecordsVersion {
+		return 0, fmt.Errorf("unsupported tx records version %d, expect at most %d", version, TxRecordsVersion)
+	}
+	_, _ = buf.Discard(TxRecordPrefixLength)
+	return version, nil
+}
+
 func (*devNull) Write(p []byte) (n int, err error) { return len(p), nil }
 
 func (*devNull) Close() error { return nil }
@@ -33,15 +89,34 @@ type txRecords[T any, Constraint types.TXConstraint[T]] struct {
 	logger   logrus.FieldLogger
 	filePath string
 	writer   io.WriteCloser
+
+	// loadingTokens caps the loaded txs which are not released by the consumer yet, nil means unlimited
+	loadingTokens chan struct{}
 }
 
-func newTxRecords[T any, Constraint types.TXConstraint[T]](filePath string, logger logrus.FieldLogger) *txRecords[T, Constraint] {
-	return &txRecords[T, Constraint]{
+func newTxRecords[T any, Constraint types.TXConstraint[T]](filePath string, maxLoadingTxs uint64, logger logrus.FieldLogger) *txRecords[T, Constraint] {
+	r := &txRecords[T, Constraint]{
 		filePath: filePath,
 		logger:   logger,
 	}
+	if maxLoadingTxs > 0 {
+		r.loadingTokens = make(chan struct{}, maxLoadingTxs)
+	}
+	return r
+}
+
+// release should be called by the consumer of load after a batch is handled, so that the loading can go on
+func (r *txRecords[T, Constraint]) release(n int) {
+	if r.loadingTokens == nil {
+		return
+	}
+	for i := 0; i < n; i++ {
+		<-r.loadingTokens
+	}
 }
 
+// load reads the txs of input in batches, it blocks once the unreleased txs reach the cap of loadingTokens,
+// which prevents loading a huge records file from running out of memory.
 func (r *txRecords[T, Constraint]) load(input *os.File, taskDoneCh chan struct{}) chan []*T {
 	batchCh := make(chan []*T, 1024)
 
@@ -50,9 +125,22 @@ func (r *txRecords[T, Constraint]) load(input *os.File, taskDoneCh chan struct{}
 
 	buf := bufio.NewReader(input)
 	var txNums uint64
-	batch := make([]*T, 0, TxRecordsBatchSize)
+	batchSize := TxRecordsBatchSize
+	if r.loadingTokens != nil && cap(r.loadingTokens) < batchSize {
+		// a batch must be sent before the tokens run out, otherwise the loading blocks forever
+		batchSize = cap(r.loadingTokens)
+	}
+	batch := make([]*T, 0, batchSize)
 
 	go func(txNums uint64) {
+		version, err := readTxRecordsVersion(buf)
+		if err != nil {
+			r.logger.Errorf("TxRecords load failed to read header of %s: %v", r.filePath, err)
+			taskDoneCh <- struct{}{}
+			return
+		}
+		r.logger.Debugf("TxRecords load %s, version: %d", r.filePath, version)
+
 		for {
 			lengthBytes, err := buf.Peek(TxRecordPrefixLength)
 			if err != nil {
@@ -71,25 +159,31 @@ func (r *txRecords[T, Constraint]) load(input *os.File, taskDoneCh chan struct{}
 			length := binary.LittleEndian.Uint64(lengthBytes)
 			_, _ = buf.Discard(TxRecordPrefixLength)
 
+			if r.loadingTokens != nil {
+				r.loadingTokens <- struct{}{}
+			}
+
 			data := make([]byte, length)
 			if _, err := io.ReadFull(buf, data); err != nil {
 				r.logger.Errorf("TxRecords load failed to error reading transaction data: %v", err)
+				r.release(1)
 				continue
 			}
 
 			tx := new(T)
 			if err = Constraint(tx).RbftUnmarshal(data); err != nil {
 				r.logger.Errorf("TxRecords load failed to unmarshal transaction: %v", err)
+				r.release(1)
 				continue
 			}
 
 			batch = append(batch, tx)
-			if len(batch) >= TxRecordsBatchSize {
+			if len(batch) >= batchSize {
 				getBatch := make([]*T, len(batch))
 				copy(getBatch, batch)
 				batchCh <- getBatch
 				// Get a batch from the pool
-				batch = make([]*T, 0, TxRecordsBatchSize)
+				batch = make([]*T, 0, batchSize)
 			}
 			txNums++
 		}
@@ -115,6 +209,36 @@ func (r *txRecords[T, Constraint]) insert(tx *T) error {
 	if _, err := r.writer.Write(b); err != nil {
 		return err
 	}
+	if _, ok := r.writer.(*devNull); !ok {
+		traceRecordsWritten("insert", 1, int64(TxRecordPrefixLength+len(b)))
+	}
+	return nil
+}
+
+// GetTxRecordsFilePath returns the path of tx records file, dir overrides the default txpool storage path if it is not empty
+func GetTxRecordsFilePath(repoRoot string, dir string) string {
+	if dir == "" {
+		return path.Join(repo.GetStoragePath(repoRoot, storagemgr.TxPool), TxRecordsFile)
+	}
+	return path.Join(dir, TxRecordsFile)
+}
+
+// checkTxRecordsDir ensures the dir is writable and supports atomic rename, which is required by rotate
+// (the temp file is created in the same dir to stay on the same filesystem).
+func checkTxRecordsDir(dir string) error {
+	probe := path.Join(dir, TxRecordsFile+".probe")
+	f, err := os.OpenFile(probe+".new", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("tx records dir %s is not writable: %w", dir, err)
+	}
+	_ = f.Close()
+	defer func() {
+		_ = os.Remove(probe + ".new")
+		_ = os.Remove(probe)
+	}()
+	if err = os.Rename(probe+".new", probe); err != nil {
+		return fmt.Errorf("tx records dir %s does not support atomic rename: %w", dir, err)
+	}
 	return nil
 }
 
@@ -135,6 +259,10 @@ func (r *txRecords[T, Constraint]) rotate(all map[string]*txSortedMap[T, Constra
 	if err != nil {
 		return err
 	}
+	if _, err = replacement.Write(txRecordsHeader()); err != nil {
+		replacement.Close()
+		return err
+	}
 	var batch []byte
 	batchCount := 0
 	record := 0
@@ -180,6 +308,10 @@ func (r *txRecords[T, Constraint]) rotate(all map[string]*txSortedMap[T, Constra
 		return err
 	}
 	r.writer = sink
+	if info, err := sink.Stat(); err == nil {
+		traceRecordsFileSize(info.Size())
+	}
+	traceRecordsWritten("rotate", record, 0)
 	r.logger.Infof("TxRecords rotated and regenerated txRecords, wrote transactions: %d, accounts: %d", record, len(all))
 
 	return nil
@@ -192,6 +324,9 @@ func GetAllTxRecords(filePath string) ([][]byte, error) {
 	}
 	defer input.Close()
 	buf := bufio.NewReader(input)
+	if _, err = readTxRecordsVersion(buf); err != nil {
+		return nil, err
+	}
 	var res [][]byte
 	for {
 		lengthBytes, err := buf.Peek(TxRecordPrefixLength)
@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"context"
 	"os"
+	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/axiomesh/axiom-kit/types"
+	"github.com/axiomesh/axiom-ledger/internal/chainstate"
+	"github.com/axiomesh/axiom-ledger/pkg/repo"
 )
 
 func TestTxRecords(t *testing.T) {
@@ -126,6 +130,46 @@ func TestTxRecords_load(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestTxRecords_LoadWithBoundedMemory(t *testing.T) {
+	pool := mockTxPoolImpl[types.Transaction, *types.Transaction](t)
+	err := pool.Start()
+	assert.Nil(t, err)
+	defer pool.Stop()
+	records := pool.txRecords
+	records.loadingTokens = make(chan struct{}, 3)
+
+	s, err := types.GenerateSigner()
+	assert.Nil(t, err)
+	for _, tx := range constructTxs(s, 7) {
+		err = records.insert(tx)
+		assert.Nil(t, err)
+	}
+	input, err := os.Open(records.filePath)
+	assert.Nil(t, err)
+	defer input.Close()
+
+	taskDoneCh := make(chan struct{}, 1)
+	batchCh := records.load(input, taskDoneCh)
+	batch := <-batchCh
+	assert.Equal(t, 3, len(batch))
+	// loading is blocked until the loaded txs are released
+	select {
+	case <-batchCh:
+		t.Fatal("loading should be blocked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	records.release(len(batch))
+	batch = <-batchCh
+	assert.Equal(t, 3, len(batch))
+	records.release(len(batch))
+	<-taskDoneCh
+	batch = <-batchCh
+	assert.Equal(t, 1, len(batch))
+	records.release(len(batch))
+	assert.Equal(t, 0, len(records.loadingTokens))
+}
+
 func TestTxRecords_LoadMoreThanOneBatch(t *testing.T) {
 	// init pool and txs
 	pool := mockTxPoolImpl[types.Transaction, *types.Transaction](t)
@@ -201,3 +245,102 @@ func TestTxRecords_RotateMoreThanOneBatch(t *testing.T) {
 	assert.Nil(t, err)
 	assert.True(t, len(records) == TxRecordsBatchSize+1)
 }
+
+func TestTxRecords_CustomDir(t *testing.T) {
+	recordsDir := path.Join(t.TempDir(), "records")
+	poolConf := NewMockTxPoolConfig(t)
+	poolConf.TxRecordsDir = recordsDir
+	r := repo.MockRepo(t)
+	pool, err := newTxPoolImpl[types.Transaction, *types.Transaction](poolConf, chainstate.NewMockChainState(r.GenesisConfig, nil))
+	assert.Nil(t, err)
+	assert.Equal(t, path.Join(recordsDir, TxRecordsFile), pool.txRecordsFile)
+	assert.FileExists(t, pool.txRecordsFile)
+
+	s, err := types.GenerateSigner()
+	assert.Nil(t, err)
+	_, err = pool.addTx(constructTx(s, 0), true)
+	assert.Nil(t, err)
+	err = pool.txRecords.rotate(pool.txStore.allTxs)
+	assert.Nil(t, err)
+	assert.NoFileExists(t, pool.txRecordsFile+".new")
+
+	// probe files should be removed after check
+	entries, err := os.ReadDir(recordsDir)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+
+	assert.Equal(t, path.Join(repo.GetStoragePath("root", "txpool"), TxRecordsFile), GetTxRecordsFilePath("root", ""))
+}
+
+func TestTxRecords_Header(t *testing.T) {
+	pool := mockTxPoolImpl[types.Transaction, *types.Transaction](t)
+	records := pool.txRecords
+	s, err := types.GenerateSigner()
+	assert.Nil(t, err)
+	tx := constructTx(s, 0)
+	_, err = pool.addTx(tx, true)
+	assert.Nil(t, err)
+
+	err = records.rotate(pool.txStore.allTxs)
+	assert.Nil(t, err)
+	assert.Nil(t, records.close())
+	raw, err := os.ReadFile(records.filePath)
+	assert.Nil(t, err)
+	assert.Equal(t, txRecordsHeader(), raw[:TxRecordPrefixLength])
+	all, err := GetAllTxRecords(records.filePath)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(all))
+
+	// headerless file is treated as version 0
+	headerless := path.Join(t.TempDir(), TxRecordsFile)
+	err = os.WriteFile(headerless, raw[TxRecordPrefixLength:], 0644)
+	assert.Nil(t, err)
+	all, err = GetAllTxRecords(headerless)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(all))
+
+	input, err := os.Open(headerless)
+	assert.Nil(t, err)
+	defer input.Close()
+	taskDoneCh := make(chan struct{}, 1)
+	batchCh := records.load(input, taskDoneCh)
+	<-taskDoneCh
+	batch := <-batchCh
+	assert.Equal(t, 1, len(batch))
+	assert.Equal(t, tx.RbftGetTxHash(), batch[0].RbftGetTxHash())
+
+	// newer version is not supported
+	newer := append(txRecordsMagic[:], TxRecordsVersion+1)
+	err = os.WriteFile(headerless, append(newer, raw[TxRecordPrefixLength:]...), 0644)
+	assert.Nil(t, err)
+	_, err = GetAllTxRecords(headerless)
+	assert.NotNil(t, err)
+}
+
+func TestTxRecords_HeaderOnNewFile(t *testing.T) {
+	// the file of a new pool has the header before the first rotation
+	poolConf := NewMockTxPoolConfig(t)
+	r := repo.MockRepo(t)
+	pool, err := newTxPoolImpl[types.Transaction, *types.Transaction](poolConf, chainstate.NewMockChainState(r.GenesisConfig, nil))
+	assert.Nil(t, err)
+	raw, err := os.ReadFile(pool.txRecordsFile)
+	assert.Nil(t, err)
+	assert.Equal(t, txRecordsHeader(), raw)
+
+	// empty file gets the header, non-empty file is left untouched
+	emptyFile := path.Join(t.TempDir(), TxRecordsFile)
+	err = os.WriteFile(emptyFile, nil, 0644)
+	assert.Nil(t, err)
+	assert.Nil(t, initTxRecordsFile(emptyFile))
+	raw, err = os.ReadFile(emptyFile)
+	assert.Nil(t, err)
+	assert.Equal(t, txRecordsHeader(), raw)
+
+	legacy := []byte{1, 0, 0, 0, 0, 0, 0, 0, 0xff}
+	err = os.WriteFile(emptyFile, legacy, 0644)
+	assert.Nil(t, err)
+	assert.Nil(t, initTxRecordsFile(emptyFile))
+	raw, err = os.ReadFile(emptyFile)
+	assert.Nil(t, err)
+	assert.Equal(t, legacy, raw)
+}
@@ -3,6 +3,7 @@ package txpool
 import (
 	"context"
 	"fmt"
+	"hash"
 	"math/big"
 	"os"
 	"path"
@@ -23,7 +24,7 @@ import (
 	"github.com/axiomesh/axiom-ledger/internal/components"
 	"github.com/axiomesh/axiom-ledger/internal/components/status"
 	"github.com/axiomesh/axiom-ledger/internal/components/timer"
-	"github.com/axiomesh/axiom-ledger/internal/storagemgr"
+	"github.com/axiomesh/axiom-ledger/internal/consensus/common"
 	"github.com/axiomesh/axiom-ledger/pkg/repo"
 )
 
@@ -47,13 +48,20 @@ type txPoolImpl[T any, Constraint types.TXConstraint[T]] struct {
 	toleranceNonceGap      uint64
 	toleranceTime          time.Duration
 	toleranceRemoveTime    time.Duration
+	txMaxAge               time.Duration
 	cleanEmptyAccountTime  time.Duration
 	rotateTxLocalsInterval time.Duration
 	poolMaxSize            uint64
+	highWatermark          uint64                  // number of txs crossing which fires an early warning, 0 means disabled
+	lowWatermark           uint64                  // number of txs dropping below which recovers from the high watermark
+	batchHasher            func() hash.Hash        // hash function of batch digest
+	maxAccountsPerBatch    uint64                  // max number of distinct sender accounts in a batch, 0 means unlimited
+	evictByFee             bool                    // evict the cheapest tx for a higher fee tx when the pool is full
 	priceLimit             atomic.Pointer[big.Int] // Minimum gas price to enforce for acceptance into the pool
 	PriceBump              uint64                  // Minimum price bump percentage to replace an already existing transaction (nonce)
 	enableLocalsPersist    bool
 	txRecordsFile          string
+	pendingTxsFile         string
 	enablePricePriority    bool
 
 	getAccountNonce       GetAccountNonceFunc
@@ -73,7 +81,29 @@ type txPoolImpl[T any, Constraint types.TXConstraint[T]] struct {
 	wg     sync.WaitGroup
 }
 
-func NewTxPool[T any, Constraint types.TXConstraint[T]](config Config, chainState *chainstate.ChainState) (commonpool.TxPool[T, Constraint], error) {
+// TxPool is the txpool held by the node, it extends commonpool.TxPool with the methods not provided by axiom-kit
+type TxPool[T any, Constraint types.TXConstraint[T]] interface {
+	commonpool.TxPool[T, Constraint]
+
+	// FilterKnownTxs returns the subset of hashes whose txs are in txpool
+	FilterKnownTxs(hashes []string) map[string]struct{}
+
+	// PendingBatches returns the generation time(unix nano) of the batches which are not committed yet, keyed by batch digest
+	PendingBatches() map[string]int64
+
+	// ExportPending serializes all txs in pool, the result can be restored by ImportPending
+	ExportPending() ([]byte, error)
+
+	// ImportPending restores the txs exported by ExportPending as remote txs
+	ImportPending(data []byte) error
+}
+
+var (
+	_ TxPool[types.Transaction, *types.Transaction] = (*txPoolImpl[types.Transaction, *types.Transaction])(nil)
+	_ common.TxPool                                 = (*txPoolImpl[types.Transaction, *types.Transaction])(nil)
+)
+
+func NewTxPool[T any, Constraint types.TXConstraint[T]](config Config, chainState *chainstate.ChainState) (TxPool[T, Constraint], error) {
 	return newTxPoolImpl[T, Constraint](config, chainState)
 }
 
@@ -83,6 +113,10 @@ func (p *txPoolImpl[T, Constraint]) Start() error {
 	}
 	go p.listenEvent()
 
+	if p.enableLocalsPersist {
+		p.importPendingFile()
+	}
+
 	err := p.timerMgr.StartTimer(RemoveTx)
 	if err != nil {
 		return err
@@ -97,6 +131,12 @@ func (p *txPoolImpl[T, Constraint]) Start() error {
 			return err
 		}
 	}
+	if p.txMaxAge > 0 {
+		err = p.timerMgr.StartTimer(ExpireTx)
+		if err != nil {
+			return err
+		}
+	}
 
 	p.started.Store(true)
 	p.logger.Info("txpool started")
@@ -124,10 +164,12 @@ func (p *txPoolImpl[T, Constraint]) processRecords() error {
 				return nil
 			}
 			totalInsertCount += p.processRecordsTask(txs)
+			p.txRecords.release(len(txs))
 		case <-taskDoneCh:
 			close(txsCh)
 			for txs := range txsCh {
 				totalInsertCount += p.processRecordsTask(txs)
+				p.txRecords.release(len(txs))
 			}
 
 			p.logger.WithFields(logrus.Fields{
@@ -151,6 +193,7 @@ func (p *txPoolImpl[T, Constraint]) processRecordsTask(txs []*T) int {
 	if p.checkPoolFull() {
 		p.setFull()
 	}
+	p.checkHighWatermark()
 
 	p.logger.WithFields(logrus.Fields{
 		"add_num": insertCount,
@@ -243,7 +286,7 @@ func (p *txPoolImpl[T, Constraint]) dispatchAddTxsEvent(event *addTxsEvent) []tx
 	switch event.EventType {
 	case localTxEvent:
 		req := event.Event.(*reqLocalTx[T, Constraint])
-		if p.statusMgr.In(PoolFull) {
+		if p.statusMgr.In(PoolFull) && !(p.evictByFee && p.makeRoomByFee(req.tx, p.newFeeEvictor())) {
 			traceRejectTx(ErrTxPoolFull.Error())
 			req.errCh <- ErrTxPoolFull
 			return nil
@@ -302,25 +345,35 @@ func (p *txPoolImpl[T, Constraint]) dispatchAddTxsEvent(event *addTxsEvent) []tx
 			}
 			if p.statusMgr.In(PoolFull) {
 				overSpaceTxs = txs
+				txs = nil
+			}
+			if len(txs) == 0 && p.statusMgr.In(PoolFull) && !(p.evictByFee && len(overSpaceTxs) > 0) {
 				return nil
 			}
 		}
 
-		lo.ForEach(txs, func(tx *T, i int) {
+		addRemoteTx := func(tx *T) {
 			replaced, err := p.addTx(tx, false)
 
 			// trigger remove all high nonce txs, ensure this event is only triggered once
-			if errors.Is(err, ErrNonceTooHigh) && !nonceTooHighAccounts[Constraint(txs[i]).RbftGetFrom()] {
-				removeEvent := p.genHighNonceEvent(txs[i])
+			if errors.Is(err, ErrNonceTooHigh) && !nonceTooHighAccounts[Constraint(tx).RbftGetFrom()] {
+				removeEvent := p.genHighNonceEvent(tx)
 				nextEvents = append(nextEvents, removeEvent)
-				nonceTooHighAccounts[Constraint(txs[i]).RbftGetFrom()] = true
+				nonceTooHighAccounts[Constraint(tx).RbftGetFrom()] = true
 			}
 			if err != nil {
 				traceRejectTx(err.Error())
 			} else {
 				p.updateValidTxs(&validTxs, tx, replaced)
 			}
+		}
+		lo.ForEach(txs, func(tx *T, _ int) {
+			addRemoteTx(tx)
 		})
+		// make room for the over space txs paying higher fee than the cheapest txs in pool
+		if p.evictByFee && len(overSpaceTxs) > 0 {
+			overSpaceTxs = p.admitByFee(overSpaceTxs, addRemoteTx)
+		}
 
 		p.postConsensusSignal(validTxs)
 
@@ -351,6 +404,7 @@ func (p *txPoolImpl[T, Constraint]) dispatchAddTxsEvent(event *addTxsEvent) []tx
 	if p.checkPoolFull() {
 		p.setFull()
 	}
+	p.checkHighWatermark()
 
 	return nextEvents
 }
@@ -474,6 +528,13 @@ func (p *txPoolImpl[T, Constraint]) dispatchRemoveTxsEvent(event *removeTxsEvent
 			p.logger.Infof("Successful remove timeout txs, count: %d", removeCount)
 			traceRemovedTx("timeout", removeCount)
 		}
+	case expiredTxsEvent:
+		removeCount = p.handleRemoveExpiredTxs()
+		if removeCount > 0 {
+			p.logger.Infof("Successfully remove expired txs, count: %d", removeCount)
+			traceRemovedTx("expired", removeCount)
+			expiredTxNum.Add(float64(removeCount))
+		}
 	case committedTxsEvent:
 		removeCount = p.handleRemoveStateUpdatingTxs(event.Event.(*reqRemoveCommittedTxs).txPointerList)
 		if removeCount > 0 {
@@ -498,6 +559,7 @@ func (p *txPoolImpl[T, Constraint]) dispatchRemoveTxsEvent(event *removeTxsEvent
 	if !p.checkPoolFull() {
 		p.setNotFull()
 	}
+	p.checkHighWatermark()
 }
 
 // remove invalid txs(invalid signature or gasPrice too low)
@@ -668,12 +730,22 @@ func (p *txPoolImpl[T, Constraint]) dispatchPoolInfoEvent(event *poolInfoEvent)
 	case reqTxEvent:
 		req := event.Event.(*reqTxMsg[T, Constraint])
 		req.ch <- p.handleGetPendingTxByHash(req.hash)
+	case reqKnownTxsEvent:
+		req := event.Event.(*reqKnownTxsMsg)
+		req.ch <- p.handleFilterKnownTxs(req.hashes)
+	case reqPendingBatchesEvent:
+		req := event.Event.(*reqPendingBatchesMsg)
+		req.ch <- p.handlePendingBatches()
 	case reqAccountMetaEvent:
 		req := event.Event.(*reqAccountPoolMetaMsg[T, Constraint])
 		req.ch <- p.handleGetAccountMeta(req.account, req.full)
 	case reqPoolMetaEvent:
 		req := event.Event.(*reqPoolMetaMsg[T, Constraint])
 		req.ch <- p.handleGetMeta(req.full)
+	case reqExportPendingEvent:
+		req := event.Event.(*reqExportPendingMsg)
+		data, err := p.handleExportPending(true)
+		req.ch <- &respExportPending{data: data, err: err}
 	}
 }
 
@@ -817,31 +889,15 @@ func (p *txPoolImpl[T, Constraint]) addTx(tx *T, local bool) (bool, error) {
 	txAccount := Constraint(tx).RbftGetFrom()
 	txHash := Constraint(tx).RbftGetTxHash()
 	txNonce := Constraint(tx).RbftGetNonce()
-	gasPrice := Constraint(tx).RbftGetGasPrice()
-	var (
-		needReplace bool
-		replaced    bool
-	)
+	var replaced bool
 
 	currentSeqNo := p.txStore.nonceCache.getPendingNonce(txAccount)
 
 	// 1. validate tx
-	err := p.validateTx(txHash, txNonce, currentSeqNo, gasPrice)
-	if errors.Is(err, ErrNonceTooLow) || err == nil {
-		// if exist old tx with same nonce, replace it
-		if p.txStore.allTxs[txAccount] != nil {
-			oldTx, exist := p.txStore.allTxs[txAccount].items[txNonce]
-			if exist {
-				if p.isValidPriceBump(oldTx.getGasPrice(), gasPrice) {
-					p.logger.Warningf("Receive duplicate nonce transaction [account: %s, nonce: %d, hash: %s],"+
-						" will replace old tx[hash: %s]", txAccount, txNonce, txHash, oldTx.getHash())
-					needReplace = true
-					err = nil
-				} else {
-					err = ErrBelowPriceBump
-				}
-			}
-		}
+	needReplace, err := p.checkTx(tx)
+	if needReplace {
+		p.logger.Warningf("Receive duplicate nonce transaction [account: %s, nonce: %d, hash: %s],"+
+			" will replace old tx[hash: %s]", txAccount, txNonce, txHash, p.txStore.allTxs[txAccount].items[txNonce].getHash())
 	}
 	if err != nil {
 		traceRejectTx(err.Error())
@@ -869,6 +925,30 @@ func (p *txPoolImpl[T, Constraint]) addTx(tx *T, local bool) (bool, error) {
 	return replaced, nil
 }
 
+// checkTx validates tx against the pool without changing it, needReplace reports whether tx is a valid replacement of
+// the pool tx with the same nonce
+func (p *txPoolImpl[T, Constraint]) checkTx(tx *T) (needReplace bool, err error) {
+	txAccount := Constraint(tx).RbftGetFrom()
+	txNonce := Constraint(tx).RbftGetNonce()
+	gasPrice := Constraint(tx).RbftGetGasPrice()
+	currentSeqNo := p.txStore.nonceCache.getPendingNonce(txAccount)
+
+	err = p.validateTx(Constraint(tx).RbftGetTxHash(), txNonce, currentSeqNo, gasPrice)
+	if errors.Is(err, ErrNonceTooLow) || err == nil {
+		// if exist old tx with same nonce, replace it
+		if p.txStore.allTxs[txAccount] != nil {
+			oldTx, exist := p.txStore.allTxs[txAccount].items[txNonce]
+			if exist {
+				if p.isValidPriceBump(oldTx.getGasPrice(), gasPrice) {
+					return true, nil
+				}
+				err = ErrBelowPriceBump
+			}
+		}
+	}
+	return false, err
+}
+
 func (p *txPoolImpl[T, Constraint]) validateTx(txHash string, txNonce, currentSeqNo uint64, gasPrice *big.Int) error {
 	// 1. reject duplicate tx
 	if pointer := p.txStore.txHashMap[txHash]; pointer != nil {
@@ -968,6 +1048,72 @@ func (p *txPoolImpl[T, Constraint]) handleRemoveTimeoutTxs() int {
 	return len(removedTxs)
 }
 
+// handleRemoveExpiredTxs evicts the txs staying in pool longer than txMaxAge, the following txs of the same account
+// are evicted too since they can not be executed without the expired one, batched txs are left to be committed.
+func (p *txPoolImpl[T, Constraint]) handleRemoveExpiredTxs() int {
+	if p.txMaxAge <= 0 {
+		return 0
+	}
+	now := time.Now().UnixNano()
+	updateAccounts := make(map[string]uint64)
+	removeCount := 0
+	removePriorityCount := 0
+	for account, list := range p.txStore.allTxs {
+		// find the expired tx with the lowest nonce
+		var expiredTx *internalTransaction[T, Constraint]
+		list.index.data.Ascend(func(i btree.Item) bool {
+			poolTx := list.items[i.(*sortedNonceKey).nonce]
+			if now-poolTx.arrivedTime > p.txMaxAge.Nanoseconds() {
+				expiredTx = poolTx
+				return false
+			}
+			return true
+		})
+		if expiredTx == nil {
+			continue
+		}
+
+		removeTxs := list.behind(expiredTx.getNonce())
+		if lo.ContainsBy(removeTxs, func(poolTx *internalTransaction[T, Constraint]) bool {
+			_, ok := p.txStore.batchedTxs[txPointer{account: account, nonce: poolTx.getNonce()}]
+			return ok
+		}) {
+			continue
+		}
+
+		pendingNonce := p.txStore.nonceCache.getPendingNonce(account)
+		var priorityCount int
+		if p.enablePricePriority {
+			if expiredTx.getNonce() < pendingNonce {
+				priorityCount = len(p.txStore.priorityByPrice.removeTxBehindNonce(expiredTx))
+			}
+		} else {
+			priorityCount = lo.CountBy(removeTxs, func(poolTx *internalTransaction[T, Constraint]) bool {
+				return poolTx.getNonce() < pendingNonce
+			})
+		}
+		if err := p.cleanTxsByAccount(account, list, removeTxs, true); err != nil {
+			p.logger.Errorf("cleanTxsByAccount failed: %s", err)
+			continue
+		}
+		removeCount += len(removeTxs)
+		removePriorityCount += priorityCount
+		p.revertPendingNonce(&txPointer{account: account, nonce: expiredTx.getNonce()}, updateAccounts)
+	}
+
+	if p.txStore.priorityNonBatchSize < uint64(removePriorityCount) {
+		p.logger.Errorf("decrease nonBatchSize error, want decrease to %d, actual size %d", removePriorityCount, p.txStore.priorityNonBatchSize)
+		p.setPriorityNonBatchSize(0)
+	} else {
+		p.decreasePriorityNonBatchSize(uint64(removePriorityCount))
+	}
+
+	for account, pendingNonce := range updateAccounts {
+		p.logger.Debugf("Account %s revert it's pendingNonce to %d after removing expired txs", account, pendingNonce)
+	}
+	return removeCount
+}
+
 // GetUncommittedTransactions returns the uncommitted transactions.
 // not used
 func (p *txPoolImpl[T, Constraint]) GetUncommittedTransactions(_ uint64) []*T {
@@ -978,6 +1124,9 @@ func (p *txPoolImpl[T, Constraint]) Stop() {
 	p.timerMgr.Stop()
 	p.cancel()
 	p.wg.Wait()
+	if p.enableLocalsPersist {
+		p.exportPendingFile()
+	}
 	if p.txRecords != nil {
 		if err := p.txRecords.close(); err != nil {
 			p.logger.Errorf("Failed to close txRecords: %v", err)
@@ -999,6 +1148,10 @@ func (p *txPoolImpl[T, Constraint]) getPriceLimit() *big.Int {
 func newTxPoolImpl[T any, Constraint types.TXConstraint[T]](config Config, chainState *chainstate.ChainState) (*txPoolImpl[T, Constraint], error) {
 	// check config parameters
 	config.sanitize()
+	batchHasher, err := newBatchHasher(config.BatchDigestAlgo)
+	if err != nil {
+		return nil, err
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 
 	txpoolImp := &txPoolImpl[T, Constraint]{
@@ -1011,10 +1164,16 @@ func newTxPoolImpl[T any, Constraint types.TXConstraint[T]](config Config, chain
 		toleranceTime:          config.ToleranceTime,
 		toleranceNonceGap:      config.ToleranceNonceGap,
 		toleranceRemoveTime:    config.ToleranceRemoveTime,
+		txMaxAge:               config.TxMaxAge,
 		cleanEmptyAccountTime:  config.CleanEmptyAccountTime,
 		poolMaxSize:            config.PoolSize,
+		highWatermark:          config.PoolSize * config.HighWatermarkPercent / 100,
+		lowWatermark:           config.PoolSize * config.LowWatermarkPercent / 100,
 		rotateTxLocalsInterval: config.RotateTxLocalsInterval,
 		PriceBump:              config.PriceBump,
+		batchHasher:            batchHasher,
+		maxAccountsPerBatch:    config.MaxAccountsPerBatch,
+		evictByFee:             config.EvictByFee,
 
 		statusMgr: status.NewStatusMgr(),
 
@@ -1025,9 +1184,10 @@ func newTxPoolImpl[T any, Constraint types.TXConstraint[T]](config Config, chain
 	txpoolImp.txStore = newTransactionStore[T, Constraint](config.GetAccountNonce, config.Logger)
 
 	txpoolImp.enableLocalsPersist = config.EnableLocalsPersist
-	txpoolImp.txRecordsFile = path.Join(repo.GetStoragePath(config.RepoRoot, storagemgr.TxPool), TxRecordsFile)
+	txpoolImp.txRecordsFile = GetTxRecordsFilePath(config.RepoRoot, config.TxRecordsDir)
+	txpoolImp.pendingTxsFile = path.Join(path.Dir(txpoolImp.txRecordsFile), PendingTxsFile)
 	if txpoolImp.enableLocalsPersist {
-		txpoolImp.txRecords = newTxRecords[T, Constraint](txpoolImp.txRecordsFile, config.Logger)
+		txpoolImp.txRecords = newTxRecords[T, Constraint](txpoolImp.txRecordsFile, config.MaxLoadingRecordTxs, config.Logger)
 	}
 	if config.GenerateBatchType == repo.GenerateBatchByGasPrice {
 		txpoolImp.enablePricePriority = true
@@ -1035,7 +1195,7 @@ func newTxPoolImpl[T any, Constraint types.TXConstraint[T]](config Config, chain
 
 	// init timer for remove tx
 	txpoolImp.timerMgr = timer.NewTimerManager(txpoolImp.logger)
-	err := txpoolImp.timerMgr.CreateTimer(RemoveTx, txpoolImp.toleranceRemoveTime, txpoolImp.handleRemoveTimeout)
+	err = txpoolImp.timerMgr.CreateTimer(RemoveTx, txpoolImp.toleranceRemoveTime, txpoolImp.handleRemoveTimeout)
 	if err != nil {
 		return nil, err
 	}
@@ -1043,6 +1203,12 @@ func newTxPoolImpl[T any, Constraint types.TXConstraint[T]](config Config, chain
 	if err != nil {
 		return nil, err
 	}
+	if txpoolImp.txMaxAge > 0 {
+		err = txpoolImp.timerMgr.CreateTimer(ExpireTx, min(txpoolImp.txMaxAge, maxExpireTxCheckInterval), txpoolImp.handleRemoveTimeout)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if txpoolImp.enableLocalsPersist {
 		if !fileutil.ExistDir(path.Dir(txpoolImp.txRecordsFile)) {
 			err = os.MkdirAll(filepath.Dir(txpoolImp.txRecordsFile), 0755)
@@ -1050,12 +1216,12 @@ func newTxPoolImpl[T any, Constraint types.TXConstraint[T]](config Config, chain
 				return nil, err
 			}
 		}
+		if err = checkTxRecordsDir(path.Dir(txpoolImp.txRecordsFile)); err != nil {
+			return nil, err
+		}
 
-		if !fileutil.Exist(txpoolImp.txRecordsFile) {
-			_, err = os.Create(txpoolImp.txRecordsFile)
-			if err != nil {
-				return nil, err
-			}
+		if err = initTxRecordsFile(txpoolImp.txRecordsFile); err != nil {
+			return nil, err
 		}
 		err = txpoolImp.timerMgr.CreateTimer(RotateTxLocals, txpoolImp.rotateTxLocalsInterval, txpoolImp.handleRemoveTimeout)
 		if err != nil {
@@ -1070,6 +1236,7 @@ func newTxPoolImpl[T any, Constraint types.TXConstraint[T]](config Config, chain
 	txpoolImp.logger.Infof("TxPool enable generate empty batch = %v", txpoolImp.chainState.EpochInfo.ConsensusParams.EnableTimedGenEmptyBlock)
 	txpoolImp.logger.Infof("TxPool tolerance time = %v", txpoolImp.toleranceTime)
 	txpoolImp.logger.Infof("TxPool tolerance remove time = %v", txpoolImp.toleranceRemoveTime)
+	txpoolImp.logger.Infof("TxPool tx max age = %v", txpoolImp.txMaxAge)
 	txpoolImp.logger.Infof("TxPool tolerance nonce gap = %d", txpoolImp.toleranceNonceGap)
 	txpoolImp.logger.Infof("TxPool clean empty account time = %v", txpoolImp.cleanEmptyAccountTime)
 	txpoolImp.logger.Infof("TxPool rotate tx locals interval = %v", txpoolImp.rotateTxLocalsInterval)
@@ -1077,6 +1244,7 @@ func newTxPoolImpl[T any, Constraint types.TXConstraint[T]](config Config, chain
 	txpoolImp.logger.Infof("TxPool tx records file = %s", txpoolImp.txRecordsFile)
 	txpoolImp.logger.Infof("TxPool price limit = %v, priceBump = %v", txpoolImp.getPriceLimit(), txpoolImp.PriceBump)
 	txpoolImp.logger.Infof("TxPool enable price priority = %v", txpoolImp.enablePricePriority)
+	txpoolImp.logger.Infof("TxPool batch digest algo = %s", batchDigestAlgoName(config.BatchDigestAlgo))
 	return txpoolImp, nil
 }
 
@@ -1135,7 +1303,7 @@ func (p *txPoolImpl[T, Constraint]) generateRequestBatch(typ int) (*commonpool.R
 }
 
 func (p *txPoolImpl[T, Constraint]) validateTxData(tx *T) error {
-	minGasPrice := p.chainState.EpochInfo.FinanceParams.MinGasPrice.ToBigInt()
+	minGasPrice := p.chainState.CurrentGasPrice()
 
 	if !p.enablePricePriority {
 		if Constraint(tx).RbftGetGasPrice().Cmp(minGasPrice) < 0 {
@@ -1153,9 +1321,39 @@ func (p *txPoolImpl[T, Constraint]) popExecutableTxs(size uint64, batch *commonp
 	return p.popExecutableTxsByTime(size, batch)
 }
 
+// batchAccounts tracks the distinct sender accounts of the batch being generated.
+type batchAccounts struct {
+	limit    uint64
+	accounts map[string]struct{}
+}
+
+func (p *txPoolImpl[T, Constraint]) newBatchAccounts() *batchAccounts {
+	return &batchAccounts{limit: p.maxAccountsPerBatch, accounts: make(map[string]struct{})}
+}
+
+// admit reports whether the txs of account can be filled into the batch, the account is tracked once admitted.
+func (b *batchAccounts) admit(account string) bool {
+	if _, ok := b.accounts[account]; ok {
+		return true
+	}
+	if b.limit != 0 && uint64(len(b.accounts)) >= b.limit {
+		return false
+	}
+	b.accounts[account] = struct{}{}
+	return true
+}
+
 func (p *txPoolImpl[T, Constraint]) popExecutableTxsByPrice(size uint64, batch *commonpool.RequestHashBatch[T, Constraint]) map[string]*internalTransaction[T, Constraint] {
 	currentSize := uint64(0)
 	removeInvalidTxs := make(map[string]*internalTransaction[T, Constraint])
+	accounts := p.newBatchAccounts()
+	// txs of accounts exceeding maxAccountsPerBatch are taken out of the price queue temporarily, and deferred to the next batch
+	var deferredTxs []*internalTransaction[T, Constraint]
+	defer func() {
+		for _, tx := range deferredTxs {
+			p.txStore.priorityByPrice.txsByPrice.push(tx)
+		}
+	}()
 	for p.txStore.priorityByPrice.txsByPrice.length() > 0 && currentSize < size {
 		poolTx := p.txStore.priorityByPrice.peek()
 		from := poolTx.getAccount()
@@ -1175,6 +1373,10 @@ func (p *txPoolImpl[T, Constraint]) popExecutableTxsByPrice(size uint64, batch *
 			}
 			continue
 		}
+		if !accounts.admit(from) {
+			deferredTxs = append(deferredTxs, p.txStore.priorityByPrice.txsByPrice.pop())
+			continue
+		}
 
 		p.txStore.priorityByPrice.pop()
 		batch.FillBatchItem(poolTx.rawTx, poolTx.local)
@@ -1187,6 +1389,7 @@ func (p *txPoolImpl[T, Constraint]) popExecutableTxsByPrice(size uint64, batch *
 func (p *txPoolImpl[T, Constraint]) popExecutableTxsByTime(batchSize uint64, txBatch *commonpool.RequestHashBatch[T, Constraint]) map[string]*internalTransaction[T, Constraint] {
 	skippedTxs := make(map[txPointer]*internalTransaction[T, Constraint])
 	removeInvalidTxs := make(map[string]*internalTransaction[T, Constraint])
+	accounts := p.newBatchAccounts()
 	p.txStore.priorityByTime.data.Ascend(func(a btree.Item) bool {
 		tx := a.(*orderedIndexKey)
 		ptr := txPointer{account: tx.account, nonce: tx.nonce}
@@ -1214,6 +1417,10 @@ func (p *txPoolImpl[T, Constraint]) popExecutableTxsByTime(batchSize uint64, txB
 		if _, ok := p.txStore.batchedTxs[ptr]; ok {
 			return true
 		}
+		// defer txs of accounts exceeding maxAccountsPerBatch to the next batch
+		if !accounts.admit(tx.account) {
+			return true
+		}
 		txSeq := tx.nonce
 		// p.logger.Debugf("txpool txNonce:%s-%d", tx.account, tx.nonce)
 		commitNonce := p.txStore.nonceCache.getCommitNonce(tx.account)
@@ -1310,7 +1517,7 @@ func (p *txPoolImpl[T, Constraint]) handleGenerateRequestBatch(typ int) (
 		return removeInvalidTxs, nil, errors.New("there is no valid tx to generate batch")
 	}
 	txBatch.Timestamp = time.Now().UnixNano()
-	batchHash := txBatch.GenerateBatchHash()
+	batchHash := p.generateBatchHash(txBatch)
 	txBatch.BatchHash = batchHash
 	p.txStore.batchesCache[batchHash] = txBatch
 
@@ -1970,7 +2177,7 @@ func (p *txPoolImpl[T, Constraint]) handleReConstructBatchByOrder(oldBatch *comm
 		Timestamp:  oldBatch.Timestamp,
 	}
 	// The given batch hash should match with the calculated batch hash.
-	batch.BatchHash = batch.GenerateBatchHash()
+	batch.BatchHash = p.generateBatchHash(batch)
 	if batch.BatchHash != oldBatch.BatchHash {
 		p.logger.Warningf("The given batch hash %s does not match with the "+
 			"calculated batch hash %s.", oldBatch.BatchHash, batch.BatchHash)
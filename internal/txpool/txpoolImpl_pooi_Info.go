@@ -3,6 +3,7 @@ package txpool
 import (
 	"github.com/google/btree"
 	"github.com/samber/lo"
+	"github.com/sirupsen/logrus"
 
 	commonpool "github.com/axiomesh/axiom-kit/txpool"
 )
@@ -76,6 +77,52 @@ func (p *txPoolImpl[T, Constraint]) handleGetPendingTxByHash(hash string) *T {
 	return item.rawTx
 }
 
+// FilterKnownTxs returns the subset of hashes whose txs are in txpool, it costs a single round-trip of the event loop
+// no matter how many hashes are looked up.
+func (p *txPoolImpl[T, Constraint]) FilterKnownTxs(hashes []string) map[string]struct{} {
+	req := &reqKnownTxsMsg{
+		hashes: hashes,
+		ch:     make(chan map[string]struct{}),
+	}
+	ev := &poolInfoEvent{
+		EventType: reqKnownTxsEvent,
+		Event:     req,
+	}
+	p.postEvent(ev)
+	return <-req.ch
+}
+
+func (p *txPoolImpl[T, Constraint]) handleFilterKnownTxs(hashes []string) map[string]struct{} {
+	known := make(map[string]struct{})
+	for _, hash := range hashes {
+		if p.handleGetPendingTxByHash(hash) != nil {
+			known[hash] = struct{}{}
+		}
+	}
+	return known
+}
+
+// PendingBatches returns the generation time(unix nano) of the batches which are not committed yet, keyed by batch digest
+func (p *txPoolImpl[T, Constraint]) PendingBatches() map[string]int64 {
+	req := &reqPendingBatchesMsg{
+		ch: make(chan map[string]int64),
+	}
+	ev := &poolInfoEvent{
+		EventType: reqPendingBatchesEvent,
+		Event:     req,
+	}
+	p.postEvent(ev)
+	return <-req.ch
+}
+
+func (p *txPoolImpl[T, Constraint]) handlePendingBatches() map[string]int64 {
+	batches := make(map[string]int64, len(p.txStore.batchesCache))
+	for digest, batch := range p.txStore.batchesCache {
+		batches[digest] = batch.Timestamp
+	}
+	return batches
+}
+
 func (p *txPoolImpl[T, Constraint]) GetAccountMeta(account string, full bool) *commonpool.AccountMeta[T, Constraint] {
 	req := &reqAccountPoolMetaMsg[T, Constraint]{
 		account: account,
@@ -215,3 +262,32 @@ func (p *txPoolImpl[T, Constraint]) IsPoolFull() bool {
 func (p *txPoolImpl[T, Constraint]) checkPoolFull() bool {
 	return uint64(len(p.txStore.txHashMap)) >= p.poolMaxSize
 }
+
+// checkHighWatermark warns when the number of txs crosses the high watermark before the pool is full,
+// and recovers when it drops below the low watermark, the gap between them avoids flapping.
+func (p *txPoolImpl[T, Constraint]) checkHighWatermark() {
+	if p.highWatermark == 0 {
+		return
+	}
+	size := uint64(len(p.txStore.txHashMap))
+	switch {
+	case !p.statusMgr.In(PoolHighWatermark) && size >= p.highWatermark:
+		p.statusMgr.On(PoolHighWatermark)
+		highWatermark.Set(1)
+		highWatermarkEventNum.WithLabelValues("crossed").Inc()
+		p.logger.WithFields(logrus.Fields{
+			"size":           size,
+			"high_watermark": p.highWatermark,
+			"pool_size":      p.poolMaxSize,
+		}).Warning("TxPool crossed high watermark")
+	case p.statusMgr.In(PoolHighWatermark) && size < p.lowWatermark:
+		p.statusMgr.Off(PoolHighWatermark)
+		highWatermark.Set(0)
+		highWatermarkEventNum.WithLabelValues("recovered").Inc()
+		p.logger.WithFields(logrus.Fields{
+			"size":          size,
+			"low_watermark": p.lowWatermark,
+			"pool_size":     p.poolMaxSize,
+		}).Info("TxPool dropped below low watermark")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/samber/lo"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -19,6 +20,7 @@ import (
 	log2 "github.com/axiomesh/axiom-kit/log"
 	commonpool "github.com/axiomesh/axiom-kit/txpool"
 	"github.com/axiomesh/axiom-kit/types"
+	"github.com/axiomesh/axiom-ledger/internal/chainstate"
 	"github.com/axiomesh/axiom-ledger/internal/components/timer"
 	"github.com/axiomesh/axiom-ledger/internal/consensus/common"
 	"github.com/axiomesh/axiom-ledger/pkg/repo"
@@ -528,6 +530,149 @@ func TestTxPoolImpl_AddLocalTx(t *testing.T) {
 	})
 }
 
+func TestTxPoolImpl_ExportImportPending(t *testing.T) {
+	ast := assert.New(t)
+	pool := mockTxPoolImpl[types.Transaction, *types.Transaction](t)
+	err := pool.Start()
+	ast.Nil(err)
+	defer pool.Stop()
+
+	s1, err := types.GenerateSigner()
+	ast.Nil(err)
+	s2, err := types.GenerateSigner()
+	ast.Nil(err)
+	txs := constructTxs(s1, 3)
+	// nonce gap, the tx is parked in pool
+	txs = append(txs, constructTx(s2, 0), constructTx(s2, 2))
+	pool.AddRemoteTxs(txs)
+	ast.Equal(uint64(len(txs)), pool.GetTotalPendingTxCount())
+
+	data, err := pool.ExportPending()
+	ast.Nil(err)
+
+	newPool := mockTxPoolImpl[types.Transaction, *types.Transaction](t)
+	err = newPool.Start()
+	ast.Nil(err)
+	defer newPool.Stop()
+	err = newPool.ImportPending(data)
+	ast.Nil(err)
+	ast.Equal(uint64(len(txs)), newPool.GetTotalPendingTxCount())
+	for _, tx := range txs {
+		ast.NotNil(newPool.GetPendingTxByHash(tx.RbftGetTxHash()))
+	}
+	ast.Equal(uint64(3), newPool.GetPendingTxCountByAccount(s1.Addr.String()))
+	ast.Equal(uint64(1), newPool.GetPendingTxCountByAccount(s2.Addr.String()))
+
+	// import again, duplicate txs are rejected by pool
+	err = newPool.ImportPending(data)
+	ast.Nil(err)
+	ast.Equal(uint64(len(txs)), newPool.GetTotalPendingTxCount())
+
+	err = newPool.ImportPending(data[:len(data)-1])
+	ast.ErrorIs(err, ErrInvalidPendingTxs)
+
+	// different txs with the same nonce
+	conflictPool := mockTxPoolImpl[types.Transaction, *types.Transaction](t)
+	err = conflictPool.Start()
+	ast.Nil(err)
+	defer conflictPool.Stop()
+	conflictTx, err := types.GenerateTransactionWithSigner(0, to, big.NewInt(1), nil, s1)
+	ast.Nil(err)
+	conflictPool.AddRemoteTxs([]*types.Transaction{conflictTx})
+	conflictData, err := conflictPool.ExportPending()
+	ast.Nil(err)
+	err = newPool.ImportPending(append(data, conflictData...))
+	ast.ErrorIs(err, ErrInvalidPendingTxs)
+}
+
+func TestTxPoolImpl_PersistPendingOnRestart(t *testing.T) {
+	ast := assert.New(t)
+	r := repo.MockRepo(t)
+	chainState := chainstate.NewMockChainState(r.GenesisConfig, nil)
+	chainState.EpochInfo.FinanceParams.MinGasPrice = types.CoinNumberByMol(0)
+	conf := NewMockTxPoolConfig(t)
+	newPool := func() *txPoolImpl[types.Transaction, *types.Transaction] {
+		pool, err := newTxPoolImpl[types.Transaction, *types.Transaction](conf, chainState)
+		ast.Nil(err)
+		pool.Init(commonpool.ConsensusConfig{SelfID: 1, NotifyGenerateBatchFn: func(typ int) {}})
+		ast.Nil(pool.Start())
+		return pool
+	}
+
+	s1, err := types.GenerateSigner()
+	ast.Nil(err)
+	s2, err := types.GenerateSigner()
+	ast.Nil(err)
+	remoteTxs := constructTxs(s1, 3)
+	localTx := constructTx(s2, 0)
+
+	pool := newPool()
+	pool.AddRemoteTxs(remoteTxs)
+	ast.Nil(pool.AddLocalTx(localTx))
+	ast.Equal(uint64(4), pool.GetTotalPendingTxCount())
+	pool.Stop()
+	ast.FileExists(pool.pendingTxsFile)
+
+	restarted := newPool()
+	defer restarted.Stop()
+	for _, tx := range remoteTxs {
+		ast.NotNil(restarted.GetPendingTxByHash(tx.RbftGetTxHash()))
+	}
+	ast.NotNil(restarted.GetPendingTxByHash(localTx.RbftGetTxHash()))
+	ast.Equal(uint64(4), restarted.GetTotalPendingTxCount())
+	// local tx is restored from tx records and keeps local
+	ast.True(restarted.txStore.allTxs[s2.Addr.String()].items[0].local)
+	ast.False(restarted.txStore.allTxs[s1.Addr.String()].items[0].local)
+	ast.NoFileExists(restarted.pendingTxsFile)
+}
+
+func TestTxPoolImpl_FilterKnownTxs(t *testing.T) {
+	ast := assert.New(t)
+	pool := mockTxPoolImpl[types.Transaction, *types.Transaction](t)
+	err := pool.Start()
+	ast.Nil(err)
+	defer pool.Stop()
+
+	s, err := types.GenerateSigner()
+	ast.Nil(err)
+	txs := constructTxs(s, 3)
+	pool.AddRemoteTxs(txs[:2])
+
+	known := pool.FilterKnownTxs([]string{txs[0].RbftGetTxHash(), txs[1].RbftGetTxHash(), txs[2].RbftGetTxHash()})
+	ast.Equal(2, len(known))
+	ast.Contains(known, txs[0].RbftGetTxHash())
+	ast.Contains(known, txs[1].RbftGetTxHash())
+	ast.NotContains(known, txs[2].RbftGetTxHash())
+	ast.Empty(pool.FilterKnownTxs(nil))
+}
+
+func TestTxPoolImpl_PendingBatches(t *testing.T) {
+	ast := assert.New(t)
+	pool := mockTxPoolImpl[types.Transaction, *types.Transaction](t)
+	pool.chainState.EpochInfo.ConsensusParams.BlockMaxTxNum = 4
+	ch := make(chan int, 1)
+	pool.notifyGenerateBatchFn = func(typ int) {
+		ch <- typ
+	}
+	err := pool.Start()
+	ast.Nil(err)
+	defer pool.Stop()
+	ast.Empty(pool.PendingBatches())
+
+	s, err := types.GenerateSigner()
+	ast.Nil(err)
+	pool.AddRemoteTxs(constructTxs(s, 4))
+	batch, err := pool.GenerateRequestBatch(<-ch)
+	ast.Nil(err)
+
+	batches := pool.PendingBatches()
+	ast.Equal(1, len(batches))
+	ast.Equal(batch.Timestamp, batches[batch.BatchHash])
+
+	pool.RemoveBatches([]string{batch.BatchHash})
+	ast.Empty(pool.PendingBatches())
+}
+
 func TestTxPoolImpl_AddRemoteTxs(t *testing.T) {
 	t.Parallel()
 	t.Run("nonce is wanted", func(t *testing.T) {
@@ -1377,6 +1522,47 @@ func TestTxPoolImpl_GenerateRequestBatch(t *testing.T) {
 		}
 	})
 
+	t.Run("generate batch by gas price with mixed fee accounts", func(t *testing.T) {
+		ast := assert.New(t)
+		pool := mockTxPoolImplWithTyp[types.Transaction, *types.Transaction](t, repo.GenerateBatchByGasPrice)
+		pool.chainState.EpochInfo.ConsensusParams.BlockMaxTxNum = 4
+		ch := make(chan int, 1)
+		pool.notifyGenerateBatchFn = func(typ int) {
+			ch <- typ
+		}
+		err := pool.Start()
+		ast.Nil(err)
+		defer pool.Stop()
+
+		lowFeeSigner, err := types.GenerateSigner()
+		ast.Nil(err)
+		highFeeSigner, err := types.GenerateSigner()
+		ast.Nil(err)
+		basePrice := constructTx(lowFeeSigner, 0).RbftGetGasPrice()
+		price := func(times int64) *big.Int {
+			return new(big.Int).Mul(basePrice, big.NewInt(times))
+		}
+
+		// low fee txs arrive first
+		lowTx0 := constructPoolTxByGas(lowFeeSigner, 0, price(2)).rawTx
+		lowTx1 := constructPoolTxByGas(lowFeeSigner, 1, price(2)).rawTx
+		// the nonce 1 tx pays more than nonce 0 tx, but it must be batched after nonce 0 tx
+		highTx0 := constructPoolTxByGas(highFeeSigner, 0, price(3)).rawTx
+		highTx1 := constructPoolTxByGas(highFeeSigner, 1, price(10)).rawTx
+		pool.AddRemoteTxs([]*types.Transaction{lowTx0, lowTx1, highTx0, highTx1})
+
+		typ := <-ch
+		ast.Equal(commonpool.GenBatchSizeEvent, typ)
+		batch, err := pool.GenerateRequestBatch(typ)
+		ast.Nil(err)
+		ast.Equal([]string{
+			highTx0.RbftGetTxHash(),
+			highTx1.RbftGetTxHash(),
+			lowTx0.RbftGetTxHash(),
+			lowTx1.RbftGetTxHash(),
+		}, batch.TxHashList)
+	})
+
 	t.Run("generate batch size event which is less than batchSize", func(t *testing.T) {
 		ast := assert.New(t)
 		testcase := map[string]*txPoolImpl[types.Transaction, *types.Transaction]{
@@ -2373,6 +2559,167 @@ func TestTxPoolImpl_RemoveStateUpdatingTxs(t *testing.T) {
 	}
 }
 
+func TestTxPoolImpl_HighWatermark(t *testing.T) {
+	ast := assert.New(t)
+	pool := mockTxPoolImpl[types.Transaction, *types.Transaction](t)
+	pool.highWatermark = 4
+	pool.lowWatermark = 2
+	err := pool.Start()
+	ast.Nil(err)
+	defer pool.Stop()
+
+	s, err := types.GenerateSigner()
+	ast.Nil(err)
+	txs := constructTxs(s, 5)
+	pool.AddRemoteTxs(txs[:3])
+	ast.False(pool.statusMgr.In(PoolHighWatermark))
+	pool.AddRemoteTxs(txs[3:])
+	ast.Equal(5, len(pool.txStore.txHashMap))
+	ast.True(pool.statusMgr.In(PoolHighWatermark))
+
+	toPointer := func(tx *types.Transaction) *commonpool.WrapperTxPointer {
+		return &commonpool.WrapperTxPointer{TxHash: tx.RbftGetTxHash(), Account: tx.RbftGetFrom(), Nonce: tx.RbftGetNonce()}
+	}
+	// stay in high watermark until dropping below the low watermark
+	pool.RemoveStateUpdatingTxs([]*commonpool.WrapperTxPointer{toPointer(txs[0]), toPointer(txs[1])})
+	ast.Equal(3, len(pool.txStore.txHashMap))
+	ast.True(pool.statusMgr.In(PoolHighWatermark))
+
+	pool.RemoveStateUpdatingTxs([]*commonpool.WrapperTxPointer{toPointer(txs[2]), toPointer(txs[3])})
+	ast.Equal(1, len(pool.txStore.txHashMap))
+	ast.False(pool.statusMgr.In(PoolHighWatermark))
+}
+
+func TestTxPoolImpl_MaxAccountsPerBatch(t *testing.T) {
+	testcase := map[string]*txPoolImpl[types.Transaction, *types.Transaction]{
+		"price_priority": mockTxPoolImplWithTyp[types.Transaction, *types.Transaction](t, repo.GenerateBatchByGasPrice),
+		"time":           mockTxPoolImplWithTyp[types.Transaction, *types.Transaction](t, repo.GenerateBatchByTime),
+	}
+
+	for name, tc := range testcase {
+		t.Run(name, func(t *testing.T) {
+			ast := assert.New(t)
+			pool := tc
+			pool.maxAccountsPerBatch = 3
+			err := pool.Start()
+			ast.Nil(err)
+			defer pool.Stop()
+
+			// 10 accounts with one tx, and the first account with two txs
+			txs := make([]*types.Transaction, 0)
+			for i := 0; i < 10; i++ {
+				s, err := types.GenerateSigner()
+				ast.Nil(err)
+				if i == 0 {
+					txs = append(txs, constructTxs(s, 2)...)
+				} else {
+					txs = append(txs, constructTx(s, 0))
+				}
+			}
+			pool.AddRemoteTxs(txs)
+			ast.Equal(uint64(11), pool.txStore.priorityNonBatchSize)
+
+			batchedTxs := 0
+			accountsPerBatch := make([]int, 0)
+			for pool.txStore.priorityNonBatchSize > 0 {
+				batch, err := pool.GenerateRequestBatch(commonpool.GenBatchTimeoutEvent)
+				ast.Nil(err)
+				accounts := make(map[string]struct{})
+				for _, tx := range batch.TxList {
+					accounts[tx.RbftGetFrom()] = struct{}{}
+				}
+				accountsPerBatch = append(accountsPerBatch, len(accounts))
+				batchedTxs += len(batch.TxList)
+			}
+			ast.Equal([]int{3, 3, 3, 1}, accountsPerBatch)
+			ast.Equal(11, batchedTxs)
+			ast.Equal(4, len(pool.txStore.batchesCache))
+		})
+	}
+}
+
+func TestTxPoolImpl_EvictByFee(t *testing.T) {
+	testcase := map[string]*txPoolImpl[types.Transaction, *types.Transaction]{
+		"price_priority": mockTxPoolImplWithTyp[types.Transaction, *types.Transaction](t, repo.GenerateBatchByGasPrice),
+		"time":           mockTxPoolImplWithTyp[types.Transaction, *types.Transaction](t, repo.GenerateBatchByTime),
+	}
+
+	for name, tc := range testcase {
+		t.Run(name, func(t *testing.T) {
+			ast := assert.New(t)
+			pool := tc
+			pool.poolMaxSize = 3
+			pool.evictByFee = true
+			err := pool.Start()
+			ast.Nil(err)
+			defer pool.Stop()
+
+			newTx := func(price int64, nonce uint64) *types.Transaction {
+				s, err := types.GenerateSigner()
+				ast.Nil(err)
+				return constructPoolTxByGas(s, nonce, big.NewInt(price)).rawTx
+			}
+			s1, err := types.GenerateSigner()
+			ast.Nil(err)
+			tx10 := constructPoolTxByGas(s1, 0, big.NewInt(1000)).rawTx
+			tx11 := constructPoolTxByGas(s1, 1, big.NewInt(1000)).rawTx
+			tx2 := newTx(2000, 0)
+			pool.AddRemoteTxs([]*types.Transaction{tx10, tx11, tx2})
+			ast.NotNil(pool.GetPendingTxByHash(tx11.RbftGetTxHash()))
+			ast.True(pool.IsPoolFull())
+
+			before := testutil.ToFloat64(evictedByFeeNum)
+			// only the tail tx of account can be evicted
+			tx3 := newTx(1500, 0)
+			pool.AddRemoteTxs([]*types.Transaction{tx3})
+			ast.NotNil(pool.GetPendingTxByHash(tx3.RbftGetTxHash()))
+			ast.Nil(pool.GetPendingTxByHash(tx11.RbftGetTxHash()))
+			ast.NotNil(pool.GetPendingTxByHash(tx10.RbftGetTxHash()))
+			ast.Equal(3, len(pool.txStore.txHashMap))
+			ast.Equal(uint64(3), pool.txStore.priorityNonBatchSize)
+			ast.Equal(before+1, testutil.ToFloat64(evictedByFeeNum))
+
+			// the incoming tx must pay more than the cheapest tx
+			tx4 := newTx(1000, 0)
+			pool.AddRemoteTxs([]*types.Transaction{tx4})
+			ast.Nil(pool.GetPendingTxByHash(tx4.RbftGetTxHash()))
+			ast.Equal(3, len(pool.txStore.txHashMap))
+			ast.Equal(before+1, testutil.ToFloat64(evictedByFeeNum))
+
+			// txs rejected by validation anyway(duplicate, nonce too high) never evict
+			pool.AddRemoteTxs([]*types.Transaction{tx2, newTx(5000, 1000000)})
+			ast.NotNil(pool.GetPendingTxByHash(tx3.RbftGetTxHash()))
+			ast.Equal(3, len(pool.txStore.txHashMap))
+			ast.Equal(before+1, testutil.ToFloat64(evictedByFeeNum))
+			ast.Equal(ErrTxPoolFull, pool.AddLocalTx(newTx(5000, 1000000)))
+			ast.Equal(before+1, testutil.ToFloat64(evictedByFeeNum))
+
+			tx5 := newTx(3000, 0)
+			ast.Nil(pool.AddLocalTx(tx5))
+			ast.Nil(pool.GetPendingTxByHash(tx10.RbftGetTxHash()))
+			ast.Equal(3, len(pool.txStore.txHashMap))
+			ast.Equal(before+2, testutil.ToFloat64(evictedByFeeNum))
+
+			// local txs are never evicted
+			tx6 := newTx(5000, 0)
+			pool.AddRemoteTxs([]*types.Transaction{tx6})
+			ast.NotNil(pool.GetPendingTxByHash(tx6.RbftGetTxHash()))
+			ast.NotNil(pool.GetPendingTxByHash(tx5.RbftGetTxHash()))
+			ast.Nil(pool.GetPendingTxByHash(tx3.RbftGetTxHash()))
+
+			// several txs of a message evict the cheapest txs in order
+			tx7, tx8 := newTx(6000, 0), newTx(7000, 0)
+			pool.AddRemoteTxs([]*types.Transaction{tx7, tx8})
+			ast.NotNil(pool.GetPendingTxByHash(tx7.RbftGetTxHash()))
+			ast.NotNil(pool.GetPendingTxByHash(tx8.RbftGetTxHash()))
+			ast.NotNil(pool.GetPendingTxByHash(tx5.RbftGetTxHash()))
+			ast.Nil(pool.GetPendingTxByHash(tx2.RbftGetTxHash()))
+			ast.Nil(pool.GetPendingTxByHash(tx6.RbftGetTxHash()))
+			ast.Equal(3, len(pool.txStore.txHashMap))
+		})
+	}
+}
+
 func TestTxPoolImpl_GetLocalTxs(t *testing.T) {
 	s, err := types.GenerateSigner()
 	assert.Nil(t, err)
@@ -0,0 +1,159 @@
+package txpool
+
+import (
+	"encoding/binary"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/axiomesh/axiom-kit/types"
+)
+
+// PendingTxsFile is the file next to tx records file, the remote txs are exported into it on stop and imported on start
+const PendingTxsFile = "pending_txs.pb"
+
+var ErrInvalidPendingTxs = errors.New("invalid exported pending txs")
+
+// ExportPending serializes all txs in pool(including remote txs) ordered by account and nonce, the result can be
+// restored by ImportPending after restart, which avoids the re-gossip burst of remote txs after a rolling upgrade.
+func (p *txPoolImpl[T, Constraint]) ExportPending() ([]byte, error) {
+	req := &reqExportPendingMsg{ch: make(chan *respExportPending)}
+	ev := &poolInfoEvent{
+		EventType: reqExportPendingEvent,
+		Event:     req,
+	}
+	p.postEvent(ev)
+	resp := <-req.ch
+	return resp.data, resp.err
+}
+
+func (p *txPoolImpl[T, Constraint]) handleExportPending(includeLocal bool) ([]byte, error) {
+	accounts := make([]string, 0, len(p.txStore.allTxs))
+	for account := range p.txStore.allTxs {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+
+	var data []byte
+	var lengthBytes [TxRecordPrefixLength]byte
+	for _, account := range accounts {
+		list := p.txStore.allTxs[account]
+		nonces := make([]uint64, 0, len(list.items))
+		for nonce := range list.items {
+			nonces = append(nonces, nonce)
+		}
+		sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+		for _, nonce := range nonces {
+			if !includeLocal && list.items[nonce].local {
+				continue
+			}
+			raw, err := Constraint(list.items[nonce].rawTx).RbftMarshal()
+			if err != nil {
+				return nil, errors.Wrapf(err, "marshal tx[account: %s, nonce: %d]", account, nonce)
+			}
+			binary.LittleEndian.PutUint64(lengthBytes[:], uint64(len(raw)))
+			data = append(data, lengthBytes[:]...)
+			data = append(data, raw...)
+		}
+	}
+	return data, nil
+}
+
+// ImportPending restores the txs exported by ExportPending as remote txs, the whole data is rejected if it is
+// malformed or contains different txs with the same nonce of an account. The txs are validated by pool as usual,
+// so the txs committed during the restart are dropped by the nonce check.
+func (p *txPoolImpl[T, Constraint]) ImportPending(data []byte) error {
+	txs, err := decodePendingTxs[T, Constraint](data)
+	if err != nil {
+		return err
+	}
+	if len(txs) == 0 {
+		return nil
+	}
+
+	nonces := make(map[string]map[uint64]string)
+	for _, tx := range txs {
+		account, nonce, hash := Constraint(tx).RbftGetFrom(), Constraint(tx).RbftGetNonce(), Constraint(tx).RbftGetTxHash()
+		if nonces[account] == nil {
+			nonces[account] = make(map[uint64]string)
+		}
+		if existed, ok := nonces[account][nonce]; ok && existed != hash {
+			return errors.Wrapf(ErrInvalidPendingTxs, "duplicate nonce %d of account %s", nonce, account)
+		}
+		nonces[account][nonce] = hash
+	}
+	// txs of an account should be added in nonce order to avoid parking them in pool
+	sort.SliceStable(txs, func(i, j int) bool {
+		fromI, fromJ := Constraint(txs[i]).RbftGetFrom(), Constraint(txs[j]).RbftGetFrom()
+		if fromI != fromJ {
+			return fromI < fromJ
+		}
+		return Constraint(txs[i]).RbftGetNonce() < Constraint(txs[j]).RbftGetNonce()
+	})
+
+	p.AddRemoteTxs(txs)
+	p.logger.Infof("Import %d pending txs into txpool", len(txs))
+	return nil
+}
+
+// exportPendingFile exports the remote txs into pending txs file, local txs are excluded as they are restored from
+// tx records. It is called after the event loop exits, so the pool is read directly.
+func (p *txPoolImpl[T, Constraint]) exportPendingFile() {
+	data, err := p.handleExportPending(false)
+	if err != nil {
+		p.logger.Errorf("Failed to export pending txs: %v", err)
+		return
+	}
+	if len(data) == 0 {
+		return
+	}
+	if err = os.WriteFile(p.pendingTxsFile+".new", data, 0644); err != nil {
+		p.logger.Errorf("Failed to write pending txs file: %v", err)
+		return
+	}
+	if err = os.Rename(p.pendingTxsFile+".new", p.pendingTxsFile); err != nil {
+		p.logger.Errorf("Failed to rename pending txs file: %v", err)
+		return
+	}
+	p.logger.Infof("Export pending txs to %s, size: %d", p.pendingTxsFile, len(data))
+}
+
+// importPendingFile imports the txs exported on the last stop, the file is removed after import
+// so that the txs are not imported again after the next restart.
+func (p *txPoolImpl[T, Constraint]) importPendingFile() {
+	data, err := os.ReadFile(p.pendingTxsFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			p.logger.Errorf("Failed to read pending txs file: %v", err)
+		}
+		return
+	}
+	if err = p.ImportPending(data); err != nil {
+		p.logger.Errorf("Failed to import pending txs: %v", err)
+	}
+	if err = os.Remove(p.pendingTxsFile); err != nil {
+		p.logger.Errorf("Failed to remove pending txs file: %v", err)
+	}
+}
+
+func decodePendingTxs[T any, Constraint types.TXConstraint[T]](data []byte) ([]*T, error) {
+	var txs []*T
+	for offset := 0; offset < len(data); {
+		if len(data)-offset < TxRecordPrefixLength {
+			return nil, errors.Wrapf(ErrInvalidPendingTxs, "truncated length prefix at offset %d", offset)
+		}
+		length := binary.LittleEndian.Uint64(data[offset : offset+TxRecordPrefixLength])
+		offset += TxRecordPrefixLength
+		if length > uint64(len(data)-offset) {
+			return nil, errors.Wrapf(ErrInvalidPendingTxs, "truncated tx at offset %d", offset)
+		}
+		tx := new(T)
+		if err := Constraint(tx).RbftUnmarshal(data[offset : offset+int(length)]); err != nil {
+			return nil, errors.Wrapf(ErrInvalidPendingTxs, "unmarshal tx at offset %d: %v", offset, err)
+		}
+		offset += int(length)
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
@@ -15,12 +15,14 @@ const (
 	HasPendingRequest
 	PoolFull
 	PoolEmpty
+	PoolHighWatermark
 )
 
 const (
 	RemoveTx          timer.TimeoutEvent = "RemoveTx"
 	CleanEmptyAccount timer.TimeoutEvent = "CleanEmptyAccount"
 	RotateTxLocals    timer.TimeoutEvent = "RotateTxLocals"
+	ExpireTx          timer.TimeoutEvent = "ExpireTx"
 )
 
 // nolint
@@ -32,6 +34,12 @@ const (
 	DefaultToleranceRemoveTime    = 15 * time.Minute
 	DefaultCleanEmptyAccountTime  = 10 * time.Minute
 	DefaultRotateTxLocalsInterval = 1 * time.Hour
+	DefaultMaxLoadingRecordTxs    = 100000
+	DefaultHighWatermarkPercent   = 80
+	DefaultLowWatermarkPercent    = 70
+
+	// maxExpireTxCheckInterval is the max interval of checking expired txs
+	maxExpireTxCheckInterval = 1 * time.Minute
 
 	maxChanSize = 1024
 )
@@ -106,6 +114,7 @@ const (
 	committedTxsEvent
 	batchedTxsEvent
 	invalidTxsEvent
+	expiredTxsEvent
 )
 
 var removeTxsEventToStr = map[int]string{
@@ -114,6 +123,7 @@ var removeTxsEventToStr = map[int]string{
 	committedTxsEvent: "committedTxsEvent",
 	batchedTxsEvent:   "batchedTxsEvent",
 	invalidTxsEvent:   "invalidTxsEvent",
+	expiredTxsEvent:   "expiredTxsEvent",
 }
 
 type removeTxsEvent struct {
@@ -242,6 +252,9 @@ const (
 	reqPendingTxCountEvent
 	reqPoolMetaEvent
 	reqAccountMetaEvent
+	reqExportPendingEvent
+	reqKnownTxsEvent
+	reqPendingBatchesEvent
 )
 
 var poolInfoEventToStr = map[int]string{
@@ -250,6 +263,9 @@ var poolInfoEventToStr = map[int]string{
 	reqPendingTxCountEvent: "reqPendingTxCountEvent",
 	reqPoolMetaEvent:       "reqPoolMetaEvent",
 	reqAccountMetaEvent:    "reqAccountMetaEvent",
+	reqExportPendingEvent:  "reqExportPendingEvent",
+	reqKnownTxsEvent:       "reqKnownTxsEvent",
+	reqPendingBatchesEvent: "reqPendingBatchesEvent",
 }
 
 // poolInfoEvent represents poolInfo event sent by local api modules
@@ -263,6 +279,15 @@ type reqTxMsg[T any, Constraint types.TXConstraint[T]] struct {
 	ch   chan *T
 }
 
+type reqKnownTxsMsg struct {
+	hashes []string
+	ch     chan map[string]struct{}
+}
+
+type reqPendingBatchesMsg struct {
+	ch chan map[string]int64
+}
+
 type reqNonceMsg struct {
 	account string
 	ch      chan uint64
@@ -283,6 +308,15 @@ type reqPoolMetaMsg[T any, Constraint types.TXConstraint[T]] struct {
 	ch   chan *common_pool.Meta[T, Constraint]
 }
 
+type reqExportPendingMsg struct {
+	ch chan *respExportPending
+}
+
+type respExportPending struct {
+	data []byte
+	err  error
+}
+
 type reqChainInfoMsg struct {
 	ch chan *common_pool.ChainInfo
 }
@@ -3,29 +3,67 @@ package profile
 import (
 	"fmt"
 	"net/http"
+	"os"
+	"sync"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
 	"github.com/sirupsen/logrus"
 
 	"github.com/axiomesh/axiom-ledger/pkg/loggers"
 	"github.com/axiomesh/axiom-ledger/pkg/repo"
 )
 
+const pushJobName = "axiom-ledger"
+
+// cleanShutdown is set to 1 and pushed to the push gateway right before a clean shutdown, a crashed node leaves it
+// at 0, so that clean restarts and crashes can be distinguished in monitoring. The /metrics endpoint is closed on
+// shutdown, so the marker is only set when a push gateway is configured.
+var cleanShutdown = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: "axiom_ledger",
+	Subsystem: "monitor",
+	Name:      "clean_shutdown",
+	Help:      "1 if the node is shutting down cleanly",
+})
+
+func init() {
+	prometheus.MustRegister(cleanShutdown)
+}
+
 type Monitor struct {
 	enable bool
 	port   int64
 	server *http.Server
 	logger logrus.FieldLogger
+
+	// pusher pushes metrics to the push gateway periodically and once more on stop, nil means disabled
+	pusher       *push.Pusher
+	pushInterval time.Duration
+	stopCh       chan struct{}
+	stopOnce     sync.Once
 }
 
 func NewMonitor(config *repo.Config) (*Monitor, error) {
 	monitor := &Monitor{
-		enable: config.Monitor.Enable,
-		port:   config.Port.Monitor,
-		logger: loggers.Logger(loggers.Profile),
+		enable:       config.Monitor.Enable,
+		port:         config.Port.Monitor,
+		logger:       loggers.Logger(loggers.Profile),
+		pushInterval: config.Monitor.PushInterval.ToDuration(),
+		stopCh:       make(chan struct{}),
 	}
 
 	monitor.init()
+	if config.Monitor.PushGateway != "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, err
+		}
+		monitor.pusher = push.New(config.Monitor.PushGateway, pushJobName).
+			Gatherer(prometheus.DefaultGatherer).
+			Grouping("instance", fmt.Sprintf("%s:%d", hostname, config.Port.Monitor))
+	}
 
 	return monitor, nil
 }
@@ -46,6 +84,7 @@ func (m *Monitor) init() {
 
 // Start start prometheus monitor
 func (m *Monitor) Start() error {
+	cleanShutdown.Set(0)
 	if m.enable {
 		m.logger.WithField("port", m.port).Info("Prepare monitor")
 		go func() {
@@ -55,16 +94,44 @@ func (m *Monitor) Start() error {
 			}
 		}()
 	}
+	if m.pusher != nil && m.pushInterval > 0 {
+		go m.pushLoop()
+	}
 
 	return nil
 }
 
-// Stop start prometheus monitor
-func (m *Monitor) Stop() error {
-	if m.enable {
-		m.logger.WithField("port", m.port).Info("Stop monitor")
-		return m.server.Close()
+func (m *Monitor) pushLoop() {
+	ticker := time.NewTicker(m.pushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			if err := m.pusher.Push(); err != nil {
+				m.logger.Warnf("Push metrics failed, err: %s", err.Error())
+			}
+		}
 	}
+}
 
-	return nil
+// Stop start prometheus monitor, it records the clean shutdown marker and flushes metrics to the push gateway
+// if configured before closing, so that the last values are not lost.
+func (m *Monitor) Stop() error {
+	var err error
+	m.stopOnce.Do(func() {
+		close(m.stopCh)
+		if m.pusher != nil {
+			cleanShutdown.Set(1)
+			if pushErr := m.pusher.Push(); pushErr != nil {
+				m.logger.Errorf("Flush metrics to push gateway failed, err: %s", pushErr.Error())
+			}
+		}
+		if m.enable {
+			m.logger.WithField("port", m.port).Info("Stop monitor")
+			err = m.server.Close()
+		}
+	})
+	return err
 }
@@ -1,14 +1,18 @@
 package repo
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"os"
 	"path"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/axiomesh/axiom-bft/common/consensus"
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
 
 	"github.com/axiomesh/axiom-kit/fileutil"
 	"github.com/axiomesh/axiom-kit/types"
@@ -25,6 +29,7 @@ type SyncArgs struct {
 }
 
 type Config struct {
+	ConfigVersion  uint64         `mapstructure:"config_version" toml:"config_version"`
 	Ulimit         uint64         `mapstructure:"ulimit" toml:"ulimit"`
 	Port           Port           `mapstructure:"port" toml:"port"`
 	Node           Node           `mapstructure:"node" toml:"node"`
@@ -67,15 +72,53 @@ type GasPriceOracle struct {
 
 type JsonRPC struct {
 	GasCap                       uint64     `mapstructure:"gas_cap" toml:"gas_cap"`
+	CallGasBudget                uint64     `mapstructure:"call_gas_budget" toml:"call_gas_budget"`
 	EVMTimeout                   Duration   `mapstructure:"evm_timeout" toml:"evm_timeout"`
 	ReadLimiter                  JLimiter   `mapstructure:"read_limiter" toml:"read_limiter"`
 	WriteLimiter                 JLimiter   `mapstructure:"write_limiter" toml:"write_limiter"`
 	RejectTxsIfConsensusAbnormal bool       `mapstructure:"reject_txs_if_consensus_abnormal" toml:"reject_txs_if_consensus_abnormal"`
 	QueryLimit                   QueryLimit `mapstructure:"query_limit" toml:"query_limit"`
+
+	StorageReadFilter StorageReadFilter `mapstructure:"storage_read_filter" toml:"storage_read_filter"`
+}
+
+// StorageReadFilter restricts the contract storage read by rpc(e.g. eth_getStorageAt, eth_getProof), a prefix is
+// a hex string matched against the 20-byte address followed by the 32-byte storage key
+type StorageReadFilter struct {
+	// AllowPrefixes only permits the matched reads if it is not empty
+	AllowPrefixes []string `mapstructure:"allow_prefixes" toml:"allow_prefixes"`
+	// DenyPrefixes rejects the matched reads, it takes precedence over AllowPrefixes
+	DenyPrefixes []string `mapstructure:"deny_prefixes" toml:"deny_prefixes"`
+}
+
+// Decode returns the prefixes in bytes
+func (f StorageReadFilter) Decode() (allow [][]byte, deny [][]byte, err error) {
+	decode := func(prefixes []string) ([][]byte, error) {
+		res := make([][]byte, 0, len(prefixes))
+		for _, prefix := range prefixes {
+			b, err := hex.DecodeString(strings.TrimPrefix(prefix, "0x"))
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid prefix %s", prefix)
+			}
+			if len(b) == 0 || len(b) > StorageReadKeyLength {
+				return nil, errors.Errorf("prefix %s must be 1 to %d bytes", prefix, StorageReadKeyLength)
+			}
+			res = append(res, b)
+		}
+		return res, nil
+	}
+	if allow, err = decode(f.AllowPrefixes); err != nil {
+		return nil, nil, errors.Wrap(err, "allow_prefixes")
+	}
+	if deny, err = decode(f.DenyPrefixes); err != nil {
+		return nil, nil, errors.Wrap(err, "deny_prefixes")
+	}
+	return allow, deny, nil
 }
 
 type QueryLimit struct {
 	GetLogsBlockRangeLimit uint64 `mapstructure:"get_logs_block_range_limit" toml:"get_logs_block_range_limit"`
+	ResultLimit            uint64 `mapstructure:"result_limit" toml:"result_limit"`
 }
 
 type P2PPipeGossipsub struct {
@@ -110,11 +153,38 @@ type P2P struct {
 	CompressionAlgo        network.CompressionAlgo `mapstructure:"compression_option" toml:"compression_option"`
 	EnableMetrics          bool                    `mapstructure:"enable_metrics" toml:"enable_metrics"`
 	Pipe                   P2PPipe                 `mapstructure:"pipe" toml:"pipe"`
+
+	// Limiter limits the messages received by the network layer, e.g. block sync requests and responses
+	Limiter P2PLimiter `mapstructure:"limiter" toml:"limiter"`
+}
+
+type P2PLimiter struct {
+	Enable bool  `mapstructure:"enable" toml:"enable"`
+	Limit  int64 `mapstructure:"limit" toml:"limit"`
+	Burst  int64 `mapstructure:"burst" toml:"burst"`
+
+	// MsgTypes overrides the limit of specific message types(e.g. SYNC_STATE_REQUEST), keys are case-insensitive,
+	// message types not listed share the global bucket
+	MsgTypes map[string]P2PMsgLimit `mapstructure:"msg_types" toml:"msg_types"`
+
+	// Pipes limits the messages received by specific pipes(e.g. sync_block_pipe_v1_response), which do not go
+	// through the message handlers, pipes not listed are not limited
+	Pipes map[string]P2PMsgLimit `mapstructure:"pipes" toml:"pipes"`
+}
+
+type P2PMsgLimit struct {
+	Limit int64 `mapstructure:"limit" toml:"limit"`
+	Burst int64 `mapstructure:"burst" toml:"burst"`
 }
 
 type Monitor struct {
 	Enable          bool `mapstructure:"enable" toml:"enable"`
 	EnableExpensive bool `mapstructure:"enable_expensive" toml:"enable_expensive"`
+
+	// PushGateway is the url of prometheus push gateway which metrics are pushed to, empty means disabled,
+	// metrics are pushed every PushInterval and flushed once more on shutdown
+	PushGateway  string   `mapstructure:"push_gateway" toml:"push_gateway"`
+	PushInterval Duration `mapstructure:"push_interval" toml:"push_interval"`
 }
 
 type PProf struct {
@@ -188,6 +258,8 @@ type Storage struct {
 	Sync        bool   `mapstructure:"sync" toml:"sync"`
 	KVCacheSize int64  `mapstructure:"kv_cache_size" toml:"kv_cache_size"` // mb
 	Pebble      Pebble `mapstructure:"pebble" toml:"pebble"`
+	// PebbleOverrides overrides the pebble tuning knobs of a component(e.g. ledger, blockchain), zero fields are inherited from Pebble
+	PebbleOverrides map[string]PebbleOverride `mapstructure:"pebble_overrides" toml:"pebble_overrides"`
 }
 
 type Pebble struct {
@@ -196,16 +268,69 @@ type Pebble struct {
 	MemTableStopWritesThreshold int   `mapstructure:"mem_table_stop_writes_threshold" toml:"mem_table_stop_writes_threshold"`
 	LBaseMaxSize                int64 `mapstructure:"lbase_max_size" toml:"lbase_max_size"` //unit mb
 	L0CompactionFileThreshold   int   `mapstructure:"l0_cmpaction_file_threshold" toml:"l0_cmpaction_file_threshold"`
+	// WriteStallDetection counts and logs write stalls, which is an early warning of IO saturation
+	WriteStallDetection bool `mapstructure:"write_stall_detection" toml:"write_stall_detection"`
+	// WALDir puts the write-ahead-log of pebble storages on a separate directory(e.g. a low latency disk),
+	// it must be an absolute path, empty means the WAL is stored with the sst files
+	WALDir string `mapstructure:"wal_dir" toml:"wal_dir"`
+	// WALMinSyncInterval is the minimum duration between WAL syncs, concurrent sync writes within it share one sync,
+	// 0 means every sync write syncs the WAL immediately
+	WALMinSyncInterval Duration `mapstructure:"wal_min_sync_interval" toml:"wal_min_sync_interval"`
+}
+
+type PebbleOverride struct {
+	MaxOpenFiles                int   `mapstructure:"max_open_files" toml:"max_open_files"`
+	MemTableSize                int   `mapstructure:"mem_table_size" toml:"mem_table_size"` // mb
+	MemTableStopWritesThreshold int   `mapstructure:"mem_table_stop_writes_threshold" toml:"mem_table_stop_writes_threshold"`
+	LBaseMaxSize                int64 `mapstructure:"lbase_max_size" toml:"lbase_max_size"` //unit mb
+	L0CompactionFileThreshold   int   `mapstructure:"l0_cmpaction_file_threshold" toml:"l0_cmpaction_file_threshold"`
+}
+
+// Validate checks the override is within the ranges accepted by pebble, zero means not overridden
+func (o PebbleOverride) Validate() error {
+	if o.MaxOpenFiles < 0 {
+		return errors.Errorf("max_open_files cannot be negative: %d", o.MaxOpenFiles)
+	}
+	if o.MemTableSize < 0 || o.MemTableSize >= MaxPebbleMemTableSizeMegabytes {
+		return errors.Errorf("mem_table_size must be in [0, %d): %d", MaxPebbleMemTableSizeMegabytes, o.MemTableSize)
+	}
+	if o.MemTableStopWritesThreshold != 0 && o.MemTableStopWritesThreshold < 2 {
+		return errors.Errorf("mem_table_stop_writes_threshold must be at least 2: %d", o.MemTableStopWritesThreshold)
+	}
+	if o.LBaseMaxSize < 0 {
+		return errors.Errorf("lbase_max_size cannot be negative: %d", o.LBaseMaxSize)
+	}
+	if o.L0CompactionFileThreshold < 0 {
+		return errors.Errorf("l0_cmpaction_file_threshold cannot be negative: %d", o.L0CompactionFileThreshold)
+	}
+	return nil
 }
 
 type Ledger struct {
-	ChainLedgerCacheSize                      int  `mapstructure:"chain_ledger_cache_size" toml:"chain_ledger_cache_size"`
-	StateLedgerAccountTrieCacheMegabytesLimit int  `mapstructure:"state_ledger_account_trie_cache_megabytes_limit" toml:"state_ledger_account_trie_cache_megabytes_limit"`
-	StateLedgerStorageTrieCacheMegabytesLimit int  `mapstructure:"state_ledger_storage_trie_cache_megabytes_limit" toml:"state_ledger_storage_trie_cache_megabytes_limit"`
-	EnablePrune                               bool `mapstructure:"enable_prune" toml:"enable_prune"`
-	EnablePreload                             bool `mapstructure:"enable_preload" toml:"enable_preload"`
-	EnableIndexer                             bool `mapstructure:"enable_indexer" toml:"enable_indexer"`
-	StateLedgerReservedHistoryBlockNum        int  `mapstructure:"state_ledger_reserved_history_block_num" toml:"state_ledger_reserved_history_block_num"`
+	ChainLedgerCacheSize                      int    `mapstructure:"chain_ledger_cache_size" toml:"chain_ledger_cache_size"`
+	StateLedgerAccountTrieCacheMegabytesLimit int    `mapstructure:"state_ledger_account_trie_cache_megabytes_limit" toml:"state_ledger_account_trie_cache_megabytes_limit"`
+	StateLedgerStorageTrieCacheMegabytesLimit int    `mapstructure:"state_ledger_storage_trie_cache_megabytes_limit" toml:"state_ledger_storage_trie_cache_megabytes_limit"`
+	StateLedgerTrieCachePolicy                string `mapstructure:"state_ledger_trie_cache_policy" toml:"state_ledger_trie_cache_policy"`
+	EnablePrune                               bool   `mapstructure:"enable_prune" toml:"enable_prune"`
+	EnablePreload                             bool   `mapstructure:"enable_preload" toml:"enable_preload"`
+	TriePreloadWorkers                        int    `mapstructure:"trie_preload_workers" toml:"trie_preload_workers"`
+	EnableIndexer                             bool   `mapstructure:"enable_indexer" toml:"enable_indexer"`
+	StateLedgerReservedHistoryBlockNum        int    `mapstructure:"state_ledger_reserved_history_block_num" toml:"state_ledger_reserved_history_block_num"`
+	SnapshotBatchSizeMegabytes                int    `mapstructure:"snapshot_batch_size_megabytes" toml:"snapshot_batch_size_megabytes"`
+	MaxConcurrentViews                        int    `mapstructure:"max_concurrent_views" toml:"max_concurrent_views"`
+
+	// TrieVerifyInterval is the interval of verifying the state trie of the latest block in background, 0 means disabled
+	TrieVerifyInterval Duration `mapstructure:"trie_verify_interval" toml:"trie_verify_interval"`
+	// TrieVerifySamples is the number of random root-to-leaf paths checked by each background verification,
+	// 0 means verifying the whole trie
+	TrieVerifySamples int `mapstructure:"trie_verify_samples" toml:"trie_verify_samples"`
+	// TrieVerifyHaltOnFailure stops the node once the background verification fails
+	TrieVerifyHaltOnFailure bool `mapstructure:"trie_verify_halt_on_failure" toml:"trie_verify_halt_on_failure"`
+
+	// SnapshotFailurePolicy decides what to do when snapshot generation still fails after SnapshotGenerationRetries
+	// retries: fatal stops the node, disable continues without snapshot until restart
+	SnapshotFailurePolicy     string `mapstructure:"snapshot_failure_policy" toml:"snapshot_failure_policy"`
+	SnapshotGenerationRetries int    `mapstructure:"snapshot_generation_retries" toml:"snapshot_generation_retries"`
 }
 
 type Snapshot struct {
@@ -218,13 +343,152 @@ type Executor struct {
 	DisableRollback bool   `mapstructure:"disable_rollback" toml:"disable_rollback"`
 }
 
+// ConsensusCapabilities describes what a registered consensus type supports
+type ConsensusCapabilities struct {
+	// Support running with multiple nodes
+	SupportMultiNode bool
+
+	// Support adding or removing consensus nodes at runtime
+	SupportDynamicMembership bool
+
+	// Support byzantine fault tolerance
+	SupportBFT bool
+}
+
 var SupportMultiNode = make(map[string]bool)
+var consensusCapabilities = make(map[string]ConsensusCapabilities)
 var registrationMutex sync.Mutex
 
-func Register(consensusType string, isSupported bool) {
+func Register(consensusType string, capabilities ConsensusCapabilities) {
+	registrationMutex.Lock()
+	defer registrationMutex.Unlock()
+	SupportMultiNode[consensusType] = capabilities.SupportMultiNode
+	consensusCapabilities[consensusType] = capabilities
+}
+
+// GetCapabilities returns the capabilities of the registered consensus type
+func GetCapabilities(consensusType string) (ConsensusCapabilities, bool) {
 	registrationMutex.Lock()
 	defer registrationMutex.Unlock()
-	SupportMultiNode[consensusType] = isSupported
+	capabilities, ok := consensusCapabilities[consensusType]
+	return capabilities, ok
+}
+
+// RegisteredConsensusTypes returns the sorted names of all registered consensus types
+func RegisteredConsensusTypes() []string {
+	registrationMutex.Lock()
+	defer registrationMutex.Unlock()
+	consensusTypes := lo.Keys(consensusCapabilities)
+	sort.Strings(consensusTypes)
+	return consensusTypes
+}
+
+// checkDirWritable creates the dir if it does not exist, and probes whether files can be created in it
+func checkDirWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	probe, err := os.CreateTemp(dir, ".probe")
+	if err != nil {
+		return errors.Wrapf(err, "%s is not writable", dir)
+	}
+	_ = probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// Validate checks the config after all config files are merged
+func (c *Config) Validate() error {
+	ports := map[string]int64{
+		"jsonrpc":   c.Port.JsonRpc,
+		"websocket": c.Port.WebSocket,
+		"p2p":       c.Port.P2P,
+		"pprof":     c.Port.PProf,
+		"monitor":   c.Port.Monitor,
+	}
+	used := make(map[int64]string)
+	for name, port := range ports {
+		if port < 0 || port > 65535 {
+			return errors.Errorf("invalid %s port: %d", name, port)
+		}
+		if port == 0 {
+			continue
+		}
+		if other, ok := used[port]; ok {
+			return errors.Errorf("port %d is used by both %s and %s", port, other, name)
+		}
+		used[port] = name
+	}
+
+	if c.Consensus.Type == "" {
+		return errors.New("consensus.type cannot be empty")
+	}
+
+	if c.JsonRPC.CallGasBudget != 0 && (c.JsonRPC.GasCap == 0 || c.JsonRPC.CallGasBudget < c.JsonRPC.GasCap) {
+		return errors.Errorf("jsonrpc.call_gas_budget must not be less than jsonrpc.gas_cap(0 means no cap): %d < %d", c.JsonRPC.CallGasBudget, c.JsonRPC.GasCap)
+	}
+
+	if _, _, err := c.JsonRPC.StorageReadFilter.Decode(); err != nil {
+		return errors.Wrap(err, "invalid jsonrpc.storage_read_filter")
+	}
+
+	switch c.Storage.KvType {
+	case KVStorageTypeLeveldb, KVStorageTypePebble:
+	default:
+		return errors.Errorf("unsupported storage.kv_type: %s", c.Storage.KvType)
+	}
+
+	if c.Storage.Pebble.WALDir != "" {
+		if !path.IsAbs(c.Storage.Pebble.WALDir) {
+			return errors.Errorf("storage.pebble.wal_dir must be an absolute path: %s", c.Storage.Pebble.WALDir)
+		}
+		if err := checkDirWritable(c.Storage.Pebble.WALDir); err != nil {
+			return errors.Wrap(err, "invalid storage.pebble.wal_dir")
+		}
+	}
+	if c.Storage.Pebble.WALMinSyncInterval < 0 {
+		return errors.Errorf("storage.pebble.wal_min_sync_interval cannot be negative: %s", c.Storage.Pebble.WALMinSyncInterval.ToDuration())
+	}
+
+	for component, override := range c.Storage.PebbleOverrides {
+		if !lo.Contains(PebbleOverrideComponents, component) {
+			return errors.Errorf("unknown component %s in storage.pebble_overrides, available components: %s", component, strings.Join(PebbleOverrideComponents, ", "))
+		}
+		if err := override.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid storage.pebble_overrides.%s", component)
+		}
+	}
+
+	switch c.Ledger.StateLedgerTrieCachePolicy {
+	case CachePolicyFastcache, CachePolicyLRU, CachePolicyLFU:
+	default:
+		return errors.Errorf("unsupported ledger.state_ledger_trie_cache_policy: %s", c.Ledger.StateLedgerTrieCachePolicy)
+	}
+
+	if c.Ledger.TriePreloadWorkers <= 0 {
+		return errors.Errorf("ledger.trie_preload_workers must be positive: %d", c.Ledger.TriePreloadWorkers)
+	}
+
+	if c.Ledger.SnapshotBatchSizeMegabytes < MinSnapshotBatchSizeMegabytes || c.Ledger.SnapshotBatchSizeMegabytes > MaxSnapshotBatchSizeMegabytes {
+		return errors.Errorf("ledger.snapshot_batch_size_megabytes must be in [%d, %d]: %d", MinSnapshotBatchSizeMegabytes, MaxSnapshotBatchSizeMegabytes, c.Ledger.SnapshotBatchSizeMegabytes)
+	}
+
+	if c.Ledger.TrieVerifySamples < 0 {
+		return errors.Errorf("ledger.trie_verify_samples cannot be negative: %d", c.Ledger.TrieVerifySamples)
+	}
+
+	if c.Ledger.MaxConcurrentViews < 0 {
+		return errors.Errorf("ledger.max_concurrent_views cannot be negative: %d", c.Ledger.MaxConcurrentViews)
+	}
+
+	switch c.Ledger.SnapshotFailurePolicy {
+	case SnapshotFailureFatal, SnapshotFailureDisable:
+	default:
+		return errors.Errorf("unsupported ledger.snapshot_failure_policy: %s", c.Ledger.SnapshotFailurePolicy)
+	}
+	if c.Ledger.SnapshotGenerationRetries < 0 {
+		return errors.Errorf("ledger.snapshot_generation_retries cannot be negative: %d", c.Ledger.SnapshotGenerationRetries)
+	}
+	return nil
 }
 
 func (c *Config) Bytes() ([]byte, error) {
@@ -236,6 +500,16 @@ func (c *Config) Bytes() ([]byte, error) {
 	return ret, nil
 }
 
+// EffectiveTOML marshals the live config back to toml, values overridden by config overlays and AXIOM_LEDGER_
+// prefixed env vars are included, so it shows the config actually in effect rather than the config file.
+func (c *Config) EffectiveTOML() ([]byte, error) {
+	raw, err := MarshalConfig(c)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal effective config")
+	}
+	return []byte(raw), nil
+}
+
 func DefaultConfig() *Config {
 	if testNetConfigBuilder, ok := TestNetConfigBuilderMap[BuildNet]; ok {
 		return testNetConfigBuilder()
@@ -245,7 +519,8 @@ func DefaultConfig() *Config {
 
 func defaultConfig() *Config {
 	return &Config{
-		Ulimit: 65535,
+		ConfigVersion: CurrentConfigVersion,
+		Ulimit:        65535,
 		Port: Port{
 			JsonRpc:   8881,
 			WebSocket: 9991,
@@ -266,8 +541,9 @@ func defaultConfig() *Config {
 			IgnorePrice:      types.CoinNumberByMol(2),
 		},
 		JsonRPC: JsonRPC{
-			GasCap:     300000000,
-			EVMTimeout: Duration(5 * time.Second),
+			GasCap:        300000000,
+			CallGasBudget: 0,
+			EVMTimeout:    Duration(5 * time.Second),
 			ReadLimiter: JLimiter{
 				Interval: 50,
 				Quantum:  500,
@@ -283,6 +559,7 @@ func defaultConfig() *Config {
 			RejectTxsIfConsensusAbnormal: false,
 			QueryLimit: QueryLimit{
 				GetLogsBlockRangeLimit: 2000,
+				ResultLimit:            10000,
 			},
 		},
 		P2P: P2P{
@@ -307,6 +584,11 @@ func defaultConfig() *Config {
 				FindPeerTimeout:          Duration(10 * time.Second),
 				ConnectTimeout:           Duration(1 * time.Second),
 			},
+			Limiter: P2PLimiter{
+				Enable: false,
+				Limit:  10000,
+				Burst:  10000,
+			},
 		},
 		Sync: Sync{
 			WaitStatesTimeout:     Duration(30 * time.Second),
@@ -331,16 +613,26 @@ func defaultConfig() *Config {
 				MemTableStopWritesThreshold: 2,
 				LBaseMaxSize:                64,
 				L0CompactionFileThreshold:   500,
+				WriteStallDetection:         true,
 			},
 		},
 		Ledger: Ledger{
 			ChainLedgerCacheSize:                      100,
 			StateLedgerAccountTrieCacheMegabytesLimit: 128,
 			StateLedgerStorageTrieCacheMegabytesLimit: 128,
-			EnablePrune:                        true,
-			EnablePreload:                      false,
-			EnableIndexer:                      false,
-			StateLedgerReservedHistoryBlockNum: 256,
+			StateLedgerTrieCachePolicy:                CachePolicyFastcache,
+			EnablePrune:                               true,
+			EnablePreload:                             false,
+			TriePreloadWorkers:                        1,
+			EnableIndexer:                             false,
+			StateLedgerReservedHistoryBlockNum:        256,
+			SnapshotBatchSizeMegabytes:                64,
+			MaxConcurrentViews:                        0,
+			TrieVerifyInterval:                        0,
+			TrieVerifySamples:                         0,
+			TrieVerifyHaltOnFailure:                   false,
+			SnapshotFailurePolicy:                     SnapshotFailureFatal,
+			SnapshotGenerationRetries:                 0,
 		},
 		Snapshot: Snapshot{
 			AccountSnapshotCacheMegabytesLimit:  128,
@@ -359,6 +651,8 @@ func defaultConfig() *Config {
 		Monitor: Monitor{
 			Enable:          true,
 			EnableExpensive: true,
+			PushGateway:     "",
+			PushInterval:    Duration(15 * time.Second),
 		},
 		Log: Log{
 			Level:            "info",
@@ -391,7 +685,8 @@ func defaultConfig() *Config {
 	}
 }
 
-func LoadConfig(repoRoot string) (*Config, error) {
+// LoadConfig loads config.toml from repoRoot, and then merges overlayPaths in order, later files override earlier.
+func LoadConfig(repoRoot string, overlayPaths ...string) (*Config, error) {
 	cfg, err := func() (*Config, error) {
 		cfg := DefaultConfig()
 		cfgPath := path.Join(repoRoot, CfgFileName)
@@ -409,11 +704,27 @@ func LoadConfig(repoRoot string) (*Config, error) {
 			if err := CheckWritable(repoRoot); err != nil {
 				return nil, err
 			}
-			if err := ReadConfigFromFile(cfgPath, cfg); err != nil {
+			cfgPaths := append([]string{cfgPath}, overlayPaths...)
+			raws := make([][]byte, 0, len(cfgPaths))
+			for _, p := range cfgPaths {
+				raw, err := os.ReadFile(p)
+				if err != nil {
+					return nil, err
+				}
+				raw, err = MigrateConfig(raw)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to migrate config %s", p)
+				}
+				raws = append(raws, raw)
+			}
+			if err := readConfigFromRaw(cfgPaths, raws, cfg); err != nil {
 				return nil, err
 			}
 		}
 
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
 		return cfg, nil
 	}()
 	if err != nil {
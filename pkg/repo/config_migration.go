@@ -0,0 +1,117 @@
+package repo
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pkg/errors"
+)
+
+// CurrentConfigVersion is the schema version of config.toml, bump it and append a migration when the schema changes
+const CurrentConfigVersion uint64 = 1
+
+const configVersionKey = "config_version"
+
+type configMigration struct {
+	// target version after migration
+	version uint64
+	desc    string
+	migrate func(cfg map[string]any) error
+}
+
+// configMigrations must be ordered by version
+var configMigrations = []configMigration{
+	{
+		version: 1,
+		desc:    "introduce config_version",
+		migrate: func(cfg map[string]any) error {
+			return nil
+		},
+	},
+}
+
+// MigrateConfig applies ordered migrations to bring an old config up to the current schema,
+// raw is returned directly if it is already the current version.
+func MigrateConfig(raw []byte) ([]byte, error) {
+	cfg := make(map[string]any)
+	if err := toml.Unmarshal(raw, &cfg); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal config for migration")
+	}
+
+	version := uint64(0)
+	if v, ok := cfg[configVersionKey]; ok {
+		iv, ok := v.(int64)
+		if !ok || iv < 0 {
+			return nil, errors.Errorf("invalid %s: %v", configVersionKey, v)
+		}
+		version = uint64(iv)
+	}
+	if version > CurrentConfigVersion {
+		return nil, errors.Errorf("config version %d is higher than the supported version %d", version, CurrentConfigVersion)
+	}
+	if version == CurrentConfigVersion {
+		return raw, nil
+	}
+
+	for _, m := range configMigrations {
+		if m.version <= version {
+			continue
+		}
+		if err := m.migrate(cfg); err != nil {
+			return nil, errors.Wrapf(err, "failed to migrate config to version %d(%s)", m.version, m.desc)
+		}
+		cfg[configVersionKey] = int64(m.version)
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	e := toml.NewEncoder(buf)
+	e.SetIndentTables(true)
+	e.SetArraysMultiline(true)
+	if err := e.Encode(cfg); err != nil {
+		return nil, errors.Wrap(err, "failed to marshal migrated config")
+	}
+	return buf.Bytes(), nil
+}
+
+// renameConfigKey moves the value of oldKey to newKey, keys are dot separated paths, e.g. "jsonrpc.gas_cap"
+// nolint
+func renameConfigKey(cfg map[string]any, oldKey, newKey string) {
+	oldParent, oldName := configKeyParent(cfg, oldKey, false)
+	if oldParent == nil {
+		return
+	}
+	v, ok := oldParent[oldName]
+	if !ok {
+		return
+	}
+	delete(oldParent, oldName)
+	newParent, newName := configKeyParent(cfg, newKey, true)
+	newParent[newName] = v
+}
+
+// setConfigDefault sets the value of key if it does not exist
+// nolint
+func setConfigDefault(cfg map[string]any, key string, value any) {
+	parent, name := configKeyParent(cfg, key, true)
+	if _, ok := parent[name]; !ok {
+		parent[name] = value
+	}
+}
+
+func configKeyParent(cfg map[string]any, key string, create bool) (map[string]any, string) {
+	paths := strings.Split(key, ".")
+	parent := cfg
+	for _, p := range paths[:len(paths)-1] {
+		next, ok := parent[p].(map[string]any)
+		if !ok {
+			if !create {
+				return nil, ""
+			}
+			next = make(map[string]any)
+			parent[p] = next
+		}
+		parent = next
+	}
+	return parent, paths[len(paths)-1]
+}
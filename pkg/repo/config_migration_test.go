@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateConfig(t *testing.T) {
+	t.Run("migrate legacy config", func(t *testing.T) {
+		raw := []byte("ulimit = 1024\n[jsonrpc]\n  gas_cap = 100\n")
+		migrated, err := MigrateConfig(raw)
+		require.Nil(t, err)
+
+		cfg := make(map[string]any)
+		require.Nil(t, toml.Unmarshal(migrated, &cfg))
+		require.EqualValues(t, CurrentConfigVersion, cfg[configVersionKey])
+		require.EqualValues(t, 1024, cfg["ulimit"])
+		require.EqualValues(t, 100, cfg["jsonrpc"].(map[string]any)["gas_cap"])
+	})
+
+	t.Run("current version config", func(t *testing.T) {
+		raw := []byte("config_version = 1\nulimit = 1024\n")
+		migrated, err := MigrateConfig(raw)
+		require.Nil(t, err)
+		require.Equal(t, raw, migrated)
+	})
+
+	t.Run("higher version config", func(t *testing.T) {
+		_, err := MigrateConfig([]byte("config_version = 100\n"))
+		require.NotNil(t, err)
+	})
+
+	t.Run("load legacy config", func(t *testing.T) {
+		repoPath := t.TempDir()
+		err := os.WriteFile(path.Join(repoPath, CfgFileName), []byte("ulimit = 1024\n"), 0755)
+		require.Nil(t, err)
+		cfg, err := LoadConfig(repoPath)
+		require.Nil(t, err)
+		require.Equal(t, CurrentConfigVersion, cfg.ConfigVersion)
+		require.Equal(t, uint64(1024), cfg.Ulimit)
+	})
+}
+
+func TestConfigKeyHelpers(t *testing.T) {
+	cfg := map[string]any{
+		"jsonrpc": map[string]any{"gas_cap": int64(100)},
+	}
+	renameConfigKey(cfg, "jsonrpc.gas_cap", "jsonrpc.query_limit.gas_cap")
+	require.NotContains(t, cfg["jsonrpc"].(map[string]any), "gas_cap")
+	require.EqualValues(t, 100, cfg["jsonrpc"].(map[string]any)["query_limit"].(map[string]any)["gas_cap"])
+
+	setConfigDefault(cfg, "jsonrpc.query_limit.gas_cap", int64(200))
+	require.EqualValues(t, 100, cfg["jsonrpc"].(map[string]any)["query_limit"].(map[string]any)["gas_cap"])
+	setConfigDefault(cfg, "ulimit", int64(65535))
+	require.EqualValues(t, 65535, cfg["ulimit"])
+}
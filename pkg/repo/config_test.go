@@ -1,8 +1,12 @@
 package repo
 
 import (
+	"os"
 	"path"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -31,3 +35,147 @@ func TestConfig(t *testing.T) {
 	require.Equal(t, true, cnf.JsonRPC.WriteLimiter.Enable)
 	require.Equal(t, true, cnf.JsonRPC.ReadLimiter.Enable)
 }
+
+func TestConfigEffectiveTOML(t *testing.T) {
+	repoPath := t.TempDir()
+	_, err := LoadConfig(repoPath)
+	require.Nil(t, err)
+
+	t.Setenv("AXIOM_LEDGER_PORT_JSONRPC", "18881")
+	cnf, err := LoadConfig(repoPath)
+	require.Nil(t, err)
+	require.Equal(t, int64(18881), cnf.Port.JsonRpc)
+
+	raw, err := cnf.EffectiveTOML()
+	require.Nil(t, err)
+	require.Contains(t, string(raw), "jsonrpc = 18881")
+
+	cnf2 := DefaultConfig()
+	require.Nil(t, readConfigFromRaw([]string{"effective"}, [][]byte{raw}, cnf2))
+	require.Equal(t, cnf.Port, cnf2.Port)
+	require.Equal(t, cnf.Storage.KvType, cnf2.Storage.KvType)
+}
+
+func TestRegisterConsensusCapabilities(t *testing.T) {
+	_, ok := GetCapabilities("capabilities_test")
+	require.False(t, ok)
+
+	Register("capabilities_test", ConsensusCapabilities{SupportMultiNode: true, SupportBFT: true})
+	capabilities, ok := GetCapabilities("capabilities_test")
+	require.True(t, ok)
+	require.True(t, capabilities.SupportMultiNode)
+	require.True(t, capabilities.SupportBFT)
+	require.False(t, capabilities.SupportDynamicMembership)
+	require.True(t, SupportMultiNode["capabilities_test"])
+	require.Contains(t, RegisteredConsensusTypes(), "capabilities_test")
+	require.True(t, sort.StringsAreSorted(RegisteredConsensusTypes()))
+}
+
+func TestLoadConfigWithOverlays(t *testing.T) {
+	repoPath := t.TempDir()
+	_, err := LoadConfig(repoPath)
+	require.Nil(t, err)
+
+	overlay1 := path.Join(repoPath, "config.base.toml")
+	err = os.WriteFile(overlay1, []byte("[port]\n  jsonrpc = 18881\n  websocket = 19991\n"), 0755)
+	require.Nil(t, err)
+	overlay2 := path.Join(repoPath, "config.prod.toml")
+	err = os.WriteFile(overlay2, []byte("[port]\n  jsonrpc = 28881\n"), 0755)
+	require.Nil(t, err)
+
+	cnf, err := LoadConfig(repoPath, overlay1, overlay2)
+	require.Nil(t, err)
+	require.Equal(t, int64(28881), cnf.Port.JsonRpc)
+	require.Equal(t, int64(19991), cnf.Port.WebSocket)
+	require.Equal(t, int64(4001), cnf.Port.P2P)
+
+	conflict := path.Join(repoPath, "config.conflict.toml")
+	err = os.WriteFile(conflict, []byte("[port]\n  p2p = 28881\n"), 0755)
+	require.Nil(t, err)
+	_, err = LoadConfig(repoPath, overlay1, overlay2, conflict)
+	require.NotNil(t, err)
+
+	_, err = LoadConfig(repoPath, path.Join(repoPath, "not_exist.toml"))
+	require.NotNil(t, err)
+}
+
+func TestConfigValidateMaxConcurrentViews(t *testing.T) {
+	cnf := defaultConfig()
+	cnf.Ledger.MaxConcurrentViews = 16
+	require.Nil(t, cnf.Validate())
+	cnf.Ledger.MaxConcurrentViews = -1
+	require.NotNil(t, cnf.Validate())
+}
+
+func TestConfigValidate(t *testing.T) {
+	cnf := defaultConfig()
+	require.Nil(t, cnf.Validate())
+
+	cnf.Storage.KvType = "unknown"
+	require.NotNil(t, cnf.Validate())
+
+	cnf = defaultConfig()
+	cnf.Consensus.Type = ""
+	require.NotNil(t, cnf.Validate())
+
+	cnf = defaultConfig()
+	cnf.Port.Monitor = cnf.Port.JsonRpc
+	require.NotNil(t, cnf.Validate())
+
+	cnf = defaultConfig()
+	cnf.Ledger.TriePreloadWorkers = 0
+	require.NotNil(t, cnf.Validate())
+
+	cnf = defaultConfig()
+	cnf.Ledger.SnapshotBatchSizeMegabytes = 0
+	require.NotNil(t, cnf.Validate())
+	cnf.Ledger.SnapshotBatchSizeMegabytes = MaxSnapshotBatchSizeMegabytes + 1
+	require.NotNil(t, cnf.Validate())
+
+	cnf = defaultConfig()
+	cnf.Storage.Pebble.WALDir = "wal"
+	require.NotNil(t, cnf.Validate())
+	cnf.Storage.Pebble.WALDir = path.Join(t.TempDir(), "wal")
+	require.Nil(t, cnf.Validate())
+	require.DirExists(t, cnf.Storage.Pebble.WALDir)
+	cnf.Storage.Pebble.WALMinSyncInterval = Duration(-time.Second)
+	require.NotNil(t, cnf.Validate())
+
+	cnf = defaultConfig()
+	cnf.Ledger.SnapshotFailurePolicy = SnapshotFailureDisable
+	require.Nil(t, cnf.Validate())
+	cnf.Ledger.SnapshotFailurePolicy = "ignore"
+	require.NotNil(t, cnf.Validate())
+	cnf = defaultConfig()
+	cnf.Ledger.SnapshotGenerationRetries = -1
+	require.NotNil(t, cnf.Validate())
+
+	cnf = defaultConfig()
+	cnf.JsonRPC.StorageReadFilter.DenyPrefixes = []string{"0x1000"}
+	require.Nil(t, cnf.Validate())
+	cnf.JsonRPC.StorageReadFilter.DenyPrefixes = []string{"0xzz"}
+	require.NotNil(t, cnf.Validate())
+	cnf.JsonRPC.StorageReadFilter.DenyPrefixes = nil
+	cnf.JsonRPC.StorageReadFilter.AllowPrefixes = []string{"0x" + strings.Repeat("00", StorageReadKeyLength+1)}
+	require.NotNil(t, cnf.Validate())
+
+	cnf = defaultConfig()
+	cnf.JsonRPC.CallGasBudget = cnf.JsonRPC.GasCap - 1
+	require.NotNil(t, cnf.Validate())
+	cnf.JsonRPC.CallGasBudget = 4 * cnf.JsonRPC.GasCap
+	require.Nil(t, cnf.Validate())
+	cnf.JsonRPC.GasCap = 0
+	require.NotNil(t, cnf.Validate())
+
+	cnf = defaultConfig()
+	cnf.Storage.PebbleOverrides = map[string]PebbleOverride{"ledger": {MemTableSize: 256, L0CompactionFileThreshold: 1000}}
+	require.Nil(t, cnf.Validate())
+	cnf.Storage.PebbleOverrides["ledger"] = PebbleOverride{MemTableSize: MaxPebbleMemTableSizeMegabytes}
+	require.NotNil(t, cnf.Validate())
+	cnf.Storage.PebbleOverrides["ledger"] = PebbleOverride{MemTableStopWritesThreshold: 1}
+	require.NotNil(t, cnf.Validate())
+	cnf.Storage.PebbleOverrides["ledger"] = PebbleOverride{LBaseMaxSize: -1}
+	require.NotNil(t, cnf.Validate())
+	cnf.Storage.PebbleOverrides = map[string]PebbleOverride{"legder": {MemTableSize: 256}}
+	require.NotNil(t, cnf.Validate())
+}
@@ -15,6 +15,13 @@ const (
 	GenerateBatchByGasPrice = "price_priority"
 )
 
+const (
+	// CommitEventOverflowBlock waits for the executor and warns periodically when the commit event buffer is full
+	CommitEventOverflowBlock = "block" // default
+	// CommitEventOverflowFatal stops the node when the commit event buffer is full
+	CommitEventOverflowFatal = "fatal"
+)
+
 type ReceiveMsgLimiter struct {
 	Enable bool  `mapstructure:"enable" toml:"enable"`
 	Limit  int64 `mapstructure:"limit" toml:"limit"`
@@ -28,6 +35,23 @@ type ConsensusConfig struct {
 	TxCache       TxCache           `mapstructure:"tx_cache" toml:"tx_cache"`
 	Rbft          RBFT              `mapstructure:"rbft" toml:"rbft"`
 	Solo          Solo              `mapstructure:"solo" toml:"solo"`
+
+	// BroadcastRetry is the retry policy of broadcasting messages to peers, it is shared by consensus backends
+	BroadcastRetry BroadcastRetry `mapstructure:"broadcast_retry" toml:"broadcast_retry"`
+
+	PreCheck PreCheck `mapstructure:"pre_check" toml:"pre_check"`
+}
+
+type PreCheck struct {
+	// Workers is the number of concurrent workers of each pre-check stage(basic check, signature and balance
+	// verification), 0 means the number of CPUs
+	Workers uint64 `mapstructure:"workers" toml:"workers"`
+}
+
+type BroadcastRetry struct {
+	// Attempts is the max number of broadcast attempts including the first one, 0 or 1 means no retry
+	Attempts uint64   `mapstructure:"attempts" toml:"attempts"`
+	Backoff  Duration `mapstructure:"backoff" toml:"backoff"`
 }
 
 type TimedGenBlock struct {
@@ -38,13 +62,30 @@ type TxPool struct {
 	PoolSize               uint64            `mapstructure:"pool_size" toml:"pool_size"`
 	ToleranceTime          Duration          `mapstructure:"tolerance_time" toml:"tolerance_time"`
 	ToleranceRemoveTime    Duration          `mapstructure:"tolerance_remove_time" toml:"tolerance_remove_time"`
+	TxMaxAge               Duration          `mapstructure:"tx_max_age" toml:"tx_max_age"`
 	CleanEmptyAccountTime  Duration          `mapstructure:"clean_empty_account_time" toml:"clean_empty_account_time"`
 	ToleranceNonceGap      uint64            `mapstructure:"tolerance_nonce_gap" toml:"tolerance_nonce_gap"`
 	EnableLocalsPersist    bool              `mapstructure:"enable_locals_persist" toml:"enable_locals_persist"`
 	RotateTxLocalsInterval Duration          `mapstructure:"rotate_tx_locals_interval" toml:"rotate_tx_locals_interval"`
+	MaxLoadingRecordTxs    uint64            `mapstructure:"max_loading_record_txs" toml:"max_loading_record_txs"`
 	PriceLimit             *types.CoinNumber `mapstructure:"price_limit" toml:"price_limit"`
 	PriceBump              uint64            `mapstructure:"price_bump" toml:"price_bump"`
 	GenerateBatchType      string            `mapstructure:"generate_batch_type" toml:"generate_batch_type"`
+
+	// TxRecordsDir is the directory of local tx records file, empty means the txpool dir under the repo storage path
+	TxRecordsDir string `mapstructure:"tx_records_dir" toml:"tx_records_dir"`
+
+	// HighWatermarkPercent is the percentage of pool_size crossing which warns before the pool is full, 0 means disabled,
+	// it recovers when the number of txs drops below LowWatermarkPercent
+	HighWatermarkPercent uint64 `mapstructure:"high_watermark_percent" toml:"high_watermark_percent"`
+	LowWatermarkPercent  uint64 `mapstructure:"low_watermark_percent" toml:"low_watermark_percent"`
+
+	// MaxAccountsPerBatch caps the number of distinct sender accounts in a batch, txs of the excess accounts
+	// are deferred to the next batch, 0 means unlimited
+	MaxAccountsPerBatch uint64 `mapstructure:"max_accounts_per_batch" toml:"max_accounts_per_batch"`
+
+	// EvictByFee evicts the cheapest remote tx for a higher fee tx when the pool is full, instead of rejecting it
+	EvictByFee bool `mapstructure:"evict_by_fee" toml:"evict_by_fee"`
 }
 
 type TxCache struct {
@@ -56,6 +97,16 @@ type RBFT struct {
 	EnableMetrics             bool        `mapstructure:"enable_metrics" toml:"enable_metrics"`
 	CommittedBlockCacheNumber uint64      `mapstructure:"committed_block_cache_number" toml:"committed_block_cache_number"`
 	Timeout                   RBFTTimeout `mapstructure:"timeout" toml:"timeout"`
+
+	// CommitEventBufferSize is the number of committed blocks waiting for execution
+	CommitEventBufferSize uint64 `mapstructure:"commit_event_buffer_size" toml:"commit_event_buffer_size"`
+	// CommitEventOverflowPolicy is the policy when the commit event buffer is full: block or fatal
+	CommitEventOverflowPolicy string `mapstructure:"commit_event_overflow_policy" toml:"commit_event_overflow_policy"`
+
+	// ProposalTimeout is the max time for a proposal(batch) to be committed before it is reported as timed out, 0 means disabled
+	ProposalTimeout Duration `mapstructure:"proposal_timeout" toml:"proposal_timeout"`
+	// ProposalTimeoutResubmit resubmits the local txs which are not committed within ProposalTimeout and missing from tx pool
+	ProposalTimeoutResubmit bool `mapstructure:"proposal_timeout_resubmit" toml:"proposal_timeout_resubmit"`
 }
 
 type RBFTTimeout struct {
@@ -73,6 +124,27 @@ type RBFTTimeout struct {
 
 type Solo struct {
 	BatchTimeout Duration `mapstructure:"batch_timeout" toml:"batch_timeout"`
+
+	// DedupCacheSize is the max number of recently accepted tx hashes kept for deduplication, 0 means disabled
+	DedupCacheSize uint64   `mapstructure:"dedup_cache_size" toml:"dedup_cache_size"`
+	DedupTTL       Duration `mapstructure:"dedup_ttl" toml:"dedup_ttl"`
+
+	// EventRecordFile records every handled consensus event for debugging if it is not empty, relative to repo root
+	EventRecordFile string `mapstructure:"event_record_file" toml:"event_record_file"`
+
+	// WaitForPeers makes Start block until the number of connected validator peers reaches it, 0 means start immediately
+	WaitForPeers        uint64   `mapstructure:"wait_for_peers" toml:"wait_for_peers"`
+	WaitForPeersTimeout Duration `mapstructure:"wait_for_peers_timeout" toml:"wait_for_peers_timeout"`
+
+	// BatchDigestSweepInterval is the interval of removing batches below the last executed checkpoint, 0 means disabled
+	BatchDigestSweepInterval Duration `mapstructure:"batch_digest_sweep_interval" toml:"batch_digest_sweep_interval"`
+
+	// SlotDuration makes blocks produced at the wall-clock boundaries of fixed-length slots instead of batch timers
+	// if it is greater than 0, the boundaries are aligned to unix time, e.g. every 2s on the dot
+	SlotDuration Duration `mapstructure:"slot_duration" toml:"slot_duration"`
+
+	// RemoveBatchesChunkSize splits removing the batches of a checkpoint from txpool into chunks of it, 0 means no split
+	RemoveBatchesChunkSize uint64 `mapstructure:"remove_batches_chunk_size" toml:"remove_batches_chunk_size"`
 }
 
 func DefaultConsensusConfig() *ConsensusConfig {
@@ -97,13 +169,17 @@ func defaultConsensusConfig() *ConsensusConfig {
 			PoolSize:               50000,
 			ToleranceTime:          Duration(5 * time.Minute),
 			ToleranceRemoveTime:    Duration(15 * time.Minute),
+			TxMaxAge:               0,
 			CleanEmptyAccountTime:  Duration(10 * time.Minute),
 			RotateTxLocalsInterval: Duration(1 * time.Hour),
+			MaxLoadingRecordTxs:    100000,
 			ToleranceNonceGap:      1000,
 			EnableLocalsPersist:    true,
 			PriceLimit:             GetDefaultMinGasPrice(),
 			PriceBump:              10,
 			GenerateBatchType:      GenerateBatchByTime,
+			HighWatermarkPercent:   80,
+			LowWatermarkPercent:    70,
 		},
 		TxCache: TxCache{
 			SetSize:    50,
@@ -124,9 +200,25 @@ func defaultConsensusConfig() *ConsensusConfig {
 				FetchView:        Duration(1 * time.Second),
 				BatchTimeout:     Duration(500 * time.Millisecond),
 			},
+			CommitEventBufferSize:     1024,
+			CommitEventOverflowPolicy: CommitEventOverflowBlock,
+			ProposalTimeout:           Duration(1 * time.Minute),
+			ProposalTimeoutResubmit:   false,
 		},
 		Solo: Solo{
-			BatchTimeout: Duration(500 * time.Millisecond),
+			BatchTimeout:   Duration(500 * time.Millisecond),
+			DedupCacheSize: 10000,
+			DedupTTL:       Duration(1 * time.Minute),
+
+			WaitForPeers:        0,
+			WaitForPeersTimeout: Duration(1 * time.Minute),
+
+			BatchDigestSweepInterval: Duration(1 * time.Minute),
+			RemoveBatchesChunkSize:   1000,
+		},
+		BroadcastRetry: BroadcastRetry{
+			Attempts: 3,
+			Backoff:  Duration(100 * time.Millisecond),
 		},
 	}
 }
@@ -145,6 +237,10 @@ func LoadConsensusConfig(repoRoot string) (*ConsensusConfig, error) {
 				return nil, err
 			}
 		}
+
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
 		return cfg, nil
 	}()
 	if err != nil {
@@ -152,3 +248,12 @@ func LoadConsensusConfig(repoRoot string) (*ConsensusConfig, error) {
 	}
 	return cfg, nil
 }
+
+func (c *ConsensusConfig) Validate() error {
+	switch c.Rbft.CommitEventOverflowPolicy {
+	case CommitEventOverflowBlock, CommitEventOverflowFatal:
+	default:
+		return errors.Errorf("unsupported rbft.commit_event_overflow_policy: %s", c.Rbft.CommitEventOverflowPolicy)
+	}
+	return nil
+}
@@ -21,3 +21,18 @@ func TestLoadWriteConsensusConfig(t *testing.T) {
 	require.Equal(t, uint64(100), cnf2.TxPool.PoolSize)
 	require.Equal(t, Duration(200*time.Millisecond), cnf2.TxCache.SetTimeout)
 }
+
+func TestConsensusConfigValidate(t *testing.T) {
+	cnf := DefaultConsensusConfig()
+	require.Nil(t, cnf.Validate())
+	cnf.Rbft.CommitEventOverflowPolicy = CommitEventOverflowFatal
+	require.Nil(t, cnf.Validate())
+	cnf.Rbft.CommitEventOverflowPolicy = "drop"
+	require.NotNil(t, cnf.Validate())
+
+	repoPath := t.TempDir()
+	err := writeConfigWithEnv(path.Join(repoPath, consensusCfgFileName), cnf)
+	require.Nil(t, err)
+	_, err = LoadConsensusConfig(repoPath)
+	require.NotNil(t, err)
+}
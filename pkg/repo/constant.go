@@ -50,6 +50,24 @@ const (
 	KVStorageCacheSize   = 16
 	KVStorageSync        = true
 
+	CachePolicyFastcache = "fastcache"
+	CachePolicyLRU       = "lru"
+	CachePolicyLFU       = "lfu"
+
+	// SnapshotFailureFatal stops the node when snapshot generation fails
+	SnapshotFailureFatal = "fatal"
+	// SnapshotFailureDisable disables snapshot(reading through the trie) until restart when snapshot generation fails
+	SnapshotFailureDisable = "disable"
+
+	MinSnapshotBatchSizeMegabytes = 1
+	MaxSnapshotBatchSizeMegabytes = 1024
+
+	// MaxPebbleMemTableSizeMegabytes is the exclusive upper bound of pebble memtable size(4GB)
+	MaxPebbleMemTableSizeMegabytes = 4096
+
+	// StorageReadKeyLength is the length of address(20 bytes) followed by storage key(32 bytes) matched by storage read filter
+	StorageReadKeyLength = 52
+
 	P2PSecurityTLS   = "tls"
 	P2PSecurityNoise = "noise"
 
@@ -72,6 +90,10 @@ const (
 	// more expensive to propagate; larger transactions also take more resources
 	// to validate whether they fit into the pool or not.
 	DefaultTxMaxSize = 4 * txSlotSize // 128KB
+
+	// DefaultElasticityMultiplier and DefaultBaseFeeChangeDenominator are the EIP-1559 defaults of fee schedule.
+	DefaultElasticityMultiplier     = 2
+	DefaultBaseFeeChangeDenominator = 8
 )
 
 var (
@@ -79,6 +101,9 @@ var (
 	DefaultAccountBalance = types.CoinNumberByAxc(10000000)   // 10 million AXC
 
 	DefaultMinGasPrice = types.CoinNumberByGmol(1000)
+
+	// PebbleOverrideComponents are the pebble storages accepted by storage.pebble_overrides, named as the storages of storagemgr
+	PebbleOverrideComponents = []string{"blockchain", "ledger", "indexer", "snapshot", "consensus", "epoch", "txpool", "sync", "trie_indexer"}
 )
 
 func GetDefaultMinGasPrice() *types.CoinNumber {
@@ -5,6 +5,8 @@ import (
 	"path"
 	"time"
 
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/pkg/errors"
 
 	"github.com/axiomesh/axiom-kit/fileutil"
@@ -47,6 +49,84 @@ type GenesisConfig struct {
 	EpochInfo          *types.EpochInfo  `mapstructure:"epoch_info" toml:"epoch_info"`
 	Nodes              []GenesisNodeInfo `mapstructure:"nodes" toml:"nodes"`
 	Accounts           []*Account        `mapstructure:"accounts" toml:"accounts"`
+	FeeSchedule        *FeeSchedule      `mapstructure:"fee_schedule" toml:"fee_schedule"`
+
+	// BatchDigestAlgo is the hash function of batch digest(md5, keccak256 or sha256), it is part of consensus,
+	// so it lives in genesis where all validators share the same value, empty means md5
+	BatchDigestAlgo string `mapstructure:"batch_digest_algo" toml:"batch_digest_algo"`
+
+	// Alloc maps address to the account predeployed at genesis, e.g. bridge or governance contracts with initial storage
+	Alloc map[string]*GenesisAccount `mapstructure:"alloc" toml:"alloc"`
+}
+
+const (
+	BatchDigestMD5       = "md5" // default
+	BatchDigestKeccak256 = "keccak256"
+	BatchDigestSha256    = "sha256"
+)
+
+func validateBatchDigestAlgo(algo string) error {
+	switch algo {
+	case "", BatchDigestMD5, BatchDigestKeccak256, BatchDigestSha256:
+		return nil
+	default:
+		return errors.Errorf("unsupported batch_digest_algo %q, expect one of %s, %s, %s",
+			algo, BatchDigestMD5, BatchDigestKeccak256, BatchDigestSha256)
+	}
+}
+
+// CheckBatchDigestAlgo checks the batch digest algorithm is supported by the consensus type. rbft backups verify
+// the digest of every pre-prepare with md5 inside axiom-bft, so only the solo types can use another algorithm.
+func CheckBatchDigestAlgo(consensusType string, algo string) error {
+	if err := validateBatchDigestAlgo(algo); err != nil {
+		return err
+	}
+	if algo == "" || algo == BatchDigestMD5 {
+		return nil
+	}
+	switch consensusType {
+	case ConsensusTypeSolo, ConsensusTypeSoloDev:
+		return nil
+	default:
+		return errors.Errorf("batch_digest_algo %s is not supported by consensus type %s, only %s is supported",
+			algo, consensusType, BatchDigestMD5)
+	}
+}
+
+// FeeSchedule is an EIP-1559 style fee market configuration
+type FeeSchedule struct {
+	// Base fee of the genesis block
+	BaseFee *types.CoinNumber `mapstructure:"base_fee" toml:"base_fee"`
+
+	// Bounds the maximum gas limit an EIP-1559 block may have
+	ElasticityMultiplier uint64 `mapstructure:"elasticity_multiplier" toml:"elasticity_multiplier"`
+
+	// Bounds the amount the base fee can change between blocks
+	BaseFeeChangeDenominator uint64 `mapstructure:"base_fee_change_denominator" toml:"base_fee_change_denominator"`
+}
+
+func DefaultFeeSchedule() *FeeSchedule {
+	return &FeeSchedule{
+		BaseFee:                  types.CoinNumberByMol(0),
+		ElasticityMultiplier:     DefaultElasticityMultiplier,
+		BaseFeeChangeDenominator: DefaultBaseFeeChangeDenominator,
+	}
+}
+
+func (f *FeeSchedule) Validate() error {
+	if f.BaseFee == nil {
+		return errors.New("fee_schedule.base_fee cannot be empty")
+	}
+	if f.BaseFee.ToBigInt().Sign() < 0 {
+		return errors.Errorf("fee_schedule.base_fee cannot be negative: %s", f.BaseFee.String())
+	}
+	if f.ElasticityMultiplier == 0 {
+		return errors.New("fee_schedule.elasticity_multiplier must be greater than 0")
+	}
+	if f.BaseFeeChangeDenominator == 0 {
+		return errors.New("fee_schedule.base_fee_change_denominator must be greater than 0")
+	}
+	return nil
 }
 
 type Token struct {
@@ -67,11 +147,119 @@ type CouncilMember struct {
 	Name    string `mapstructure:"name" toml:"name"`
 }
 
+// validateCouncilMembers checks the genesis council, proposals can never pass if the total weight is 0.
+// An empty council is allowed for the chains without governance.
+func validateCouncilMembers(members []*CouncilMember) error {
+	if len(members) == 0 {
+		return nil
+	}
+	var totalWeight uint64
+	addrs := make(map[string]struct{}, len(members))
+	for i, member := range members {
+		if member == nil {
+			return errors.Errorf("council_members[%d] cannot be empty", i)
+		}
+		if !ethcommon.IsHexAddress(member.Address) {
+			return errors.Errorf("council_members[%d].address is invalid: %s", i, member.Address)
+		}
+		addr := ethcommon.HexToAddress(member.Address).String()
+		if _, ok := addrs[addr]; ok {
+			return errors.Errorf("council_members[%d].address is duplicated: %s", i, member.Address)
+		}
+		addrs[addr] = struct{}{}
+		if totalWeight+member.Weight < totalWeight {
+			return errors.Errorf("council_members total weight overflows at council_members[%d]", i)
+		}
+		totalWeight += member.Weight
+	}
+	if totalWeight == 0 {
+		return errors.Errorf("council_members total weight must be greater than 0, got 0 in %d members", len(members))
+	}
+	return nil
+}
+
 type Account struct {
 	Address string            `mapstructure:"address" toml:"address"`
 	Balance *types.CoinNumber `mapstructure:"balance" toml:"balance"`
 }
 
+// GenesisAccount is the state of an account predeployed at genesis
+type GenesisAccount struct {
+	Balance *types.CoinNumber `mapstructure:"balance" toml:"balance"`
+	// Code is the 0x-prefixed hex of contract runtime code
+	Code string `mapstructure:"code" toml:"code"`
+	// Storage maps 0x-prefixed hex storage key to value, both of them are at most 32 bytes and left padded
+	Storage map[string]string `mapstructure:"storage" toml:"storage"`
+}
+
+// isReservedAddress reports whether addr is in 0x0-0xffff, which is reserved for the zero address, the precompiled
+// contracts and the system contracts(0x1000-0xffff)
+func isReservedAddress(addr ethcommon.Address) bool {
+	for _, b := range addr[:ethcommon.AddressLength-2] {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// validateAlloc checks the predeployed accounts, an account cannot be both in alloc and accounts
+// since the balance would be ambiguous, and the reserved addresses cannot be predeployed.
+func validateAlloc(alloc map[string]*GenesisAccount, accounts []*Account) error {
+	funded := make(map[ethcommon.Address]struct{}, len(accounts))
+	for _, account := range accounts {
+		funded[ethcommon.HexToAddress(account.Address)] = struct{}{}
+	}
+	allocated := make(map[ethcommon.Address]string, len(alloc))
+	for addrStr, account := range alloc {
+		if !ethcommon.IsHexAddress(addrStr) {
+			return errors.Errorf("alloc address is invalid: %s", addrStr)
+		}
+		addr := ethcommon.HexToAddress(addrStr)
+		if isReservedAddress(addr) {
+			return errors.Errorf("alloc address %s is reserved for the zero address, precompiled and system contracts(0x0-0xffff)", addrStr)
+		}
+		if other, ok := allocated[addr]; ok {
+			return errors.Errorf("alloc address is duplicated: %s and %s", other, addrStr)
+		}
+		allocated[addr] = addrStr
+		if _, ok := funded[addr]; ok {
+			return errors.Errorf("alloc address %s is also in accounts", addrStr)
+		}
+		if account == nil {
+			return errors.Errorf("alloc.%s cannot be empty", addrStr)
+		}
+		if account.Balance != nil && account.Balance.ToBigInt().Sign() < 0 {
+			return errors.Errorf("alloc.%s.balance cannot be negative: %s", addrStr, account.Balance.String())
+		}
+		if account.Code != "" {
+			if _, err := hexutil.Decode(account.Code); err != nil {
+				return errors.Wrapf(err, "alloc.%s.code is invalid", addrStr)
+			}
+		}
+		for k, v := range account.Storage {
+			if err := validateStorageWord(k); err != nil {
+				return errors.Wrapf(err, "alloc.%s.storage key %s is invalid", addrStr, k)
+			}
+			if err := validateStorageWord(v); err != nil {
+				return errors.Wrapf(err, "alloc.%s.storage value of %s is invalid", addrStr, k)
+			}
+		}
+	}
+	return nil
+}
+
+func validateStorageWord(s string) error {
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return err
+	}
+	if len(b) > ethcommon.HashLength {
+		return errors.Errorf("longer than %d bytes", ethcommon.HashLength)
+	}
+	return nil
+}
+
 func GenesisEpochInfo() *types.EpochInfo {
 	return &types.EpochInfo{
 		Epoch:       1,
@@ -138,6 +326,8 @@ func defaultGenesisConfig() *GenesisConfig {
 		EpochInfo:          GenesisEpochInfo(),
 		Accounts:           []*Account{},
 		Nodes:              []GenesisNodeInfo{},
+		FeeSchedule:        DefaultFeeSchedule(),
+		BatchDigestAlgo:    BatchDigestMD5,
 	}
 }
 
@@ -164,6 +354,23 @@ func LoadGenesisConfig(repoRoot string) (*GenesisConfig, error) {
 			}
 		}
 
+		// compatible with genesis config without fee schedule
+		if genesis.FeeSchedule == nil {
+			genesis.FeeSchedule = DefaultFeeSchedule()
+		}
+		if err := genesis.FeeSchedule.Validate(); err != nil {
+			return nil, err
+		}
+		if err := validateBatchDigestAlgo(genesis.BatchDigestAlgo); err != nil {
+			return nil, err
+		}
+		if err := validateCouncilMembers(genesis.CouncilMembers); err != nil {
+			return nil, err
+		}
+		if err := validateAlloc(genesis.Alloc, genesis.Accounts); err != nil {
+			return nil, err
+		}
+
 		return genesis, nil
 	}()
 	if err != nil {
@@ -1,10 +1,14 @@
 package repo
 
 import (
+	"math"
 	"path"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/axiomesh/axiom-kit/types"
 )
 
 func TestGenesisConfig(t *testing.T) {
@@ -19,3 +23,204 @@ func TestGenesisConfig(t *testing.T) {
 	require.Nil(t, err)
 	require.Equal(t, uint64(0x9d), cnf2.ChainID)
 }
+
+func TestGenesisFeeSchedule(t *testing.T) {
+	repoPath := t.TempDir()
+	cnf, err := LoadGenesisConfig(repoPath)
+	require.Nil(t, err)
+	require.EqualValues(t, DefaultFeeSchedule(), cnf.FeeSchedule)
+
+	cnf.FeeSchedule.BaseFee = types.CoinNumberByGmol(1)
+	cnf.FeeSchedule.ElasticityMultiplier = 4
+	err = writeConfigWithEnv(path.Join(repoPath, genesisCfgFileName), cnf)
+	require.Nil(t, err)
+	cnf2, err := LoadGenesisConfig(repoPath)
+	require.Nil(t, err)
+	require.Equal(t, types.CoinNumberByGmol(1).String(), cnf2.FeeSchedule.BaseFee.String())
+	require.Equal(t, uint64(4), cnf2.FeeSchedule.ElasticityMultiplier)
+	require.Equal(t, uint64(DefaultBaseFeeChangeDenominator), cnf2.FeeSchedule.BaseFeeChangeDenominator)
+
+	cnf2.FeeSchedule.BaseFeeChangeDenominator = 0
+	err = writeConfigWithEnv(path.Join(repoPath, genesisCfgFileName), cnf2)
+	require.Nil(t, err)
+	_, err = LoadGenesisConfig(repoPath)
+	require.NotNil(t, err)
+}
+
+func TestGenesisBatchDigestAlgo(t *testing.T) {
+	repoPath := t.TempDir()
+	cnf, err := LoadGenesisConfig(repoPath)
+	require.Nil(t, err)
+	require.Equal(t, BatchDigestMD5, cnf.BatchDigestAlgo)
+
+	cnf.BatchDigestAlgo = BatchDigestSha256
+	err = writeConfigWithEnv(path.Join(repoPath, genesisCfgFileName), cnf)
+	require.Nil(t, err)
+	cnf2, err := LoadGenesisConfig(repoPath)
+	require.Nil(t, err)
+	require.Equal(t, BatchDigestSha256, cnf2.BatchDigestAlgo)
+
+	cnf2.BatchDigestAlgo = "sha1"
+	err = writeConfigWithEnv(path.Join(repoPath, genesisCfgFileName), cnf2)
+	require.Nil(t, err)
+	_, err = LoadGenesisConfig(repoPath)
+	require.ErrorContains(t, err, "batch_digest_algo")
+
+	// only solo supports the algorithms other than md5
+	require.Nil(t, CheckBatchDigestAlgo(ConsensusTypeRbft, ""))
+	require.Nil(t, CheckBatchDigestAlgo(ConsensusTypeRbft, BatchDigestMD5))
+	require.NotNil(t, CheckBatchDigestAlgo(ConsensusTypeRbft, BatchDigestSha256))
+	require.Nil(t, CheckBatchDigestAlgo(ConsensusTypeSolo, BatchDigestKeccak256))
+	require.Nil(t, CheckBatchDigestAlgo(ConsensusTypeSoloDev, BatchDigestSha256))
+	require.NotNil(t, CheckBatchDigestAlgo(ConsensusTypeSolo, "sha1"))
+}
+
+func TestGenesisCouncilMembers(t *testing.T) {
+	repoPath := t.TempDir()
+	cnf, err := LoadGenesisConfig(repoPath)
+	require.Nil(t, err)
+	require.Empty(t, cnf.CouncilMembers)
+
+	cnf.CouncilMembers = []*CouncilMember{
+		{Address: "0xc7F999b83Af6DF9e67d0a37Ee7e900bF38b3D013", Weight: 1, Name: "S"},
+		{Address: "0x79a1215469FaB6f9c63c1816b45183AD3624bE34", Weight: 0, Name: "F"},
+	}
+	err = writeConfigWithEnv(path.Join(repoPath, genesisCfgFileName), cnf)
+	require.Nil(t, err)
+	cnf2, err := LoadGenesisConfig(repoPath)
+	require.Nil(t, err)
+	require.Len(t, cnf2.CouncilMembers, 2)
+
+	tests := []struct {
+		name    string
+		members []*CouncilMember
+		errMsg  string
+	}{
+		{
+			name: "invalid address",
+			members: []*CouncilMember{
+				{Address: "0x123", Weight: 1},
+			},
+			errMsg: "council_members[0].address is invalid",
+		},
+		{
+			name: "duplicated address",
+			members: []*CouncilMember{
+				{Address: "0xc7F999b83Af6DF9e67d0a37Ee7e900bF38b3D013", Weight: 1},
+				{Address: "0xc7f999b83af6df9e67d0a37ee7e900bf38b3d013", Weight: 1},
+			},
+			errMsg: "council_members[1].address is duplicated",
+		},
+		{
+			name: "zero total weight",
+			members: []*CouncilMember{
+				{Address: "0xc7F999b83Af6DF9e67d0a37Ee7e900bF38b3D013", Weight: 0},
+				{Address: "0x79a1215469FaB6f9c63c1816b45183AD3624bE34", Weight: 0},
+			},
+			errMsg: "council_members total weight must be greater than 0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cnf2.CouncilMembers = tt.members
+			err = writeConfigWithEnv(path.Join(repoPath, genesisCfgFileName), cnf2)
+			require.Nil(t, err)
+			_, err = LoadGenesisConfig(repoPath)
+			require.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+
+	err = validateCouncilMembers([]*CouncilMember{
+		{Address: "0xc7F999b83Af6DF9e67d0a37Ee7e900bF38b3D013", Weight: math.MaxUint64},
+		{Address: "0x79a1215469FaB6f9c63c1816b45183AD3624bE34", Weight: 1},
+	})
+	require.ErrorContains(t, err, "council_members total weight overflows")
+}
+
+func TestGenesisAlloc(t *testing.T) {
+	repoPath := t.TempDir()
+	cnf, err := LoadGenesisConfig(repoPath)
+	require.Nil(t, err)
+	require.Empty(t, cnf.Alloc)
+
+	cnf.Alloc = map[string]*GenesisAccount{
+		"0xc7F999b83Af6DF9e67d0a37Ee7e900bF38b3D013": {
+			Balance: types.CoinNumberByAxc(1),
+			Code:    "0x6080",
+			Storage: map[string]string{"0x01": "0x02"},
+		},
+	}
+	err = writeConfigWithEnv(path.Join(repoPath, genesisCfgFileName), cnf)
+	require.Nil(t, err)
+	cnf2, err := LoadGenesisConfig(repoPath)
+	require.Nil(t, err)
+	require.Len(t, cnf2.Alloc, 1)
+	for _, account := range cnf2.Alloc {
+		require.Equal(t, types.CoinNumberByAxc(1).String(), account.Balance.String())
+		require.Equal(t, "0x6080", account.Code)
+		require.Equal(t, "0x02", account.Storage["0x01"])
+	}
+
+	addr := "0xc7F999b83Af6DF9e67d0a37Ee7e900bF38b3D013"
+	tests := []struct {
+		name     string
+		alloc    map[string]*GenesisAccount
+		accounts []*Account
+		errMsg   string
+	}{
+		{
+			name:   "invalid address",
+			alloc:  map[string]*GenesisAccount{"0x123": {}},
+			errMsg: "alloc address is invalid",
+		},
+		{
+			name: "duplicated address",
+			alloc: map[string]*GenesisAccount{
+				addr: {},
+				"0xc7f999b83af6df9e67d0a37ee7e900bf38b3d013": {},
+			},
+			errMsg: "alloc address is duplicated",
+		},
+		{
+			name:     "address in accounts",
+			alloc:    map[string]*GenesisAccount{addr: {}},
+			accounts: []*Account{{Address: addr, Balance: types.CoinNumberByAxc(1)}},
+			errMsg:   "is also in accounts",
+		},
+		{
+			name:   "zero address",
+			alloc:  map[string]*GenesisAccount{"0x0000000000000000000000000000000000000000": {}},
+			errMsg: "is reserved",
+		},
+		{
+			name:   "precompiled contract address",
+			alloc:  map[string]*GenesisAccount{"0x0000000000000000000000000000000000000001": {}},
+			errMsg: "is reserved",
+		},
+		{
+			name:   "system contract address",
+			alloc:  map[string]*GenesisAccount{"0x0000000000000000000000000000000000009999": {}},
+			errMsg: "is reserved",
+		},
+		{
+			name:   "invalid code",
+			alloc:  map[string]*GenesisAccount{addr: {Code: "6080"}},
+			errMsg: "code is invalid",
+		},
+		{
+			name:   "invalid storage key",
+			alloc:  map[string]*GenesisAccount{addr: {Storage: map[string]string{"0xzz": "0x01"}}},
+			errMsg: "storage key 0xzz is invalid",
+		},
+		{
+			name:   "too long storage value",
+			alloc:  map[string]*GenesisAccount{addr: {Storage: map[string]string{"0x01": "0x" + strings.Repeat("00", 33)}}},
+			errMsg: "storage value of 0x01 is invalid",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, validateAlloc(tt.alloc, tt.accounts), tt.errMsg)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package repo
+
+import (
+	"fmt"
+	"net"
+)
+
+// PreflightResult is the result of a single startup check
+type PreflightResult struct {
+	Name   string
+	Passed bool
+	// Fatal indicates the node should not start if the check is failed
+	Fatal  bool
+	Detail string
+}
+
+// PreflightCheck validates the repo before starting node, it reports all checks instead of stopping at the first failure,
+// keystore should be read and decrypted before the check.
+func (r *Repo) PreflightCheck() []PreflightResult {
+	var results []PreflightResult
+	results = append(results, r.checkStoragePath())
+	results = append(results, r.checkPorts()...)
+	results = append(results, r.checkKeystore()...)
+	results = append(results, r.checkConsensusType())
+	return results
+}
+
+// PreflightFailed returns whether any fatal check is failed
+func PreflightFailed(results []PreflightResult) bool {
+	for _, res := range results {
+		if res.Fatal && !res.Passed {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Repo) checkStoragePath() PreflightResult {
+	storagePath := GetStoragePath(r.RepoRoot)
+	res := PreflightResult{Name: "storage path", Fatal: true, Detail: storagePath}
+	if err := CheckWritable(storagePath); err != nil {
+		res.Detail = err.Error()
+		return res
+	}
+	res.Passed = true
+	return res
+}
+
+func (r *Repo) checkPorts() []PreflightResult {
+	ports := []struct {
+		name   string
+		port   int64
+		enable bool
+	}{
+		{name: "jsonrpc", port: r.Config.Port.JsonRpc, enable: true},
+		{name: "websocket", port: r.Config.Port.WebSocket, enable: true},
+		{name: "p2p", port: r.Config.Port.P2P, enable: true},
+		{name: "pprof", port: r.Config.Port.PProf, enable: r.Config.PProf.Enable},
+		{name: "monitor", port: r.Config.Port.Monitor, enable: r.Config.Monitor.Enable},
+	}
+
+	var results []PreflightResult
+	for _, p := range ports {
+		if !p.enable {
+			continue
+		}
+		res := PreflightResult{Name: fmt.Sprintf("%s port", p.name), Fatal: true, Detail: fmt.Sprintf("%d", p.port)}
+		l, err := net.Listen("tcp", fmt.Sprintf(":%d", p.port))
+		if err != nil {
+			res.Detail = fmt.Sprintf("port %d is not available: %v", p.port, err)
+		} else {
+			_ = l.Close()
+			res.Passed = true
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+func (r *Repo) checkKeystore() []PreflightResult {
+	consensusRes := PreflightResult{Name: "consensus keystore", Fatal: true}
+	switch {
+	case r.ConsensusKeystore == nil:
+		consensusRes.Detail = "keystore is not loaded"
+	case r.ConsensusKeystore.PrivateKey == nil:
+		consensusRes.Detail = "keystore is not decrypted"
+	default:
+		consensusRes.Passed = true
+		consensusRes.Detail = r.ConsensusKeystore.Path
+	}
+
+	p2pRes := PreflightResult{Name: "p2p keystore", Fatal: true}
+	switch {
+	case r.P2PKeystore == nil:
+		p2pRes.Detail = "keystore is not loaded"
+	case r.P2PKeystore.PrivateKey == nil:
+		p2pRes.Detail = "keystore is not decrypted"
+	default:
+		p2pRes.Passed = true
+		p2pRes.Detail = r.P2PKeystore.Path
+	}
+	return []PreflightResult{consensusRes, p2pRes}
+}
+
+func (r *Repo) checkConsensusType() PreflightResult {
+	res := PreflightResult{Name: "consensus type", Fatal: true, Detail: r.Config.Consensus.Type}
+	if _, ok := GetCapabilities(r.Config.Consensus.Type); !ok {
+		res.Detail = fmt.Sprintf("consensus type %s is not registered, available: %v", r.Config.Consensus.Type, RegisteredConsensusTypes())
+		return res
+	}
+	res.Passed = true
+	return res
+}
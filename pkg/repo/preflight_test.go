@@ -0,0 +1,40 @@
+package repo
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreflightCheck(t *testing.T) {
+	r := MockRepo(t)
+	r.Config.Port.JsonRpc = 0
+	r.Config.Port.WebSocket = 0
+	r.Config.Port.P2P = 0
+	r.Config.Port.PProf = 0
+	r.Config.Port.Monitor = 0
+	r.Config.Consensus.Type = "preflight_test"
+
+	results := r.PreflightCheck()
+	require.True(t, PreflightFailed(results))
+	for _, res := range results {
+		require.Equal(t, res.Name != "consensus type", res.Passed, res.Name)
+	}
+
+	Register("preflight_test", ConsensusCapabilities{})
+	results = r.PreflightCheck()
+	require.False(t, PreflightFailed(results))
+
+	l, err := net.Listen("tcp", ":0")
+	require.Nil(t, err)
+	defer l.Close()
+	r.Config.Port.JsonRpc = int64(l.Addr().(*net.TCPAddr).Port)
+	results = r.PreflightCheck()
+	require.True(t, PreflightFailed(results))
+
+	r.Config.Port.JsonRpc = 0
+	r.P2PKeystore.PrivateKey = nil
+	results = r.PreflightCheck()
+	require.True(t, PreflightFailed(results))
+}
@@ -128,14 +128,15 @@ func Default(repoRoot string) (*Repo, error) {
 	}, nil
 }
 
-// Load config from the repo, which is automatically initialized when the repo is empty
-func Load(repoRoot string) (*Repo, error) {
+// Load config from the repo, which is automatically initialized when the repo is empty,
+// configOverlays are merged into config.toml in order.
+func Load(repoRoot string, configOverlays ...string) (*Repo, error) {
 	repoRoot, err := LoadRepoRootFromEnv(repoRoot)
 	if err != nil {
 		return nil, err
 	}
 
-	cfg, err := LoadConfig(repoRoot)
+	cfg, err := LoadConfig(repoRoot, configOverlays...)
 	if err != nil {
 		return nil, err
 	}
@@ -149,6 +150,9 @@ func Load(repoRoot string) (*Repo, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := CheckBatchDigestAlgo(cfg.Consensus.Type, genesisCfg.BatchDigestAlgo); err != nil {
+		return nil, err
+	}
 
 	repo := &Repo{
 		RepoRoot:        repoRoot,
@@ -184,34 +188,44 @@ func LoadRepoRootFromEnv(repoRoot string) (string, error) {
 
 func ReadConfigFromEnv(config any) error {
 	vp := viper.New()
-	return readConfig(vp, config, false)
+	return readConfig(vp, config)
 }
 
 func ReadConfigFromFile(cfgFilePath string, config any) error {
-	vp := viper.New()
-	vp.SetConfigFile(cfgFilePath)
-	vp.SetConfigType("toml")
-
-	// only check types, viper does not have a strong type checking
 	raw, err := os.ReadFile(cfgFilePath)
 	if err != nil {
 		return err
 	}
-	decoder := toml.NewDecoder(bytes.NewBuffer(raw))
-	checker := reflect.New(reflect.TypeOf(config).Elem())
-	if err := decoder.Decode(checker.Interface()); err != nil {
-		var decodeError *toml.DecodeError
-		if errors.As(err, &decodeError) {
-			return errors.Errorf("check config formater failed from %s:\n%s", cfgFilePath, decodeError.String())
+	return readConfigFromRaw([]string{cfgFilePath}, [][]byte{raw}, config)
+}
+
+// readConfigFromRaw merges raw toml contents in order (later overrides earlier) and reads config from the result,
+// cfgFilePaths are only used for error message
+func readConfigFromRaw(cfgFilePaths []string, raws [][]byte, config any) error {
+	vp := viper.New()
+	vp.SetConfigType("toml")
+
+	for i, raw := range raws {
+		// only check types, viper does not have a strong type checking
+		decoder := toml.NewDecoder(bytes.NewBuffer(raw))
+		checker := reflect.New(reflect.TypeOf(config).Elem())
+		if err := decoder.Decode(checker.Interface()); err != nil {
+			var decodeError *toml.DecodeError
+			if errors.As(err, &decodeError) {
+				return errors.Errorf("check config formater failed from %s:\n%s", cfgFilePaths[i], decodeError.String())
+			}
+
+			return errors.Wrapf(err, "check config formater failed from %s", cfgFilePaths[i])
 		}
 
-		return errors.Wrapf(err, "check config formater failed from %s", cfgFilePath)
+		if err := vp.MergeConfig(bytes.NewBuffer(raw)); err != nil {
+			return errors.Wrapf(err, "failed to merge config from %s", cfgFilePaths[i])
+		}
 	}
-
-	return readConfig(vp, config, true)
+	return readConfig(vp, config)
 }
 
-func readConfig(vp *viper.Viper, config any, fromFile bool) error {
+func readConfig(vp *viper.Viper, config any) error {
 	// not use viper 1.18.2(it not support only read env without file default)
 	vp.AutomaticEnv()
 	envPrefix := "AXIOM_LEDGER"
@@ -225,13 +239,6 @@ func readConfig(vp *viper.Viper, config any, fromFile bool) error {
 	replacer := strings.NewReplacer(".", "_")
 	vp.SetEnvKeyReplacer(replacer)
 
-	if fromFile {
-		err := vp.ReadInConfig()
-		if err != nil {
-			return err
-		}
-	}
-
 	if err := vp.Unmarshal(config, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
 		StringToTimeDurationHookFunc(),
 		StringToCoinNumberHookFunc(),